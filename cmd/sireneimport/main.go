@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"chemin-du-local.bzh/graphql/internal/config"
+	"chemin-du-local.bzh/graphql/internal/database"
+	"chemin-du-local.bzh/graphql/internal/sirene"
+)
+
+// Commande d'administration permettant d'importer un extrait CSV du stock
+// des établissements SIRENE (https://www.data.gouv.fr/fr/datasets/base-sirene-des-entreprises-et-de-leurs-etablissements-siren-siret/)
+// dans la collection utilisée par la requête lookupSiret
+//
+// Utilisation : go run ./cmd/sireneimport -file StockEtablissement.csv
+func main() {
+	filePath := flag.String("file", "", "chemin vers l'extrait CSV SIRENE")
+	configPath := flag.String("config", "config.yml", "chemin vers le fichier de configuration")
+	flag.Parse()
+
+	if *filePath == "" {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	// Initialisation des config
+	if os.Getenv("APP_ENV") == "production" {
+		config.InitFromEnv()
+	} else {
+		config.Init(*configPath)
+	}
+
+	// Initialisation de la base de données
+	shouldDropDb := false
+	database.Init(&shouldDropDb)
+
+	file, err := os.Open(*filePath)
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	defer file.Close()
+
+	sireneService := sirene.NewSireneService()
+	imported, err := sireneService.Import(file)
+
+	if err != nil {
+		log.Fatalf("import interrompu après %d établissements : %v", imported, err)
+	}
+
+	fmt.Printf("%d établissements importés\n", imported)
+}
@@ -1,9 +1,15 @@
 package model
 
 import (
+	"strings"
 	"time"
+	_ "time/tzdata"
 )
 
+// Les horaires des commerces, des marchés et des points de retrait sont
+// donnés à l'heure française
+const BusinessHoursTimezone = "Europe/Paris"
+
 // Ici on utilise un modèle différent de celui généré car on
 // ne veut pas générer une requette pour certaines choses comme
 // le commerçant, les produits ou les commentaires temps
@@ -71,32 +77,86 @@ func (input *NewBusinessHours) ToModel() *BusinessHours {
 	}
 }
 
+// Renvoie les créneaux correspondant au jour de la semaine donné
+func (hours *BusinessHours) ForDay(day time.Weekday) []*Schedule {
+	switch day {
+	case time.Monday:
+		return hours.Monday
+	case time.Tuesday:
+		return hours.Tuesday
+	case time.Wednesday:
+		return hours.Wednesday
+	case time.Thursday:
+		return hours.Thursday
+	case time.Friday:
+		return hours.Friday
+	case time.Saturday:
+		return hours.Saturday
+	default:
+		return hours.Sunday
+	}
+}
+
+// Indique si la date donnée tombe dans l'un des créneaux. La date est
+// d'abord ramenée à l'heure française, quel que soit le fuseau dans
+// lequel le client l'a envoyée. Les horaires sont au format HH:MM, on
+// peut donc les comparer en tant que chaînes.
+func (hours *BusinessHours) IsOpenAt(date time.Time) bool {
+	location, err := time.LoadLocation(BusinessHoursTimezone)
+
+	if err != nil {
+		return false
+	}
+
+	date = date.In(location)
+	hour := date.Format("15:04")
+
+	for _, schedule := range hours.ForDay(date.Weekday()) {
+		if schedule == nil {
+			continue
+		}
+
+		if strings.Compare(hour, schedule.Opening) >= 0 && strings.Compare(hour, schedule.Closing) <= 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
 type Commerce struct {
-	ID                         string        `json:"id"`
-	Siret                      string        `json:"siret"`
-	StorekeeperID              string        `json:"storekeeper"`
-	Name                       string        `json:"name"`
-	Description                string        `json:"description"`
-	StorekeeperWord            string        `json:"storekeeperWord"`
-	Address                    Address       `json:"address"`
-	Latitude                   float64       `json:"latitude"`
-	Longitude                  float64       `json:"longitude"`
-	Phone                      string        `json:"phone"`
-	Email                      string        `json:"email"`
-	IBANOwner                  *string       `json:"ibanOwner"`
-	IBAN                       *string       `json:"iban"`
-	BIC                        *string       `json:"bic"`
-	Facebook                   *string       `json:"facebook"`
-	Twitter                    *string       `json:"twitter"`
-	Instagram                  *string       `json:"instagram"`
-	BusinessHours              BusinessHours `json:"businessHours"`
-	ClickAndCollectHours       BusinessHours `json:"clickAndCollectHours"`
-	Services                   []string      `json:"services"`
-	LastBilledDate             *time.Time    `json:"lastBilledDate"`
-	Balance                    float64       `json:"balance"`
-	DueBalanceClickAndCollectC float64       `json:"dueBalanceClickAndCollectC"`
-	DueBalanceClickAndCollectM float64       `json:"dueBalanceClickAndCollectM"`
-	DueBalancePaniersC         float64       `json:"dueBalancePaniersC"`
-	DueBalancePaniersM         float64       `json:"dueBalancePaniersM"`
-	Transferts                 []Transfert   `json:"transferts"`
+	ID                                  string            `json:"id"`
+	Slug                                string            `json:"slug"`
+	Siret                               string            `json:"siret"`
+	Status                              CommerceStatus    `json:"status"`
+	ClosedAt                            *time.Time        `json:"closedAt"`
+	StorekeeperID                       string            `json:"storekeeper"`
+	Name                                string            `json:"name"`
+	Description                         string            `json:"description"`
+	StorekeeperWord                     string            `json:"storekeeperWord"`
+	Address                             Address           `json:"address"`
+	Latitude                            float64           `json:"latitude"`
+	Longitude                           float64           `json:"longitude"`
+	Phone                               string            `json:"phone"`
+	Email                               string            `json:"email"`
+	IBANOwner                           *string           `json:"ibanOwner"`
+	IBAN                                *string           `json:"iban"`
+	BIC                                 *string           `json:"bic"`
+	Facebook                            *string           `json:"facebook"`
+	Twitter                             *string           `json:"twitter"`
+	Instagram                           *string           `json:"instagram"`
+	BusinessHours                       BusinessHours     `json:"businessHours"`
+	ClickAndCollectHours                BusinessHours     `json:"clickAndCollectHours"`
+	Vacation                            *CommerceVacation `json:"vacation"`
+	IsOnVacation                        bool              `json:"isOnVacation"`
+	Services                            []string          `json:"services"`
+	VariantsAvailableForClickAndCollect []string          `json:"variantsAvailableForClickAndCollect"`
+	LastBilledDate                      *time.Time        `json:"lastBilledDate"`
+	Balance                             float64           `json:"balance"`
+	DueBalanceClickAndCollectC          float64           `json:"dueBalanceClickAndCollectC"`
+	DueBalanceClickAndCollectM          float64           `json:"dueBalanceClickAndCollectM"`
+	DueBalancePaniersC                  float64           `json:"dueBalancePaniersC"`
+	DueBalancePaniersM                  float64           `json:"dueBalancePaniersM"`
+	Transferts                          []Transfert       `json:"transferts"`
+	AcceptsGiftCards                    bool              `json:"acceptsGiftCards"`
 }
@@ -7,4 +7,23 @@ type CommerceCommand struct {
 	PickupDate time.Time `json:"pickupDate"`
 	Status     string    `json:"status"`
 	Price      float64   `json:"price"`
+
+	FulfilmentMode  FulfilmentMode `json:"fulfilmentMode"`
+	DeliveryAddress *Address       `json:"deliveryAddress"`
+	DeliveryFee     float64        `json:"deliveryFee"`
+	PickupMarketID  *string        `json:"pickupMarket"`
+	PickupPointID   *string        `json:"pickupPoint"`
+
+	Discount   float64             `json:"discount"`
+	Promotions []*AppliedPromotion `json:"promotions"`
+
+	PromoCode         *string           `json:"promoCode"`
+	PromoCodeDiscount float64           `json:"promoCodeDiscount"`
+	PromoCodeFundedBy *PromoCodeFunding `json:"promoCodeFundedBy"`
+
+	GiftCardCode   *string `json:"giftCardCode"`
+	GiftCardAmount float64 `json:"giftCardAmount"`
+
+	WeightAdjustment  float64 `json:"weightAdjustment"`
+	IsAwaitingWeights bool    `json:"isAwaitingWeights"`
 }
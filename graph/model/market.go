@@ -0,0 +1,12 @@
+package model
+
+type Market struct {
+	ID          string        `json:"id"`
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Address     Address       `json:"address"`
+	Latitude    float64       `json:"latitude"`
+	Longitude   float64       `json:"longitude"`
+	Schedule    BusinessHours `json:"schedule"`
+	CommercesID []string      `json:"commerces"`
+}
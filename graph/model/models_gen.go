@@ -11,13 +11,29 @@ import (
 	"github.com/99designs/gqlgen/graphql"
 )
 
+type ActualWeightInput struct {
+	CcProductID    string  `json:"ccProductID"`
+	ActualQuantity float64 `json:"actualQuantity"`
+}
+
 type Address struct {
-	ID            string  `json:"id"`
-	Number        *string `json:"number"`
-	Route         *string `json:"route"`
-	OptionalRoute *string `json:"optionalRoute"`
-	PostalCode    *string `json:"postalCode"`
-	City          *string `json:"city"`
+	ID            string   `json:"id"`
+	Number        *string  `json:"number"`
+	Route         *string  `json:"route"`
+	OptionalRoute *string  `json:"optionalRoute"`
+	PostalCode    *string  `json:"postalCode"`
+	City          *string  `json:"city"`
+	Latitude      *float64 `json:"latitude"`
+	Longitude     *float64 `json:"longitude"`
+}
+
+type AppliedPromotion struct {
+	PromotionID string  `json:"promotionID"`
+	Name        string  `json:"name"`
+	ProductID   *string `json:"productID"`
+	VariantID   *string `json:"variantID"`
+	PanierID    *string `json:"panierID"`
+	Discount    float64 `json:"discount"`
 }
 
 type Basket struct {
@@ -35,6 +51,25 @@ type BasketProduct struct {
 	Product  *Product `json:"product"`
 }
 
+type BooleanFacetValue struct {
+	Value bool `json:"value"`
+	Count int  `json:"count"`
+}
+
+type BoundingBox struct {
+	West  float64 `json:"west"`
+	South float64 `json:"south"`
+	East  float64 `json:"east"`
+	North float64 `json:"north"`
+}
+
+type Bounds struct {
+	West  float64 `json:"west"`
+	South float64 `json:"south"`
+	East  float64 `json:"east"`
+	North float64 `json:"north"`
+}
+
 type BulkChangesProduct struct {
 	ID      string                 `json:"id"`
 	Changes map[string]interface{} `json:"changes"`
@@ -51,16 +86,56 @@ type BusinessHours struct {
 }
 
 type CCProduct struct {
-	Quantity int      `json:"quantity"`
-	Product  *Product `json:"product"`
+	ID             string            `json:"id"`
+	Quantity       float64           `json:"quantity"`
+	Product        *Product          `json:"product"`
+	Variant        *ProductVariant   `json:"variant"`
+	Name           string            `json:"name"`
+	VariantName    *string           `json:"variantName"`
+	Price          float64           `json:"price"`
+	Unit           string            `json:"unit"`
+	Tva            float64           `json:"tva"`
+	Discount       float64           `json:"discount"`
+	Promotion      *AppliedPromotion `json:"promotion"`
+	IsWeighed      bool              `json:"isWeighed"`
+	ActualQuantity *float64          `json:"actualQuantity"`
+}
+
+type CatalogueExport struct {
+	FileName    string `json:"fileName"`
+	ContentType string `json:"contentType"`
+	Data        string `json:"data"`
+}
+
+type Category struct {
+	ID          string  `json:"id"`
+	CommerceID  string  `json:"commerceID"`
+	Name        string  `json:"name"`
+	Position    int     `json:"position"`
+	Description *string `json:"description"`
+	HasImage    bool    `json:"hasImage"`
 }
 
 type ChangesAddress struct {
-	Number        *string `json:"number"`
-	Route         *string `json:"route"`
-	OptionalRoute *string `json:"optionalRoute"`
-	PostalCode    *string `json:"postalCode"`
-	City          *string `json:"city"`
+	Number        *string  `json:"number"`
+	Route         *string  `json:"route"`
+	OptionalRoute *string  `json:"optionalRoute"`
+	PostalCode    *string  `json:"postalCode"`
+	City          *string  `json:"city"`
+	Latitude      *float64 `json:"latitude"`
+	Longitude     *float64 `json:"longitude"`
+}
+
+type ChangesCategory struct {
+	Description *string         `json:"description"`
+	Image       *graphql.Upload `json:"image"`
+}
+
+type ChangesProductVariant struct {
+	Name            *string  `json:"name"`
+	Price           *float64 `json:"price"`
+	PerUnitQuantity *float64 `json:"perUnitQuantity"`
+	Sku             *string  `json:"sku"`
 }
 
 type ChangesRegistedPaymentMethod struct {
@@ -94,6 +169,13 @@ type CommandsFilter struct {
 	Status []string `json:"status"`
 }
 
+type CommerceCluster struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Count     int     `json:"count"`
+	Bounds    *Bounds `json:"bounds"`
+}
+
 type CommerceCommandConnection struct {
 	Edges    []*CommerceCommandEdge   `json:"edges"`
 	PageInfo *CommerceCommandPageInfo `json:"pageInfo"`
@@ -129,9 +211,10 @@ type CommerceEdge struct {
 }
 
 type CommerceFilter struct {
-	NearLatitude  *float64 `json:"nearLatitude"`
-	NearLongitude *float64 `json:"nearLongitude"`
-	Radius        *float64 `json:"radius"`
+	NearLatitude  *float64         `json:"nearLatitude"`
+	NearLongitude *float64         `json:"nearLongitude"`
+	Radius        *float64         `json:"radius"`
+	Status        []CommerceStatus `json:"status"`
 }
 
 type CommercePageInfo struct {
@@ -140,38 +223,130 @@ type CommercePageInfo struct {
 	HasNextPage bool   `json:"hasNextPage"`
 }
 
+type CommerceStatistics struct {
+	Revenue            float64              `json:"revenue"`
+	OrderCount         int                  `json:"orderCount"`
+	AverageBasket      float64              `json:"averageBasket"`
+	TopProducts        []*ProductStatistics `json:"topProducts"`
+	TopPaniers         []*PanierStatistics  `json:"topPaniers"`
+	NewCustomers       int                  `json:"newCustomers"`
+	ReturningCustomers int                  `json:"returningCustomers"`
+	TimeSeries         []*StatisticsPoint   `json:"timeSeries"`
+}
+
+type CommerceVacation struct {
+	StartDate      time.Time `json:"startDate"`
+	EndDate        time.Time `json:"endDate"`
+	Message        string    `json:"message"`
+	ProrateBilling bool      `json:"prorateBilling"`
+}
+
+type CommercesInBounds struct {
+	Commerces []*Commerce        `json:"commerces"`
+	Clusters  []*CommerceCluster `json:"clusters"`
+}
+
+type DeliveryZone struct {
+	ID            string           `json:"id"`
+	Name          string           `json:"name"`
+	Type          DeliveryZoneType `json:"type"`
+	Polygon       [][]float64      `json:"polygon"`
+	Latitude      *float64         `json:"latitude"`
+	Longitude     *float64         `json:"longitude"`
+	Radius        *float64         `json:"radius"`
+	Fee           float64          `json:"fee"`
+	MinimumOrder  float64          `json:"minimumOrder"`
+	DeliveryHours *BusinessHours   `json:"deliveryHours"`
+}
+
+type FacetValue struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
 type Filter struct {
 	Key   string `json:"key"`
 	Value string `json:"value"`
 }
 
+type GeoJSONExport struct {
+	Data string `json:"data"`
+	Etag string `json:"etag"`
+}
+
+type GiftCard struct {
+	ID             string                 `json:"id"`
+	Code           string                 `json:"code"`
+	RecipientName  *string                `json:"recipientName"`
+	RecipientEmail *string                `json:"recipientEmail"`
+	Message        *string                `json:"message"`
+	InitialAmount  float64                `json:"initialAmount"`
+	Balance        float64                `json:"balance"`
+	Status         GiftCardStatus         `json:"status"`
+	IsUsable       bool                   `json:"isUsable"`
+	CreationDate   time.Time              `json:"creationDate"`
+	ExpiryDate     time.Time              `json:"expiryDate"`
+	Transactions   []*GiftCardTransaction `json:"transactions"`
+}
+
+type GiftCardTransaction struct {
+	Type              GiftCardTransactionType `json:"type"`
+	Amount            float64                 `json:"amount"`
+	CommerceID        *string                 `json:"commerceID"`
+	CommerceCommandID *string                 `json:"commerceCommandID"`
+	Date              time.Time               `json:"date"`
+}
+
 type Login struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
 }
 
+type MetaTag struct {
+	Property string `json:"property"`
+	Content  string `json:"content"`
+}
+
 type NewAddress struct {
-	Number        *string `json:"number"`
-	Route         *string `json:"route"`
-	OptionalRoute *string `json:"optionalRoute"`
-	PostalCode    *string `json:"postalCode"`
-	City          *string `json:"city"`
+	Number        *string  `json:"number"`
+	Route         *string  `json:"route"`
+	OptionalRoute *string  `json:"optionalRoute"`
+	PostalCode    *string  `json:"postalCode"`
+	City          *string  `json:"city"`
+	Latitude      *float64 `json:"latitude"`
+	Longitude     *float64 `json:"longitude"`
+}
+
+type NewAppliedPromotion struct {
+	PromotionID string  `json:"promotionID"`
+	Name        string  `json:"name"`
+	ProductID   *string `json:"productID"`
+	VariantID   *string `json:"variantID"`
+	PanierID    *string `json:"panierID"`
+	Discount    float64 `json:"discount"`
 }
 
 type NewBasket struct {
-	Commerces []*NewBasketCommerce `json:"commerces"`
+	Commerces     []*NewBasketCommerce `json:"commerces"`
+	PickupPointID *string              `json:"pickupPointID"`
+	PromoCode     *string              `json:"promoCode"`
+	GiftCardCode  *string              `json:"giftCardCode"`
 }
 
 type NewBasketCommerce struct {
-	CommerceID string              `json:"commerceID"`
-	Products   []*NewBasketProduct `json:"products"`
-	Paniers    []string            `json:"paniers"`
-	PickupDate *time.Time          `json:"pickupDate"`
+	CommerceID        string              `json:"commerceID"`
+	Products          []*NewBasketProduct `json:"products"`
+	Paniers           []string            `json:"paniers"`
+	PickupDate        *time.Time          `json:"pickupDate"`
+	FulfilmentMode    *FulfilmentMode     `json:"fulfilmentMode"`
+	DeliveryAddressID *string             `json:"deliveryAddressID"`
+	PickupMarketID    *string             `json:"pickupMarketID"`
 }
 
 type NewBasketProduct struct {
 	Quantity  float64 `json:"quantity"`
 	ProductID string  `json:"productID"`
+	VariantID *string `json:"variantID"`
 }
 
 type NewBusinessHours struct {
@@ -190,8 +365,24 @@ type NewCCCommand struct {
 }
 
 type NewCCProcuct struct {
-	Quantity  int    `json:"quantity"`
-	ProductID string `json:"productID"`
+	Quantity    float64              `json:"quantity"`
+	ProductID   string               `json:"productID"`
+	VariantID   *string              `json:"variantID"`
+	UnitPrice   *float64             `json:"unitPrice"`
+	IsWeighed   *bool                `json:"isWeighed"`
+	Name        *string              `json:"name"`
+	VariantName *string              `json:"variantName"`
+	Price       *float64             `json:"price"`
+	Unit        *string              `json:"unit"`
+	Tva         *float64             `json:"tva"`
+	Discount    *float64             `json:"discount"`
+	Promotion   *NewAppliedPromotion `json:"promotion"`
+}
+
+type NewCategory struct {
+	Name        string          `json:"name"`
+	Description *string         `json:"description"`
+	Image       *graphql.Upload `json:"image"`
 }
 
 type NewCommand struct {
@@ -219,12 +410,61 @@ type NewCommerce struct {
 }
 
 type NewCommerceCommand struct {
-	CommerceID           string    `json:"commerceID"`
-	PickupDate           time.Time `json:"pickupDate"`
-	PaymentMethod        string    `json:"paymentMethod"`
-	Price                int       `json:"price"`
-	PriceClickAndCollect float64   `json:"priceClickAndCollect"`
-	PricePaniers         float64   `json:"pricePaniers"`
+	CommerceID           string                 `json:"commerceID"`
+	PickupDate           time.Time              `json:"pickupDate"`
+	PaymentMethod        string                 `json:"paymentMethod"`
+	Price                int                    `json:"price"`
+	PriceClickAndCollect float64                `json:"priceClickAndCollect"`
+	PricePaniers         float64                `json:"pricePaniers"`
+	FulfilmentMode       *FulfilmentMode        `json:"fulfilmentMode"`
+	DeliveryAddress      *NewAddress            `json:"deliveryAddress"`
+	DeliveryFee          *float64               `json:"deliveryFee"`
+	PickupMarketID       *string                `json:"pickupMarketID"`
+	PickupPointID        *string                `json:"pickupPointID"`
+	Discount             *float64               `json:"discount"`
+	Promotions           []*NewAppliedPromotion `json:"promotions"`
+	PromoCode            *string                `json:"promoCode"`
+	PromoCodeDiscount    *float64               `json:"promoCodeDiscount"`
+	PromoCodeFundedBy    *PromoCodeFunding      `json:"promoCodeFundedBy"`
+	GiftCardID           *string                `json:"giftCardID"`
+	GiftCardCode         *string                `json:"giftCardCode"`
+	GiftCardAmount       *float64               `json:"giftCardAmount"`
+	WeighedPrice         *float64               `json:"weighedPrice"`
+}
+
+type NewCommerceVacation struct {
+	StartDate      time.Time `json:"startDate"`
+	EndDate        time.Time `json:"endDate"`
+	Message        *string   `json:"message"`
+	ProrateBilling *bool     `json:"prorateBilling"`
+}
+
+type NewDeliveryZone struct {
+	Name          string            `json:"name"`
+	Type          DeliveryZoneType  `json:"type"`
+	Polygon       [][]float64       `json:"polygon"`
+	Latitude      *float64          `json:"latitude"`
+	Longitude     *float64          `json:"longitude"`
+	Radius        *float64          `json:"radius"`
+	Fee           float64           `json:"fee"`
+	MinimumOrder  *float64          `json:"minimumOrder"`
+	DeliveryHours *NewBusinessHours `json:"deliveryHours"`
+}
+
+type NewGiftCard struct {
+	Amount         float64 `json:"amount"`
+	RecipientName  *string `json:"recipientName"`
+	RecipientEmail *string `json:"recipientEmail"`
+	Message        *string `json:"message"`
+}
+
+type NewMarket struct {
+	Name        string            `json:"name"`
+	Description *string           `json:"description"`
+	Address     *NewAddress       `json:"address"`
+	Latitude    float64           `json:"latitude"`
+	Longitude   float64           `json:"longitude"`
+	Schedule    *NewBusinessHours `json:"schedule"`
 }
 
 type NewPanier struct {
@@ -241,8 +481,12 @@ type NewPanier struct {
 }
 
 type NewPanierCommand struct {
-	PanierID   string    `json:"panierID"`
-	PickupDate time.Time `json:"pickupDate"`
+	PanierID   string               `json:"panierID"`
+	PickupDate time.Time            `json:"pickupDate"`
+	Name       *string              `json:"name"`
+	Price      *float64             `json:"price"`
+	Discount   *float64             `json:"discount"`
+	Promotion  *NewAppliedPromotion `json:"promotion"`
 }
 
 type NewPanierProduct struct {
@@ -250,20 +494,77 @@ type NewPanierProduct struct {
 	ProductID string `json:"productID"`
 }
 
+type NewPickupPoint struct {
+	Name         string            `json:"name"`
+	Address      *NewAddress       `json:"address"`
+	Latitude     float64           `json:"latitude"`
+	Longitude    float64           `json:"longitude"`
+	OpeningHours *NewBusinessHours `json:"openingHours"`
+	ManagerID    *string           `json:"managerID"`
+}
+
 type NewProduct struct {
-	Name                string          `json:"name"`
-	Description         string          `json:"description"`
-	Price               float64         `json:"price"`
-	Unit                string          `json:"unit"`
-	PerUnitQuantity     float64         `json:"perUnitQuantity"`
-	PerUnitQuantityUnit string          `json:"perUnitQuantityUnit"`
-	Tva                 float64         `json:"tva"`
-	IsBreton            bool            `json:"isBreton"`
-	HasGluten           bool            `json:"hasGluten"`
-	Tags                []string        `json:"tags"`
-	Allergens           []string        `json:"allergens"`
-	Categories          []string        `json:"categories"`
-	Image               *graphql.Upload `json:"image"`
+	ExternalRef         *string               `json:"externalRef"`
+	Name                string                `json:"name"`
+	Description         string                `json:"description"`
+	Price               float64               `json:"price"`
+	Unit                string                `json:"unit"`
+	PerUnitQuantity     float64               `json:"perUnitQuantity"`
+	PerUnitQuantityUnit string                `json:"perUnitQuantityUnit"`
+	Tva                 float64               `json:"tva"`
+	IsBreton            bool                  `json:"isBreton"`
+	HasGluten           bool                  `json:"hasGluten"`
+	IsWeighed           *bool                 `json:"isWeighed"`
+	Tags                []string              `json:"tags"`
+	Allergens           []string              `json:"allergens"`
+	CategoryIDs         []string              `json:"categoryIDs"`
+	Categories          []string              `json:"categories"`
+	Variants            []*NewProductVariant  `json:"variants"`
+	Status              *ProductStatus        `json:"status"`
+	PublishAt           *time.Time            `json:"publishAt"`
+	UnpublishAt         *time.Time            `json:"unpublishAt"`
+	Seasons             []*ProductSeasonInput `json:"seasons"`
+	Image               *graphql.Upload       `json:"image"`
+}
+
+type NewProductVariant struct {
+	Name            string  `json:"name"`
+	Price           float64 `json:"price"`
+	PerUnitQuantity float64 `json:"perUnitQuantity"`
+	Sku             *string `json:"sku"`
+}
+
+type NewPromoCode struct {
+	Code           string            `json:"code"`
+	Type           PromoCodeType     `json:"type"`
+	Value          float64           `json:"value"`
+	MinimumBasket  *float64          `json:"minimumBasket"`
+	StartDate      time.Time         `json:"startDate"`
+	EndDate        time.Time         `json:"endDate"`
+	MaxUses        *int              `json:"maxUses"`
+	MaxUsesPerUser *int              `json:"maxUsesPerUser"`
+	FirstOrderOnly *bool             `json:"firstOrderOnly"`
+	FundedBy       *PromoCodeFunding `json:"fundedBy"`
+}
+
+type NewPromotion struct {
+	Name           string              `json:"name"`
+	Type           PromotionType       `json:"type"`
+	Target         PromotionTarget     `json:"target"`
+	ProductID      *string             `json:"productID"`
+	Category       *string             `json:"category"`
+	PanierID       *string             `json:"panierID"`
+	Value          *float64            `json:"value"`
+	BuyQuantity    *int                `json:"buyQuantity"`
+	FreeQuantity   *int                `json:"freeQuantity"`
+	QuantityBreaks []*NewQuantityBreak `json:"quantityBreaks"`
+	StartDate      time.Time           `json:"startDate"`
+	EndDate        time.Time           `json:"endDate"`
+}
+
+type NewQuantityBreak struct {
+	MinQuantity int     `json:"minQuantity"`
+	Percentage  float64 `json:"percentage"`
 }
 
 type NewUser struct {
@@ -303,20 +604,21 @@ type PanierProduct struct {
 	Product  *Product `json:"product"`
 }
 
-type Product struct {
-	ID                  string   `json:"id"`
-	Name                string   `json:"name"`
-	Description         string   `json:"description"`
-	Price               float64  `json:"price"`
-	Unit                string   `json:"unit"`
-	PerUnitQuantity     float64  `json:"perUnitQuantity"`
-	PerUnitQuantityUnit string   `json:"perUnitQuantityUnit"`
-	Tva                 float64  `json:"tva"`
-	IsBreton            bool     `json:"isBreton"`
-	HasGluten           bool     `json:"hasGluten"`
-	Tags                []string `json:"tags"`
-	Allergens           []string `json:"allergens"`
-	Categories          []string `json:"categories"`
+type PanierStatistics struct {
+	Panier   *Panier `json:"panier"`
+	Quantity int     `json:"quantity"`
+	Revenue  float64 `json:"revenue"`
+}
+
+type PriceFacetValue struct {
+	Min   float64  `json:"min"`
+	Max   *float64 `json:"max"`
+	Count int      `json:"count"`
+}
+
+type ProductColumnMapping struct {
+	Column string                `json:"column"`
+	Field  ProductCatalogueField `json:"field"`
 }
 
 type ProductConnection struct {
@@ -330,7 +632,23 @@ type ProductEdge struct {
 }
 
 type ProductFilter struct {
-	Category *string `json:"category"`
+	Category *string         `json:"category"`
+	Status   []ProductStatus `json:"status"`
+	InSeason *bool           `json:"inSeason"`
+}
+
+type ProductImportError struct {
+	Line    int     `json:"line"`
+	Column  *string `json:"column"`
+	Message string  `json:"message"`
+}
+
+type ProductImportReport struct {
+	DryRun    bool                  `json:"dryRun"`
+	LineCount int                   `json:"lineCount"`
+	Created   int                   `json:"created"`
+	Updated   int                   `json:"updated"`
+	Errors    []*ProductImportError `json:"errors"`
 }
 
 type ProductPageInfo struct {
@@ -339,6 +657,111 @@ type ProductPageInfo struct {
 	HasNextPage bool   `json:"hasNextPage"`
 }
 
+type ProductPriceChange struct {
+	PreviousPrice float64   `json:"previousPrice"`
+	Price         float64   `json:"price"`
+	UserID        string    `json:"userID"`
+	Date          time.Time `json:"date"`
+}
+
+type ProductSearchConnection struct {
+	TotalCount int                  `json:"totalCount"`
+	Edges      []*ProductSearchEdge `json:"edges"`
+	PageInfo   *ProductPageInfo     `json:"pageInfo"`
+	Facets     *ProductSearchFacets `json:"facets"`
+}
+
+type ProductSearchEdge struct {
+	Cursor   string    `json:"cursor"`
+	Node     *Product  `json:"node"`
+	Commerce *Commerce `json:"commerce"`
+}
+
+type ProductSearchFacets struct {
+	IsBreton  []*BooleanFacetValue `json:"isBreton"`
+	HasGluten []*BooleanFacetValue `json:"hasGluten"`
+	Allergens []*FacetValue        `json:"allergens"`
+	Tags      []*FacetValue        `json:"tags"`
+	Prices    []*PriceFacetValue   `json:"prices"`
+}
+
+type ProductSearchFilter struct {
+	Query            *string  `json:"query"`
+	IsBreton         *bool    `json:"isBreton"`
+	HasGluten        *bool    `json:"hasGluten"`
+	WithoutAllergens []string `json:"withoutAllergens"`
+	Tags             []string `json:"tags"`
+	MinPrice         *float64 `json:"minPrice"`
+	MaxPrice         *float64 `json:"maxPrice"`
+	NearLatitude     *float64 `json:"nearLatitude"`
+	NearLongitude    *float64 `json:"nearLongitude"`
+	Radius           *float64 `json:"radius"`
+}
+
+type ProductSeason struct {
+	StartMonth *int       `json:"startMonth"`
+	EndMonth   *int       `json:"endMonth"`
+	StartDate  *time.Time `json:"startDate"`
+	EndDate    *time.Time `json:"endDate"`
+}
+
+type ProductSeasonInput struct {
+	StartMonth *int       `json:"startMonth"`
+	EndMonth   *int       `json:"endMonth"`
+	StartDate  *time.Time `json:"startDate"`
+	EndDate    *time.Time `json:"endDate"`
+}
+
+type ProductStatistics struct {
+	Product  *Product `json:"product"`
+	Quantity float64  `json:"quantity"`
+	Revenue  float64  `json:"revenue"`
+}
+
+type ProductStock struct {
+	Quantity          int  `json:"quantity"`
+	LowStockThreshold int  `json:"lowStockThreshold"`
+	IsLow             bool `json:"isLow"`
+}
+
+type PromoCode struct {
+	ID             string           `json:"id"`
+	Code           string           `json:"code"`
+	CommerceID     *string          `json:"commerceID"`
+	Type           PromoCodeType    `json:"type"`
+	Value          float64          `json:"value"`
+	MinimumBasket  float64          `json:"minimumBasket"`
+	StartDate      time.Time        `json:"startDate"`
+	EndDate        time.Time        `json:"endDate"`
+	MaxUses        *int             `json:"maxUses"`
+	MaxUsesPerUser *int             `json:"maxUsesPerUser"`
+	FirstOrderOnly bool             `json:"firstOrderOnly"`
+	FundedBy       PromoCodeFunding `json:"fundedBy"`
+	UsageCount     int              `json:"usageCount"`
+}
+
+type Promotion struct {
+	ID             string           `json:"id"`
+	Name           string           `json:"name"`
+	Type           PromotionType    `json:"type"`
+	Target         PromotionTarget  `json:"target"`
+	ProductID      *string          `json:"productID"`
+	Category       *string          `json:"category"`
+	PanierID       *string          `json:"panierID"`
+	Value          float64          `json:"value"`
+	BuyQuantity    *int             `json:"buyQuantity"`
+	FreeQuantity   *int             `json:"freeQuantity"`
+	QuantityBreaks []*QuantityBreak `json:"quantityBreaks"`
+	StartDate      time.Time        `json:"startDate"`
+	EndDate        time.Time        `json:"endDate"`
+	IsActive       bool             `json:"isActive"`
+}
+
+type QuantityBreak struct {
+	MinQuantity int     `json:"minQuantity"`
+	Percentage  float64 `json:"percentage"`
+}
+
 type RegisteredPaymentMethod struct {
 	Name            string  `json:"name"`
 	StripeID        string  `json:"stripeID"`
@@ -356,6 +779,11 @@ type ScheduleInput struct {
 	Closing string `json:"closing"`
 }
 
+type SeoMetadata struct {
+	JSONLd    string     `json:"jsonLd"`
+	OpenGraph []*MetaTag `json:"openGraph"`
+}
+
 type ServiceInfo struct {
 	ID                       string   `json:"id"`
 	Name                     string   `json:"name"`
@@ -370,6 +798,37 @@ type ServiceInfo struct {
 	TransactionAdvantages    []string `json:"transactionAdvantages"`
 }
 
+type SiretLookup struct {
+	Siret    string   `json:"siret"`
+	Siren    string   `json:"siren"`
+	Name     string   `json:"name"`
+	Address  *Address `json:"address"`
+	NafCode  string   `json:"nafCode"`
+	IsActive bool     `json:"isActive"`
+}
+
+type StatisticsPeriod struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+type StatisticsPoint struct {
+	Date       time.Time `json:"date"`
+	Revenue    float64   `json:"revenue"`
+	OrderCount int       `json:"orderCount"`
+}
+
+type StockMovement struct {
+	ID                string            `json:"id"`
+	Type              StockMovementType `json:"type"`
+	Quantity          int               `json:"quantity"`
+	QuantityAfter     int               `json:"quantityAfter"`
+	VariantID         *string           `json:"variantID"`
+	Reason            *string           `json:"reason"`
+	CommerceCommandID *string           `json:"commerceCommandID"`
+	Date              time.Time         `json:"date"`
+}
+
 type Transfert struct {
 	Value     float64 `json:"value"`
 	IbanOwner string  `json:"ibanOwner"`
@@ -377,45 +836,759 @@ type Transfert struct {
 	Bic       string  `json:"bic"`
 }
 
-type Role string
+type CatalogueFormat string
 
 const (
-	RoleAdmin       Role = "ADMIN"
-	RoleStorekeeper Role = "STOREKEEPER"
-	RoleUser        Role = "USER"
+	CatalogueFormatCSV  CatalogueFormat = "CSV"
+	CatalogueFormatXlsx CatalogueFormat = "XLSX"
 )
 
-var AllRole = []Role{
-	RoleAdmin,
-	RoleStorekeeper,
-	RoleUser,
+var AllCatalogueFormat = []CatalogueFormat{
+	CatalogueFormatCSV,
+	CatalogueFormatXlsx,
 }
 
-func (e Role) IsValid() bool {
+func (e CatalogueFormat) IsValid() bool {
 	switch e {
-	case RoleAdmin, RoleStorekeeper, RoleUser:
+	case CatalogueFormatCSV, CatalogueFormatXlsx:
 		return true
 	}
 	return false
 }
 
-func (e Role) String() string {
+func (e CatalogueFormat) String() string {
 	return string(e)
 }
 
-func (e *Role) UnmarshalGQL(v interface{}) error {
+func (e *CatalogueFormat) UnmarshalGQL(v interface{}) error {
 	str, ok := v.(string)
 	if !ok {
 		return fmt.Errorf("enums must be strings")
 	}
 
-	*e = Role(str)
+	*e = CatalogueFormat(str)
 	if !e.IsValid() {
-		return fmt.Errorf("%s is not a valid Role", str)
+		return fmt.Errorf("%s is not a valid CatalogueFormat", str)
 	}
 	return nil
 }
 
-func (e Role) MarshalGQL(w io.Writer) {
+func (e CatalogueFormat) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type CommerceStatus string
+
+const (
+	CommerceStatusDraft             CommerceStatus = "DRAFT"
+	CommerceStatusPendingValidation CommerceStatus = "PENDING_VALIDATION"
+	CommerceStatusActive            CommerceStatus = "ACTIVE"
+	CommerceStatusSuspended         CommerceStatus = "SUSPENDED"
+	CommerceStatusClosed            CommerceStatus = "CLOSED"
+)
+
+var AllCommerceStatus = []CommerceStatus{
+	CommerceStatusDraft,
+	CommerceStatusPendingValidation,
+	CommerceStatusActive,
+	CommerceStatusSuspended,
+	CommerceStatusClosed,
+}
+
+func (e CommerceStatus) IsValid() bool {
+	switch e {
+	case CommerceStatusDraft, CommerceStatusPendingValidation, CommerceStatusActive, CommerceStatusSuspended, CommerceStatusClosed:
+		return true
+	}
+	return false
+}
+
+func (e CommerceStatus) String() string {
+	return string(e)
+}
+
+func (e *CommerceStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = CommerceStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid CommerceStatus", str)
+	}
+	return nil
+}
+
+func (e CommerceStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type DeliveryZoneType string
+
+const (
+	DeliveryZoneTypePolygon DeliveryZoneType = "POLYGON"
+	DeliveryZoneTypeRadius  DeliveryZoneType = "RADIUS"
+)
+
+var AllDeliveryZoneType = []DeliveryZoneType{
+	DeliveryZoneTypePolygon,
+	DeliveryZoneTypeRadius,
+}
+
+func (e DeliveryZoneType) IsValid() bool {
+	switch e {
+	case DeliveryZoneTypePolygon, DeliveryZoneTypeRadius:
+		return true
+	}
+	return false
+}
+
+func (e DeliveryZoneType) String() string {
+	return string(e)
+}
+
+func (e *DeliveryZoneType) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = DeliveryZoneType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid DeliveryZoneType", str)
+	}
+	return nil
+}
+
+func (e DeliveryZoneType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type FulfilmentMode string
+
+const (
+	FulfilmentModePickup   FulfilmentMode = "PICKUP"
+	FulfilmentModeDelivery FulfilmentMode = "DELIVERY"
+)
+
+var AllFulfilmentMode = []FulfilmentMode{
+	FulfilmentModePickup,
+	FulfilmentModeDelivery,
+}
+
+func (e FulfilmentMode) IsValid() bool {
+	switch e {
+	case FulfilmentModePickup, FulfilmentModeDelivery:
+		return true
+	}
+	return false
+}
+
+func (e FulfilmentMode) String() string {
+	return string(e)
+}
+
+func (e *FulfilmentMode) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = FulfilmentMode(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid FulfilmentMode", str)
+	}
+	return nil
+}
+
+func (e FulfilmentMode) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type GiftCardStatus string
+
+const (
+	GiftCardStatusPending GiftCardStatus = "PENDING"
+	GiftCardStatusActive  GiftCardStatus = "ACTIVE"
+)
+
+var AllGiftCardStatus = []GiftCardStatus{
+	GiftCardStatusPending,
+	GiftCardStatusActive,
+}
+
+func (e GiftCardStatus) IsValid() bool {
+	switch e {
+	case GiftCardStatusPending, GiftCardStatusActive:
+		return true
+	}
+	return false
+}
+
+func (e GiftCardStatus) String() string {
+	return string(e)
+}
+
+func (e *GiftCardStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = GiftCardStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid GiftCardStatus", str)
+	}
+	return nil
+}
+
+func (e GiftCardStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type GiftCardTransactionType string
+
+const (
+	GiftCardTransactionTypePurchase       GiftCardTransactionType = "PURCHASE"
+	GiftCardTransactionTypeRedemption     GiftCardTransactionType = "REDEMPTION"
+	GiftCardTransactionTypeRefund         GiftCardTransactionType = "REFUND"
+	GiftCardTransactionTypeWeighingRefund GiftCardTransactionType = "WEIGHING_REFUND"
+)
+
+var AllGiftCardTransactionType = []GiftCardTransactionType{
+	GiftCardTransactionTypePurchase,
+	GiftCardTransactionTypeRedemption,
+	GiftCardTransactionTypeRefund,
+	GiftCardTransactionTypeWeighingRefund,
+}
+
+func (e GiftCardTransactionType) IsValid() bool {
+	switch e {
+	case GiftCardTransactionTypePurchase, GiftCardTransactionTypeRedemption, GiftCardTransactionTypeRefund, GiftCardTransactionTypeWeighingRefund:
+		return true
+	}
+	return false
+}
+
+func (e GiftCardTransactionType) String() string {
+	return string(e)
+}
+
+func (e *GiftCardTransactionType) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = GiftCardTransactionType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid GiftCardTransactionType", str)
+	}
+	return nil
+}
+
+func (e GiftCardTransactionType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ProductCatalogueField string
+
+const (
+	ProductCatalogueFieldExternalRef         ProductCatalogueField = "EXTERNAL_REF"
+	ProductCatalogueFieldName                ProductCatalogueField = "NAME"
+	ProductCatalogueFieldDescription         ProductCatalogueField = "DESCRIPTION"
+	ProductCatalogueFieldPrice               ProductCatalogueField = "PRICE"
+	ProductCatalogueFieldUnit                ProductCatalogueField = "UNIT"
+	ProductCatalogueFieldPerUnitQuantity     ProductCatalogueField = "PER_UNIT_QUANTITY"
+	ProductCatalogueFieldPerUnitQuantityUnit ProductCatalogueField = "PER_UNIT_QUANTITY_UNIT"
+	ProductCatalogueFieldTva                 ProductCatalogueField = "TVA"
+	ProductCatalogueFieldIsBreton            ProductCatalogueField = "IS_BRETON"
+	ProductCatalogueFieldHasGluten           ProductCatalogueField = "HAS_GLUTEN"
+	ProductCatalogueFieldTags                ProductCatalogueField = "TAGS"
+	ProductCatalogueFieldAllergens           ProductCatalogueField = "ALLERGENS"
+	ProductCatalogueFieldCategories          ProductCatalogueField = "CATEGORIES"
+)
+
+var AllProductCatalogueField = []ProductCatalogueField{
+	ProductCatalogueFieldExternalRef,
+	ProductCatalogueFieldName,
+	ProductCatalogueFieldDescription,
+	ProductCatalogueFieldPrice,
+	ProductCatalogueFieldUnit,
+	ProductCatalogueFieldPerUnitQuantity,
+	ProductCatalogueFieldPerUnitQuantityUnit,
+	ProductCatalogueFieldTva,
+	ProductCatalogueFieldIsBreton,
+	ProductCatalogueFieldHasGluten,
+	ProductCatalogueFieldTags,
+	ProductCatalogueFieldAllergens,
+	ProductCatalogueFieldCategories,
+}
+
+func (e ProductCatalogueField) IsValid() bool {
+	switch e {
+	case ProductCatalogueFieldExternalRef, ProductCatalogueFieldName, ProductCatalogueFieldDescription, ProductCatalogueFieldPrice, ProductCatalogueFieldUnit, ProductCatalogueFieldPerUnitQuantity, ProductCatalogueFieldPerUnitQuantityUnit, ProductCatalogueFieldTva, ProductCatalogueFieldIsBreton, ProductCatalogueFieldHasGluten, ProductCatalogueFieldTags, ProductCatalogueFieldAllergens, ProductCatalogueFieldCategories:
+		return true
+	}
+	return false
+}
+
+func (e ProductCatalogueField) String() string {
+	return string(e)
+}
+
+func (e *ProductCatalogueField) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ProductCatalogueField(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ProductCatalogueField", str)
+	}
+	return nil
+}
+
+func (e ProductCatalogueField) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ProductSeasonStatus string
+
+const (
+	ProductSeasonStatusAllYear     ProductSeasonStatus = "ALL_YEAR"
+	ProductSeasonStatusInSeason    ProductSeasonStatus = "IN_SEASON"
+	ProductSeasonStatusOutOfSeason ProductSeasonStatus = "OUT_OF_SEASON"
+)
+
+var AllProductSeasonStatus = []ProductSeasonStatus{
+	ProductSeasonStatusAllYear,
+	ProductSeasonStatusInSeason,
+	ProductSeasonStatusOutOfSeason,
+}
+
+func (e ProductSeasonStatus) IsValid() bool {
+	switch e {
+	case ProductSeasonStatusAllYear, ProductSeasonStatusInSeason, ProductSeasonStatusOutOfSeason:
+		return true
+	}
+	return false
+}
+
+func (e ProductSeasonStatus) String() string {
+	return string(e)
+}
+
+func (e *ProductSeasonStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ProductSeasonStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ProductSeasonStatus", str)
+	}
+	return nil
+}
+
+func (e ProductSeasonStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ProductStatus string
+
+const (
+	ProductStatusDraft     ProductStatus = "DRAFT"
+	ProductStatusPublished ProductStatus = "PUBLISHED"
+	ProductStatusArchived  ProductStatus = "ARCHIVED"
+)
+
+var AllProductStatus = []ProductStatus{
+	ProductStatusDraft,
+	ProductStatusPublished,
+	ProductStatusArchived,
+}
+
+func (e ProductStatus) IsValid() bool {
+	switch e {
+	case ProductStatusDraft, ProductStatusPublished, ProductStatusArchived:
+		return true
+	}
+	return false
+}
+
+func (e ProductStatus) String() string {
+	return string(e)
+}
+
+func (e *ProductStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ProductStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ProductStatus", str)
+	}
+	return nil
+}
+
+func (e ProductStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type PromoCodeFunding string
+
+const (
+	PromoCodeFundingPlatform PromoCodeFunding = "PLATFORM"
+	PromoCodeFundingCommerce PromoCodeFunding = "COMMERCE"
+)
+
+var AllPromoCodeFunding = []PromoCodeFunding{
+	PromoCodeFundingPlatform,
+	PromoCodeFundingCommerce,
+}
+
+func (e PromoCodeFunding) IsValid() bool {
+	switch e {
+	case PromoCodeFundingPlatform, PromoCodeFundingCommerce:
+		return true
+	}
+	return false
+}
+
+func (e PromoCodeFunding) String() string {
+	return string(e)
+}
+
+func (e *PromoCodeFunding) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PromoCodeFunding(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PromoCodeFunding", str)
+	}
+	return nil
+}
+
+func (e PromoCodeFunding) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type PromoCodeType string
+
+const (
+	PromoCodeTypePercentage  PromoCodeType = "PERCENTAGE"
+	PromoCodeTypeFixedAmount PromoCodeType = "FIXED_AMOUNT"
+)
+
+var AllPromoCodeType = []PromoCodeType{
+	PromoCodeTypePercentage,
+	PromoCodeTypeFixedAmount,
+}
+
+func (e PromoCodeType) IsValid() bool {
+	switch e {
+	case PromoCodeTypePercentage, PromoCodeTypeFixedAmount:
+		return true
+	}
+	return false
+}
+
+func (e PromoCodeType) String() string {
+	return string(e)
+}
+
+func (e *PromoCodeType) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PromoCodeType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PromoCodeType", str)
+	}
+	return nil
+}
+
+func (e PromoCodeType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type PromotionTarget string
+
+const (
+	PromotionTargetProduct  PromotionTarget = "PRODUCT"
+	PromotionTargetCategory PromotionTarget = "CATEGORY"
+	PromotionTargetPanier   PromotionTarget = "PANIER"
+)
+
+var AllPromotionTarget = []PromotionTarget{
+	PromotionTargetProduct,
+	PromotionTargetCategory,
+	PromotionTargetPanier,
+}
+
+func (e PromotionTarget) IsValid() bool {
+	switch e {
+	case PromotionTargetProduct, PromotionTargetCategory, PromotionTargetPanier:
+		return true
+	}
+	return false
+}
+
+func (e PromotionTarget) String() string {
+	return string(e)
+}
+
+func (e *PromotionTarget) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PromotionTarget(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PromotionTarget", str)
+	}
+	return nil
+}
+
+func (e PromotionTarget) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type PromotionType string
+
+const (
+	PromotionTypePercentage    PromotionType = "PERCENTAGE"
+	PromotionTypeFixedAmount   PromotionType = "FIXED_AMOUNT"
+	PromotionTypeBuyXGetY      PromotionType = "BUY_X_GET_Y"
+	PromotionTypeQuantityBreak PromotionType = "QUANTITY_BREAK"
+)
+
+var AllPromotionType = []PromotionType{
+	PromotionTypePercentage,
+	PromotionTypeFixedAmount,
+	PromotionTypeBuyXGetY,
+	PromotionTypeQuantityBreak,
+}
+
+func (e PromotionType) IsValid() bool {
+	switch e {
+	case PromotionTypePercentage, PromotionTypeFixedAmount, PromotionTypeBuyXGetY, PromotionTypeQuantityBreak:
+		return true
+	}
+	return false
+}
+
+func (e PromotionType) String() string {
+	return string(e)
+}
+
+func (e *PromotionType) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PromotionType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PromotionType", str)
+	}
+	return nil
+}
+
+func (e PromotionType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type Role string
+
+const (
+	RoleAdmin         Role = "ADMIN"
+	RoleStorekeeper   Role = "STOREKEEPER"
+	RolePickupManager Role = "PICKUP_MANAGER"
+	RoleUser          Role = "USER"
+)
+
+var AllRole = []Role{
+	RoleAdmin,
+	RoleStorekeeper,
+	RolePickupManager,
+	RoleUser,
+}
+
+func (e Role) IsValid() bool {
+	switch e {
+	case RoleAdmin, RoleStorekeeper, RolePickupManager, RoleUser:
+		return true
+	}
+	return false
+}
+
+func (e Role) String() string {
+	return string(e)
+}
+
+func (e *Role) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Role(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Role", str)
+	}
+	return nil
+}
+
+func (e Role) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type StatisticsGranularity string
+
+const (
+	StatisticsGranularityDay   StatisticsGranularity = "DAY"
+	StatisticsGranularityWeek  StatisticsGranularity = "WEEK"
+	StatisticsGranularityMonth StatisticsGranularity = "MONTH"
+)
+
+var AllStatisticsGranularity = []StatisticsGranularity{
+	StatisticsGranularityDay,
+	StatisticsGranularityWeek,
+	StatisticsGranularityMonth,
+}
+
+func (e StatisticsGranularity) IsValid() bool {
+	switch e {
+	case StatisticsGranularityDay, StatisticsGranularityWeek, StatisticsGranularityMonth:
+		return true
+	}
+	return false
+}
+
+func (e StatisticsGranularity) String() string {
+	return string(e)
+}
+
+func (e *StatisticsGranularity) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = StatisticsGranularity(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid StatisticsGranularity", str)
+	}
+	return nil
+}
+
+func (e StatisticsGranularity) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type StockMovementType string
+
+const (
+	StockMovementTypeReservation StockMovementType = "RESERVATION"
+	StockMovementTypeRelease     StockMovementType = "RELEASE"
+	StockMovementTypeAdjustment  StockMovementType = "ADJUSTMENT"
+)
+
+var AllStockMovementType = []StockMovementType{
+	StockMovementTypeReservation,
+	StockMovementTypeRelease,
+	StockMovementTypeAdjustment,
+}
+
+func (e StockMovementType) IsValid() bool {
+	switch e {
+	case StockMovementTypeReservation, StockMovementTypeRelease, StockMovementTypeAdjustment:
+		return true
+	}
+	return false
+}
+
+func (e StockMovementType) String() string {
+	return string(e)
+}
+
+func (e *StockMovementType) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = StockMovementType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid StockMovementType", str)
+	}
+	return nil
+}
+
+func (e StockMovementType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type Weekday string
+
+const (
+	WeekdayMonday    Weekday = "MONDAY"
+	WeekdayTuesday   Weekday = "TUESDAY"
+	WeekdayWednesday Weekday = "WEDNESDAY"
+	WeekdayThursday  Weekday = "THURSDAY"
+	WeekdayFriday    Weekday = "FRIDAY"
+	WeekdaySaturday  Weekday = "SATURDAY"
+	WeekdaySunday    Weekday = "SUNDAY"
+)
+
+var AllWeekday = []Weekday{
+	WeekdayMonday,
+	WeekdayTuesday,
+	WeekdayWednesday,
+	WeekdayThursday,
+	WeekdayFriday,
+	WeekdaySaturday,
+	WeekdaySunday,
+}
+
+func (e Weekday) IsValid() bool {
+	switch e {
+	case WeekdayMonday, WeekdayTuesday, WeekdayWednesday, WeekdayThursday, WeekdayFriday, WeekdaySaturday, WeekdaySunday:
+		return true
+	}
+	return false
+}
+
+func (e Weekday) String() string {
+	return string(e)
+}
+
+func (e *Weekday) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Weekday(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Weekday", str)
+	}
+	return nil
+}
+
+func (e Weekday) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
@@ -6,4 +6,9 @@ type PanierCommand struct {
 	ID         string    `json:"id"`
 	Status     string    `json:"status"`
 	PickupDate time.Time `json:"pickupDate"`
+
+	Name      string            `json:"name"`
+	Price     float64           `json:"price"`
+	Discount  float64           `json:"discount"`
+	Promotion *AppliedPromotion `json:"promotion"`
 }
@@ -0,0 +1,22 @@
+package model
+
+type PickupPoint struct {
+	ID           string                 `json:"id"`
+	Name         string                 `json:"name"`
+	Address      Address                `json:"address"`
+	Latitude     float64                `json:"latitude"`
+	Longitude    float64                `json:"longitude"`
+	OpeningHours BusinessHours          `json:"openingHours"`
+	ManagerID    *string                `json:"manager"`
+	Commerces    []*PickupPointCommerce `json:"commerces"`
+}
+
+type PickupPointCommerce struct {
+	CommerceID string    `json:"commerce"`
+	Days       []Weekday `json:"days"`
+}
+
+type PickupPointParcel struct {
+	CommandID        string             `json:"command"`
+	CommerceCommands []*CommerceCommand `json:"commerceCommands"`
+}
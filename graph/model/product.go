@@ -0,0 +1,47 @@
+package model
+
+import "time"
+
+type Product struct {
+	ID                    string              `json:"id"`
+	CommerceID            string              `json:"-"`
+	ExternalRef           *string             `json:"externalRef"`
+	Name                  string              `json:"name"`
+	Description           string              `json:"description"`
+	Price                 float64             `json:"price"`
+	Unit                  string              `json:"unit"`
+	PerUnitQuantity       float64             `json:"perUnitQuantity"`
+	PerUnitQuantityUnit   string              `json:"perUnitQuantityUnit"`
+	Tva                   float64             `json:"tva"`
+	IsBreton              bool                `json:"isBreton"`
+	HasGluten             bool                `json:"hasGluten"`
+	IsWeighed             bool                `json:"isWeighed"`
+	Tags                  []string            `json:"tags"`
+	Allergens             []string            `json:"allergens"`
+	Categories            []string            `json:"categories"`
+	CategoryIDs           []string            `json:"categoryIDs"`
+	Stock                 *ProductStock       `json:"stock"`
+	IsOutOfStock          bool                `json:"isOutOfStock"`
+	Variants              []*ProductVariant   `json:"variants"`
+	Status                ProductStatus       `json:"status"`
+	PublishAt             *time.Time          `json:"publishAt"`
+	UnpublishAt           *time.Time          `json:"unpublishAt"`
+	IsVisible             bool                `json:"isVisible"`
+	Seasons               []*ProductSeason    `json:"seasons"`
+	SeasonStatus          ProductSeasonStatus `json:"seasonStatus"`
+	OriginalPrice         float64             `json:"originalPrice"`
+	LowestPriceLast30Days float64             `json:"lowestPriceLast30Days"`
+}
+
+type ProductVariant struct {
+	ID              string        `json:"id"`
+	ProductID       string        `json:"-"`
+	CommerceID      string        `json:"-"`
+	Categories      []string      `json:"-"`
+	Name            string        `json:"name"`
+	Price           float64       `json:"price"`
+	PerUnitQuantity float64       `json:"perUnitQuantity"`
+	Sku             *string       `json:"sku"`
+	Stock           *ProductStock `json:"stock"`
+	IsOutOfStock    bool          `json:"isOutOfStock"`
+}
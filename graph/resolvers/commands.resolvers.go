@@ -77,7 +77,23 @@ func (r *commerceCommandResolver) Paniers(ctx context.Context, obj *model.Commer
 	panierCommands := []*model.PanierCommand{}
 
 	for _, databasePanierCommand := range databasePanierCommands {
-		panierCommands = append(panierCommands, databasePanierCommand.ToModel())
+		panierCommand := databasePanierCommand.ToModel()
+
+		// Sans instantané, on ne peut afficher que le panier actuel
+		if !databasePanierCommand.HasSnapshot() {
+			databasePanier, err := r.PaniersService.GetById(databasePanierCommand.PanierID.Hex())
+
+			if err != nil {
+				return nil, err
+			}
+
+			if databasePanier != nil {
+				panierCommand.Name = databasePanier.Name
+				panierCommand.Price = databasePanier.GetPrice()
+			}
+		}
+
+		panierCommands = append(panierCommands, panierCommand)
 	}
 
 	return panierCommands, nil
@@ -94,6 +110,44 @@ func (r *commerceCommandResolver) User(ctx context.Context, obj *model.CommerceC
 	return user, nil
 }
 
+// PickupMarket is the resolver for the pickupMarket field.
+func (r *commerceCommandResolver) PickupMarket(ctx context.Context, obj *model.CommerceCommand) (*model.Market, error) {
+	if obj.PickupMarketID == nil {
+		return nil, nil
+	}
+
+	databaseMarket, err := r.MarketsService.GetById(*obj.PickupMarketID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if databaseMarket == nil {
+		return nil, nil
+	}
+
+	return databaseMarket.ToModel(), nil
+}
+
+// PickupPoint is the resolver for the pickupPoint field.
+func (r *commerceCommandResolver) PickupPoint(ctx context.Context, obj *model.CommerceCommand) (*model.PickupPoint, error) {
+	if obj.PickupPointID == nil {
+		return nil, nil
+	}
+
+	databasePickupPoint, err := r.PickupPointsService.GetById(*obj.PickupPointID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if databasePickupPoint == nil {
+		return nil, nil
+	}
+
+	return databasePickupPoint.ToModel(), nil
+}
+
 // Command returns generated.CommandResolver implementation.
 func (r *Resolver) Command() generated.CommandResolver { return &commandResolver{r} }
 
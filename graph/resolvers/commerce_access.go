@@ -0,0 +1,200 @@
+package resolvers
+
+import (
+	"context"
+
+	"chemin-du-local.bzh/graphql/internal/auth"
+	"chemin-du-local.bzh/graphql/internal/categories"
+	"chemin-du-local.bzh/graphql/internal/commerces"
+	"chemin-du-local.bzh/graphql/internal/products"
+	"chemin-du-local.bzh/graphql/internal/services/commands"
+	"chemin-du-local.bzh/graphql/internal/users"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Retrouve le commerce visé par une mutation : celui donné en paramètre,
+// ou à défaut celui de l'utilisateur connecté. Seuls le commerçant du
+// commerce et les administrateurs y ont accès.
+func (r *Resolver) getManagedCommerce(ctx context.Context, commerceID *string) (*commerces.Commerce, error) {
+	user := auth.ForContext(ctx)
+
+	if user == nil {
+		return nil, &users.UserAccessDenied{}
+	}
+
+	var databaseCommerce *commerces.Commerce
+	var err error
+
+	if commerceID == nil {
+		databaseCommerce, err = r.CommercesService.GetForUser(user.ID.Hex())
+	} else {
+		databaseCommerce, err = r.CommercesService.GetById(*commerceID)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if databaseCommerce == nil {
+		return nil, &commerces.CommerceErrorNotFound{}
+	}
+
+	if user.Role != users.USERROLE_ADMIN && user.ID != databaseCommerce.StorekeeperID {
+		return nil, &users.UserAccessDenied{}
+	}
+
+	return databaseCommerce, nil
+}
+
+// Ferme le commerce s'il n'a plus de commandes en cours
+func (r *Resolver) closeCommerce(databaseCommerce *commerces.Commerce) error {
+	filter := bson.M{
+		"commerceID": databaseCommerce.ID,
+		"status":     commands.COMMERCE_COMMAND_STATUS_IN_PROGRESS,
+	}
+
+	inProgressCommands, err := r.CommerceCommandsService.GetFiltered(filter, nil)
+
+	if err != nil {
+		return err
+	}
+
+	if len(inProgressCommands) > 0 {
+		return &commerces.CommerceCommandsInProgressError{}
+	}
+
+	return r.CommercesService.Close(databaseCommerce)
+}
+
+// Retrouve un produit d'un commerce géré par l'utilisateur connecté
+func (r *Resolver) getManagedProduct(ctx context.Context, productID string) (*products.Product, error) {
+	databaseProduct, err := r.ProductsService.GetById(productID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if databaseProduct == nil {
+		return nil, &products.ProductNotFoundError{}
+	}
+
+	commerceID := databaseProduct.CommerceID.Hex()
+	_, err = r.getManagedCommerce(ctx, &commerceID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return databaseProduct, nil
+}
+
+// Supprime un produit jamais commandé ni utilisé dans un panier, et le
+// retire du Click&Collect du commerce
+func (r *Resolver) deleteProduct(databaseProduct *products.Product) error {
+	usageFilter := bson.M{"products.productID": databaseProduct.ID}
+	opts := options.Find().SetLimit(1)
+
+	ccCommands, err := r.CCCommandsService.GetFiltered(usageFilter, opts)
+
+	if err != nil {
+		return err
+	}
+
+	databasePaniers, err := r.PaniersService.GetFiltered(usageFilter, opts)
+
+	if err != nil {
+		return err
+	}
+
+	if len(ccCommands) > 0 || len(databasePaniers) > 0 {
+		return &products.ProductAlreadyOrderedError{}
+	}
+
+	err = r.ProductsService.Delete(databaseProduct)
+
+	if err != nil {
+		return err
+	}
+
+	databaseCommerce, err := r.CommercesService.GetById(databaseProduct.CommerceID.Hex())
+
+	if err != nil || databaseCommerce == nil {
+		return err
+	}
+
+	variantIDs := map[string]bool{}
+
+	for _, variant := range databaseProduct.Variants {
+		variantIDs[variant.ID.Hex()] = true
+	}
+
+	availableProducts := []string{}
+
+	for _, productID := range databaseCommerce.ProductsAvailableForClickAndCollect {
+		if productID != databaseProduct.ID.Hex() {
+			availableProducts = append(availableProducts, productID)
+		}
+	}
+
+	availableVariants := []string{}
+
+	for _, variantID := range databaseCommerce.VariantsAvailableForClickAndCollect {
+		if !variantIDs[variantID] {
+			availableVariants = append(availableVariants, variantID)
+		}
+	}
+
+	databaseCommerce.ProductsAvailableForClickAndCollect = availableProducts
+	databaseCommerce.VariantsAvailableForClickAndCollect = availableVariants
+
+	return r.CommercesService.Update(databaseCommerce, nil, nil)
+}
+
+// Les déclinaisons proposées en Click&Collect doivent appartenir aux
+// produits du commerce
+func (r *Resolver) checkAvailableVariants(databaseCommerce *commerces.Commerce) error {
+	databaseProducts, err := r.ProductsService.GetForCommerce(databaseCommerce.ID.Hex())
+
+	if err != nil {
+		return err
+	}
+
+	variantIDs := map[string]bool{}
+
+	for _, databaseProduct := range databaseProducts {
+		for _, variant := range databaseProduct.Variants {
+			variantIDs[variant.ID.Hex()] = true
+		}
+	}
+
+	for _, variantID := range databaseCommerce.VariantsAvailableForClickAndCollect {
+		if !variantIDs[variantID] {
+			return &products.ProductVariantNotFoundError{}
+		}
+	}
+
+	return nil
+}
+
+// Retrouve une catégorie d'un commerce géré par l'utilisateur connecté
+func (r *Resolver) getManagedCategory(ctx context.Context, categoryID string) (*categories.Category, error) {
+	databaseCategory, err := r.CategoriesService.GetById(categoryID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if databaseCategory == nil {
+		return nil, &categories.CategoryNotFoundError{}
+	}
+
+	commerceID := databaseCategory.CommerceID.Hex()
+	_, err = r.getManagedCommerce(ctx, &commerceID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return databaseCategory, nil
+}
@@ -6,13 +6,17 @@ package resolvers
 import (
 	"context"
 	"encoding/base64"
+	"time"
 
 	"chemin-du-local.bzh/graphql/graph/generated"
 	"chemin-du-local.bzh/graphql/graph/model"
+	"chemin-du-local.bzh/graphql/internal/auth"
+	"chemin-du-local.bzh/graphql/internal/categories"
 	"chemin-du-local.bzh/graphql/internal/commerces"
-	"chemin-du-local.bzh/graphql/internal/helper"
+	"chemin-du-local.bzh/graphql/internal/promotions"
 	"chemin-du-local.bzh/graphql/internal/registeredpaymentmethod"
-	"go.mongodb.org/mongo-driver/bson"
+	"chemin-du-local.bzh/graphql/internal/seo"
+	"chemin-du-local.bzh/graphql/internal/users"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -35,33 +39,48 @@ func (r *commerceResolver) Categories(ctx context.Context, obj *model.Commerce)
 		return nil, err
 	}
 
-	filter := bson.D{
-		primitive.E{
-			Key:   "commerceID",
-			Value: commerceObjectID,
-		},
+	databaseCategories, err := r.CategoriesService.GetForCommerce(commerceObjectID)
+
+	if err != nil {
+		return nil, err
 	}
 
-	databaseProducts, err := r.ProductsService.GetFiltered(filter, nil)
-	categories := []string{}
+	return categories.Names(databaseCategories), nil
+}
+
+// ProductCategories is the resolver for the productCategories field.
+func (r *commerceResolver) ProductCategories(ctx context.Context, obj *model.Commerce) ([]*model.Category, error) {
+	commerceObjectID, err := primitive.ObjectIDFromHex(obj.ID)
 
 	if err != nil {
 		return nil, err
 	}
 
-	for _, databaseProduct := range databaseProducts {
-		for _, category := range databaseProduct.Categories {
-			if !helper.Contains(categories, category) {
-				categories = append(categories, category)
-			}
-		}
+	databaseCategories, err := r.CategoriesService.GetForCommerce(commerceObjectID)
+
+	if err != nil {
+		return nil, err
 	}
 
-	return categories, nil
+	productCategories := []*model.Category{}
+
+	for _, databaseCategory := range databaseCategories {
+		productCategories = append(productCategories, databaseCategory.ToModel())
+	}
+
+	return productCategories, nil
 }
 
 // Products is the resolver for the products field.
 func (r *commerceResolver) Products(ctx context.Context, obj *model.Commerce, first *int, after *string, filters *model.ProductFilter) (*model.ProductConnection, error) {
+	// Seuls le commerçant et les administrateurs voient les brouillons et
+	// les produits archivés
+	user := auth.ForContext(ctx)
+
+	if filters != nil && (user == nil || (user.Role != users.USERROLE_ADMIN && user.ID.Hex() != obj.StorekeeperID)) {
+		filters.Status = nil
+	}
+
 	var decodedCursor *string
 
 	if after != nil {
@@ -140,7 +159,7 @@ func (r *commerceResolver) ProductsAvailableForClickAndCollect(ctx context.Conte
 			return nil, err
 		}
 
-		if databaseProduct != nil {
+		if databaseProduct != nil && databaseProduct.IsVisible(time.Now()) && databaseProduct.IsInSeason(time.Now()) {
 			productsResult = append(productsResult, databaseProduct.ToModel())
 		}
 	}
@@ -189,6 +208,95 @@ func (r *commerceResolver) DueBalance(ctx context.Context, obj *model.Commerce)
 	return dueBalance, nil
 }
 
+// DeliveryZones is the resolver for the deliveryZones field.
+func (r *commerceResolver) DeliveryZones(ctx context.Context, obj *model.Commerce) ([]*model.DeliveryZone, error) {
+	databaseZones, err := r.DeliveryZonesService.GetForCommerce(obj.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	zones := []*model.DeliveryZone{}
+
+	for _, databaseZone := range databaseZones {
+		zones = append(zones, databaseZone.ToModel())
+	}
+
+	return zones, nil
+}
+
+// Promotions is the resolver for the promotions field.
+func (r *commerceResolver) Promotions(ctx context.Context, obj *model.Commerce) ([]*model.Promotion, error) {
+	var databasePromotions []promotions.Promotion
+	var err error
+
+	// Seuls le commerçant et les administrateurs voient les promotions
+	// programmées
+	if user := auth.ForContext(ctx); user != nil && (user.Role == users.USERROLE_ADMIN || user.ID.Hex() == obj.StorekeeperID) {
+		databasePromotions, err = r.PromotionsService.GetForCommerce(obj.ID)
+	} else {
+		commerceObjectID, parseErr := primitive.ObjectIDFromHex(obj.ID)
+
+		if parseErr != nil {
+			return nil, parseErr
+		}
+
+		databasePromotions, err = promotions.ActiveForCommerce(ctx, r.PromotionsService, commerceObjectID)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*model.Promotion{}
+
+	for _, databasePromotion := range databasePromotions {
+		result = append(result, databasePromotion.ToModel())
+	}
+
+	return result, nil
+}
+
+// PromoCodes is the resolver for the promoCodes field.
+func (r *commerceResolver) PromoCodes(ctx context.Context, obj *model.Commerce) ([]*model.PromoCode, error) {
+	databaseCommerce, err := r.getManagedCommerce(ctx, &obj.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	databasePromoCodes, err := r.PromoCodesService.GetForCommerce(&databaseCommerce.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*model.PromoCode{}
+
+	for _, databasePromoCode := range databasePromoCodes {
+		result = append(result, databasePromoCode.ToModel())
+	}
+
+	return result, nil
+}
+
+// Markets is the resolver for the markets field.
+func (r *commerceResolver) Markets(ctx context.Context, obj *model.Commerce) ([]*model.Market, error) {
+	databaseMarkets, err := r.MarketsService.GetForCommerce(obj.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	markets := []*model.Market{}
+
+	for _, databaseMarket := range databaseMarkets {
+		markets = append(markets, databaseMarket.ToModel())
+	}
+
+	return markets, nil
+}
+
 // Paniers is the resolver for the paniers field.
 func (r *commerceResolver) Paniers(ctx context.Context, obj *model.Commerce, first *int, after *string, filters *model.PanierFilter) (*model.PanierConnection, error) {
 	var decodedCursor *string
@@ -249,6 +357,24 @@ func (r *commerceResolver) Paniers(ctx context.Context, obj *model.Commerce, fir
 	return &connection, nil
 }
 
+// Seo is the resolver for the seo field.
+func (r *commerceResolver) Seo(ctx context.Context, obj *model.Commerce) (*model.SeoMetadata, error) {
+	return seo.CommerceMetadata(obj)
+}
+
+// Statistics is the resolver for the statistics field.
+func (r *commerceResolver) Statistics(ctx context.Context, obj *model.Commerce, period model.StatisticsPeriod, granularity model.StatisticsGranularity) (*model.CommerceStatistics, error) {
+	user := auth.ForContext(ctx)
+
+	// Seul le commerçant propriétaire et les administrateurs
+	// peuvent consulter les statistiques
+	if user == nil || (user.Role != users.USERROLE_ADMIN && user.ID.Hex() != obj.StorekeeperID) {
+		return nil, &users.UserAccessDenied{}
+	}
+
+	return r.StatisticsService.GetForCommerce(obj.ID, period, granularity)
+}
+
 // Commerce returns generated.CommerceResolver implementation.
 func (r *Resolver) Commerce() generated.CommerceResolver { return &commerceResolver{r} }
 
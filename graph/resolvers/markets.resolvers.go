@@ -0,0 +1,38 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+
+	"chemin-du-local.bzh/graphql/graph/generated"
+	"chemin-du-local.bzh/graphql/graph/model"
+	"chemin-du-local.bzh/graphql/internal/commerces"
+)
+
+// Commerces is the resolver for the commerces field.
+func (r *marketResolver) Commerces(ctx context.Context, obj *model.Market) ([]*model.Commerce, error) {
+	marketCommerces := []*model.Commerce{}
+
+	for _, commerceID := range obj.CommercesID {
+		databaseCommerce, err := r.CommercesService.GetById(commerceID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if databaseCommerce == nil {
+			return nil, &commerces.CommerceErrorNotFound{}
+		}
+
+		marketCommerces = append(marketCommerces, databaseCommerce.ToModel())
+	}
+
+	return marketCommerces, nil
+}
+
+// Market returns generated.MarketResolver implementation.
+func (r *Resolver) Market() generated.MarketResolver { return &marketResolver{r} }
+
+type marketResolver struct{ *Resolver }
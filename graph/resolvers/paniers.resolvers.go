@@ -8,6 +8,7 @@ import (
 
 	"chemin-du-local.bzh/graphql/graph/generated"
 	"chemin-du-local.bzh/graphql/graph/model"
+	"chemin-du-local.bzh/graphql/internal/promotions"
 	"chemin-du-local.bzh/graphql/internal/services/paniers"
 )
 
@@ -16,6 +17,38 @@ func (r *panierResolver) Products(ctx context.Context, obj *model.Panier) ([]*mo
 	return r.PaniersService.GetProducts(obj.ID)
 }
 
+// DiscountedPrice is the resolver for the discountedPrice field.
+func (r *panierResolver) DiscountedPrice(ctx context.Context, obj *model.Panier) (float64, error) {
+	panierPromotions, err := r.getPanierPromotions(ctx, obj.ID, obj.CommerceID)
+
+	if err != nil {
+		return 0, err
+	}
+
+	// La réduction du panier s'applique avant les promotions
+	price := paniers.ReducedPrice(obj.Price, obj.Reduction)
+	_, discount := promotions.Best(panierPromotions, price, 1)
+
+	return price - discount, nil
+}
+
+// Promotions is the resolver for the promotions field.
+func (r *panierResolver) Promotions(ctx context.Context, obj *model.Panier) ([]*model.Promotion, error) {
+	panierPromotions, err := r.getPanierPromotions(ctx, obj.ID, obj.CommerceID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*model.Promotion{}
+
+	for i := range panierPromotions {
+		result = append(result, panierPromotions[i].ToModel())
+	}
+
+	return result, nil
+}
+
 // Panier is the resolver for the panier field.
 func (r *panierCommandResolver) Panier(ctx context.Context, obj *model.PanierCommand) (*model.Panier, error) {
 	databasePanierCommand, err := r.PanierCommandsService.GetById(obj.ID)
@@ -0,0 +1,85 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+
+	"chemin-du-local.bzh/graphql/graph/generated"
+	"chemin-du-local.bzh/graphql/graph/model"
+	"chemin-du-local.bzh/graphql/internal/commerces"
+	"chemin-du-local.bzh/graphql/internal/services/commands"
+)
+
+// Manager is the resolver for the manager field.
+func (r *pickupPointResolver) Manager(ctx context.Context, obj *model.PickupPoint) (*model.User, error) {
+	if obj.ManagerID == nil {
+		return nil, nil
+	}
+
+	manager, err := r.UsersService.GetUserById(*obj.ManagerID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Le gestionnaire a pu être supprimé depuis
+	if manager == nil {
+		return nil, nil
+	}
+
+	return manager.ToModel(), nil
+}
+
+// Commerce is the resolver for the commerce field.
+func (r *pickupPointCommerceResolver) Commerce(ctx context.Context, obj *model.PickupPointCommerce) (*model.Commerce, error) {
+	databaseCommerce, err := r.CommercesService.GetById(obj.CommerceID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if databaseCommerce == nil {
+		return nil, &commerces.CommerceErrorNotFound{}
+	}
+
+	return databaseCommerce.ToModel(), nil
+}
+
+// User is the resolver for the user field.
+func (r *pickupPointParcelResolver) User(ctx context.Context, obj *model.PickupPointParcel) (*model.User, error) {
+	return r.CommandsService.GetUser(obj.CommandID)
+}
+
+// Command is the resolver for the command field.
+func (r *pickupPointParcelResolver) Command(ctx context.Context, obj *model.PickupPointParcel) (*model.Command, error) {
+	databaseCommand, err := r.CommandsService.GetById(obj.CommandID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if databaseCommand == nil {
+		return nil, &commands.CommandNotFoundError{}
+	}
+
+	return databaseCommand.ToModel(), nil
+}
+
+// PickupPoint returns generated.PickupPointResolver implementation.
+func (r *Resolver) PickupPoint() generated.PickupPointResolver { return &pickupPointResolver{r} }
+
+// PickupPointCommerce returns generated.PickupPointCommerceResolver implementation.
+func (r *Resolver) PickupPointCommerce() generated.PickupPointCommerceResolver {
+	return &pickupPointCommerceResolver{r}
+}
+
+// PickupPointParcel returns generated.PickupPointParcelResolver implementation.
+func (r *Resolver) PickupPointParcel() generated.PickupPointParcelResolver {
+	return &pickupPointParcelResolver{r}
+}
+
+type pickupPointResolver struct{ *Resolver }
+type pickupPointCommerceResolver struct{ *Resolver }
+type pickupPointParcelResolver struct{ *Resolver }
@@ -0,0 +1,87 @@
+package resolvers
+
+import (
+	"chemin-du-local.bzh/graphql/graph/model"
+	"chemin-du-local.bzh/graphql/internal/categories"
+	"chemin-du-local.bzh/graphql/internal/products"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Retrouve les catégories d'un produit par leurs identifiants, ou à
+// défaut par leurs noms, en créant alors celles qui manquent
+func (r *Resolver) resolveProductCategories(commerceID primitive.ObjectID, categoryIDs []string, names []string) ([]categories.Category, error) {
+	if categoryIDs != nil {
+		return r.CategoriesService.GetByIds(commerceID, categoryIDs)
+	}
+
+	return r.CategoriesService.ResolveNames(commerceID, names)
+}
+
+// Remplace les catégories demandées par celles du commerce, le service
+// des produits enregistrant les identifiants et les noms tels quels
+func (r *Resolver) setNewProductCategories(commerceID string, input *model.NewProduct) error {
+	commerceObjectID, err := primitive.ObjectIDFromHex(commerceID)
+
+	if err != nil {
+		return err
+	}
+
+	productCategories, err := r.resolveProductCategories(commerceObjectID, input.CategoryIDs, input.Categories)
+
+	if err != nil {
+		return err
+	}
+
+	input.CategoryIDs = []string{}
+
+	for _, categoryID := range categories.IDs(productCategories) {
+		input.CategoryIDs = append(input.CategoryIDs, categoryID.Hex())
+	}
+
+	input.Categories = categories.Names(productCategories)
+
+	return nil
+}
+
+// Applique les changements de catégories d'un produit et les retire des
+// changements, les autres champs étant appliqués tels quels
+func (r *Resolver) applyProductCategoryChanges(product *products.Product, changes map[string]interface{}) error {
+	categoryIDs := toStrings(changes["categoryIDs"])
+	names := toStrings(changes["categories"])
+
+	delete(changes, "categoryIDs")
+	delete(changes, "categories")
+
+	if categoryIDs == nil && names == nil {
+		return nil
+	}
+
+	productCategories, err := r.resolveProductCategories(product.CommerceID, categoryIDs, names)
+
+	if err != nil {
+		return err
+	}
+
+	product.CategoryIDs = categories.IDs(productCategories)
+	product.Categories = categories.Names(productCategories)
+
+	return nil
+}
+
+func toStrings(value interface{}) []string {
+	values, ok := value.([]interface{})
+
+	if !ok {
+		return nil
+	}
+
+	result := []string{}
+
+	for _, value := range values {
+		if str, ok := value.(string); ok {
+			result = append(result, str)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,137 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+
+	"chemin-du-local.bzh/graphql/graph/generated"
+	"chemin-du-local.bzh/graphql/graph/model"
+	"chemin-du-local.bzh/graphql/internal/commerces"
+	"chemin-du-local.bzh/graphql/internal/promotions"
+	"chemin-du-local.bzh/graphql/internal/seo"
+	"chemin-du-local.bzh/graphql/internal/users"
+)
+
+// StockMovements is the resolver for the stockMovements field.
+func (r *productResolver) StockMovements(ctx context.Context, obj *model.Product, first *int) ([]*model.StockMovement, error) {
+	_, err := r.getManagedProduct(ctx, obj.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Le client peut envoyer null malgré la valeur par défaut du schéma
+	limit := 20
+
+	if first != nil {
+		limit = *first
+	}
+
+	databaseMovements, err := r.StockService.GetMovements(obj.ID, limit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	movements := []*model.StockMovement{}
+
+	for _, databaseMovement := range databaseMovements {
+		movements = append(movements, databaseMovement.ToModel())
+	}
+
+	return movements, nil
+}
+
+// Seo is the resolver for the seo field.
+func (r *productResolver) Seo(ctx context.Context, obj *model.Product) (*model.SeoMetadata, error) {
+	databaseCommerce, err := r.CommercesService.GetById(obj.CommerceID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if databaseCommerce == nil {
+		return nil, &commerces.CommerceErrorNotFound{}
+	}
+
+	return seo.ProductMetadata(obj, databaseCommerce)
+}
+
+// DiscountedPrice is the resolver for the discountedPrice field.
+func (r *productResolver) DiscountedPrice(ctx context.Context, obj *model.Product) (float64, error) {
+	productPromotions, err := r.getProductPromotions(ctx, obj.ID, obj.CommerceID, obj.Categories)
+
+	if err != nil {
+		return 0, err
+	}
+
+	_, discount := promotions.Best(productPromotions, obj.Price, 1)
+
+	return obj.Price - discount, nil
+}
+
+// Promotions is the resolver for the promotions field.
+func (r *productResolver) Promotions(ctx context.Context, obj *model.Product) ([]*model.Promotion, error) {
+	productPromotions, err := r.getProductPromotions(ctx, obj.ID, obj.CommerceID, obj.Categories)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*model.Promotion{}
+
+	for i := range productPromotions {
+		result = append(result, productPromotions[i].ToModel())
+	}
+
+	return result, nil
+}
+
+// PriceHistory is the resolver for the priceHistory field.
+func (r *productResolver) PriceHistory(ctx context.Context, obj *model.Product) ([]*model.ProductPriceChange, error) {
+	history := []*model.ProductPriceChange{}
+
+	databaseProduct, err := r.getManagedProduct(ctx, obj.ID)
+
+	// Le produit reste lisible par tous, sans son historique
+	if _, ok := err.(*users.UserAccessDenied); ok {
+		return history, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Du changement le plus récent au plus ancien
+	for index := len(databaseProduct.PriceHistory) - 1; index >= 0; index-- {
+		history = append(history, databaseProduct.PriceHistory[index].ToModel())
+	}
+
+	return history, nil
+}
+
+// DiscountedPrice is the resolver for the discountedPrice field.
+func (r *productVariantResolver) DiscountedPrice(ctx context.Context, obj *model.ProductVariant) (float64, error) {
+	productPromotions, err := r.getProductPromotions(ctx, obj.ProductID, obj.CommerceID, obj.Categories)
+
+	if err != nil {
+		return 0, err
+	}
+
+	_, discount := promotions.Best(productPromotions, obj.Price, 1)
+
+	return obj.Price - discount, nil
+}
+
+// Product returns generated.ProductResolver implementation.
+func (r *Resolver) Product() generated.ProductResolver { return &productResolver{r} }
+
+// ProductVariant returns generated.ProductVariantResolver implementation.
+func (r *Resolver) ProductVariant() generated.ProductVariantResolver {
+	return &productVariantResolver{r}
+}
+
+type productResolver struct{ *Resolver }
+type productVariantResolver struct{ *Resolver }
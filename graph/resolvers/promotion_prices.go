@@ -0,0 +1,54 @@
+package resolvers
+
+import (
+	"context"
+
+	"chemin-du-local.bzh/graphql/internal/promotions"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Promotions en cours de son commerce qui s'appliquent au produit. Elles
+// sont lues une fois par commerce pour toute la requête.
+func (r *Resolver) getProductPromotions(ctx context.Context, productID string, commerceID string, categories []string) ([]promotions.Promotion, error) {
+	productObjectID, err := primitive.ObjectIDFromHex(productID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	commerceObjectID, err := primitive.ObjectIDFromHex(commerceID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	databasePromotions, err := promotions.ActiveForCommerce(ctx, r.PromotionsService, commerceObjectID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return promotions.ForProduct(databasePromotions, productObjectID, categories), nil
+}
+
+func (r *Resolver) getPanierPromotions(ctx context.Context, panierID string, commerceID string) ([]promotions.Promotion, error) {
+	panierObjectID, err := primitive.ObjectIDFromHex(panierID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	commerceObjectID, err := primitive.ObjectIDFromHex(commerceID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	databasePromotions, err := promotions.ActiveForCommerce(ctx, r.PromotionsService, commerceObjectID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return promotions.ForPanier(databasePromotions, panierObjectID), nil
+}
@@ -1,10 +1,20 @@
 package resolvers
 
 import (
+	"chemin-du-local.bzh/graphql/internal/categories"
 	"chemin-du-local.bzh/graphql/internal/commerces"
+	"chemin-du-local.bzh/graphql/internal/deliveryzones"
+	"chemin-du-local.bzh/graphql/internal/giftcards"
+	"chemin-du-local.bzh/graphql/internal/markets"
+	"chemin-du-local.bzh/graphql/internal/pickuppoints"
 	"chemin-du-local.bzh/graphql/internal/products"
+	"chemin-du-local.bzh/graphql/internal/promocodes"
+	"chemin-du-local.bzh/graphql/internal/promotions"
 	"chemin-du-local.bzh/graphql/internal/services/commands"
 	"chemin-du-local.bzh/graphql/internal/services/paniers"
+	"chemin-du-local.bzh/graphql/internal/sirene"
+	"chemin-du-local.bzh/graphql/internal/statistics"
+	"chemin-du-local.bzh/graphql/internal/stock"
 	"chemin-du-local.bzh/graphql/internal/users"
 )
 
@@ -21,4 +31,14 @@ type Resolver struct {
 	CommerceCommandsService commands.CommerceCommandsService
 	CCCommandsService       commands.CCCommandsService
 	PanierCommandsService   commands.PanierCommandsService
+	SireneService           sirene.SireneService
+	StatisticsService       statistics.StatisticsService
+	DeliveryZonesService    deliveryzones.DeliveryZonesService
+	MarketsService          markets.MarketsService
+	PickupPointsService     pickuppoints.PickupPointsService
+	StockService            stock.StockService
+	PromotionsService       promotions.PromotionsService
+	PromoCodesService       promocodes.PromoCodesService
+	GiftCardsService        giftcards.GiftCardsService
+	CategoriesService       categories.CategoriesService
 }
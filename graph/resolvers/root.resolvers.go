@@ -17,17 +17,28 @@ import (
 	"chemin-du-local.bzh/graphql/graph/model"
 	"chemin-du-local.bzh/graphql/internal/auth"
 	"chemin-du-local.bzh/graphql/internal/commerces"
+	"chemin-du-local.bzh/graphql/internal/deliveryzones"
+	"chemin-du-local.bzh/graphql/internal/giftcards"
 	"chemin-du-local.bzh/graphql/internal/helper"
+	"chemin-du-local.bzh/graphql/internal/markets"
+	"chemin-du-local.bzh/graphql/internal/pickuppoints"
 	"chemin-du-local.bzh/graphql/internal/products"
+	"chemin-du-local.bzh/graphql/internal/promocodes"
+	"chemin-du-local.bzh/graphql/internal/promotions"
 	"chemin-du-local.bzh/graphql/internal/services/commands"
 	"chemin-du-local.bzh/graphql/internal/services/paniers"
 	"chemin-du-local.bzh/graphql/internal/services/servicesinfo"
+	"chemin-du-local.bzh/graphql/internal/sirene"
 	"chemin-du-local.bzh/graphql/internal/users"
 	"chemin-du-local.bzh/graphql/pkg/geojson"
 	"chemin-du-local.bzh/graphql/pkg/jwt"
 	"chemin-du-local.bzh/graphql/pkg/notifications"
+	"chemin-du-local.bzh/graphql/pkg/opendatahandler"
+	"chemin-du-local.bzh/graphql/pkg/stripehandler"
 	"chemin-du-local.bzh/graphql/pkg/utils"
+	"chemin-du-local.bzh/graphql/pkg/xlsx"
 	"github.com/99designs/gqlgen/graphql"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -164,10 +175,40 @@ func (r *mutationResolver) UpdateCommerce(ctx context.Context, id string, change
 		return nil, &commerces.CommerceErrorNotFound{}
 	}
 
+	// L'adresse porte une position, qui ne correspond à aucun champ
+	if addressChanges, ok := changes["address"].(map[string]interface{}); ok {
+		err = databaseCommerce.Address.ApplyChanges(addressChanges)
+
+		if err != nil {
+			return nil, err
+		}
+
+		delete(changes, "address")
+	}
+
 	// On a besoin d'un workaround pour les services
 	tempServices := databaseCommerce.Services
+	tempSlug := databaseCommerce.Slug
 	helper.ApplyChanges(changes, databaseCommerce)
 	databaseCommerce.Services = tempServices
+	databaseCommerce.Slug = tempSlug
+
+	if changes["variantsAvailableForClickAndCollect"] != nil {
+		err = r.checkAvailableVariants(databaseCommerce)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Le slug doit rester unique et l'ancien est gardé pour les redirections
+	if changes["slug"] != nil {
+		err = r.CommercesService.ChangeSlug(databaseCommerce, changes["slug"].(string))
+
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	// Les changements de service
 	if changes["services"] != nil {
@@ -311,6 +352,7 @@ func (r *mutationResolver) UpdateCommerce(ctx context.Context, id string, change
 			Type:        "Point",
 			Coordinates: []float64{longitude, latitude},
 		}
+		databaseCommerce.Address.Location = &databaseCommerce.AddressGeo
 
 	}
 
@@ -323,285 +365,1212 @@ func (r *mutationResolver) UpdateCommerce(ctx context.Context, id string, change
 	return databaseCommerce.ToModel(), nil
 }
 
-// CreateProduct is the resolver for the createProduct field.
-func (r *mutationResolver) CreateProduct(ctx context.Context, commerceID *string, input model.NewProduct) (*model.Product, error) {
-	user := auth.ForContext(ctx)
+// UpdateCommerceStatus is the resolver for the updateCommerceStatus field.
+func (r *mutationResolver) UpdateCommerceStatus(ctx context.Context, id string, status model.CommerceStatus) (*model.Commerce, error) {
+	databaseCommerce, err := r.CommercesService.GetById(id)
 
-	if user.Role == users.USERROLE_ADMIN && commerceID == nil {
-		return nil, &products.MustSpecifyCommerceIDError{}
+	if err != nil {
+		return nil, err
 	}
 
-	// Si l'utilisateur est un commerçant, il doit créer des produits
-	// pour son commerce
-	if user.Role == users.USERROLE_STOREKEEPER {
-		databaseCommerce, err := r.CommercesService.GetForUser(user.ID.Hex())
+	if databaseCommerce == nil {
+		return nil, &commerces.CommerceErrorNotFound{}
+	}
 
-		// Cela permet aussi d'éviter qu'un commerçant créer un
-		// produit sans commerce
-		if err != nil {
-			return nil, err
-		}
+	// La fermeture passe par les mêmes vérifications que pour le
+	// commerçant
+	if status == model.CommerceStatusClosed {
+		err = r.closeCommerce(databaseCommerce)
+	} else {
+		databaseCommerce.Status = status.String()
+		err = r.CommercesService.Update(databaseCommerce, nil, nil)
+	}
 
-		databaseCommerceID := databaseCommerce.ID.Hex()
-		commerceID = &databaseCommerceID
+	if err != nil {
+		return nil, err
 	}
 
-	databaseProduct, err := r.ProductsService.Create(*commerceID, input)
+	return databaseCommerce.ToModel(), nil
+}
+
+// CloseCommerce is the resolver for the closeCommerce field.
+func (r *mutationResolver) CloseCommerce(ctx context.Context, id string) (*model.Commerce, error) {
+	databaseCommerce, err := r.getManagedCommerce(ctx, &id)
 
 	if err != nil {
 		return nil, err
 	}
 
-	// Si le commerçant a souscrit au Click&Collect, le produit doit automatiquement y être ajouté
-	databaseCommerce, err := r.CommercesService.GetForUser(user.ID.Hex())
+	err = r.closeCommerce(databaseCommerce)
 
 	if err != nil {
-		return databaseProduct.ToModel(), nil
+		return nil, err
 	}
 
-	commerceSubscribedToCC := false
+	return databaseCommerce.ToModel(), nil
+}
 
-	for _, service := range databaseCommerce.Services {
-		if strings.Contains(service, "CLICKANDCOLLECT") {
-			commerceSubscribedToCC = true
-			break
-		}
+// UpdateCommerceVacation is the resolver for the updateCommerceVacation field.
+func (r *mutationResolver) UpdateCommerceVacation(ctx context.Context, id string, input *model.NewCommerceVacation) (*model.Commerce, error) {
+	user := auth.ForContext(ctx)
+
+	databaseCommerce, err := r.CommercesService.GetById(id)
+
+	if err != nil {
+		return nil, err
 	}
 
-	if commerceSubscribedToCC {
-		databaseCommerce.ProductsAvailableForClickAndCollect = append(databaseCommerce.ProductsAvailableForClickAndCollect, databaseProduct.ID.Hex())
+	if databaseCommerce == nil {
+		return nil, &commerces.CommerceErrorNotFound{}
 	}
 
-	r.CommercesService.Update(databaseCommerce, nil, nil)
+	// Seul le commerçant du commerce peut gérer ses congés
+	if user.Role != users.USERROLE_ADMIN && user.ID != databaseCommerce.StorekeeperID {
+		return nil, &users.UserAccessDenied{}
+	}
 
-	return databaseProduct.ToModel(), nil
+	// Sans période, les congés sont annulés
+	if input == nil {
+		databaseCommerce.Vacation = nil
+	} else {
+		if input.EndDate.Before(input.StartDate) {
+			return nil, &commerces.InvalidVacationError{}
+		}
+
+		message := ""
+		prorateBilling := false
+
+		if input.Message != nil {
+			message = *input.Message
+		}
+
+		if input.ProrateBilling != nil {
+			prorateBilling = *input.ProrateBilling
+		}
+
+		databaseCommerce.Vacation = &model.CommerceVacation{
+			StartDate:      input.StartDate,
+			EndDate:        input.EndDate,
+			Message:        message,
+			ProrateBilling: prorateBilling,
+		}
+	}
+
+	err = r.CommercesService.Update(databaseCommerce, nil, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return databaseCommerce.ToModel(), nil
 }
 
-// CreateProducts is the resolver for the createProducts field.
-func (r *mutationResolver) CreateProducts(ctx context.Context, commerceID *string, input []*model.NewProduct) ([]*model.Product, error) {
+// CreateDeliveryZone is the resolver for the createDeliveryZone field.
+func (r *mutationResolver) CreateDeliveryZone(ctx context.Context, commerceID *string, input model.NewDeliveryZone) (*model.DeliveryZone, error) {
 	user := auth.ForContext(ctx)
 
-	if user.Role == users.USERROLE_ADMIN && commerceID == nil {
-		return nil, &products.MustSpecifyCommerceIDError{}
-	}
+	var databaseCommerce *commerces.Commerce
 
-	// Si l'utilisateur est un commerçant, il doit créer des produits
-	// pour son commerce
-	if user.Role == users.USERROLE_STOREKEEPER {
-		databaseCommerce, err := r.CommercesService.GetForUser(user.ID.Hex())
+	// On a d'abord besoin de trouver le commerce de l'utilisateur ou celui en paramètre
+	if commerceID == nil {
+		userDatabaseCommerce, err := r.CommercesService.GetForUser(user.ID.Hex())
 
-		// Cela permet aussi d'éviter qu'un commerçant créer un
-		// produit sans commerce
 		if err != nil {
 			return nil, err
 		}
 
-		databaseCommerceID := databaseCommerce.ID.Hex()
-		commerceID = &databaseCommerceID
-	}
-
-	result := []*model.Product{}
-
-	for _, produdct := range input {
-		databaseProduct, err := r.ProductsService.Create(*commerceID, *produdct)
+		databaseCommerce = userDatabaseCommerce
+	} else {
+		commerceDatabaseCommerce, err := r.CommercesService.GetById(*commerceID)
 
 		if err != nil {
 			return nil, err
 		}
 
-		result = append(result, databaseProduct.ToModel())
+		databaseCommerce = commerceDatabaseCommerce
 	}
 
-	return result, nil
-}
+	if databaseCommerce == nil {
+		return nil, &commerces.CommerceErrorNotFound{}
+	}
 
-// UpdateProduct is the resolver for the updateProduct field.
-func (r *mutationResolver) UpdateProduct(ctx context.Context, id string, changes map[string]interface{}) (*model.Product, error) {
-	databaseProduct, err := r.ProductsService.GetById(id)
+	if user.Role != users.USERROLE_ADMIN && user.ID != databaseCommerce.StorekeeperID {
+		return nil, &users.UserAccessDenied{}
+	}
+
+	databaseZone, err := r.DeliveryZonesService.Create(databaseCommerce.ID, input)
 
 	if err != nil {
 		return nil, err
 	}
 
-	if databaseProduct == nil {
-		return nil, &products.ProductNotFoundError{}
+	return databaseZone.ToModel(), nil
+}
+
+// DeleteDeliveryZone is the resolver for the deleteDeliveryZone field.
+func (r *mutationResolver) DeleteDeliveryZone(ctx context.Context, id string) (bool, error) {
+	user := auth.ForContext(ctx)
+
+	databaseZone, err := r.DeliveryZonesService.GetById(id)
+
+	if err != nil {
+		return false, err
 	}
 
-	helper.ApplyChanges(changes, databaseProduct)
+	if databaseZone == nil {
+		return false, &deliveryzones.DeliveryZoneNotFoundError{}
+	}
 
-	var image *graphql.Upload
+	databaseCommerce, err := r.CommercesService.GetById(databaseZone.CommerceID.Hex())
 
-	if changes["image"] != nil {
-		castedImage := changes["image"].(graphql.Upload)
-		image = &castedImage
+	if err != nil {
+		return false, err
+	}
+
+	if databaseCommerce == nil {
+		return false, &commerces.CommerceErrorNotFound{}
+	}
+
+	if user.Role != users.USERROLE_ADMIN && user.ID != databaseCommerce.StorekeeperID {
+		return false, &users.UserAccessDenied{}
 	}
 
-	err = r.ProductsService.Update(databaseProduct, image)
+	err = r.DeliveryZonesService.Delete(id)
 
 	if err != nil {
-		return nil, err
+		return false, err
 	}
 
-	return databaseProduct.ToModel(), nil
+	return true, nil
 }
 
-// UpdateProducts is the resolver for the updateProducts field.
-func (r *mutationResolver) UpdateProducts(ctx context.Context, changes []*model.BulkChangesProduct) ([]*model.Product, error) {
-	result := []*model.Product{}
+// CreatePromotion is the resolver for the createPromotion field.
+func (r *mutationResolver) CreatePromotion(ctx context.Context, commerceID *string, input model.NewPromotion) (*model.Promotion, error) {
+	databaseCommerce, err := r.getManagedCommerce(ctx, commerceID)
 
-	for _, change := range changes {
-		databaseProduct, err := r.ProductsService.GetById(change.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Le produit ou le panier visé doit appartenir au commerce
+	if input.ProductID != nil && input.Target == model.PromotionTargetProduct {
+		databaseProduct, err := r.ProductsService.GetById(*input.ProductID)
 
 		if err != nil {
 			return nil, err
 		}
 
-		if databaseProduct == nil {
+		if databaseProduct == nil || databaseProduct.CommerceID != databaseCommerce.ID {
 			return nil, &products.ProductNotFoundError{}
 		}
+	}
 
-		helper.ApplyChanges(change.Changes, databaseProduct)
-
-		var image *graphql.Upload
-
-		if change.Changes["image"] != nil {
-			castedImage := change.Changes["image"].(graphql.Upload)
-			image = &castedImage
-		}
-
-		err = r.ProductsService.Update(databaseProduct, image)
+	if input.PanierID != nil && input.Target == model.PromotionTargetPanier {
+		databasePanier, err := r.PaniersService.GetById(*input.PanierID)
 
 		if err != nil {
 			return nil, err
 		}
 
-		result = append(result, databaseProduct.ToModel())
+		if databasePanier == nil || databasePanier.CommerceID != databaseCommerce.ID {
+			return nil, &paniers.PanierNotFoundError{}
+		}
 	}
 
-	return result, nil
+	databasePromotion, err := r.PromotionsService.Create(databaseCommerce.ID, input)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return databasePromotion.ToModel(), nil
 }
 
-// UpdateCommerceCommand is the resolver for the updateCommerceCommand field.
-func (r *mutationResolver) UpdateCommerceCommand(ctx context.Context, id string, changes map[string]interface{}) (*model.CommerceCommand, error) {
-	databaseCommerceCommand, err := r.CommerceCommandsService.GetById(id)
+// DeletePromotion is the resolver for the deletePromotion field.
+func (r *mutationResolver) DeletePromotion(ctx context.Context, id string) (bool, error) {
+	databasePromotion, err := r.PromotionsService.GetById(id)
 
 	if err != nil {
-		return nil, err
+		return false, err
 	}
 
-	if databaseCommerceCommand == nil {
-		return nil, &commands.CommerceCommandNotFoundError{}
+	if databasePromotion == nil {
+		return false, &promotions.PromotionNotFoundError{}
 	}
 
-	helper.ApplyChanges(changes, databaseCommerceCommand)
+	commerceID := databasePromotion.CommerceID.Hex()
+	_, err = r.getManagedCommerce(ctx, &commerceID)
 
-	err = r.CommerceCommandsService.Update(databaseCommerceCommand)
+	if err != nil {
+		return false, err
+	}
+
+	err = r.PromotionsService.Delete(id)
 
 	if err != nil {
-		return nil, err
+		return false, err
 	}
 
-	return databaseCommerceCommand.ToModel(), nil
+	return true, nil
 }
 
-// CreatePanier is the resolver for the createPanier field.
-func (r *mutationResolver) CreatePanier(ctx context.Context, commerceID *string, input model.NewPanier) (*model.Panier, error) {
-	user := auth.ForContext(ctx)
-
-	var databaseCommerce *commerces.Commerce
+// CreatePromoCode is the resolver for the createPromoCode field.
+func (r *mutationResolver) CreatePromoCode(ctx context.Context, commerceID *string, input model.NewPromoCode) (*model.PromoCode, error) {
+	var commerceObjectID *primitive.ObjectID
 
-	// On a d'abord besoin de trouver le commerce de l'utilisateur ou celui en paramètre
-	if commerceID == nil {
-		userDatabaseCommerce, err := r.CommercesService.GetForUser(user.ID.Hex())
+	// Sans commerce, un administrateur crée un code de la plateforme
+	if commerceID != nil || !auth.ForContext(ctx).HasRole(model.RoleAdmin) {
+		databaseCommerce, err := r.getManagedCommerce(ctx, commerceID)
 
 		if err != nil {
 			return nil, err
 		}
 
-		databaseCommerce = userDatabaseCommerce
+		commerceObjectID = &databaseCommerce.ID
+	}
+
+	databasePromoCode, err := r.PromoCodesService.Create(commerceObjectID, input)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return databasePromoCode.ToModel(), nil
+}
+
+// DeletePromoCode is the resolver for the deletePromoCode field.
+func (r *mutationResolver) DeletePromoCode(ctx context.Context, id string) (bool, error) {
+	databasePromoCode, err := r.PromoCodesService.GetById(id)
+
+	if err != nil {
+		return false, err
+	}
+
+	if databasePromoCode == nil {
+		return false, &promocodes.PromoCodeNotFoundError{}
+	}
+
+	if databasePromoCode.CommerceID == nil {
+		if !auth.ForContext(ctx).HasRole(model.RoleAdmin) {
+			return false, &users.UserAccessDenied{}
+		}
 	} else {
-		commerceDatabaseCommerce, err := r.CommercesService.GetById(*commerceID)
+		commerceID := databasePromoCode.CommerceID.Hex()
+		_, err = r.getManagedCommerce(ctx, &commerceID)
 
 		if err != nil {
-			return nil, err
+			return false, err
 		}
+	}
 
-		databaseCommerce = commerceDatabaseCommerce
+	err = r.PromoCodesService.Delete(id)
+
+	if err != nil {
+		return false, err
 	}
 
-	if databaseCommerce == nil {
-		return nil, &commerces.CommerceErrorNotFound{}
+	return true, nil
+}
+
+// CreateCategory is the resolver for the createCategory field.
+func (r *mutationResolver) CreateCategory(ctx context.Context, commerceID *string, input model.NewCategory) (*model.Category, error) {
+	databaseCommerce, err := r.getManagedCommerce(ctx, commerceID)
+
+	if err != nil {
+		return nil, err
 	}
 
-	databasePanier, err := r.PaniersService.Create(databaseCommerce.ID, input)
+	databaseCategory, err := r.CategoriesService.Create(databaseCommerce.ID, input)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return databasePanier.ToModel(), nil
+	return databaseCategory.ToModel(), nil
 }
 
-// UpdatePanier is the resolver for the updatePanier field.
-func (r *mutationResolver) UpdatePanier(ctx context.Context, id string, changes map[string]interface{}) (*model.Panier, error) {
-	databasePanier, err := r.PaniersService.GetById(id)
+// UpdateCategory is the resolver for the updateCategory field.
+func (r *mutationResolver) UpdateCategory(ctx context.Context, id string, changes model.ChangesCategory) (*model.Category, error) {
+	databaseCategory, err := r.getManagedCategory(ctx, id)
 
 	if err != nil {
 		return nil, err
 	}
 
-	if databasePanier == nil {
-		return nil, &paniers.PanierNotFoundError{}
+	err = r.CategoriesService.Update(databaseCategory, changes)
+
+	if err != nil {
+		return nil, err
 	}
 
-	// On doit faire un traitement spécial pour les produits
-	products := []paniers.PanierProduct{}
+	return databaseCategory.ToModel(), nil
+}
 
-	if changes["products"] == nil {
-		products = databasePanier.Products
-	} else {
-		productsChange := changes["products"].([]interface{})
-		for _, product := range productsChange {
-			castedProduct := product.(map[string]interface{})
+// RenameCategory is the resolver for the renameCategory field.
+func (r *mutationResolver) RenameCategory(ctx context.Context, id string, name string) (*model.Category, error) {
+	databaseCategory, err := r.getManagedCategory(ctx, id)
 
-			productObjectID, err := primitive.ObjectIDFromHex(castedProduct["productID"].(string))
+	if err != nil {
+		return nil, err
+	}
 
-			if err != nil {
+	err = r.CategoriesService.Rename(databaseCategory, name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return databaseCategory.ToModel(), nil
+}
+
+// ReorderCategories is the resolver for the reorderCategories field.
+func (r *mutationResolver) ReorderCategories(ctx context.Context, commerceID *string, categoryIDs []string) ([]*model.Category, error) {
+	databaseCommerce, err := r.getManagedCommerce(ctx, commerceID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	databaseCategories, err := r.CategoriesService.Reorder(databaseCommerce.ID, categoryIDs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*model.Category{}
+
+	for _, databaseCategory := range databaseCategories {
+		result = append(result, databaseCategory.ToModel())
+	}
+
+	return result, nil
+}
+
+// MergeCategories is the resolver for the mergeCategories field.
+func (r *mutationResolver) MergeCategories(ctx context.Context, targetID string, sourceIDs []string) (*model.Category, error) {
+	databaseCategory, err := r.getManagedCategory(ctx, targetID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.CategoriesService.Merge(databaseCategory, sourceIDs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return databaseCategory.ToModel(), nil
+}
+
+// CreateProduct is the resolver for the createProduct field.
+func (r *mutationResolver) CreateProduct(ctx context.Context, commerceID *string, input model.NewProduct) (*model.Product, error) {
+	user := auth.ForContext(ctx)
+
+	if user.Role == users.USERROLE_ADMIN && commerceID == nil {
+		return nil, &products.MustSpecifyCommerceIDError{}
+	}
+
+	// Si l'utilisateur est un commerçant, il doit créer des produits
+	// pour son commerce
+	if user.Role == users.USERROLE_STOREKEEPER {
+		databaseCommerce, err := r.CommercesService.GetForUser(user.ID.Hex())
+
+		// Cela permet aussi d'éviter qu'un commerçant créer un
+		// produit sans commerce
+		if err != nil {
+			return nil, err
+		}
+
+		databaseCommerceID := databaseCommerce.ID.Hex()
+		commerceID = &databaseCommerceID
+	}
+
+	err := r.setNewProductCategories(*commerceID, &input)
+
+	if err != nil {
+		return nil, err
+	}
+
+	databaseProduct, err := r.ProductsService.Create(*commerceID, input)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Si le commerçant a souscrit au Click&Collect, le produit doit automatiquement y être ajouté
+	databaseCommerce, err := r.CommercesService.GetForUser(user.ID.Hex())
+
+	if err != nil {
+		return databaseProduct.ToModel(), nil
+	}
+
+	commerceSubscribedToCC := false
+
+	for _, service := range databaseCommerce.Services {
+		if strings.Contains(service, "CLICKANDCOLLECT") {
+			commerceSubscribedToCC = true
+			break
+		}
+	}
+
+	if commerceSubscribedToCC {
+		databaseCommerce.ProductsAvailableForClickAndCollect = append(databaseCommerce.ProductsAvailableForClickAndCollect, databaseProduct.ID.Hex())
+	}
+
+	r.CommercesService.Update(databaseCommerce, nil, nil)
+
+	return databaseProduct.ToModel(), nil
+}
+
+// CreateProducts is the resolver for the createProducts field.
+func (r *mutationResolver) CreateProducts(ctx context.Context, commerceID *string, input []*model.NewProduct) ([]*model.Product, error) {
+	user := auth.ForContext(ctx)
+
+	if user.Role == users.USERROLE_ADMIN && commerceID == nil {
+		return nil, &products.MustSpecifyCommerceIDError{}
+	}
+
+	// Si l'utilisateur est un commerçant, il doit créer des produits
+	// pour son commerce
+	if user.Role == users.USERROLE_STOREKEEPER {
+		databaseCommerce, err := r.CommercesService.GetForUser(user.ID.Hex())
+
+		// Cela permet aussi d'éviter qu'un commerçant créer un
+		// produit sans commerce
+		if err != nil {
+			return nil, err
+		}
+
+		databaseCommerceID := databaseCommerce.ID.Hex()
+		commerceID = &databaseCommerceID
+	}
+
+	result := []*model.Product{}
+
+	for _, produdct := range input {
+		err := r.setNewProductCategories(*commerceID, produdct)
+
+		if err != nil {
+			return nil, err
+		}
+
+		databaseProduct, err := r.ProductsService.Create(*commerceID, *produdct)
+
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, databaseProduct.ToModel())
+	}
+
+	return result, nil
+}
+
+// SetProductStatus is the resolver for the setProductStatus field.
+func (r *mutationResolver) SetProductStatus(ctx context.Context, id string, status model.ProductStatus, publishAt *time.Time, unpublishAt *time.Time) (*model.Product, error) {
+	databaseProduct, err := r.getManagedProduct(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.ProductsService.SetStatus(databaseProduct, status, publishAt, unpublishAt)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return databaseProduct.ToModel(), nil
+}
+
+// SetProductSeasons is the resolver for the setProductSeasons field.
+func (r *mutationResolver) SetProductSeasons(ctx context.Context, id string, seasons []*model.ProductSeasonInput) (*model.Product, error) {
+	databaseProduct, err := r.getManagedProduct(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.ProductsService.SetSeasons(databaseProduct, seasons)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return databaseProduct.ToModel(), nil
+}
+
+// DeleteProduct is the resolver for the deleteProduct field.
+func (r *mutationResolver) DeleteProduct(ctx context.Context, id string) (*model.Product, error) {
+	databaseProduct, err := r.getManagedProduct(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.deleteProduct(databaseProduct)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return databaseProduct.ToModel(), nil
+}
+
+// ImportProducts is the resolver for the importProducts field.
+func (r *mutationResolver) ImportProducts(ctx context.Context, commerceID *string, file graphql.Upload, mapping []*model.ProductColumnMapping, dryRun *bool) (*model.ProductImportReport, error) {
+	databaseCommerce, err := r.getManagedCommerce(ctx, commerceID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := products.ReadCatalogueFile(file)
+
+	if err != nil {
+		return nil, err
+	}
+
+	user := auth.ForContext(ctx)
+	report, err := r.ProductsService.Import(databaseCommerce.ID, rows, mapping, dryRun == nil || *dryRun, user.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Le catalogue importé ne donne que les noms des catégories
+	if dryRun != nil && !*dryRun {
+		err = r.CategoriesService.SyncProductCategories(bson.M{"commerceID": databaseCommerce.ID})
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return report.ToModel(), nil
+}
+
+// UpdateProduct is the resolver for the updateProduct field.
+func (r *mutationResolver) UpdateProduct(ctx context.Context, id string, changes map[string]interface{}) (*model.Product, error) {
+	databaseProduct, err := r.ProductsService.GetById(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if databaseProduct == nil {
+		return nil, &products.ProductNotFoundError{}
+	}
+
+	err = r.applyProductCategoryChanges(databaseProduct, changes)
+
+	if err != nil {
+		return nil, err
+	}
+
+	helper.ApplyChanges(changes, databaseProduct)
+
+	var image *graphql.Upload
+
+	if changes["image"] != nil {
+		castedImage := changes["image"].(graphql.Upload)
+		image = &castedImage
+	}
+
+	err = r.ProductsService.Update(databaseProduct, image, auth.ForContext(ctx).ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return databaseProduct.ToModel(), nil
+}
+
+// UpdateProducts is the resolver for the updateProducts field.
+func (r *mutationResolver) UpdateProducts(ctx context.Context, changes []*model.BulkChangesProduct) ([]*model.Product, error) {
+	result := []*model.Product{}
+
+	for _, change := range changes {
+		databaseProduct, err := r.ProductsService.GetById(change.ID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if databaseProduct == nil {
+			return nil, &products.ProductNotFoundError{}
+		}
+
+		err = r.applyProductCategoryChanges(databaseProduct, change.Changes)
+
+		if err != nil {
+			return nil, err
+		}
+
+		helper.ApplyChanges(change.Changes, databaseProduct)
+
+		var image *graphql.Upload
+
+		if change.Changes["image"] != nil {
+			castedImage := change.Changes["image"].(graphql.Upload)
+			image = &castedImage
+		}
+
+		err = r.ProductsService.Update(databaseProduct, image, auth.ForContext(ctx).ID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, databaseProduct.ToModel())
+	}
+
+	return result, nil
+}
+
+// UpdateCommerceCommand is the resolver for the updateCommerceCommand field.
+func (r *mutationResolver) UpdateCommerceCommand(ctx context.Context, id string, changes map[string]interface{}) (*model.CommerceCommand, error) {
+	databaseCommerceCommand, err := r.CommerceCommandsService.GetById(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if databaseCommerceCommand == nil {
+		return nil, &commands.CommerceCommandNotFoundError{}
+	}
+
+	commerceID := databaseCommerceCommand.CommerceID.Hex()
+	_, err = r.getManagedCommerce(ctx, &commerceID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	previousStatus := databaseCommerceCommand.Status
+	wasCredited := databaseCommerceCommand.IsCredited()
+	helper.ApplyChanges(changes, databaseCommerceCommand)
+
+	if !commands.IsValidCommerceCommandStatus(databaseCommerceCommand.Status) {
+		return nil, &commands.InvalidCommerceCommandStatusError{}
+	}
+
+	// Une annulation n'est faite qu'une fois : le stock, la carte cadeau
+	// et le solde du commerce ne sont rendus qu'à ce moment
+	if previousStatus == commands.COMMERCE_COMMAND_STATUS_CANCELED && databaseCommerceCommand.Status != commands.COMMERCE_COMMAND_STATUS_CANCELED {
+		return nil, &commands.CommerceCommandCanceledError{}
+	}
+
+	isCanceling := databaseCommerceCommand.Status == commands.COMMERCE_COMMAND_STATUS_CANCELED && databaseCommerceCommand.CanceledAt == nil
+
+	if isCanceling {
+		// Le paiement par carte est rendu en premier : s'il échoue, la
+		// commande n'est pas annulée et l'annulation peut être refaite
+		err = stripehandler.RefundCanceledOrder(databaseCommerceCommand)
+
+		if err != nil {
+			return nil, err
+		}
+
+		now := time.Now()
+		databaseCommerceCommand.CanceledAt = &now
+	}
+
+	err = r.CommerceCommandsService.Update(databaseCommerceCommand)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Les produits d'une commande annulée sont remis en stock, le montant
+	// payé par carte cadeau est recrédité sur la carte et le commerce perd
+	// ce qui lui avait été crédité
+	if isCanceling {
+		err = r.StockService.Release(databaseCommerceCommand.ID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if wasCredited {
+			err = r.CommercesService.UpdateBalancesForOrder(
+				databaseCommerceCommand.CommerceID.Hex(),
+				-databaseCommerceCommand.Price,
+				-databaseCommerceCommand.PriceClickAndCollect,
+				-databaseCommerceCommand.PricePaniers,
+				-databaseCommerceCommand.PlatformFundedAmount(),
+			)
+
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if databaseCommerceCommand.GiftCardID != nil && databaseCommerceCommand.GiftCardAmount > 0 {
+			err = r.GiftCardsService.Refund(
+				*databaseCommerceCommand.GiftCardID,
+				int(math.Round(databaseCommerceCommand.GiftCardAmount*100)),
+				databaseCommerceCommand.CommerceID,
+				databaseCommerceCommand.ID,
+			)
+
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return databaseCommerceCommand.ToModel(), nil
+}
+
+// RecordActualWeights is the resolver for the recordActualWeights field.
+func (r *mutationResolver) RecordActualWeights(ctx context.Context, commerceCommandID string, weights []*model.ActualWeightInput) (*model.CommerceCommand, error) {
+	databaseCommerceCommand, err := r.CommerceCommandsService.GetById(commerceCommandID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if databaseCommerceCommand == nil {
+		return nil, &commands.CommerceCommandNotFoundError{}
+	}
+
+	commerceID := databaseCommerceCommand.CommerceID.Hex()
+	_, err = r.getManagedCommerce(ctx, &commerceID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if databaseCommerceCommand.WeighedAt != nil {
+		return nil, &commands.CommerceCommandAlreadyWeighedError{}
+	}
+
+	databaseCCCommands, err := r.CCCommandsService.SetActualQuantities(commerceCommandID, weights)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Les lignes pouvant être repesées, le prix est corrigé de la
+	// différence avec l'écart précédent
+	weightAdjustment, isComplete := commands.WeightAdjustment(databaseCCCommands)
+	difference := weightAdjustment - databaseCommerceCommand.WeightAdjustment
+
+	databaseCommerceCommand.Price = databaseCommerceCommand.Price + int(math.Round(difference*100))
+	databaseCommerceCommand.PriceClickAndCollect = databaseCommerceCommand.PriceClickAndCollect + difference
+	databaseCommerceCommand.WeightAdjustment = weightAdjustment
+
+	// Tout est pesé : le paiement est ajusté au montant réel, dont le
+	// commerce est alors crédité
+	if isComplete {
+		now := time.Now()
+		databaseCommerceCommand.WeighedAt = &now
+
+		paidPrice := 0
+
+		if databaseCommerceCommand.PaymentIntentID != nil {
+			paidPrice, err = stripehandler.SettleWeighedOrder(databaseCommerceCommand)
+
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// Au delà du montant payé par carte bancaire, l'écart reste à la
+		// charge du commerce
+		if paidPrice < databaseCommerceCommand.Price {
+			unpaid := float64(databaseCommerceCommand.Price-paidPrice) / 100
+
+			databaseCommerceCommand.Price = paidPrice
+			databaseCommerceCommand.PriceClickAndCollect = databaseCommerceCommand.PriceClickAndCollect - unpaid
+			databaseCommerceCommand.WeightAdjustment = databaseCommerceCommand.WeightAdjustment - unpaid
+		}
+
+		// La carte cadeau a payé plus que le montant réel : la
+		// différence lui est rendue
+		if databaseCommerceCommand.Price < 0 && databaseCommerceCommand.GiftCardID != nil {
+			err = r.GiftCardsService.RefundWeighing(
+				*databaseCommerceCommand.GiftCardID,
+				-databaseCommerceCommand.Price,
+				databaseCommerceCommand.CommerceID,
+				databaseCommerceCommand.ID,
+			)
+
+			if err != nil {
+				return nil, err
+			}
+
+			databaseCommerceCommand.GiftCardAmount = databaseCommerceCommand.GiftCardAmount + float64(databaseCommerceCommand.Price)/100
+			databaseCommerceCommand.Price = 0
+		}
+
+		err = r.CommercesService.UpdateBalancesForOrder(
+			commerceID,
+			databaseCommerceCommand.Price,
+			databaseCommerceCommand.PriceClickAndCollect,
+			databaseCommerceCommand.PricePaniers,
+			databaseCommerceCommand.PlatformFundedAmount(),
+		)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = r.CommerceCommandsService.Update(databaseCommerceCommand)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return databaseCommerceCommand.ToModel(), nil
+}
+
+// SetProductStockTracking is the resolver for the setProductStockTracking field.
+func (r *mutationResolver) SetProductStockTracking(ctx context.Context, productID string, variantID *string, tracked bool, lowStockThreshold *int) (*model.Product, error) {
+	databaseProduct, err := r.getManagedProduct(ctx, productID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	variantObjectID, err := utils.OptionalObjectID(variantID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	threshold := 0
+
+	if lowStockThreshold != nil {
+		threshold = *lowStockThreshold
+	}
+
+	err = r.StockService.SetTracking(databaseProduct, variantObjectID, tracked, threshold)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return databaseProduct.ToModel(), nil
+}
+
+// AdjustProductStock is the resolver for the adjustProductStock field.
+func (r *mutationResolver) AdjustProductStock(ctx context.Context, productID string, variantID *string, quantity int, reason *string) (*model.Product, error) {
+	databaseProduct, err := r.getManagedProduct(ctx, productID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	variantObjectID, err := utils.OptionalObjectID(variantID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	user := auth.ForContext(ctx)
+	err = r.StockService.Adjust(databaseProduct, variantObjectID, quantity, reason, user.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return databaseProduct.ToModel(), nil
+}
+
+// CreateProductVariant is the resolver for the createProductVariant field.
+func (r *mutationResolver) CreateProductVariant(ctx context.Context, productID string, input model.NewProductVariant) (*model.ProductVariant, error) {
+	databaseProduct, err := r.getManagedProduct(ctx, productID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	variant, err := r.ProductsService.CreateVariant(databaseProduct, input)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return variant.ToModel(databaseProduct), nil
+}
+
+// UpdateProductVariant is the resolver for the updateProductVariant field.
+func (r *mutationResolver) UpdateProductVariant(ctx context.Context, productID string, variantID string, changes model.ChangesProductVariant) (*model.ProductVariant, error) {
+	databaseProduct, err := r.getManagedProduct(ctx, productID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	variantObjectID, err := primitive.ObjectIDFromHex(variantID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	variant, err := r.ProductsService.UpdateVariant(databaseProduct, variantObjectID, changes)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return variant.ToModel(databaseProduct), nil
+}
+
+// DeleteProductVariant is the resolver for the deleteProductVariant field.
+func (r *mutationResolver) DeleteProductVariant(ctx context.Context, productID string, variantID string) (*model.ProductVariant, error) {
+	databaseProduct, err := r.getManagedProduct(ctx, productID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	variantObjectID, err := primitive.ObjectIDFromHex(variantID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	variant, err := r.ProductsService.DeleteVariant(databaseProduct, variantObjectID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// La déclinaison n'est plus proposée en Click&Collect
+	databaseCommerce, err := r.CommercesService.GetById(databaseProduct.CommerceID.Hex())
+
+	if err == nil && databaseCommerce != nil {
+		for index, availableVariantID := range databaseCommerce.VariantsAvailableForClickAndCollect {
+			if availableVariantID == variantID {
+				databaseCommerce.VariantsAvailableForClickAndCollect = utils.RemoveString(databaseCommerce.VariantsAvailableForClickAndCollect, index)
+				r.CommercesService.Update(databaseCommerce, nil, nil)
+				break
+			}
+		}
+	}
+
+	return variant.ToModel(databaseProduct), nil
+}
+
+// CreatePanier is the resolver for the createPanier field.
+func (r *mutationResolver) CreatePanier(ctx context.Context, commerceID *string, input model.NewPanier) (*model.Panier, error) {
+	user := auth.ForContext(ctx)
+
+	var databaseCommerce *commerces.Commerce
+
+	// On a d'abord besoin de trouver le commerce de l'utilisateur ou celui en paramètre
+	if commerceID == nil {
+		userDatabaseCommerce, err := r.CommercesService.GetForUser(user.ID.Hex())
+
+		if err != nil {
+			return nil, err
+		}
+
+		databaseCommerce = userDatabaseCommerce
+	} else {
+		commerceDatabaseCommerce, err := r.CommercesService.GetById(*commerceID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		databaseCommerce = commerceDatabaseCommerce
+	}
+
+	if databaseCommerce == nil {
+		return nil, &commerces.CommerceErrorNotFound{}
+	}
+
+	databasePanier, err := r.PaniersService.Create(databaseCommerce.ID, input)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return databasePanier.ToModel(), nil
+}
+
+// UpdatePanier is the resolver for the updatePanier field.
+func (r *mutationResolver) UpdatePanier(ctx context.Context, id string, changes map[string]interface{}) (*model.Panier, error) {
+	databasePanier, err := r.PaniersService.GetById(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if databasePanier == nil {
+		return nil, &paniers.PanierNotFoundError{}
+	}
+
+	// On doit faire un traitement spécial pour les produits
+	products := []paniers.PanierProduct{}
+
+	if changes["products"] == nil {
+		products = databasePanier.Products
+	} else {
+		productsChange := changes["products"].([]interface{})
+		for _, product := range productsChange {
+			castedProduct := product.(map[string]interface{})
+
+			productObjectID, err := primitive.ObjectIDFromHex(castedProduct["productID"].(string))
+
+			if err != nil {
 				return nil, err
 			}
 
 			castedQuantity := castedProduct["quantity"].(json.Number)
 			quantity, err := castedQuantity.Int64()
 
-			if err != nil {
-				return nil, err
-			}
+			if err != nil {
+				return nil, err
+			}
+
+			products = append(products, paniers.PanierProduct{
+				ID:        primitive.NewObjectID(),
+				ProductID: productObjectID,
+				Quantity:  int(quantity),
+			})
+		}
+	}
+
+	helper.ApplyChanges(changes, databasePanier)
+	databasePanier.Products = products
+
+	var image *graphql.Upload
+
+	if changes["image"] != nil {
+		castedImage := changes["image"].(graphql.Upload)
+		image = &castedImage
+	}
+
+	err = r.PaniersService.Update(databasePanier, image)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return databasePanier.ToModel(), nil
+}
+
+// CreateMarket is the resolver for the createMarket field.
+func (r *mutationResolver) CreateMarket(ctx context.Context, input model.NewMarket) (*model.Market, error) {
+	databaseMarket, err := r.MarketsService.Create(input)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return databaseMarket.ToModel(), nil
+}
+
+// JoinMarket is the resolver for the joinMarket field.
+func (r *mutationResolver) JoinMarket(ctx context.Context, marketID string, commerceID *string) (*model.Market, error) {
+	databaseCommerce, err := r.getManagedCommerce(ctx, commerceID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	databaseMarket, err := r.MarketsService.AddCommerce(marketID, databaseCommerce.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return databaseMarket.ToModel(), nil
+}
+
+// LeaveMarket is the resolver for the leaveMarket field.
+func (r *mutationResolver) LeaveMarket(ctx context.Context, marketID string, commerceID *string) (*model.Market, error) {
+	databaseCommerce, err := r.getManagedCommerce(ctx, commerceID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	databaseMarket, err := r.MarketsService.RemoveCommerce(marketID, databaseCommerce.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return databaseMarket.ToModel(), nil
+}
+
+// CreatePickupPoint is the resolver for the createPickupPoint field.
+func (r *mutationResolver) CreatePickupPoint(ctx context.Context, input model.NewPickupPoint) (*model.PickupPoint, error) {
+	// Le gestionnaire du point relais doit avoir le rôle adapté
+	var manager *users.User
+
+	if input.ManagerID != nil {
+		databaseUser, err := r.UsersService.GetUserById(*input.ManagerID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if databaseUser == nil {
+			return nil, &users.UserNotFoundError{}
+		}
+
+		manager = databaseUser
+	}
+
+	databasePickupPoint, err := r.PickupPointsService.Create(input)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if manager != nil && manager.Role == users.USERROLE_USER {
+		manager.Role = users.USERROLE_PICKUP_MANAGER
+		err = r.UsersService.Update(manager)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return databasePickupPoint.ToModel(), nil
+}
+
+// JoinPickupPoint is the resolver for the joinPickupPoint field.
+func (r *mutationResolver) JoinPickupPoint(ctx context.Context, pickupPointID string, commerceID *string, days []model.Weekday) (*model.PickupPoint, error) {
+	databaseCommerce, err := r.getManagedCommerce(ctx, commerceID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	databasePickupPoint, err := r.PickupPointsService.SetCommerce(pickupPointID, databaseCommerce.ID, days)
 
-			products = append(products, paniers.PanierProduct{
-				ID:        primitive.NewObjectID(),
-				ProductID: productObjectID,
-				Quantity:  int(quantity),
-			})
-		}
+	if err != nil {
+		return nil, err
 	}
 
-	helper.ApplyChanges(changes, databasePanier)
-	databasePanier.Products = products
+	return databasePickupPoint.ToModel(), nil
+}
 
-	var image *graphql.Upload
+// LeavePickupPoint is the resolver for the leavePickupPoint field.
+func (r *mutationResolver) LeavePickupPoint(ctx context.Context, pickupPointID string, commerceID *string) (*model.PickupPoint, error) {
+	databaseCommerce, err := r.getManagedCommerce(ctx, commerceID)
 
-	if changes["image"] != nil {
-		castedImage := changes["image"].(graphql.Upload)
-		image = &castedImage
+	if err != nil {
+		return nil, err
 	}
 
-	err = r.PaniersService.Update(databasePanier, image)
+	databasePickupPoint, err := r.PickupPointsService.RemoveCommerce(pickupPointID, databaseCommerce.ID)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return databasePanier.ToModel(), nil
+	return databasePickupPoint.ToModel(), nil
 }
 
 // Users is the resolver for the users field.
@@ -648,6 +1617,13 @@ func (r *queryResolver) User(ctx context.Context, id *string) (*model.User, erro
 
 // Commerces is the resolver for the commerces field.
 func (r *queryResolver) Commerces(ctx context.Context, first *int, after *string, filter *model.CommerceFilter) (*model.CommerceConnection, error) {
+	// Seuls les administrateurs peuvent lister les commerces non actifs
+	user := auth.ForContext(ctx)
+
+	if filter != nil && (user == nil || user.Role != users.USERROLE_ADMIN) {
+		filter.Status = nil
+	}
+
 	var decodedCursor *string
 
 	if after != nil {
@@ -709,7 +1685,21 @@ func (r *queryResolver) Commerces(ctx context.Context, first *int, after *string
 }
 
 // Commerce is the resolver for the commerce field.
-func (r *queryResolver) Commerce(ctx context.Context, id *string) (*model.Commerce, error) {
+func (r *queryResolver) Commerce(ctx context.Context, id *string, slug *string) (*model.Commerce, error) {
+	if slug != nil {
+		databaseCommerce, err := r.CommercesService.GetBySlug(*slug)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if databaseCommerce == nil {
+			return nil, &commerces.CommerceErrorNotFound{}
+		}
+
+		return databaseCommerce.ToModel(), nil
+	}
+
 	if id != nil {
 		databaseCommerce, err := r.CommercesService.GetById(*id)
 
@@ -743,6 +1733,62 @@ func (r *queryResolver) Commerce(ctx context.Context, id *string) (*model.Commer
 	return databaseCommerce.ToModel(), nil
 }
 
+// CommercesInBounds is the resolver for the commercesInBounds field.
+func (r *queryResolver) CommercesInBounds(ctx context.Context, bbox model.BoundingBox, zoom int) (*model.CommercesInBounds, error) {
+	box := geojson.BoundingBox{
+		West:  bbox.West,
+		South: bbox.South,
+		East:  bbox.East,
+		North: bbox.North,
+	}
+
+	databaseCommerces, databaseClusters, err := r.CommercesService.GetInBounds(box, zoom)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := model.CommercesInBounds{
+		Commerces: []*model.Commerce{},
+		Clusters:  []*model.CommerceCluster{},
+	}
+
+	for _, databaseCommerce := range databaseCommerces {
+		result.Commerces = append(result.Commerces, databaseCommerce.ToModel())
+	}
+
+	for _, databaseCluster := range databaseClusters {
+		result.Clusters = append(result.Clusters, databaseCluster.ToModel())
+	}
+
+	return &result, nil
+}
+
+// CommercesGeoJSON is the resolver for the commercesGeoJSON field.
+func (r *queryResolver) CommercesGeoJSON(ctx context.Context, postalCode *string, bbox *model.BoundingBox) (*model.GeoJSONExport, error) {
+	var box *geojson.BoundingBox
+
+	if bbox != nil {
+		box = &geojson.BoundingBox{
+			West:  bbox.West,
+			South: bbox.South,
+			East:  bbox.East,
+			North: bbox.North,
+		}
+	}
+
+	data, etag, err := opendatahandler.CommercesGeoJSON(r.CommercesService, r.ProductsService, postalCode, box)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.GeoJSONExport{
+		Data: string(data),
+		Etag: etag,
+	}, nil
+}
+
 // Product is the resolver for the product field.
 func (r *queryResolver) Product(ctx context.Context, id string) (*model.Product, error) {
 	databaseProduct, err := r.ProductsService.GetById(id)
@@ -754,6 +1800,193 @@ func (r *queryResolver) Product(ctx context.Context, id string) (*model.Product,
 	return databaseProduct.ToModel(), nil
 }
 
+// PlatformPromoCodes is the resolver for the platformPromoCodes field.
+func (r *queryResolver) PlatformPromoCodes(ctx context.Context) ([]*model.PromoCode, error) {
+	databasePromoCodes, err := r.PromoCodesService.GetForCommerce(nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*model.PromoCode{}
+
+	for _, databasePromoCode := range databasePromoCodes {
+		result = append(result, databasePromoCode.ToModel())
+	}
+
+	return result, nil
+}
+
+// GiftCards is the resolver for the giftCards field.
+func (r *queryResolver) GiftCards(ctx context.Context) ([]*model.GiftCard, error) {
+	user := auth.ForContext(ctx)
+
+	databaseGiftCards, err := r.GiftCardsService.GetForPurchaser(user.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*model.GiftCard{}
+
+	for _, databaseGiftCard := range databaseGiftCards {
+		result = append(result, databaseGiftCard.ToModel())
+	}
+
+	return result, nil
+}
+
+// GiftCard is the resolver for the giftCard field.
+func (r *queryResolver) GiftCard(ctx context.Context, code string) (*model.GiftCard, error) {
+	databaseGiftCard, err := r.GiftCardsService.GetByCode(code)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if databaseGiftCard == nil {
+		return nil, &giftcards.GiftCardNotFoundError{}
+	}
+
+	giftCard := databaseGiftCard.ToModel()
+
+	// Le code suffit à consulter le solde, le destinataire et l'usage de
+	// la carte ne regardent que l'acheteur
+	user := auth.ForContext(ctx)
+
+	if user == nil || (user.Role != users.USERROLE_ADMIN && user.ID != databaseGiftCard.PurchaserID) {
+		giftCard.RecipientEmail = nil
+		giftCard.Message = nil
+		giftCard.Transactions = []*model.GiftCardTransaction{}
+	}
+
+	return giftCard, nil
+}
+
+// SearchProducts is the resolver for the searchProducts field.
+func (r *queryResolver) SearchProducts(ctx context.Context, first *int, after *string, filter *model.ProductSearchFilter) (*model.ProductSearchConnection, error) {
+	var decodedCursor *string
+
+	if after != nil {
+		bytes, err := base64.StdEncoding.DecodeString(*after)
+
+		if err != nil {
+			return nil, err
+		}
+
+		decodedCursorString := string(bytes)
+		decodedCursor = &decodedCursorString
+	}
+
+	// Seuls les produits des commerces actifs sont proposés
+	var databaseCommerces []commerces.Commerce
+	var err error
+
+	if filter != nil && filter.NearLatitude != nil && filter.NearLongitude != nil {
+		databaseCommerces, err = r.CommercesService.GetActiveNear(*filter.NearLatitude, *filter.NearLongitude, filter.Radius)
+	} else {
+		databaseCommerces, err = r.CommercesService.GetActive(nil, nil)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	commercesByID := map[primitive.ObjectID]*commerces.Commerce{}
+	commerceIDs := []primitive.ObjectID{}
+
+	for i := range databaseCommerces {
+		commercesByID[databaseCommerces[i].ID] = &databaseCommerces[i]
+		commerceIDs = append(commerceIDs, databaseCommerces[i].ID)
+	}
+
+	// Le client peut envoyer null malgré la valeur par défaut du schéma
+	limit := 20
+
+	if first != nil {
+		limit = *first
+	}
+
+	result, err := r.ProductsService.Search(commerceIDs, filter, decodedCursor, limit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	edges := []*model.ProductSearchEdge{}
+
+	for _, databaseProduct := range result.Products {
+		commerce := commercesByID[databaseProduct.CommerceID]
+
+		if commerce == nil {
+			continue
+		}
+
+		edges = append(edges, &model.ProductSearchEdge{
+			Cursor:   base64.StdEncoding.EncodeToString([]byte(databaseProduct.ID.Hex())),
+			Node:     databaseProduct.ToModel(),
+			Commerce: commerce.ToModel(),
+		})
+	}
+
+	pageInfo := model.ProductPageInfo{
+		HasNextPage: result.HasNextPage,
+	}
+
+	if len(edges) > 0 {
+		pageInfo.StartCursor = edges[0].Cursor
+		pageInfo.EndCursor = edges[len(edges)-1].Cursor
+	}
+
+	return &model.ProductSearchConnection{
+		TotalCount: result.TotalCount,
+		Edges:      edges,
+		PageInfo:   &pageInfo,
+		Facets:     result.Facets.ToModel(),
+	}, nil
+}
+
+// ExportProducts is the resolver for the exportProducts field.
+func (r *queryResolver) ExportProducts(ctx context.Context, commerceID *string, format *model.CatalogueFormat) (*model.CatalogueExport, error) {
+	databaseCommerce, err := r.getManagedCommerce(ctx, commerceID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	exportFormat := model.CatalogueFormatCSV
+
+	if format != nil {
+		exportFormat = *format
+	}
+
+	data, err := r.ProductsService.Export(databaseCommerce.ID, exportFormat)
+
+	if err != nil {
+		return nil, err
+	}
+
+	fileName := "produits-" + databaseCommerce.Slug
+	contentType := "text/csv"
+
+	if databaseCommerce.Slug == "" {
+		fileName = "produits-" + databaseCommerce.ID.Hex()
+	}
+
+	if exportFormat == model.CatalogueFormatXlsx {
+		fileName += ".xlsx"
+		contentType = xlsx.CONTENT_TYPE
+	} else {
+		fileName += ".csv"
+	}
+
+	return &model.CatalogueExport{
+		FileName:    fileName,
+		ContentType: contentType,
+		Data:        base64.StdEncoding.EncodeToString(data),
+	}, nil
+}
+
 // Commands is the resolver for the commands field.
 func (r *queryResolver) Commands(ctx context.Context, first *int, after *string, filter *model.CommandsFilter) (*model.CommandConnection, error) {
 	if filter == nil {
@@ -969,6 +2202,166 @@ func (r *queryResolver) Panier(ctx context.Context, id string) (*model.Panier, e
 	return databasePanier.ToModel(), nil
 }
 
+// Markets is the resolver for the markets field.
+func (r *queryResolver) Markets(ctx context.Context, nearLatitude float64, nearLongitude float64, radius *float64) ([]*model.Market, error) {
+	databaseMarkets, err := r.MarketsService.GetNear(nearLatitude, nearLongitude, radius)
+
+	if err != nil {
+		return nil, err
+	}
+
+	markets := []*model.Market{}
+
+	for _, databaseMarket := range databaseMarkets {
+		markets = append(markets, databaseMarket.ToModel())
+	}
+
+	return markets, nil
+}
+
+// Market is the resolver for the market field.
+func (r *queryResolver) Market(ctx context.Context, id string) (*model.Market, error) {
+	databaseMarket, err := r.MarketsService.GetById(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if databaseMarket == nil {
+		return nil, &markets.MarketNotFoundError{}
+	}
+
+	return databaseMarket.ToModel(), nil
+}
+
+// PickupPoints is the resolver for the pickupPoints field.
+func (r *queryResolver) PickupPoints(ctx context.Context, nearLatitude float64, nearLongitude float64, radius *float64) ([]*model.PickupPoint, error) {
+	databasePickupPoints, err := r.PickupPointsService.GetNear(nearLatitude, nearLongitude, radius)
+
+	if err != nil {
+		return nil, err
+	}
+
+	pickupPoints := []*model.PickupPoint{}
+
+	for _, databasePickupPoint := range databasePickupPoints {
+		pickupPoints = append(pickupPoints, databasePickupPoint.ToModel())
+	}
+
+	return pickupPoints, nil
+}
+
+// PickupPoint is the resolver for the pickupPoint field.
+func (r *queryResolver) PickupPoint(ctx context.Context, id string) (*model.PickupPoint, error) {
+	databasePickupPoint, err := r.PickupPointsService.GetById(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if databasePickupPoint == nil {
+		return nil, &pickuppoints.PickupPointNotFoundError{}
+	}
+
+	return databasePickupPoint.ToModel(), nil
+}
+
+// PickupPointParcels is the resolver for the pickupPointParcels field.
+func (r *queryResolver) PickupPointParcels(ctx context.Context, pickupPointID *string, date time.Time) ([]*model.PickupPointParcel, error) {
+	user := auth.ForContext(ctx)
+
+	// Le gestionnaire ne voit que les colis de son point relais
+	var databasePickupPoint *pickuppoints.PickupPoint
+	var err error
+
+	if pickupPointID == nil {
+		databasePickupPoint, err = r.PickupPointsService.GetForManager(user.ID.Hex())
+	} else {
+		databasePickupPoint, err = r.PickupPointsService.GetById(*pickupPointID)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if databasePickupPoint == nil {
+		return nil, &pickuppoints.PickupPointNotFoundError{}
+	}
+
+	if user.Role != users.USERROLE_ADMIN && (databasePickupPoint.ManagerID == nil || *databasePickupPoint.ManagerID != user.ID) {
+		return nil, &users.UserAccessDenied{}
+	}
+
+	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+
+	filter := bson.M{
+		"pickupPointID": databasePickupPoint.ID,
+		"pickupDate": bson.M{
+			"$gte": startOfDay,
+			"$lt":  startOfDay.AddDate(0, 0, 1),
+		},
+		"status": bson.M{
+			"$ne": commands.COMMERCE_COMMAND_STATUS_CANCELED,
+		},
+	}
+
+	databaseCommerceCommands, err := r.CommerceCommandsService.GetFiltered(filter, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// On regroupe les commandes par client pour qu'il reparte avec
+	// tous ses colis en une fois
+	parcels := []*model.PickupPointParcel{}
+	parcelsByCommand := map[string]*model.PickupPointParcel{}
+
+	for _, databaseCommerceCommand := range databaseCommerceCommands {
+		commandID := databaseCommerceCommand.CommandID.Hex()
+		parcel, ok := parcelsByCommand[commandID]
+
+		if !ok {
+			parcel = &model.PickupPointParcel{
+				CommandID:        commandID,
+				CommerceCommands: []*model.CommerceCommand{},
+			}
+
+			parcelsByCommand[commandID] = parcel
+			parcels = append(parcels, parcel)
+		}
+
+		parcel.CommerceCommands = append(parcel.CommerceCommands, databaseCommerceCommand.ToModel())
+	}
+
+	return parcels, nil
+}
+
+// LookupSiret is the resolver for the lookupSiret field.
+func (r *queryResolver) LookupSiret(ctx context.Context, siret string) (*model.SiretLookup, error) {
+	// Les SIRET sont parfois saisis avec des espaces
+	siret = strings.ReplaceAll(siret, " ", "")
+
+	if !sirene.IsValidSiret(siret) {
+		return nil, &sirene.InvalidSiretError{}
+	}
+
+	databaseEstablishment, err := r.SireneService.GetBySiret(siret)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if databaseEstablishment == nil {
+		return nil, &sirene.EstablishmentNotFoundError{}
+	}
+
+	if !databaseEstablishment.IsActive() {
+		return nil, &sirene.EstablishmentClosedError{}
+	}
+
+	return databaseEstablishment.ToModel(), nil
+}
+
 // Mutation returns generated.MutationResolver implementation.
 func (r *Resolver) Mutation() generated.MutationResolver { return &mutationResolver{r} }
 
@@ -0,0 +1,423 @@
+package resolver_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"chemin-du-local.bzh/graphql/graph/generated"
+	"chemin-du-local.bzh/graphql/graph/model"
+	"chemin-du-local.bzh/graphql/graph/resolvers"
+	"chemin-du-local.bzh/graphql/internal/auth"
+	"chemin-du-local.bzh/graphql/internal/categories"
+	"chemin-du-local.bzh/graphql/internal/commerces"
+	"chemin-du-local.bzh/graphql/internal/mocks"
+	"chemin-du-local.bzh/graphql/internal/promotions"
+	"chemin-du-local.bzh/graphql/internal/users"
+	"chemin-du-local.bzh/graphql/pkg/geojson"
+	"github.com/99designs/gqlgen/client"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Reprend la configuration des directives du serveur
+func withDirectives(c generated.Config) generated.Config {
+	c.Directives.NeedAuthentication = func(ctx context.Context, obj interface{}, next graphql.Resolver) (interface{}, error) {
+		if auth.ForContext(ctx) == nil {
+			return nil, &users.UserAccessDenied{}
+		}
+
+		return next(ctx)
+	}
+	c.Directives.HasRole = func(ctx context.Context, obj interface{}, next graphql.Resolver, role model.Role) (interface{}, error) {
+		if !auth.ForContext(ctx).HasRole(role) {
+			return nil, &users.UserAccessDenied{}
+		}
+
+		return next(ctx)
+	}
+
+	return c
+}
+
+// Tests sur les statistiques de vente d'un commerce
+func TestCommerceResolver_Statistics(t *testing.T) {
+	// Les modèles
+	storekeeper := users.User{
+		ID:   primitive.NewObjectID(),
+		Role: users.USERROLE_STOREKEEPER,
+	}
+
+	otherStorekeeper := users.User{
+		ID:   primitive.NewObjectID(),
+		Role: users.USERROLE_STOREKEEPER,
+	}
+
+	admin := users.User{
+		ID:   primitive.NewObjectID(),
+		Role: users.USERROLE_ADMIN,
+	}
+
+	commerceID := primitive.NewObjectID()
+	commerce := commerces.Commerce{
+		ID:            commerceID,
+		StorekeeperID: storekeeper.ID,
+		Name:          "Mon Super Commerce",
+		AddressGeo: geojson.GeoJSON{
+			Type:        "Point",
+			Coordinates: []float64{-1.7779691219329834, 48.09312057495117},
+		},
+	}
+
+	expectedStatistics := model.CommerceStatistics{
+		Revenue:            152.5,
+		OrderCount:         4,
+		AverageBasket:      38.13,
+		NewCustomers:       3,
+		ReturningCustomers: 1,
+		TopProducts:        []*model.ProductStatistics{},
+		TopPaniers:         []*model.PanierStatistics{},
+		TimeSeries:         []*model.StatisticsPoint{},
+	}
+
+	q := `
+		query GetStatistics($id: ID, $from: Time!, $to: Time!) {
+			commerce(id: $id) {
+				statistics(period: { from: $from, to: $to }, granularity: WEEK) {
+					revenue
+					orderCount
+					averageBasket
+					newCustomers
+					returningCustomers
+				}
+			}
+		}
+	`
+
+	from := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2022, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	newClient := func(user *users.User) (*client.Client, *mocks.StatisticsService) {
+		testCommercesService := new(mocks.CommercesService)
+		testStatisticsService := new(mocks.StatisticsService)
+		resolvers := resolvers.Resolver{
+			CommercesService:  testCommercesService,
+			StatisticsService: testStatisticsService,
+		}
+
+		testCommercesService.On("GetById", commerceID.Hex()).Return(&commerce, nil)
+		testStatisticsService.On(
+			"GetForCommerce",
+			commerceID.Hex(),
+			mock.AnythingOfType("model.StatisticsPeriod"),
+			model.StatisticsGranularityWeek,
+		).Return(&expectedStatistics, nil)
+
+		options := []client.Option{}
+
+		if user != nil {
+			options = append(options, addContext(user))
+		}
+
+		return client.New(
+			handler.NewDefaultServer(generated.NewExecutableSchema(withDirectives(generated.Config{Resolvers: &resolvers}))),
+			options...,
+		), testStatisticsService
+	}
+
+	t.Run("query statistics as owner", func(t *testing.T) {
+		var resp struct {
+			Commerce struct {
+				Statistics struct {
+					Revenue            float64 `json:"revenue"`
+					OrderCount         int     `json:"orderCount"`
+					AverageBasket      float64 `json:"averageBasket"`
+					NewCustomers       int     `json:"newCustomers"`
+					ReturningCustomers int     `json:"returningCustomers"`
+				} `json:"statistics"`
+			} `json:"commerce"`
+		}
+
+		c, _ := newClient(&storekeeper)
+		c.MustPost(
+			q,
+			&resp,
+			client.Var("id", commerceID.Hex()),
+			client.Var("from", from),
+			client.Var("to", to),
+		)
+
+		require.Equal(t, expectedStatistics.Revenue, resp.Commerce.Statistics.Revenue)
+		require.Equal(t, expectedStatistics.OrderCount, resp.Commerce.Statistics.OrderCount)
+		require.Equal(t, expectedStatistics.AverageBasket, resp.Commerce.Statistics.AverageBasket)
+		require.Equal(t, expectedStatistics.NewCustomers, resp.Commerce.Statistics.NewCustomers)
+		require.Equal(t, expectedStatistics.ReturningCustomers, resp.Commerce.Statistics.ReturningCustomers)
+	})
+
+	t.Run("query statistics as admin", func(t *testing.T) {
+		var resp struct {
+			Commerce struct {
+				Statistics struct {
+					Revenue            float64 `json:"revenue"`
+					OrderCount         int     `json:"orderCount"`
+					AverageBasket      float64 `json:"averageBasket"`
+					NewCustomers       int     `json:"newCustomers"`
+					ReturningCustomers int     `json:"returningCustomers"`
+				} `json:"statistics"`
+			} `json:"commerce"`
+		}
+
+		c, _ := newClient(&admin)
+		c.MustPost(
+			q,
+			&resp,
+			client.Var("id", commerceID.Hex()),
+			client.Var("from", from),
+			client.Var("to", to),
+		)
+
+		require.Equal(t, expectedStatistics.Revenue, resp.Commerce.Statistics.Revenue)
+	})
+
+	t.Run("query statistics of another commerce", func(t *testing.T) {
+		var resp struct{}
+
+		c, testStatisticsService := newClient(&otherStorekeeper)
+		err := c.Post(
+			q,
+			&resp,
+			client.Var("id", commerceID.Hex()),
+			client.Var("from", from),
+			client.Var("to", to),
+		)
+
+		testStatisticsService.AssertNotCalled(t, "GetForCommerce", mock.Anything, mock.Anything, mock.Anything)
+		require.Error(t, err)
+	})
+
+	t.Run("query statistics without authentication", func(t *testing.T) {
+		var resp struct{}
+
+		c, testStatisticsService := newClient(nil)
+		err := c.Post(
+			q,
+			&resp,
+			client.Var("id", commerceID.Hex()),
+			client.Var("from", from),
+			client.Var("to", to),
+		)
+
+		testStatisticsService.AssertNotCalled(t, "GetForCommerce", mock.Anything, mock.Anything, mock.Anything)
+		require.Error(t, err)
+	})
+}
+
+// Tests sur les métadonnées SEO d'un commerce
+func TestCommerceResolver_Seo(t *testing.T) {
+	commerce := commerces.Commerce{
+		ID:         primitive.NewObjectID(),
+		Slug:       "mon-super-commerce",
+		Name:       "Mon Super Commerce",
+		AddressGeo: geojson.NewPoint(-1.68, 48.11),
+	}
+
+	testCommercesService := new(mocks.CommercesService)
+	resolvers := resolvers.Resolver{
+		CommercesService: testCommercesService,
+	}
+
+	testCommercesService.On("GetBySlug", commerce.Slug).Return(&commerce, nil)
+
+	c := client.New(handler.NewDefaultServer(generated.NewExecutableSchema(withDirectives(generated.Config{Resolvers: &resolvers}))))
+
+	t.Run("the metadata are built without fetching the commerce again", func(t *testing.T) {
+		var resp struct {
+			Commerce struct {
+				Seo struct {
+					JSONLd    string `json:"jsonLd"`
+					OpenGraph []struct {
+						Property string `json:"property"`
+						Content  string `json:"content"`
+					} `json:"openGraph"`
+				} `json:"seo"`
+			} `json:"commerce"`
+		}
+
+		c.MustPost(`
+			query {
+				commerce(slug: "mon-super-commerce") {
+					seo {
+						jsonLd
+						openGraph {
+							property
+							content
+						}
+					}
+				}
+			}
+		`, &resp)
+
+		require.Contains(t, resp.Commerce.Seo.JSONLd, `"name":"Mon Super Commerce"`)
+		require.Equal(t, "og:type", resp.Commerce.Seo.OpenGraph[0].Property)
+		testCommercesService.AssertNumberOfCalls(t, "GetBySlug", 1)
+		testCommercesService.AssertNotCalled(t, "GetById", mock.Anything)
+	})
+}
+
+// Tests sur les promotions d'un commerce
+func TestCommerceResolver_Promotions(t *testing.T) {
+	storekeeper := users.User{
+		ID:   primitive.NewObjectID(),
+		Role: users.USERROLE_STOREKEEPER,
+	}
+
+	commerce := commerces.Commerce{
+		ID:            primitive.NewObjectID(),
+		StorekeeperID: storekeeper.ID,
+		Name:          "Mon Super Commerce",
+		AddressGeo:    geojson.NewPoint(-1.68, 48.11),
+	}
+
+	category := "Fruits"
+	activePromotion := promotions.Promotion{
+		ID:         primitive.NewObjectID(),
+		CommerceID: commerce.ID,
+		Name:       "Semaine des fruits",
+		Type:       model.PromotionTypePercentage.String(),
+		Target:     model.PromotionTargetCategory.String(),
+		Category:   &category,
+		Value:      10,
+		StartDate:  time.Now().Add(-time.Hour),
+		EndDate:    time.Now().Add(time.Hour),
+	}
+	scheduledPromotion := promotions.Promotion{
+		ID:         primitive.NewObjectID(),
+		CommerceID: commerce.ID,
+		Name:       "Soldes d'hiver",
+		Type:       model.PromotionTypePercentage.String(),
+		Target:     model.PromotionTargetCategory.String(),
+		Category:   &category,
+		Value:      30,
+		StartDate:  time.Now().AddDate(0, 1, 0),
+		EndDate:    time.Now().AddDate(0, 2, 0),
+	}
+
+	q := `
+		query Commerce($id: ID) {
+			commerce(id: $id) {
+				promotions {
+					name
+				}
+			}
+		}
+	`
+
+	newClient := func(user *users.User) *client.Client {
+		testCommercesService := new(mocks.CommercesService)
+		testPromotionsService := new(mocks.PromotionsService)
+		resolvers := resolvers.Resolver{
+			CommercesService:  testCommercesService,
+			PromotionsService: testPromotionsService,
+		}
+
+		testCommercesService.On("GetById", commerce.ID.Hex()).Return(&commerce, nil)
+		testPromotionsService.On("GetForCommerce", commerce.ID.Hex()).Return([]promotions.Promotion{activePromotion, scheduledPromotion}, nil)
+		testPromotionsService.On("GetActiveForCommerce", commerce.ID, mock.AnythingOfType("time.Time")).Return([]promotions.Promotion{activePromotion}, nil)
+
+		options := []client.Option{}
+
+		if user != nil {
+			options = append(options, addContext(user))
+		}
+
+		return client.New(
+			handler.NewDefaultServer(generated.NewExecutableSchema(withDirectives(generated.Config{Resolvers: &resolvers}))),
+			options...,
+		)
+	}
+
+	type response struct {
+		Commerce struct {
+			Promotions []struct {
+				Name string `json:"name"`
+			} `json:"promotions"`
+		} `json:"commerce"`
+	}
+
+	t.Run("the public only sees the current promotions", func(t *testing.T) {
+		var resp response
+
+		newClient(nil).MustPost(q, &resp, client.Var("id", commerce.ID.Hex()))
+
+		require.Len(t, resp.Commerce.Promotions, 1)
+		require.Equal(t, activePromotion.Name, resp.Commerce.Promotions[0].Name)
+	})
+
+	t.Run("the storekeeper also sees the scheduled promotions", func(t *testing.T) {
+		var resp response
+
+		newClient(&storekeeper).MustPost(q, &resp, client.Var("id", commerce.ID.Hex()))
+
+		require.Len(t, resp.Commerce.Promotions, 2)
+	})
+}
+
+// Tests sur les catégories d'un commerce
+func TestCommerceResolver_Categories(t *testing.T) {
+	commerce := commerces.Commerce{
+		ID:         primitive.NewObjectID(),
+		Name:       "Mon Super Commerce",
+		AddressGeo: geojson.NewPoint(-1.68, 48.11),
+	}
+
+	commerceCategories := []categories.Category{
+		{ID: primitive.NewObjectID(), CommerceID: commerce.ID, Name: "Légumes", Position: 0},
+		{ID: primitive.NewObjectID(), CommerceID: commerce.ID, Name: "Fruits", Position: 1},
+	}
+
+	testCommercesService := new(mocks.CommercesService)
+	testCategoriesService := new(mocks.CategoriesService)
+	resolvers := resolvers.Resolver{
+		CommercesService:  testCommercesService,
+		CategoriesService: testCategoriesService,
+	}
+
+	testCommercesService.On("GetById", commerce.ID.Hex()).Return(&commerce, nil)
+	testCategoriesService.On("GetForCommerce", commerce.ID).Return(commerceCategories, nil)
+
+	c := client.New(handler.NewDefaultServer(generated.NewExecutableSchema(withDirectives(generated.Config{Resolvers: &resolvers}))))
+
+	t.Run("the categories keep the order of the storekeeper", func(t *testing.T) {
+		var resp struct {
+			Commerce struct {
+				Categories        []string `json:"categories"`
+				ProductCategories []struct {
+					ID       string `json:"id"`
+					Name     string `json:"name"`
+					Position int    `json:"position"`
+				} `json:"productCategories"`
+			} `json:"commerce"`
+		}
+
+		c.MustPost(`
+			query Commerce($id: ID) {
+				commerce(id: $id) {
+					categories
+					productCategories {
+						id
+						name
+						position
+					}
+				}
+			}
+		`, &resp, client.Var("id", commerce.ID.Hex()))
+
+		require.Equal(t, []string{"Légumes", "Fruits"}, resp.Commerce.Categories)
+		require.Len(t, resp.Commerce.ProductCategories, 2)
+		require.Equal(t, commerceCategories[0].ID.Hex(), resp.Commerce.ProductCategories[0].ID)
+		require.Equal(t, 1, resp.Commerce.ProductCategories[1].Position)
+	})
+}
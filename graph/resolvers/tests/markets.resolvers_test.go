@@ -0,0 +1,76 @@
+package resolver_test
+
+import (
+	"testing"
+
+	"chemin-du-local.bzh/graphql/graph/generated"
+	"chemin-du-local.bzh/graphql/graph/resolvers"
+	"chemin-du-local.bzh/graphql/internal/commerces"
+	"chemin-du-local.bzh/graphql/internal/markets"
+	"chemin-du-local.bzh/graphql/internal/mocks"
+	"chemin-du-local.bzh/graphql/pkg/geojson"
+	"github.com/99designs/gqlgen/client"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Tests sur les marchés autour d'un point et leurs commerces
+func TestQueryResolver_Markets(t *testing.T) {
+	// Les modèles
+	commerce := commerces.Commerce{
+		ID:         primitive.NewObjectID(),
+		Name:       "Mon Super Commerce",
+		AddressGeo: geojson.NewPoint(-1.7779691219329834, 48.09312057495117),
+	}
+
+	market := markets.Market{
+		ID:          primitive.NewObjectID(),
+		Name:        "Marché des Lices",
+		AddressGeo:  geojson.NewPoint(-1.6833, 48.1125),
+		CommercesID: []primitive.ObjectID{commerce.ID},
+	}
+
+	testCommercesService := new(mocks.CommercesService)
+	testMarketsService := new(mocks.MarketsService)
+	resolvers := resolvers.Resolver{
+		CommercesService: testCommercesService,
+		MarketsService:   testMarketsService,
+	}
+
+	radius := 5000.0
+	testMarketsService.On("GetNear", 48.11, -1.68, &radius).Return([]markets.Market{market}, nil)
+	testCommercesService.On("GetById", commerce.ID.Hex()).Return(&commerce, nil)
+
+	c := client.New(handler.NewDefaultServer(generated.NewExecutableSchema(withDirectives(generated.Config{Resolvers: &resolvers}))))
+
+	t.Run("the markets near a point are returned with their commerces", func(t *testing.T) {
+		var resp struct {
+			Markets []struct {
+				ID        string `json:"id"`
+				Commerces []struct {
+					ID   string `json:"id"`
+					Name string `json:"name"`
+				} `json:"commerces"`
+			} `json:"markets"`
+		}
+
+		c.MustPost(`
+			query {
+				markets(nearLatitude: 48.11, nearLongitude: -1.68, radius: 5000) {
+					id
+					commerces {
+						id
+						name
+					}
+				}
+			}
+		`, &resp)
+
+		require.Len(t, resp.Markets, 1)
+		require.Equal(t, market.ID.Hex(), resp.Markets[0].ID)
+		require.Len(t, resp.Markets[0].Commerces, 1)
+		require.Equal(t, commerce.ID.Hex(), resp.Markets[0].Commerces[0].ID)
+		require.Equal(t, "Mon Super Commerce", resp.Markets[0].Commerces[0].Name)
+	})
+}
@@ -0,0 +1,89 @@
+package resolver_test
+
+import (
+	"testing"
+	"time"
+
+	"chemin-du-local.bzh/graphql/graph/generated"
+	"chemin-du-local.bzh/graphql/graph/model"
+	"chemin-du-local.bzh/graphql/graph/resolvers"
+	"chemin-du-local.bzh/graphql/internal/mocks"
+	"chemin-du-local.bzh/graphql/internal/promotions"
+	"chemin-du-local.bzh/graphql/internal/services/paniers"
+	"github.com/99designs/gqlgen/client"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Tests sur le prix remisé d'un panier
+func TestPanierResolver_DiscountedPrice(t *testing.T) {
+	commerceID := primitive.NewObjectID()
+	panier := paniers.Panier{
+		ID:         primitive.NewObjectID(),
+		CommerceID: commerceID,
+		Name:       "Panier de saison",
+		Price:      20,
+		Reduction:  10,
+	}
+
+	newClient := func(activePromotions []promotions.Promotion) *client.Client {
+		testPaniersService := new(mocks.PaniersService)
+		testPromotionsService := new(mocks.PromotionsService)
+		resolvers := resolvers.Resolver{
+			PaniersService:    testPaniersService,
+			PromotionsService: testPromotionsService,
+		}
+
+		testPaniersService.On("GetById", panier.ID.Hex()).Return(&panier, nil)
+		testPromotionsService.On("GetActiveForCommerce", commerceID, mock.AnythingOfType("time.Time")).Return(activePromotions, nil)
+
+		return client.New(handler.NewDefaultServer(generated.NewExecutableSchema(withDirectives(generated.Config{Resolvers: &resolvers}))))
+	}
+
+	q := `
+		query Panier($id: ID!) {
+			panier(id: $id) {
+				originalPrice
+				discountedPrice
+			}
+		}
+	`
+
+	type response struct {
+		Panier struct {
+			OriginalPrice   float64 `json:"originalPrice"`
+			DiscountedPrice float64 `json:"discountedPrice"`
+		} `json:"panier"`
+	}
+
+	t.Run("the reduction is applied", func(t *testing.T) {
+		var resp response
+
+		newClient([]promotions.Promotion{}).MustPost(q, &resp, client.Var("id", panier.ID.Hex()))
+
+		require.Equal(t, 20.0, resp.Panier.OriginalPrice)
+		require.Equal(t, 18.0, resp.Panier.DiscountedPrice)
+	})
+
+	t.Run("a promotion applies to the reduced price", func(t *testing.T) {
+		var resp response
+
+		newClient([]promotions.Promotion{
+			{
+				ID:         primitive.NewObjectID(),
+				CommerceID: commerceID,
+				Name:       "Deux euros de remise",
+				Type:       model.PromotionTypeFixedAmount.String(),
+				Target:     model.PromotionTargetPanier.String(),
+				PanierID:   &panier.ID,
+				Value:      2,
+				StartDate:  time.Now().Add(-time.Hour),
+				EndDate:    time.Now().Add(time.Hour),
+			},
+		}).MustPost(q, &resp, client.Var("id", panier.ID.Hex()))
+
+		require.Equal(t, 16.0, resp.Panier.DiscountedPrice)
+	})
+}
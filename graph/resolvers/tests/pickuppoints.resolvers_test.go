@@ -0,0 +1,92 @@
+package resolver_test
+
+import (
+	"testing"
+
+	"chemin-du-local.bzh/graphql/graph/generated"
+	"chemin-du-local.bzh/graphql/graph/resolvers"
+	"chemin-du-local.bzh/graphql/internal/mocks"
+	"chemin-du-local.bzh/graphql/internal/pickuppoints"
+	"chemin-du-local.bzh/graphql/internal/users"
+	"chemin-du-local.bzh/graphql/pkg/geojson"
+	"github.com/99designs/gqlgen/client"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Tests sur le gestionnaire d'un point relais
+func TestPickupPointResolver_Manager(t *testing.T) {
+	// Les modèles
+	manager := users.User{
+		ID:   primitive.NewObjectID(),
+		Role: users.USERROLE_PICKUP_MANAGER,
+	}
+	deletedManagerID := primitive.NewObjectID()
+
+	pickupPoint := pickuppoints.PickupPoint{
+		ID:         primitive.NewObjectID(),
+		Name:       "Épicerie du bourg",
+		AddressGeo: geojson.NewPoint(-1.68, 48.11),
+		ManagerID:  &manager.ID,
+	}
+
+	orphanPickupPoint := pickuppoints.PickupPoint{
+		ID:         primitive.NewObjectID(),
+		Name:       "Bar du port",
+		AddressGeo: geojson.NewPoint(-1.69, 48.12),
+		ManagerID:  &deletedManagerID,
+	}
+
+	testUsersService := new(mocks.UsersService)
+	testPickupPointsService := new(mocks.PickupPointsService)
+	resolvers := resolvers.Resolver{
+		UsersService:        testUsersService,
+		PickupPointsService: testPickupPointsService,
+	}
+
+	testPickupPointsService.On("GetById", pickupPoint.ID.Hex()).Return(&pickupPoint, nil)
+	testPickupPointsService.On("GetById", orphanPickupPoint.ID.Hex()).Return(&orphanPickupPoint, nil)
+	testUsersService.On("GetUserById", manager.ID.Hex()).Return(&manager, nil)
+	testUsersService.On("GetUserById", deletedManagerID.Hex()).Return(nil, nil)
+
+	c := client.New(handler.NewDefaultServer(generated.NewExecutableSchema(withDirectives(generated.Config{Resolvers: &resolvers}))))
+
+	q := `
+		query PickupPoint($id: ID!) {
+			pickupPoint(id: $id) {
+				name
+				manager {
+					id
+				}
+			}
+		}
+	`
+
+	type response struct {
+		PickupPoint struct {
+			Name    string `json:"name"`
+			Manager *struct {
+				ID string `json:"id"`
+			} `json:"manager"`
+		} `json:"pickupPoint"`
+	}
+
+	t.Run("the manager of the pickup point is returned", func(t *testing.T) {
+		var resp response
+
+		c.MustPost(q, &resp, client.Var("id", pickupPoint.ID.Hex()))
+
+		require.NotNil(t, resp.PickupPoint.Manager)
+		require.Equal(t, manager.ID.Hex(), resp.PickupPoint.Manager.ID)
+	})
+
+	t.Run("a deleted manager is returned as null", func(t *testing.T) {
+		var resp response
+
+		c.MustPost(q, &resp, client.Var("id", orphanPickupPoint.ID.Hex()))
+
+		require.Equal(t, "Bar du port", resp.PickupPoint.Name)
+		require.Nil(t, resp.PickupPoint.Manager)
+	})
+}
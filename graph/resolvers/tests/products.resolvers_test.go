@@ -0,0 +1,407 @@
+package resolver_test
+
+import (
+	"testing"
+	"time"
+
+	"chemin-du-local.bzh/graphql/graph/generated"
+	"chemin-du-local.bzh/graphql/graph/model"
+	"chemin-du-local.bzh/graphql/graph/resolvers"
+	"chemin-du-local.bzh/graphql/internal/commerces"
+	"chemin-du-local.bzh/graphql/internal/mocks"
+	"chemin-du-local.bzh/graphql/internal/products"
+	"chemin-du-local.bzh/graphql/internal/promotions"
+	"chemin-du-local.bzh/graphql/internal/stock"
+	"chemin-du-local.bzh/graphql/internal/users"
+	"chemin-du-local.bzh/graphql/pkg/geojson"
+	"github.com/99designs/gqlgen/client"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Tests sur le prix remisé d'un produit
+func TestProductResolver_DiscountedPrice(t *testing.T) {
+	commerceID := primitive.NewObjectID()
+	product := products.Product{
+		ID:         primitive.NewObjectID(),
+		CommerceID: commerceID,
+		Name:       "Fraises de Plougastel",
+		Price:      10,
+		Categories: []string{"Fruits"},
+	}
+
+	category := "Fruits"
+	otherProductID := primitive.NewObjectID()
+	activePromotions := []promotions.Promotion{
+		{
+			ID:         primitive.NewObjectID(),
+			CommerceID: commerceID,
+			Name:       "Semaine des fruits",
+			Type:       model.PromotionTypePercentage.String(),
+			Target:     model.PromotionTargetCategory.String(),
+			Category:   &category,
+			Value:      20,
+			StartDate:  time.Now().Add(-time.Hour),
+			EndDate:    time.Now().Add(time.Hour),
+		},
+		{
+			ID:         primitive.NewObjectID(),
+			CommerceID: commerceID,
+			Name:       "Un euro de remise",
+			Type:       model.PromotionTypeFixedAmount.String(),
+			Target:     model.PromotionTargetProduct.String(),
+			ProductID:  &product.ID,
+			Value:      1,
+			StartDate:  time.Now().Add(-time.Hour),
+			EndDate:    time.Now().Add(time.Hour),
+		},
+		{
+			ID:         primitive.NewObjectID(),
+			CommerceID: commerceID,
+			Name:       "Autre produit",
+			Type:       model.PromotionTypePercentage.String(),
+			Target:     model.PromotionTargetProduct.String(),
+			ProductID:  &otherProductID,
+			Value:      50,
+			StartDate:  time.Now().Add(-time.Hour),
+			EndDate:    time.Now().Add(time.Hour),
+		},
+	}
+
+	testProductsService := new(mocks.ProductsService)
+	testPromotionsService := new(mocks.PromotionsService)
+	resolvers := resolvers.Resolver{
+		ProductsService:   testProductsService,
+		PromotionsService: testPromotionsService,
+	}
+
+	testProductsService.On("GetById", product.ID.Hex()).Return(&product, nil)
+	testPromotionsService.On("GetActiveForCommerce", commerceID, mock.AnythingOfType("time.Time")).Return(activePromotions, nil)
+
+	c := client.New(handler.NewDefaultServer(generated.NewExecutableSchema(withDirectives(generated.Config{Resolvers: &resolvers}))))
+
+	q := `
+		query Product($id: ID!) {
+			product(id: $id) {
+				originalPrice
+				discountedPrice
+				promotions {
+					name
+				}
+			}
+		}
+	`
+
+	t.Run("the best promotion is applied", func(t *testing.T) {
+		var resp struct {
+			Product struct {
+				OriginalPrice   float64 `json:"originalPrice"`
+				DiscountedPrice float64 `json:"discountedPrice"`
+				Promotions      []struct {
+					Name string `json:"name"`
+				} `json:"promotions"`
+			} `json:"product"`
+		}
+
+		c.MustPost(q, &resp, client.Var("id", product.ID.Hex()))
+
+		require.Equal(t, 10.0, resp.Product.OriginalPrice)
+		require.Equal(t, 8.0, resp.Product.DiscountedPrice)
+		require.Len(t, resp.Product.Promotions, 2)
+	})
+}
+
+// Tests sur le prix remisé des déclinaisons d'un produit
+func TestProductVariantResolver_DiscountedPrice(t *testing.T) {
+	commerceID := primitive.NewObjectID()
+	product := products.Product{
+		ID:         primitive.NewObjectID(),
+		CommerceID: commerceID,
+		Name:       "Cidre",
+		Price:      4,
+		Categories: []string{"Boissons"},
+		Variants: []products.ProductVariant{
+			{ID: primitive.NewObjectID(), Name: "75 cl", Price: 5},
+			{ID: primitive.NewObjectID(), Name: "1,5 l", Price: 8},
+		},
+	}
+
+	category := "Boissons"
+	activePromotions := []promotions.Promotion{
+		{
+			ID:         primitive.NewObjectID(),
+			CommerceID: commerceID,
+			Name:       "Semaine du cidre",
+			Type:       model.PromotionTypePercentage.String(),
+			Target:     model.PromotionTargetCategory.String(),
+			Category:   &category,
+			Value:      25,
+			StartDate:  time.Now().Add(-time.Hour),
+			EndDate:    time.Now().Add(time.Hour),
+		},
+	}
+
+	testProductsService := new(mocks.ProductsService)
+	testPromotionsService := new(mocks.PromotionsService)
+	resolvers := resolvers.Resolver{
+		ProductsService:   testProductsService,
+		PromotionsService: testPromotionsService,
+	}
+
+	testProductsService.On("GetById", product.ID.Hex()).Return(&product, nil)
+	testPromotionsService.On("GetActiveForCommerce", commerceID, mock.AnythingOfType("time.Time")).Return(activePromotions, nil)
+
+	server := handler.NewDefaultServer(generated.NewExecutableSchema(withDirectives(generated.Config{Resolvers: &resolvers})))
+	c := client.New(promotions.Middleware(testPromotionsService)(server))
+
+	q := `
+		query Product($id: ID!) {
+			product(id: $id) {
+				discountedPrice
+				variants {
+					price
+					discountedPrice
+				}
+			}
+		}
+	`
+
+	var resp struct {
+		Product struct {
+			DiscountedPrice float64 `json:"discountedPrice"`
+			Variants        []struct {
+				Price           float64 `json:"price"`
+				DiscountedPrice float64 `json:"discountedPrice"`
+			} `json:"variants"`
+		} `json:"product"`
+	}
+
+	c.MustPost(q, &resp, client.Var("id", product.ID.Hex()))
+
+	require.Equal(t, 3.0, resp.Product.DiscountedPrice)
+	require.Equal(t, 3.75, resp.Product.Variants[0].DiscountedPrice)
+	require.Equal(t, 6.0, resp.Product.Variants[1].DiscountedPrice)
+
+	// Les promotions du commerce ne sont lues qu'une fois par requête
+	testPromotionsService.AssertNumberOfCalls(t, "GetActiveForCommerce", 1)
+	testProductsService.AssertNumberOfCalls(t, "GetById", 1)
+}
+
+// Tests sur les métadonnées SEO d'un produit
+func TestProductResolver_Seo(t *testing.T) {
+	commerce := commerces.Commerce{
+		ID:         primitive.NewObjectID(),
+		Name:       "Mon Super Commerce",
+		AddressGeo: geojson.NewPoint(-1.68, 48.11),
+	}
+	product := products.Product{
+		ID:          primitive.NewObjectID(),
+		CommerceID:  commerce.ID,
+		Name:        "Fraises de Plougastel",
+		Description: "Barquette de 500g",
+		Price:       4.5,
+	}
+	commerce.ProductsAvailableForClickAndCollect = []string{product.ID.Hex()}
+
+	testProductsService := new(mocks.ProductsService)
+	testCommercesService := new(mocks.CommercesService)
+	resolvers := resolvers.Resolver{
+		ProductsService:  testProductsService,
+		CommercesService: testCommercesService,
+	}
+
+	testProductsService.On("GetById", product.ID.Hex()).Return(&product, nil)
+	testCommercesService.On("GetById", commerce.ID.Hex()).Return(&commerce, nil)
+
+	c := client.New(handler.NewDefaultServer(generated.NewExecutableSchema(withDirectives(generated.Config{Resolvers: &resolvers}))))
+
+	t.Run("the metadata are built without fetching the product again", func(t *testing.T) {
+		var resp struct {
+			Product struct {
+				Seo struct {
+					JSONLd    string `json:"jsonLd"`
+					OpenGraph []struct {
+						Property string `json:"property"`
+						Content  string `json:"content"`
+					} `json:"openGraph"`
+				} `json:"seo"`
+			} `json:"product"`
+		}
+
+		c.MustPost(`
+			query Product($id: ID!) {
+				product(id: $id) {
+					seo {
+						jsonLd
+						openGraph {
+							property
+							content
+						}
+					}
+				}
+			}
+		`, &resp, client.Var("id", product.ID.Hex()))
+
+		require.Contains(t, resp.Product.Seo.JSONLd, `"availability":"https://schema.org/InStock"`)
+		require.Contains(t, resp.Product.Seo.OpenGraph, struct {
+			Property string `json:"property"`
+			Content  string `json:"content"`
+		}{Property: "og:title", Content: "Fraises de Plougastel - Mon Super Commerce"})
+		testProductsService.AssertNumberOfCalls(t, "GetById", 1)
+		testCommercesService.AssertNumberOfCalls(t, "GetById", 1)
+	})
+}
+
+// Tests sur l'historique du stock d'un produit
+func TestProductResolver_StockMovements(t *testing.T) {
+	storekeeper := users.User{
+		ID:   primitive.NewObjectID(),
+		Role: users.USERROLE_STOREKEEPER,
+	}
+	commerce := commerces.Commerce{
+		ID:            primitive.NewObjectID(),
+		StorekeeperID: storekeeper.ID,
+	}
+	product := products.Product{
+		ID:         primitive.NewObjectID(),
+		CommerceID: commerce.ID,
+		Name:       "Fraises de Plougastel",
+	}
+
+	testProductsService := new(mocks.ProductsService)
+	testCommercesService := new(mocks.CommercesService)
+	testStockService := new(mocks.StockService)
+	resolvers := resolvers.Resolver{
+		ProductsService:  testProductsService,
+		CommercesService: testCommercesService,
+		StockService:     testStockService,
+	}
+
+	testProductsService.On("GetById", product.ID.Hex()).Return(&product, nil)
+	testCommercesService.On("GetById", commerce.ID.Hex()).Return(&commerce, nil)
+	testStockService.On("GetMovements", product.ID.Hex(), 20).Return([]stock.StockMovement{
+		{
+			ID:        primitive.NewObjectID(),
+			ProductID: product.ID,
+			Type:      stock.STOCK_MOVEMENT_TYPE_ADJUSTMENT,
+			Quantity:  5,
+			Date:      time.Now(),
+		},
+	}, nil)
+
+	c := client.New(handler.NewDefaultServer(generated.NewExecutableSchema(withDirectives(generated.Config{Resolvers: &resolvers}))))
+
+	t.Run("a null first falls back to the default limit", func(t *testing.T) {
+		var resp struct {
+			Product struct {
+				StockMovements []struct {
+					Quantity int `json:"quantity"`
+				} `json:"stockMovements"`
+			} `json:"product"`
+		}
+
+		c.MustPost(`
+			query Product($id: ID!) {
+				product(id: $id) {
+					stockMovements(first: null) {
+						quantity
+					}
+				}
+			}
+		`, &resp, client.Var("id", product.ID.Hex()), addContext(&storekeeper))
+
+		require.Len(t, resp.Product.StockMovements, 1)
+		require.Equal(t, 5, resp.Product.StockMovements[0].Quantity)
+	})
+}
+
+// Tests sur l'historique des prix d'un produit
+func TestProductResolver_PriceHistory(t *testing.T) {
+	storekeeper := users.User{
+		ID:   primitive.NewObjectID(),
+		Role: users.USERROLE_STOREKEEPER,
+	}
+	otherStorekeeper := users.User{
+		ID:   primitive.NewObjectID(),
+		Role: users.USERROLE_STOREKEEPER,
+	}
+	commerce := commerces.Commerce{
+		ID:            primitive.NewObjectID(),
+		StorekeeperID: storekeeper.ID,
+	}
+	product := products.Product{
+		ID:         primitive.NewObjectID(),
+		CommerceID: commerce.ID,
+		Name:       "Fraises de Plougastel",
+		Price:      10,
+		PriceHistory: []products.ProductPriceChange{
+			{PreviousPrice: 7, Price: 12, UserID: storekeeper.ID, Date: time.Now().AddDate(0, 0, -45)},
+			{PreviousPrice: 12, Price: 9, UserID: storekeeper.ID, Date: time.Now().AddDate(0, 0, -20)},
+			{PreviousPrice: 9, Price: 10, UserID: storekeeper.ID, Date: time.Now().AddDate(0, 0, -2)},
+		},
+	}
+
+	testProductsService := new(mocks.ProductsService)
+	testCommercesService := new(mocks.CommercesService)
+	resolvers := resolvers.Resolver{
+		ProductsService:  testProductsService,
+		CommercesService: testCommercesService,
+	}
+
+	testProductsService.On("GetById", product.ID.Hex()).Return(&product, nil)
+	testCommercesService.On("GetById", commerce.ID.Hex()).Return(&commerce, nil)
+
+	c := client.New(handler.NewDefaultServer(generated.NewExecutableSchema(withDirectives(generated.Config{Resolvers: &resolvers}))))
+
+	q := `
+		query Product($id: ID!) {
+			product(id: $id) {
+				lowestPriceLast30Days
+				priceHistory {
+					previousPrice
+					price
+				}
+			}
+		}
+	`
+
+	type response struct {
+		Product struct {
+			LowestPriceLast30Days float64 `json:"lowestPriceLast30Days"`
+			PriceHistory          []struct {
+				PreviousPrice float64 `json:"previousPrice"`
+				Price         float64 `json:"price"`
+			} `json:"priceHistory"`
+		} `json:"product"`
+	}
+
+	t.Run("the storekeeper sees the most recent changes first", func(t *testing.T) {
+		var resp response
+
+		c.MustPost(q, &resp, client.Var("id", product.ID.Hex()), addContext(&storekeeper))
+
+		require.Equal(t, 9.0, resp.Product.LowestPriceLast30Days)
+		require.Len(t, resp.Product.PriceHistory, 3)
+		require.Equal(t, 9.0, resp.Product.PriceHistory[0].PreviousPrice)
+		require.Equal(t, 10.0, resp.Product.PriceHistory[0].Price)
+	})
+
+	t.Run("a customer sees the product without its history", func(t *testing.T) {
+		var resp response
+
+		c.MustPost(q, &resp, client.Var("id", product.ID.Hex()))
+
+		require.Equal(t, 9.0, resp.Product.LowestPriceLast30Days)
+		require.Empty(t, resp.Product.PriceHistory)
+	})
+
+	t.Run("another storekeeper does not see the history", func(t *testing.T) {
+		var resp response
+
+		c.MustPost(q, &resp, client.Var("id", product.ID.Hex()), addContext(&otherStorekeeper))
+
+		require.Empty(t, resp.Product.PriceHistory)
+	})
+}
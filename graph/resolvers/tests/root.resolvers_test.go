@@ -10,10 +10,23 @@ import (
 	"chemin-du-local.bzh/graphql/graph/generated"
 	"chemin-du-local.bzh/graphql/graph/model"
 	"chemin-du-local.bzh/graphql/graph/resolvers"
+	"chemin-du-local.bzh/graphql/internal/address"
 	"chemin-du-local.bzh/graphql/internal/auth"
+	"chemin-du-local.bzh/graphql/internal/categories"
+	"chemin-du-local.bzh/graphql/internal/commerces"
+	"chemin-du-local.bzh/graphql/internal/giftcards"
+	"chemin-du-local.bzh/graphql/internal/markets"
 	"chemin-du-local.bzh/graphql/internal/mocks"
+	"chemin-du-local.bzh/graphql/internal/pickuppoints"
+	"chemin-du-local.bzh/graphql/internal/products"
+	"chemin-du-local.bzh/graphql/internal/promocodes"
+	"chemin-du-local.bzh/graphql/internal/services/commands"
+	"chemin-du-local.bzh/graphql/internal/services/paniers"
+	"chemin-du-local.bzh/graphql/internal/sirene"
 	"chemin-du-local.bzh/graphql/internal/users"
+	"chemin-du-local.bzh/graphql/pkg/geojson"
 	"github.com/99designs/gqlgen/client"
+	"github.com/99designs/gqlgen/graphql"
 	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -671,3 +684,1485 @@ func TestQueryResolver_User(t *testing.T) {
 		require.Error(t, err)
 	})
 }
+
+// Tests sur la recherche d'un établissement dans la base SIRENE
+func TestQueryResolver_LookupSiret(t *testing.T) {
+	// Les modèles
+	activeSiret := "12345678900015"
+	activeName := "BOULANGERIE DU BOURG"
+	activeNafCode := "10.71C"
+	activePostalCode := "35650"
+	activeCity := "LE RHEU"
+	activeEstablishment := sirene.Establishment{
+		Siret: activeSiret,
+		Siren: activeSiret[0:9],
+		Name:  activeName,
+		Address: address.Address{
+			ID:         primitive.NewObjectID(),
+			PostalCode: &activePostalCode,
+			City:       &activeCity,
+		},
+		NafCode:             activeNafCode,
+		AdministrativeState: sirene.ESTABLISHMENT_STATE_ACTIVE,
+	}
+
+	closedSiret := "98765432100031"
+	closedEstablishment := sirene.Establishment{
+		Siret:               closedSiret,
+		Siren:               closedSiret[0:9],
+		AdministrativeState: sirene.ESTABLISHMENT_STATE_CLOSED,
+	}
+
+	testSireneService := new(mocks.SireneService)
+	resolvers := resolvers.Resolver{SireneService: testSireneService}
+	c := client.New(
+		handler.NewDefaultServer(generated.NewExecutableSchema(generated.Config{Resolvers: &resolvers})),
+	)
+
+	testSireneService.On("GetBySiret", activeSiret).Return(&activeEstablishment, nil)
+	testSireneService.On("GetBySiret", closedSiret).Return(&closedEstablishment, nil)
+	testSireneService.On("GetBySiret", mock.AnythingOfType("string")).Return(nil, nil)
+
+	q := `
+		query LookupSiret($siret: String!) {
+			lookupSiret(siret: $siret) {
+				siret
+				siren
+				name
+				nafCode
+				isActive
+				address {
+					postalCode
+					city
+				}
+			}
+		}
+	`
+
+	t.Run("lookup an active establishment", func(t *testing.T) {
+		var resp struct {
+			LookupSiret struct {
+				Siret    string `json:"siret"`
+				Siren    string `json:"siren"`
+				Name     string `json:"name"`
+				NafCode  string `json:"nafCode"`
+				IsActive bool   `json:"isActive"`
+				Address  struct {
+					PostalCode string `json:"postalCode"`
+					City       string `json:"city"`
+				}
+			}
+		}
+
+		c.MustPost(q, &resp, client.Var("siret", "123 456 789 00015"))
+
+		require.Equal(t, activeSiret, resp.LookupSiret.Siret)
+		require.Equal(t, activeSiret[0:9], resp.LookupSiret.Siren)
+		require.Equal(t, activeName, resp.LookupSiret.Name)
+		require.Equal(t, activeNafCode, resp.LookupSiret.NafCode)
+		require.True(t, resp.LookupSiret.IsActive)
+		require.Equal(t, activePostalCode, resp.LookupSiret.Address.PostalCode)
+		require.Equal(t, activeCity, resp.LookupSiret.Address.City)
+	})
+
+	t.Run("lookup a closed establishment", func(t *testing.T) {
+		var resp struct{}
+
+		err := c.Post(q, &resp, client.Var("siret", closedSiret))
+
+		require.Error(t, err)
+	})
+
+	t.Run("lookup an unknown establishment", func(t *testing.T) {
+		var resp struct{}
+
+		err := c.Post(q, &resp, client.Var("siret", "11111111111110"))
+
+		require.Error(t, err)
+	})
+
+	t.Run("lookup an invalid siret", func(t *testing.T) {
+		var resp struct{}
+
+		err := c.Post(q, &resp, client.Var("siret", "1234"))
+
+		testSireneService.AssertNotCalled(t, "GetBySiret", "1234")
+		require.Error(t, err)
+	})
+
+	t.Run("lookup a siret with letters or a wrong check digit", func(t *testing.T) {
+		var resp struct{}
+
+		for _, siret := range []string{"1234567890001A", "12345678900012"} {
+			err := c.Post(q, &resp, client.Var("siret", siret))
+
+			testSireneService.AssertNotCalled(t, "GetBySiret", siret)
+			require.Error(t, err)
+		}
+	})
+}
+
+// Tests sur la gestion des congés d'un commerce
+func TestMutationResolver_UpdateCommerceVacation(t *testing.T) {
+	// Les modèles
+	storekeeper := users.User{
+		ID:   primitive.NewObjectID(),
+		Role: users.USERROLE_STOREKEEPER,
+	}
+
+	otherStorekeeper := users.User{
+		ID:   primitive.NewObjectID(),
+		Role: users.USERROLE_STOREKEEPER,
+	}
+
+	commerceID := primitive.NewObjectID()
+	newCommerce := func() *commerces.Commerce {
+		return &commerces.Commerce{
+			ID:            commerceID,
+			StorekeeperID: storekeeper.ID,
+			Name:          "Mon Super Commerce",
+			AddressGeo: geojson.GeoJSON{
+				Type:        "Point",
+				Coordinates: []float64{-1.7779691219329834, 48.09312057495117},
+			},
+		}
+	}
+
+	q := `
+		mutation UpdateCommerceVacation($id: ID!, $input: NewCommerceVacation) {
+			updateCommerceVacation(id: $id, input: $input) {
+				isOnVacation
+				vacation {
+					message
+					prorateBilling
+				}
+			}
+		}
+	`
+
+	newClient := func(user *users.User) (*client.Client, *mocks.CommercesService) {
+		testCommercesService := new(mocks.CommercesService)
+		resolvers := resolvers.Resolver{CommercesService: testCommercesService}
+
+		testCommercesService.On("GetById", commerceID.Hex()).Return(newCommerce(), nil)
+		testCommercesService.On("Update", mock.AnythingOfType("*commerces.Commerce"), (*graphql.Upload)(nil), (*graphql.Upload)(nil)).Return(nil)
+
+		return client.New(
+			handler.NewDefaultServer(generated.NewExecutableSchema(withDirectives(generated.Config{Resolvers: &resolvers}))),
+			addContext(user),
+		), testCommercesService
+	}
+
+	t.Run("start a vacation", func(t *testing.T) {
+		var resp struct {
+			UpdateCommerceVacation struct {
+				IsOnVacation bool `json:"isOnVacation"`
+				Vacation     struct {
+					Message        string `json:"message"`
+					ProrateBilling bool   `json:"prorateBilling"`
+				} `json:"vacation"`
+			} `json:"updateCommerceVacation"`
+		}
+
+		c, _ := newClient(&storekeeper)
+		c.MustPost(
+			q,
+			&resp,
+			client.Var("id", commerceID.Hex()),
+			client.Var("input", map[string]interface{}{
+				"startDate":      time.Now().AddDate(0, 0, -1),
+				"endDate":        time.Now().AddDate(0, 0, 14),
+				"message":        "Fermé pour congés annuels",
+				"prorateBilling": true,
+			}),
+		)
+
+		require.True(t, resp.UpdateCommerceVacation.IsOnVacation)
+		require.Equal(t, "Fermé pour congés annuels", resp.UpdateCommerceVacation.Vacation.Message)
+		require.True(t, resp.UpdateCommerceVacation.Vacation.ProrateBilling)
+	})
+
+	t.Run("cancel a vacation", func(t *testing.T) {
+		var resp struct {
+			UpdateCommerceVacation struct {
+				IsOnVacation bool      `json:"isOnVacation"`
+				Vacation     *struct{} `json:"vacation"`
+			} `json:"updateCommerceVacation"`
+		}
+
+		c, _ := newClient(&storekeeper)
+		c.MustPost(q, &resp, client.Var("id", commerceID.Hex()))
+
+		require.False(t, resp.UpdateCommerceVacation.IsOnVacation)
+		require.Nil(t, resp.UpdateCommerceVacation.Vacation)
+	})
+
+	t.Run("vacation ending before it starts", func(t *testing.T) {
+		var resp struct{}
+
+		c, testCommercesService := newClient(&storekeeper)
+		err := c.Post(
+			q,
+			&resp,
+			client.Var("id", commerceID.Hex()),
+			client.Var("input", map[string]interface{}{
+				"startDate": time.Now().AddDate(0, 0, 14),
+				"endDate":   time.Now(),
+			}),
+		)
+
+		testCommercesService.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+		require.Error(t, err)
+	})
+
+	t.Run("vacation of another commerce", func(t *testing.T) {
+		var resp struct{}
+
+		c, testCommercesService := newClient(&otherStorekeeper)
+		err := c.Post(
+			q,
+			&resp,
+			client.Var("id", commerceID.Hex()),
+			client.Var("input", map[string]interface{}{
+				"startDate": time.Now(),
+				"endDate":   time.Now().AddDate(0, 0, 14),
+			}),
+		)
+
+		testCommercesService.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+		require.Error(t, err)
+	})
+}
+
+// Tests sur la fermeture d'un commerce
+func TestMutationResolver_CloseCommerce(t *testing.T) {
+	// Les modèles
+	storekeeper := users.User{
+		ID:   primitive.NewObjectID(),
+		Role: users.USERROLE_STOREKEEPER,
+	}
+
+	commerceID := primitive.NewObjectID()
+	databaseCommerce := commerces.Commerce{
+		ID:            commerceID,
+		StorekeeperID: storekeeper.ID,
+		Name:          "Mon Super Commerce",
+		AddressGeo:    geojson.NewPoint(-1.7779691219329834, 48.09312057495117),
+		Status:        commerces.COMMERCE_STATUS_ACTIVE,
+	}
+
+	q := `
+		mutation CloseCommerce($id: ID!) {
+			closeCommerce(id: $id) {
+				id
+			}
+		}
+	`
+
+	newClient := func(inProgressCommands []commands.CommerceCommand) (*client.Client, *mocks.CommercesService) {
+		testCommercesService := new(mocks.CommercesService)
+		testCommerceCommandsService := new(mocks.CommerceCommandsService)
+		resolvers := resolvers.Resolver{
+			CommercesService:        testCommercesService,
+			CommerceCommandsService: testCommerceCommandsService,
+		}
+
+		testCommercesService.On("GetById", commerceID.Hex()).Return(&databaseCommerce, nil)
+		testCommercesService.On("Close", mock.AnythingOfType("*commerces.Commerce")).Return(nil)
+		testCommerceCommandsService.On("GetFiltered", mock.Anything, mock.Anything).Return(inProgressCommands, nil)
+
+		return client.New(
+			handler.NewDefaultServer(generated.NewExecutableSchema(withDirectives(generated.Config{Resolvers: &resolvers}))),
+			addContext(&storekeeper),
+		), testCommercesService
+	}
+
+	t.Run("close commerce", func(t *testing.T) {
+		var resp struct {
+			CloseCommerce struct {
+				ID string `json:"id"`
+			} `json:"closeCommerce"`
+		}
+
+		c, testCommercesService := newClient([]commands.CommerceCommand{})
+		c.MustPost(q, &resp, client.Var("id", commerceID.Hex()))
+
+		testCommercesService.AssertCalled(t, "Close", mock.AnythingOfType("*commerces.Commerce"))
+		require.Equal(t, commerceID.Hex(), resp.CloseCommerce.ID)
+	})
+
+	t.Run("close commerce with commands in progress", func(t *testing.T) {
+		var resp struct{}
+
+		c, testCommercesService := newClient([]commands.CommerceCommand{
+			{
+				ID:         primitive.NewObjectID(),
+				CommerceID: commerceID,
+				Status:     commands.COMMERCE_COMMAND_STATUS_IN_PROGRESS,
+			},
+		})
+		err := c.Post(q, &resp, client.Var("id", commerceID.Hex()))
+
+		testCommercesService.AssertNotCalled(t, "Close", mock.Anything)
+		require.Error(t, err)
+	})
+}
+
+// Tests sur la liste des colis d'un point relais
+func TestQueryResolver_PickupPointParcels(t *testing.T) {
+	// Les modèles
+	manager := users.User{
+		ID:   primitive.NewObjectID(),
+		Role: users.USERROLE_PICKUP_MANAGER,
+	}
+
+	otherManager := users.User{
+		ID:   primitive.NewObjectID(),
+		Role: users.USERROLE_PICKUP_MANAGER,
+	}
+
+	customer := users.User{
+		ID:   primitive.NewObjectID(),
+		Role: users.USERROLE_USER,
+	}
+
+	pickupPoint := pickuppoints.PickupPoint{
+		ID:         primitive.NewObjectID(),
+		Name:       "Mairie du Rheu",
+		ManagerID:  &manager.ID,
+		AddressGeo: geojson.NewPoint(-1.7779691219329834, 48.09312057495117),
+	}
+
+	date := time.Date(2022, 11, 4, 0, 0, 0, 0, time.UTC)
+	firstCommandID := primitive.NewObjectID()
+	secondCommandID := primitive.NewObjectID()
+
+	commerceCommands := []commands.CommerceCommand{
+		{ID: primitive.NewObjectID(), CommandID: firstCommandID, PickupDate: date, PickupPointID: &pickupPoint.ID},
+		{ID: primitive.NewObjectID(), CommandID: secondCommandID, PickupDate: date, PickupPointID: &pickupPoint.ID},
+		{ID: primitive.NewObjectID(), CommandID: firstCommandID, PickupDate: date, PickupPointID: &pickupPoint.ID},
+	}
+
+	q := `
+		query PickupPointParcels($date: Time!) {
+			pickupPointParcels(date: $date) {
+				user {
+					id
+				}
+				commerceCommands {
+					id
+				}
+			}
+		}
+	`
+
+	newClient := func(user *users.User) (*client.Client, *mocks.CommerceCommandsService) {
+		testPickupPointsService := new(mocks.PickupPointsService)
+		testCommerceCommandsService := new(mocks.CommerceCommandsService)
+		testCommandsService := new(mocks.CommandsService)
+		resolvers := resolvers.Resolver{
+			PickupPointsService:     testPickupPointsService,
+			CommerceCommandsService: testCommerceCommandsService,
+			CommandsService:         testCommandsService,
+		}
+
+		testPickupPointsService.On("GetForManager", user.ID.Hex()).Return(&pickupPoint, nil)
+		testCommerceCommandsService.On("GetFiltered", mock.Anything, mock.Anything).Return(commerceCommands, nil)
+		testCommandsService.On("GetUser", mock.AnythingOfType("string")).Return(customer.ToModel(), nil)
+
+		return client.New(
+			handler.NewDefaultServer(generated.NewExecutableSchema(withDirectives(generated.Config{Resolvers: &resolvers}))),
+			addContext(user),
+		), testCommerceCommandsService
+	}
+
+	t.Run("parcels grouped by customer", func(t *testing.T) {
+		var resp struct {
+			PickupPointParcels []struct {
+				User struct {
+					ID string `json:"id"`
+				} `json:"user"`
+				CommerceCommands []struct {
+					ID string `json:"id"`
+				} `json:"commerceCommands"`
+			} `json:"pickupPointParcels"`
+		}
+
+		c, _ := newClient(&manager)
+		c.MustPost(q, &resp, client.Var("date", date))
+
+		require.Len(t, resp.PickupPointParcels, 2)
+		require.Len(t, resp.PickupPointParcels[0].CommerceCommands, 2)
+		require.Len(t, resp.PickupPointParcels[1].CommerceCommands, 1)
+		require.Equal(t, customer.ID.Hex(), resp.PickupPointParcels[0].User.ID)
+	})
+
+	t.Run("parcels of another pickup point", func(t *testing.T) {
+		var resp struct{}
+
+		c, testCommerceCommandsService := newClient(&otherManager)
+		err := c.Post(q, &resp, client.Var("date", date))
+
+		testCommerceCommandsService.AssertNotCalled(t, "GetFiltered", mock.Anything, mock.Anything)
+		require.Error(t, err)
+	})
+
+	t.Run("parcels as a customer", func(t *testing.T) {
+		var resp struct{}
+
+		c, testCommerceCommandsService := newClient(&customer)
+		err := c.Post(q, &resp, client.Var("date", date))
+
+		testCommerceCommandsService.AssertNotCalled(t, "GetFiltered", mock.Anything, mock.Anything)
+		require.Error(t, err)
+	})
+}
+
+// Tests sur la recherche de produits
+func TestQueryResolver_SearchProducts(t *testing.T) {
+	// Les modèles
+	commerce := commerces.Commerce{
+		ID:         primitive.NewObjectID(),
+		Name:       "Crêperie du Port",
+		AddressGeo: geojson.NewPoint(-1.7779691219329834, 48.09312057495117),
+		Status:     commerces.COMMERCE_STATUS_ACTIVE,
+	}
+
+	product := products.Product{
+		ID:         primitive.NewObjectID(),
+		CommerceID: commerce.ID,
+		Name:       "Galette complète",
+		Price:      8.5,
+		IsBreton:   true,
+		Categories: []string{"Crêpes"},
+	}
+
+	maxPrice := 10.0
+	searchResult := products.ProductSearchResult{
+		Products:    []products.Product{product},
+		HasNextPage: true,
+		TotalCount:  3,
+		Facets: products.ProductSearchFacets{
+			IsBreton: []products.BooleanFacetValue{{Value: true, Count: 3}},
+			Prices:   []products.PriceFacetValue{{Min: 5, Max: &maxPrice, Count: 3}},
+		},
+	}
+
+	q := `
+		query SearchProducts($filter: ProductSearchFilter) {
+			searchProducts(first: 1, filter: $filter) {
+				totalCount
+				edges {
+					node { id }
+					commerce { id }
+				}
+				pageInfo { hasNextPage }
+				facets {
+					isBreton { value count }
+					prices { min max count }
+				}
+			}
+		}
+	`
+
+	newClient := func() (*client.Client, *mocks.CommercesService, *mocks.ProductsService) {
+		testCommercesService := new(mocks.CommercesService)
+		testProductsService := new(mocks.ProductsService)
+		resolvers := resolvers.Resolver{
+			CommercesService: testCommercesService,
+			ProductsService:  testProductsService,
+		}
+
+		testCommercesService.On("GetActive", mock.Anything, mock.Anything).Return([]commerces.Commerce{commerce}, nil)
+		testCommercesService.On("GetActiveNear", mock.Anything, mock.Anything, mock.Anything).Return([]commerces.Commerce{commerce}, nil)
+		testProductsService.On("Search", []primitive.ObjectID{commerce.ID}, mock.Anything, mock.Anything, 1).Return(&searchResult, nil)
+
+		return client.New(
+			handler.NewDefaultServer(generated.NewExecutableSchema(withDirectives(generated.Config{Resolvers: &resolvers}))),
+		), testCommercesService, testProductsService
+	}
+
+	var resp struct {
+		SearchProducts struct {
+			TotalCount int `json:"totalCount"`
+			Edges      []struct {
+				Node struct {
+					ID string `json:"id"`
+				} `json:"node"`
+				Commerce struct {
+					ID string `json:"id"`
+				} `json:"commerce"`
+			} `json:"edges"`
+			PageInfo struct {
+				HasNextPage bool `json:"hasNextPage"`
+			} `json:"pageInfo"`
+			Facets struct {
+				IsBreton []struct {
+					Value bool `json:"value"`
+					Count int  `json:"count"`
+				} `json:"isBreton"`
+				Prices []struct {
+					Min   float64  `json:"min"`
+					Max   *float64 `json:"max"`
+					Count int      `json:"count"`
+				} `json:"prices"`
+			} `json:"facets"`
+		} `json:"searchProducts"`
+	}
+
+	t.Run("search products", func(t *testing.T) {
+		c, testCommercesService, _ := newClient()
+		c.MustPost(q, &resp, client.Var("filter", map[string]interface{}{"query": "galette"}))
+
+		testCommercesService.AssertNotCalled(t, "GetActiveNear", mock.Anything, mock.Anything, mock.Anything)
+		require.Equal(t, 3, resp.SearchProducts.TotalCount)
+		require.Len(t, resp.SearchProducts.Edges, 1)
+		require.Equal(t, product.ID.Hex(), resp.SearchProducts.Edges[0].Node.ID)
+		require.Equal(t, commerce.ID.Hex(), resp.SearchProducts.Edges[0].Commerce.ID)
+		require.True(t, resp.SearchProducts.PageInfo.HasNextPage)
+		require.Equal(t, 3, resp.SearchProducts.Facets.IsBreton[0].Count)
+		require.Equal(t, maxPrice, *resp.SearchProducts.Facets.Prices[0].Max)
+	})
+
+	t.Run("search products near a location", func(t *testing.T) {
+		c, testCommercesService, _ := newClient()
+		c.MustPost(q, &resp, client.Var("filter", map[string]interface{}{
+			"nearLatitude":  48.1,
+			"nearLongitude": -1.7,
+		}))
+
+		testCommercesService.AssertCalled(t, "GetActiveNear", 48.1, -1.7, mock.Anything)
+		testCommercesService.AssertNotCalled(t, "GetActive", mock.Anything, mock.Anything)
+	})
+}
+
+// Tests sur la suppression d'un produit
+func TestMutationResolver_DeleteProduct(t *testing.T) {
+	// Les modèles
+	storekeeper := users.User{
+		ID:   primitive.NewObjectID(),
+		Role: users.USERROLE_STOREKEEPER,
+	}
+
+	commerceID := primitive.NewObjectID()
+	product := products.Product{
+		ID:         primitive.NewObjectID(),
+		CommerceID: commerceID,
+		Name:       "Galette complète",
+	}
+
+	commerce := commerces.Commerce{
+		ID:                                  commerceID,
+		StorekeeperID:                       storekeeper.ID,
+		Name:                                "Mon Super Commerce",
+		AddressGeo:                          geojson.NewPoint(-1.7779691219329834, 48.09312057495117),
+		ProductsAvailableForClickAndCollect: []string{product.ID.Hex()},
+	}
+
+	q := `
+		mutation DeleteProduct($id: ID!) {
+			deleteProduct(id: $id) {
+				id
+			}
+		}
+	`
+
+	newClient := func(ccCommands []commands.CCCommand) (*client.Client, *mocks.ProductsService, *mocks.CommercesService) {
+		testCommercesService := new(mocks.CommercesService)
+		testProductsService := new(mocks.ProductsService)
+		testCCCommandsService := new(mocks.CCCommandsService)
+		testPaniersService := new(mocks.PaniersService)
+		resolvers := resolvers.Resolver{
+			CommercesService:  testCommercesService,
+			ProductsService:   testProductsService,
+			CCCommandsService: testCCCommandsService,
+			PaniersService:    testPaniersService,
+		}
+
+		testProductsService.On("GetById", product.ID.Hex()).Return(&product, nil)
+		testProductsService.On("Delete", &product).Return(nil)
+		testCommercesService.On("GetById", commerce.ID.Hex()).Return(&commerce, nil)
+		testCommercesService.On("Update", mock.AnythingOfType("*commerces.Commerce"), mock.Anything, mock.Anything).Return(nil)
+		testCCCommandsService.On("GetFiltered", mock.Anything, mock.Anything).Return(ccCommands, nil)
+		testPaniersService.On("GetFiltered", mock.Anything, mock.Anything).Return([]paniers.Panier{}, nil)
+
+		return client.New(
+			handler.NewDefaultServer(generated.NewExecutableSchema(withDirectives(generated.Config{Resolvers: &resolvers}))),
+			addContext(&storekeeper),
+		), testProductsService, testCommercesService
+	}
+
+	t.Run("delete a product never ordered", func(t *testing.T) {
+		var resp struct {
+			DeleteProduct struct {
+				ID string `json:"id"`
+			} `json:"deleteProduct"`
+		}
+
+		c, testProductsService, testCommercesService := newClient([]commands.CCCommand{})
+		c.MustPost(q, &resp, client.Var("id", product.ID.Hex()))
+
+		testProductsService.AssertCalled(t, "Delete", &product)
+		testCommercesService.AssertCalled(t, "Update", mock.MatchedBy(func(c *commerces.Commerce) bool {
+			return len(c.ProductsAvailableForClickAndCollect) == 0
+		}), mock.Anything, mock.Anything)
+		require.Equal(t, product.ID.Hex(), resp.DeleteProduct.ID)
+	})
+
+	t.Run("delete an ordered product", func(t *testing.T) {
+		var resp struct{}
+
+		c, testProductsService, _ := newClient([]commands.CCCommand{
+			{
+				ID: primitive.NewObjectID(),
+				Products: []commands.CCProduct{
+					{ID: primitive.NewObjectID(), ProductID: product.ID, Quantity: 1},
+				},
+			},
+		})
+		err := c.Post(q, &resp, client.Var("id", product.ID.Hex()))
+
+		testProductsService.AssertNotCalled(t, "Delete", mock.Anything)
+		require.Error(t, err)
+	})
+}
+
+// Tests sur la création des codes promo
+func TestMutationResolver_CreatePromoCode(t *testing.T) {
+	// Les modèles
+	storekeeper := users.User{
+		ID:   primitive.NewObjectID(),
+		Role: users.USERROLE_STOREKEEPER,
+	}
+
+	admin := users.User{
+		ID:   primitive.NewObjectID(),
+		Role: users.USERROLE_ADMIN,
+	}
+
+	commerce := commerces.Commerce{
+		ID:            primitive.NewObjectID(),
+		StorekeeperID: storekeeper.ID,
+		Name:          "Mon Super Commerce",
+		AddressGeo:    geojson.NewPoint(-1.7779691219329834, 48.09312057495117),
+	}
+
+	q := `
+		mutation CreatePromoCode($input: NewPromoCode!) {
+			createPromoCode(input: $input) {
+				code
+				commerceID
+				fundedBy
+			}
+		}
+	`
+
+	input := map[string]interface{}{
+		"code":      "bienvenue",
+		"type":      "FIXED_AMOUNT",
+		"value":     5,
+		"startDate": time.Now(),
+		"endDate":   time.Now().AddDate(0, 1, 0),
+	}
+
+	newClient := func(user *users.User) (*client.Client, *mocks.PromoCodesService) {
+		testCommercesService := new(mocks.CommercesService)
+		testPromoCodesService := new(mocks.PromoCodesService)
+		resolvers := resolvers.Resolver{
+			CommercesService:  testCommercesService,
+			PromoCodesService: testPromoCodesService,
+		}
+
+		testCommercesService.On("GetForUser", storekeeper.ID.Hex()).Return(&commerce, nil)
+		testPromoCodesService.On("Create", mock.Anything, mock.AnythingOfType("model.NewPromoCode")).Return(
+			func(commerceID *primitive.ObjectID, input model.NewPromoCode) *promocodes.PromoCode {
+				fundedBy := model.PromoCodeFundingPlatform
+
+				if commerceID != nil {
+					fundedBy = model.PromoCodeFundingCommerce
+				}
+
+				return &promocodes.PromoCode{
+					ID:         primitive.NewObjectID(),
+					Code:       promocodes.NormalizeCode(input.Code),
+					CommerceID: commerceID,
+					Type:       input.Type.String(),
+					Value:      input.Value,
+					FundedBy:   fundedBy.String(),
+				}
+			},
+			nil,
+		)
+
+		return client.New(
+			handler.NewDefaultServer(generated.NewExecutableSchema(withDirectives(generated.Config{Resolvers: &resolvers}))),
+			addContext(user),
+		), testPromoCodesService
+	}
+
+	type response struct {
+		CreatePromoCode struct {
+			Code       string  `json:"code"`
+			CommerceID *string `json:"commerceID"`
+			FundedBy   string  `json:"fundedBy"`
+		} `json:"createPromoCode"`
+	}
+
+	t.Run("a storekeeper creates a code for its commerce", func(t *testing.T) {
+		var resp response
+
+		c, _ := newClient(&storekeeper)
+		c.MustPost(q, &resp, client.Var("input", input))
+
+		require.Equal(t, "BIENVENUE", resp.CreatePromoCode.Code)
+		require.NotNil(t, resp.CreatePromoCode.CommerceID)
+		require.Equal(t, commerce.ID.Hex(), *resp.CreatePromoCode.CommerceID)
+		require.Equal(t, "COMMERCE", resp.CreatePromoCode.FundedBy)
+	})
+
+	t.Run("an admin creates a platform code", func(t *testing.T) {
+		var resp response
+
+		c, _ := newClient(&admin)
+		c.MustPost(q, &resp, client.Var("input", input))
+
+		require.Nil(t, resp.CreatePromoCode.CommerceID)
+		require.Equal(t, "PLATFORM", resp.CreatePromoCode.FundedBy)
+	})
+}
+
+// Tests sur la consultation d'une carte cadeau
+func TestQueryResolver_GiftCard(t *testing.T) {
+	purchaser := users.User{
+		ID:   primitive.NewObjectID(),
+		Role: users.USERROLE_USER,
+	}
+
+	recipientEmail := "destinataire@test.com"
+	message := "Joyeux anniversaire !"
+	activeCard := giftcards.GiftCard{
+		ID:             primitive.NewObjectID(),
+		Code:           "CDL-ABCD-EFGH-JKLM",
+		PurchaserID:    purchaser.ID,
+		RecipientEmail: &recipientEmail,
+		Message:        &message,
+		InitialAmount:  5000,
+		Balance:        2550,
+		Status:         giftcards.GIFT_CARD_STATUS_ACTIVE,
+		ExpiryDate:     time.Now().AddDate(1, 0, 0),
+		Transactions: []giftcards.GiftCardTransaction{
+			{Type: giftcards.GIFT_CARD_TRANSACTION_PURCHASE, Amount: 5000, Date: time.Now()},
+			{Type: giftcards.GIFT_CARD_TRANSACTION_REDEMPTION, Amount: 2450, Date: time.Now()},
+		},
+	}
+
+	expiredCard := giftcards.GiftCard{
+		ID:            primitive.NewObjectID(),
+		Code:          "CDL-NPQR-STUV-WXYZ",
+		InitialAmount: 2000,
+		Balance:       2000,
+		Status:        giftcards.GIFT_CARD_STATUS_ACTIVE,
+		ExpiryDate:    time.Now().AddDate(0, 0, -1),
+	}
+
+	testGiftCardsService := new(mocks.GiftCardsService)
+	resolvers := resolvers.Resolver{
+		GiftCardsService: testGiftCardsService,
+	}
+
+	testGiftCardsService.On("GetByCode", "cdl-abcd-efgh-jklm").Return(&activeCard, nil)
+	testGiftCardsService.On("GetByCode", expiredCard.Code).Return(&expiredCard, nil)
+	testGiftCardsService.On("GetByCode", "CDL-0000").Return(nil, nil)
+
+	c := client.New(handler.NewDefaultServer(generated.NewExecutableSchema(withDirectives(generated.Config{Resolvers: &resolvers}))))
+
+	q := `
+		query GiftCard($code: String!) {
+			giftCard(code: $code) {
+				balance
+				isUsable
+			}
+		}
+	`
+
+	type response struct {
+		GiftCard struct {
+			Balance  float64 `json:"balance"`
+			IsUsable bool    `json:"isUsable"`
+		} `json:"giftCard"`
+	}
+
+	t.Run("the balance is returned in euros", func(t *testing.T) {
+		var resp response
+
+		c.MustPost(q, &resp, client.Var("code", "cdl-abcd-efgh-jklm"))
+
+		require.Equal(t, 25.5, resp.GiftCard.Balance)
+		require.True(t, resp.GiftCard.IsUsable)
+	})
+
+	t.Run("an expired card is not usable", func(t *testing.T) {
+		var resp response
+
+		c.MustPost(q, &resp, client.Var("code", expiredCard.Code))
+
+		require.False(t, resp.GiftCard.IsUsable)
+	})
+
+	t.Run("an unknown code returns an error", func(t *testing.T) {
+		var resp response
+
+		err := c.Post(q, &resp, client.Var("code", "CDL-0000"))
+
+		require.Error(t, err)
+	})
+
+	privateQuery := `
+		query GiftCard($code: String!) {
+			giftCard(code: $code) {
+				recipientEmail
+				message
+				transactions {
+					amount
+				}
+			}
+		}
+	`
+
+	type privateResponse struct {
+		GiftCard struct {
+			RecipientEmail *string `json:"recipientEmail"`
+			Message        *string `json:"message"`
+			Transactions   []struct {
+				Amount float64 `json:"amount"`
+			} `json:"transactions"`
+		} `json:"giftCard"`
+	}
+
+	t.Run("the recipient and the usage are hidden from others", func(t *testing.T) {
+		var resp privateResponse
+
+		c.MustPost(privateQuery, &resp, client.Var("code", "cdl-abcd-efgh-jklm"), addContext(&users.User{ID: primitive.NewObjectID()}))
+
+		require.Nil(t, resp.GiftCard.RecipientEmail)
+		require.Nil(t, resp.GiftCard.Message)
+		require.Empty(t, resp.GiftCard.Transactions)
+	})
+
+	t.Run("the purchaser sees the recipient and the usage", func(t *testing.T) {
+		var resp privateResponse
+
+		c.MustPost(privateQuery, &resp, client.Var("code", "cdl-abcd-efgh-jklm"), addContext(&purchaser))
+
+		require.Equal(t, recipientEmail, *resp.GiftCard.RecipientEmail)
+		require.Equal(t, message, *resp.GiftCard.Message)
+		require.Len(t, resp.GiftCard.Transactions, 2)
+	})
+}
+
+// Tests sur l'annulation d'une commande par le commerçant
+func TestMutationResolver_UpdateCommerceCommand(t *testing.T) {
+	storekeeper := users.User{
+		ID:   primitive.NewObjectID(),
+		Role: users.USERROLE_STOREKEEPER,
+	}
+
+	otherStorekeeper := users.User{
+		ID:   primitive.NewObjectID(),
+		Role: users.USERROLE_STOREKEEPER,
+	}
+
+	commerce := commerces.Commerce{
+		ID:            primitive.NewObjectID(),
+		StorekeeperID: storekeeper.ID,
+		Name:          "Mon Super Commerce",
+		AddressGeo:    geojson.NewPoint(-1.7779691219329834, 48.09312057495117),
+	}
+
+	giftCardID := primitive.NewObjectID()
+
+	newCommerceCommand := func(paymentStatus string) *commands.CommerceCommand {
+		return &commands.CommerceCommand{
+			ID:                   primitive.NewObjectID(),
+			CommerceID:           commerce.ID,
+			Price:                1500,
+			PriceClickAndCollect: 20,
+			Status:               commands.COMMERCE_COMMAND_STATUS_IN_PROGRESS,
+			PaymentStatus:        paymentStatus,
+			GiftCardID:           &giftCardID,
+			GiftCardAmount:       5,
+		}
+	}
+
+	newClient := func(commerceCommand *commands.CommerceCommand) (*client.Client, *mocks.CommercesService, *mocks.GiftCardsService) {
+		testCommerceCommandsService := new(mocks.CommerceCommandsService)
+		testCommercesService := new(mocks.CommercesService)
+		testStockService := new(mocks.StockService)
+		testGiftCardsService := new(mocks.GiftCardsService)
+		resolvers := resolvers.Resolver{
+			CommerceCommandsService: testCommerceCommandsService,
+			CommercesService:        testCommercesService,
+			StockService:            testStockService,
+			GiftCardsService:        testGiftCardsService,
+		}
+
+		testCommercesService.On("GetById", commerce.ID.Hex()).Return(&commerce, nil)
+		testCommerceCommandsService.On("GetById", commerceCommand.ID.Hex()).Return(commerceCommand, nil)
+		testCommerceCommandsService.On("Update", commerceCommand).Return(nil)
+		testStockService.On("Release", commerceCommand.ID).Return(nil)
+		testGiftCardsService.On("Refund", giftCardID, 500, commerceCommand.CommerceID, commerceCommand.ID).Return(nil)
+		testCommercesService.On("UpdateBalancesForOrder", commerceCommand.CommerceID.Hex(), -1500, -20.0, -0.0, -5.0).Return(nil)
+
+		return client.New(
+			handler.NewDefaultServer(generated.NewExecutableSchema(withDirectives(generated.Config{Resolvers: &resolvers}))),
+			addContext(&storekeeper),
+		), testCommercesService, testGiftCardsService
+	}
+
+	q := `
+		mutation UpdateCommerceCommand($id: ID!, $status: String) {
+			updateCommerceCommand(id: $id, changes: { status: $status }) {
+				id
+			}
+		}
+	`
+
+	type response struct {
+		UpdateCommerceCommand struct {
+			ID string `json:"id"`
+		} `json:"updateCommerceCommand"`
+	}
+
+	t.Run("canceling a paid order takes back the credited balance", func(t *testing.T) {
+		var resp response
+		commerceCommand := newCommerceCommand(commands.COMMERCE_COMMAND_PAYMENT_STATUS_PAID)
+
+		c, testCommercesService, testGiftCardsService := newClient(commerceCommand)
+		c.MustPost(q, &resp, client.Var("id", commerceCommand.ID.Hex()), client.Var("status", commands.COMMERCE_COMMAND_STATUS_CANCELED))
+
+		testCommercesService.AssertExpectations(t)
+		testGiftCardsService.AssertExpectations(t)
+		require.NotNil(t, commerceCommand.CanceledAt)
+	})
+
+	t.Run("canceling an unpaid order leaves the balance untouched", func(t *testing.T) {
+		var resp response
+		commerceCommand := newCommerceCommand(commands.COMMERCE_COMMAND_PAYMENT_STATUS_PENDING)
+
+		c, testCommercesService, _ := newClient(commerceCommand)
+		c.MustPost(q, &resp, client.Var("id", commerceCommand.ID.Hex()), client.Var("status", commands.COMMERCE_COMMAND_STATUS_CANCELED))
+
+		testCommercesService.AssertNotCalled(t, "UpdateBalancesForOrder", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("an order already canceled is not reversed twice", func(t *testing.T) {
+		var resp response
+		canceledAt := time.Now().AddDate(0, 0, -1)
+		commerceCommand := newCommerceCommand(commands.COMMERCE_COMMAND_PAYMENT_STATUS_PAID)
+		commerceCommand.Status = commands.COMMERCE_COMMAND_STATUS_CANCELED
+		commerceCommand.CanceledAt = &canceledAt
+
+		c, testCommercesService, testGiftCardsService := newClient(commerceCommand)
+		c.MustPost(q, &resp, client.Var("id", commerceCommand.ID.Hex()), client.Var("status", commands.COMMERCE_COMMAND_STATUS_CANCELED))
+
+		testCommercesService.AssertNotCalled(t, "UpdateBalancesForOrder", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		testGiftCardsService.AssertNotCalled(t, "Refund", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("a canceled order cannot go back in progress", func(t *testing.T) {
+		var resp response
+		commerceCommand := newCommerceCommand(commands.COMMERCE_COMMAND_PAYMENT_STATUS_PAID)
+		commerceCommand.Status = commands.COMMERCE_COMMAND_STATUS_CANCELED
+
+		c, _, _ := newClient(commerceCommand)
+		err := c.Post(q, &resp, client.Var("id", commerceCommand.ID.Hex()), client.Var("status", commands.COMMERCE_COMMAND_STATUS_IN_PROGRESS))
+
+		require.EqualError(t, err, `[{"message":"une commande annulée ne peut plus changer de statut","path":["updateCommerceCommand"]}]`)
+	})
+
+	t.Run("an unknown status is refused", func(t *testing.T) {
+		var resp response
+		commerceCommand := newCommerceCommand(commands.COMMERCE_COMMAND_PAYMENT_STATUS_PAID)
+
+		c, _, _ := newClient(commerceCommand)
+		err := c.Post(q, &resp, client.Var("id", commerceCommand.ID.Hex()), client.Var("status", "SHIPPED"))
+
+		require.EqualError(t, err, `[{"message":"le statut de la commande n'existe pas","path":["updateCommerceCommand"]}]`)
+	})
+
+	t.Run("another storekeeper cannot update the order", func(t *testing.T) {
+		var resp response
+		commerceCommand := newCommerceCommand(commands.COMMERCE_COMMAND_PAYMENT_STATUS_PAID)
+
+		c, testCommercesService, _ := newClient(commerceCommand)
+		err := c.Post(q, &resp, client.Var("id", commerceCommand.ID.Hex()), client.Var("status", commands.COMMERCE_COMMAND_STATUS_CANCELED), addContext(&otherStorekeeper))
+
+		require.EqualError(t, err, `[{"message":"access denied","path":["updateCommerceCommand"]}]`)
+		testCommercesService.AssertNotCalled(t, "UpdateBalancesForOrder", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// Tests sur la pesée des produits vendus au poids
+func TestMutationResolver_RecordActualWeights(t *testing.T) {
+	storekeeper := users.User{
+		ID:   primitive.NewObjectID(),
+		Role: users.USERROLE_STOREKEEPER,
+	}
+
+	commerce := commerces.Commerce{
+		ID:            primitive.NewObjectID(),
+		StorekeeperID: storekeeper.ID,
+		Name:          "Mon Super Commerce",
+		AddressGeo:    geojson.NewPoint(-1.7779691219329834, 48.09312057495117),
+	}
+
+	// 1,5 kg de pommes estimés à 3€ le kg, et un pot de miel, payés
+	// entièrement par carte cadeau
+	weighedLineID := primitive.NewObjectID()
+	otherLineID := primitive.NewObjectID()
+	giftCardID := primitive.NewObjectID()
+
+	q := `
+		mutation RecordActualWeights($commerceCommandID: ID!, $weights: [ActualWeightInput!]!) {
+			recordActualWeights(commerceCommandID: $commerceCommandID, weights: $weights) {
+				price
+				weightAdjustment
+				isAwaitingWeights
+			}
+		}
+	`
+
+	type response struct {
+		RecordActualWeights struct {
+			Price             float64 `json:"price"`
+			WeightAdjustment  float64 `json:"weightAdjustment"`
+			IsAwaitingWeights bool    `json:"isAwaitingWeights"`
+		} `json:"recordActualWeights"`
+	}
+
+	newClient := func(actualQuantity float64) (*client.Client, *commands.CommerceCommand, *mocks.CommercesService, *mocks.GiftCardsService) {
+		commerceCommand := commands.CommerceCommand{
+			ID:                   primitive.NewObjectID(),
+			CommerceID:           commerce.ID,
+			Price:                0,
+			PriceClickAndCollect: 11.5,
+			WeighedPrice:         4.5,
+			GiftCardID:           &giftCardID,
+			GiftCardAmount:       11.5,
+			Status:               commands.COMMERCE_COMMAND_STATUS_IN_PROGRESS,
+		}
+
+		ccCommands := []commands.CCCommand{
+			{
+				ID:                primitive.NewObjectID(),
+				CommerceCommandID: commerceCommand.ID,
+				Products: []commands.CCProduct{
+					{
+						ID:             weighedLineID,
+						ProductID:      primitive.NewObjectID(),
+						Quantity:       1.5,
+						UnitPrice:      3,
+						IsWeighed:      true,
+						ActualQuantity: &actualQuantity,
+					},
+					{
+						ID:        otherLineID,
+						ProductID: primitive.NewObjectID(),
+						Quantity:  1,
+						UnitPrice: 7,
+					},
+				},
+			},
+		}
+
+		testCommercesService := new(mocks.CommercesService)
+		testCommerceCommandsService := new(mocks.CommerceCommandsService)
+		testCCCommandsService := new(mocks.CCCommandsService)
+		testGiftCardsService := new(mocks.GiftCardsService)
+		resolvers := resolvers.Resolver{
+			CommercesService:        testCommercesService,
+			CommerceCommandsService: testCommerceCommandsService,
+			CCCommandsService:       testCCCommandsService,
+			GiftCardsService:        testGiftCardsService,
+		}
+
+		testCommercesService.On("GetById", commerce.ID.Hex()).Return(&commerce, nil)
+		testCommercesService.On("UpdateBalancesForOrder", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		testCommerceCommandsService.On("GetById", commerceCommand.ID.Hex()).Return(&commerceCommand, nil)
+		testCommerceCommandsService.On("Update", mock.AnythingOfType("*commands.CommerceCommand")).Return(nil)
+		testCCCommandsService.On("SetActualQuantities", commerceCommand.ID.Hex(), mock.Anything).Return(ccCommands, nil)
+		testGiftCardsService.On("RefundWeighing", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		return client.New(
+			handler.NewDefaultServer(generated.NewExecutableSchema(withDirectives(generated.Config{Resolvers: &resolvers}))),
+			addContext(&storekeeper),
+		), &commerceCommand, testCommercesService, testGiftCardsService
+	}
+
+	t.Run("the gift card gets back the difference of a lighter weight", func(t *testing.T) {
+		var resp response
+
+		c, commerceCommand, testCommercesService, testGiftCardsService := newClient(1.2)
+		c.MustPost(
+			q,
+			&resp,
+			client.Var("commerceCommandID", commerceCommand.ID.Hex()),
+			client.Var("weights", []map[string]interface{}{
+				{"ccProductID": weighedLineID.Hex(), "actualQuantity": 1.2},
+			}),
+		)
+
+		require.Equal(t, 0.0, resp.RecordActualWeights.Price)
+		require.Equal(t, 10.6, commerceCommand.PriceClickAndCollect)
+		require.Equal(t, -0.9, resp.RecordActualWeights.WeightAdjustment)
+		require.False(t, resp.RecordActualWeights.IsAwaitingWeights)
+		require.Equal(t, 10.6, commerceCommand.GiftCardAmount)
+
+		testGiftCardsService.AssertCalled(t, "RefundWeighing", giftCardID, 90, commerce.ID, commerceCommand.ID)
+		testCommercesService.AssertCalled(t, "UpdateBalancesForOrder", commerce.ID.Hex(), 0, 10.6, 0.0, 10.6)
+	})
+
+	t.Run("the commerce bears a heavier weight not paid by card", func(t *testing.T) {
+		var resp response
+
+		c, commerceCommand, testCommercesService, testGiftCardsService := newClient(1.8)
+		c.MustPost(
+			q,
+			&resp,
+			client.Var("commerceCommandID", commerceCommand.ID.Hex()),
+			client.Var("weights", []map[string]interface{}{
+				{"ccProductID": weighedLineID.Hex(), "actualQuantity": 1.8},
+			}),
+		)
+
+		require.Equal(t, 0.0, resp.RecordActualWeights.Price)
+		require.Equal(t, 11.5, commerceCommand.PriceClickAndCollect)
+		require.Equal(t, 11.5, commerceCommand.GiftCardAmount)
+
+		testGiftCardsService.AssertNotCalled(t, "RefundWeighing", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		testCommercesService.AssertCalled(t, "UpdateBalancesForOrder", commerce.ID.Hex(), 0, 11.5, 0.0, 11.5)
+	})
+}
+
+// Tests sur la fusion de catégories
+func TestMutationResolver_MergeCategories(t *testing.T) {
+	// Les modèles
+	storekeeper := users.User{
+		ID:   primitive.NewObjectID(),
+		Role: users.USERROLE_STOREKEEPER,
+	}
+
+	otherStorekeeper := users.User{
+		ID:   primitive.NewObjectID(),
+		Role: users.USERROLE_STOREKEEPER,
+	}
+
+	commerce := commerces.Commerce{
+		ID:            primitive.NewObjectID(),
+		StorekeeperID: storekeeper.ID,
+		Name:          "Épicerie du Marché",
+	}
+
+	category := categories.Category{
+		ID:         primitive.NewObjectID(),
+		CommerceID: commerce.ID,
+		Name:       "Légumes",
+	}
+
+	sourceIDs := []string{primitive.NewObjectID().Hex()}
+
+	q := `
+		mutation MergeCategories($targetID: ID!, $sourceIDs: [ID!]!) {
+			mergeCategories(targetID: $targetID, sourceIDs: $sourceIDs) {
+				id
+				name
+			}
+		}
+	`
+
+	newClient := func(user *users.User) (*client.Client, *mocks.CategoriesService) {
+		testCommercesService := new(mocks.CommercesService)
+		testCategoriesService := new(mocks.CategoriesService)
+		resolvers := resolvers.Resolver{
+			CommercesService:  testCommercesService,
+			CategoriesService: testCategoriesService,
+		}
+
+		testCommercesService.On("GetById", commerce.ID.Hex()).Return(&commerce, nil)
+		testCategoriesService.On("GetById", category.ID.Hex()).Return(&category, nil)
+		testCategoriesService.On("Merge", &category, sourceIDs).Return(nil)
+
+		return client.New(
+			handler.NewDefaultServer(generated.NewExecutableSchema(withDirectives(generated.Config{Resolvers: &resolvers}))),
+			addContext(user),
+		), testCategoriesService
+	}
+
+	t.Run("the storekeeper merges categories of its commerce", func(t *testing.T) {
+		var resp struct {
+			MergeCategories struct {
+				ID   string `json:"id"`
+				Name string `json:"name"`
+			} `json:"mergeCategories"`
+		}
+
+		c, testCategoriesService := newClient(&storekeeper)
+		c.MustPost(q, &resp, client.Var("targetID", category.ID.Hex()), client.Var("sourceIDs", sourceIDs))
+
+		testCategoriesService.AssertCalled(t, "Merge", &category, sourceIDs)
+		require.Equal(t, category.ID.Hex(), resp.MergeCategories.ID)
+		require.Equal(t, "Légumes", resp.MergeCategories.Name)
+	})
+
+	t.Run("another storekeeper cannot merge them", func(t *testing.T) {
+		var resp map[string]interface{}
+
+		c, testCategoriesService := newClient(&otherStorekeeper)
+		err := c.Post(q, &resp, client.Var("targetID", category.ID.Hex()), client.Var("sourceIDs", sourceIDs))
+
+		testCategoriesService.AssertNotCalled(t, "Merge", mock.Anything, mock.Anything)
+		require.Error(t, err)
+	})
+}
+
+// Tests sur le changement d'adresse d'un commerce
+func TestMutationResolver_UpdateCommerceAddress(t *testing.T) {
+	// Les modèles
+	storekeeper := users.User{
+		ID:   primitive.NewObjectID(),
+		Role: users.USERROLE_STOREKEEPER,
+	}
+
+	route := "Rue Nationale"
+	city := "Le Rheu"
+	commerce := commerces.Commerce{
+		ID:            primitive.NewObjectID(),
+		StorekeeperID: storekeeper.ID,
+		Name:          "Mon Super Commerce",
+		Address: address.Address{
+			ID:    primitive.NewObjectID(),
+			Route: &route,
+			City:  &city,
+		},
+		AddressGeo: geojson.NewPoint(-1.7779691219329834, 48.09312057495117),
+	}
+
+	testCommercesService := new(mocks.CommercesService)
+	resolvers := resolvers.Resolver{
+		CommercesService: testCommercesService,
+	}
+
+	testCommercesService.On("GetById", commerce.ID.Hex()).Return(&commerce, nil)
+	testCommercesService.On("Update", &commerce, mock.Anything, mock.Anything).Return(nil)
+
+	c := client.New(
+		handler.NewDefaultServer(generated.NewExecutableSchema(withDirectives(generated.Config{Resolvers: &resolvers}))),
+		addContext(&storekeeper),
+	)
+
+	q := `
+		mutation UpdateCommerce($id: ID!, $changes: ChangesCommerce!) {
+			updateCommerce(id: $id, changes: $changes) {
+				address {
+					route
+					city
+					latitude
+					longitude
+				}
+			}
+		}
+	`
+
+	t.Run("the address position is changed with the address", func(t *testing.T) {
+		var resp struct {
+			UpdateCommerce struct {
+				Address struct {
+					Route     string  `json:"route"`
+					City      string  `json:"city"`
+					Latitude  float64 `json:"latitude"`
+					Longitude float64 `json:"longitude"`
+				} `json:"address"`
+			} `json:"updateCommerce"`
+		}
+
+		c.MustPost(q, &resp, client.Var("id", commerce.ID.Hex()), client.Var("changes", map[string]interface{}{
+			"address": map[string]interface{}{
+				"city":      "Rennes",
+				"latitude":  48.1113,
+				"longitude": -1.68,
+			},
+		}))
+
+		require.Equal(t, "Rue Nationale", resp.UpdateCommerce.Address.Route)
+		require.Equal(t, "Rennes", resp.UpdateCommerce.Address.City)
+		require.Equal(t, 48.1113, resp.UpdateCommerce.Address.Latitude)
+		require.Equal(t, -1.68, resp.UpdateCommerce.Address.Longitude)
+		require.Equal(t, []float64{-1.68, 48.1113}, commerce.Address.Location.Coordinates)
+	})
+}
+
+// Tests sur les déclinaisons proposées en Click&Collect
+func TestMutationResolver_UpdateCommerceVariants(t *testing.T) {
+	// Les modèles
+	storekeeper := users.User{
+		ID:   primitive.NewObjectID(),
+		Role: users.USERROLE_STOREKEEPER,
+	}
+	commerce := commerces.Commerce{
+		ID:            primitive.NewObjectID(),
+		StorekeeperID: storekeeper.ID,
+		Name:          "Mon Super Commerce",
+		AddressGeo:    geojson.NewPoint(-1.68, 48.11),
+	}
+	product := products.Product{
+		ID:         primitive.NewObjectID(),
+		CommerceID: commerce.ID,
+		Name:       "Cidre",
+		Variants: []products.ProductVariant{
+			{ID: primitive.NewObjectID(), Name: "75 cl", Price: 4.5},
+		},
+	}
+
+	testCommercesService := new(mocks.CommercesService)
+	testProductsService := new(mocks.ProductsService)
+	resolvers := resolvers.Resolver{
+		CommercesService: testCommercesService,
+		ProductsService:  testProductsService,
+	}
+
+	testCommercesService.On("GetById", commerce.ID.Hex()).Return(&commerce, nil)
+	testCommercesService.On("Update", &commerce, mock.Anything, mock.Anything).Return(nil)
+	testProductsService.On("GetForCommerce", commerce.ID.Hex()).Return([]products.Product{product}, nil)
+
+	c := client.New(
+		handler.NewDefaultServer(generated.NewExecutableSchema(withDirectives(generated.Config{Resolvers: &resolvers}))),
+		addContext(&storekeeper),
+	)
+
+	q := `
+		mutation UpdateCommerce($id: ID!, $changes: ChangesCommerce!) {
+			updateCommerce(id: $id, changes: $changes) {
+				variantsAvailableForClickAndCollect
+			}
+		}
+	`
+
+	t.Run("a variant of the commerce can be offered", func(t *testing.T) {
+		var resp struct {
+			UpdateCommerce struct {
+				VariantsAvailableForClickAndCollect []string `json:"variantsAvailableForClickAndCollect"`
+			} `json:"updateCommerce"`
+		}
+
+		c.MustPost(q, &resp, client.Var("id", commerce.ID.Hex()), client.Var("changes", map[string]interface{}{
+			"variantsAvailableForClickAndCollect": []string{product.Variants[0].ID.Hex()},
+		}))
+
+		require.Equal(t, []string{product.Variants[0].ID.Hex()}, resp.UpdateCommerce.VariantsAvailableForClickAndCollect)
+	})
+
+	t.Run("an unknown variant is refused", func(t *testing.T) {
+		var resp struct {
+			UpdateCommerce struct {
+				VariantsAvailableForClickAndCollect []string `json:"variantsAvailableForClickAndCollect"`
+			} `json:"updateCommerce"`
+		}
+
+		err := c.Post(q, &resp, client.Var("id", commerce.ID.Hex()), client.Var("changes", map[string]interface{}{
+			"variantsAvailableForClickAndCollect": []string{primitive.NewObjectID().Hex()},
+		}))
+
+		require.EqualError(t, err, `[{"message":"la déclinaison n'a pas été trouvée","path":["updateCommerce"]}]`)
+	})
+}
+
+// Tests sur la participation d'un commerce à un marché
+func TestMutationResolver_JoinMarket(t *testing.T) {
+	// Les modèles
+	storekeeper := users.User{
+		ID:   primitive.NewObjectID(),
+		Role: users.USERROLE_STOREKEEPER,
+	}
+
+	otherStorekeeper := users.User{
+		ID:   primitive.NewObjectID(),
+		Role: users.USERROLE_STOREKEEPER,
+	}
+
+	commerce := commerces.Commerce{
+		ID:            primitive.NewObjectID(),
+		StorekeeperID: storekeeper.ID,
+		Name:          "Mon Super Commerce",
+		AddressGeo:    geojson.NewPoint(-1.7779691219329834, 48.09312057495117),
+	}
+
+	market := markets.Market{
+		ID:          primitive.NewObjectID(),
+		Name:        "Marché des Lices",
+		AddressGeo:  geojson.NewPoint(-1.6833, 48.1125),
+		CommercesID: []primitive.ObjectID{commerce.ID},
+	}
+
+	testCommercesService := new(mocks.CommercesService)
+	testMarketsService := new(mocks.MarketsService)
+	resolvers := resolvers.Resolver{
+		CommercesService: testCommercesService,
+		MarketsService:   testMarketsService,
+	}
+
+	testCommercesService.On("GetById", commerce.ID.Hex()).Return(&commerce, nil)
+	testCommercesService.On("GetForUser", storekeeper.ID.Hex()).Return(&commerce, nil)
+	testMarketsService.On("AddCommerce", market.ID.Hex(), commerce.ID).Return(&market, nil)
+
+	q := `
+		mutation JoinMarket($marketID: ID!, $commerceID: ID) {
+			joinMarket(marketID: $marketID, commerceID: $commerceID) {
+				id
+				name
+				latitude
+				longitude
+			}
+		}
+	`
+
+	t.Run("the storekeeper joins a market with its commerce", func(t *testing.T) {
+		c := client.New(
+			handler.NewDefaultServer(generated.NewExecutableSchema(withDirectives(generated.Config{Resolvers: &resolvers}))),
+			addContext(&storekeeper),
+		)
+
+		var resp struct {
+			JoinMarket struct {
+				ID        string  `json:"id"`
+				Name      string  `json:"name"`
+				Latitude  float64 `json:"latitude"`
+				Longitude float64 `json:"longitude"`
+			} `json:"joinMarket"`
+		}
+
+		c.MustPost(q, &resp, client.Var("marketID", market.ID.Hex()))
+
+		require.Equal(t, market.ID.Hex(), resp.JoinMarket.ID)
+		require.Equal(t, "Marché des Lices", resp.JoinMarket.Name)
+		require.Equal(t, 48.1125, resp.JoinMarket.Latitude)
+		require.Equal(t, -1.6833, resp.JoinMarket.Longitude)
+	})
+
+	t.Run("another storekeeper cannot join a market for the commerce", func(t *testing.T) {
+		c := client.New(
+			handler.NewDefaultServer(generated.NewExecutableSchema(withDirectives(generated.Config{Resolvers: &resolvers}))),
+			addContext(&otherStorekeeper),
+		)
+
+		var resp struct {
+			JoinMarket struct {
+				ID string `json:"id"`
+			} `json:"joinMarket"`
+		}
+
+		err := c.Post(q, &resp, client.Var("marketID", market.ID.Hex()), client.Var("commerceID", commerce.ID.Hex()))
+
+		require.Error(t, err)
+		testMarketsService.AssertNumberOfCalls(t, "AddCommerce", 1)
+	})
+}
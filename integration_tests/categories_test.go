@@ -0,0 +1,149 @@
+package integrationtests_tests
+
+import (
+	"testing"
+
+	"chemin-du-local.bzh/graphql/graph/model"
+	"chemin-du-local.bzh/graphql/internal/categories"
+	"chemin-du-local.bzh/graphql/internal/config"
+	"chemin-du-local.bzh/graphql/internal/database"
+	"chemin-du-local.bzh/graphql/internal/products"
+	"chemin-du-local.bzh/graphql/internal/promotions"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestIntegrationCategories(t *testing.T) {
+	config.Init("config_tests.yml")
+
+	shouldDropDb := true
+	database.Init(&shouldDropDb)
+
+	categoriesService := categories.NewCategoriesService()
+	productsService := products.NewProductsService()
+	promotionsService := promotions.NewPromotionsService()
+
+	commerceID := primitive.NewObjectID()
+
+	// Des produits créés avant les catégories, avec des noms qui ne
+	// diffèrent que par la casse
+	apples, err := productsService.Create(commerceID.Hex(), model.NewProduct{
+		Name:       "Pommes",
+		Price:      3,
+		Unit:       "kg",
+		Categories: []string{"Fruits", "Bio"},
+	})
+	require.NoError(t, err)
+
+	pears, err := productsService.Create(commerceID.Hex(), model.NewProduct{
+		Name:       "Poires",
+		Price:      4,
+		Unit:       "kg",
+		Categories: []string{"fruits "},
+	})
+	require.NoError(t, err)
+
+	categoryPromotion := promotions.Promotion{
+		ID:         primitive.NewObjectID(),
+		CommerceID: commerceID,
+		Name:       "Semaine des fruits",
+		Type:       model.PromotionTypePercentage.String(),
+		Target:     model.PromotionTargetCategory.String(),
+		Category:   stringPointer("Fruits"),
+		Value:      10,
+	}
+	_, err = database.CollectionPromotions.InsertOne(database.MongoContext, categoryPromotion)
+	require.NoError(t, err)
+
+	getProduct := func(id primitive.ObjectID) *products.Product {
+		databaseProduct, err := productsService.GetById(id.Hex())
+		require.NoError(t, err)
+
+		return databaseProduct
+	}
+
+	getCategory := func(name string) categories.Category {
+		databaseCategories, err := categoriesService.GetFiltered(bson.M{
+			"commerceID":     commerceID,
+			"normalizedName": categories.NormalizeName(name),
+		}, nil)
+		require.NoError(t, err)
+		require.Len(t, databaseCategories, 1)
+
+		return databaseCategories[0]
+	}
+
+	t.Run("the sync creates one category per name", func(t *testing.T) {
+		require.NoError(t, categoriesService.MigrateProductCategories())
+
+		databaseCategories, err := categoriesService.GetForCommerce(commerceID)
+		require.NoError(t, err)
+		require.Equal(t, []string{"Fruits", "Bio"}, categories.Names(databaseCategories))
+
+		fruits := getCategory("Fruits")
+
+		require.Equal(t, []string{"Fruits", "Bio"}, getProduct(apples.ID).Categories)
+		require.Equal(t, []string{"Fruits"}, getProduct(pears.ID).Categories)
+		require.Equal(t, []primitive.ObjectID{fruits.ID}, getProduct(pears.ID).CategoryIDs)
+	})
+
+	t.Run("a category cannot be created twice", func(t *testing.T) {
+		_, err := categoriesService.Create(commerceID, model.NewCategory{Name: "FRUITS"})
+
+		require.IsType(t, &categories.CategoryAlreadyExistsError{}, err)
+	})
+
+	t.Run("a rename is carried to the products and the promotions", func(t *testing.T) {
+		fruits := getCategory("Fruits")
+
+		require.NoError(t, categoriesService.Rename(&fruits, "Fruits de saison"))
+
+		require.Equal(t, []string{"Fruits de saison", "Bio"}, getProduct(apples.ID).Categories)
+		require.Equal(t, []string{"Fruits de saison"}, getProduct(pears.ID).Categories)
+
+		databasePromotion, err := promotionsService.GetById(categoryPromotion.ID.Hex())
+		require.NoError(t, err)
+		require.Equal(t, "Fruits de saison", *databasePromotion.Category)
+	})
+
+	t.Run("a rename cannot take the name of another category", func(t *testing.T) {
+		fruits := getCategory("Fruits de saison")
+
+		err := categoriesService.Rename(&fruits, "bio")
+
+		require.IsType(t, &categories.CategoryAlreadyExistsError{}, err)
+	})
+
+	t.Run("a merge moves the products and deletes the merged categories", func(t *testing.T) {
+		fruits := getCategory("Fruits de saison")
+		bio := getCategory("Bio")
+
+		require.NoError(t, categoriesService.Merge(&bio, []string{fruits.ID.Hex()}))
+
+		require.Equal(t, []string{"Bio"}, getProduct(apples.ID).Categories)
+		require.Equal(t, []primitive.ObjectID{bio.ID}, getProduct(apples.ID).CategoryIDs)
+		require.Equal(t, []string{"Bio"}, getProduct(pears.ID).Categories)
+
+		databasePromotion, err := promotionsService.GetById(categoryPromotion.ID.Hex())
+		require.NoError(t, err)
+		require.Equal(t, "Bio", *databasePromotion.Category)
+
+		databaseCategories, err := categoriesService.GetForCommerce(commerceID)
+		require.NoError(t, err)
+		require.Len(t, databaseCategories, 1)
+		require.Equal(t, 0, databaseCategories[0].Position)
+	})
+
+	t.Run("resolving names reuses the existing categories", func(t *testing.T) {
+		resolvedCategories, err := categoriesService.ResolveNames(commerceID, []string{"BIO", "Légumes", "légumes"})
+		require.NoError(t, err)
+
+		require.Equal(t, []string{"Bio", "Légumes"}, categories.Names(resolvedCategories))
+		require.Equal(t, 1, resolvedCategories[1].Position)
+	})
+}
+
+func stringPointer(value string) *string {
+	return &value
+}
@@ -0,0 +1,49 @@
+package integrationtests_tests
+
+import (
+	"testing"
+
+	"chemin-du-local.bzh/graphql/graph/model"
+	"chemin-du-local.bzh/graphql/internal/commerces"
+	"chemin-du-local.bzh/graphql/internal/config"
+	"chemin-du-local.bzh/graphql/internal/database"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestIntegrationCommerceSlugs(t *testing.T) {
+	config.Init("config_tests.yml")
+
+	shouldDropDb := true
+	database.Init(&shouldDropDb)
+
+	commercesService := commerces.NewCommercesService()
+
+	city := "Quimper"
+	input := model.NewCommerce{
+		Siret:     "0000000000",
+		Name:      "Crêperie Ty Breizh",
+		Address:   &model.NewAddress{City: &city},
+		Latitude:  47.9960,
+		Longitude: -4.1024,
+	}
+
+	first, err := commercesService.Create(input, primitive.NewObjectID())
+	require.NoError(t, err)
+
+	second, err := commercesService.Create(input, primitive.NewObjectID())
+	require.NoError(t, err)
+
+	t.Run("commerces with the same name get distinct slugs", func(t *testing.T) {
+		require.Equal(t, "creperie-ty-breizh-quimper", first.Slug)
+		require.Equal(t, "creperie-ty-breizh-quimper-2", second.Slug)
+	})
+
+	t.Run("the unique index refuses a slug already used", func(t *testing.T) {
+		second.Slug = first.Slug
+
+		err := commercesService.Update(second, nil, nil)
+
+		require.IsType(t, &commerces.SlugAlreadyUsedError{}, err)
+	})
+}
@@ -0,0 +1,61 @@
+package integrationtests_tests
+
+import (
+	"testing"
+
+	"chemin-du-local.bzh/graphql/graph/model"
+	"chemin-du-local.bzh/graphql/internal/config"
+	"chemin-du-local.bzh/graphql/internal/database"
+	"chemin-du-local.bzh/graphql/internal/giftcards"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestIntegrationGiftCards(t *testing.T) {
+	config.Init("config_tests.yml")
+
+	shouldDropDb := true
+	database.Init(&shouldDropDb)
+
+	giftCardsService := giftcards.NewGiftCardsService()
+
+	card, err := giftCardsService.Create(primitive.NewObjectID(), model.NewGiftCard{Amount: 50})
+	require.NoError(t, err)
+	require.NoError(t, giftCardsService.Activate(card, "pi_test"))
+
+	getBalance := func() int {
+		databaseCard, err := giftCardsService.GetById(card.ID.Hex())
+		require.NoError(t, err)
+
+		return databaseCard.Balance
+	}
+
+	commerceID := primitive.NewObjectID()
+
+	t.Run("a redemption cannot exceed the balance", func(t *testing.T) {
+		// Deux commandes lisent la carte avant d'être débitées
+		staleCard := *card
+
+		require.NoError(t, giftCardsService.Redeem(card, 3000, commerceID, primitive.NewObjectID()))
+
+		err := giftCardsService.Redeem(&staleCard, 3000, commerceID, primitive.NewObjectID())
+		require.IsType(t, &giftcards.GiftCardInsufficientBalanceError{}, err)
+		require.Equal(t, 2000, getBalance())
+	})
+
+	t.Run("a redeemed order is refunded once", func(t *testing.T) {
+		commerceCommandID := primitive.NewObjectID()
+
+		require.NoError(t, giftCardsService.Redeem(card, 1500, commerceID, commerceCommandID))
+		require.Equal(t, 500, getBalance())
+
+		require.NoError(t, giftCardsService.Refund(card.ID, 1500, commerceID, commerceCommandID))
+		require.NoError(t, giftCardsService.Refund(card.ID, 1500, commerceID, commerceCommandID))
+		require.Equal(t, 2000, getBalance())
+	})
+
+	t.Run("an order that was never debited is not refunded", func(t *testing.T) {
+		require.NoError(t, giftCardsService.Refund(card.ID, 1500, commerceID, primitive.NewObjectID()))
+		require.Equal(t, 2000, getBalance())
+	})
+}
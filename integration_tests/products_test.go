@@ -0,0 +1,206 @@
+package integrationtests_tests
+
+import (
+	"testing"
+	"time"
+
+	"chemin-du-local.bzh/graphql/graph/model"
+	"chemin-du-local.bzh/graphql/internal/commerces"
+	"chemin-du-local.bzh/graphql/internal/config"
+	"chemin-du-local.bzh/graphql/internal/database"
+	"chemin-du-local.bzh/graphql/internal/products"
+	"chemin-du-local.bzh/graphql/internal/stock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestIntegrationProductSearch(t *testing.T) {
+	config.Init("config_tests.yml")
+
+	shouldDropDb := true
+	database.Init(&shouldDropDb)
+
+	productsService := products.NewProductsService()
+	commerceID := primitive.NewObjectID()
+
+	_, err := productsService.Create(commerceID.Hex(), model.NewProduct{
+		Name:  "Cidre",
+		Price: 4,
+		Unit:  "bouteille",
+		Variants: []*model.NewProductVariant{
+			{Name: "75 cl", Price: 4.5},
+			{Name: "Magnum", Price: 12},
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = productsService.Create(commerceID.Hex(), model.NewProduct{
+		Name:  "Galette",
+		Price: 2,
+		Unit:  "pièce",
+	})
+	require.NoError(t, err)
+
+	offSeasonMonth := (int(time.Now().Month())+5)%12 + 1
+	_, err = productsService.Create(commerceID.Hex(), model.NewProduct{
+		Name:  "Huîtres",
+		Price: 15,
+		Unit:  "douzaine",
+		Seasons: []*model.ProductSeasonInput{
+			{StartMonth: &offSeasonMonth, EndMonth: &offSeasonMonth},
+		},
+	})
+	require.NoError(t, err)
+
+	t.Run("products out of season are not found", func(t *testing.T) {
+		result, err := productsService.Search([]primitive.ObjectID{commerceID}, nil, nil, 10)
+		require.NoError(t, err)
+
+		require.Equal(t, 2, result.TotalCount)
+
+		for _, product := range result.Products {
+			require.NotEqual(t, "Huîtres", product.Name)
+		}
+	})
+
+	t.Run("the query uses the text index", func(t *testing.T) {
+		query := "galettes"
+		result, err := productsService.Search([]primitive.ObjectID{commerceID}, &model.ProductSearchFilter{Query: &query}, nil, 10)
+		require.NoError(t, err)
+
+		require.Equal(t, 1, result.TotalCount)
+		require.Equal(t, "Galette", result.Products[0].Name)
+	})
+
+	t.Run("the page size is capped", func(t *testing.T) {
+		result, err := productsService.Search([]primitive.ObjectID{commerceID}, nil, nil, products.SEARCH_MAX_FIRST+1)
+		require.NoError(t, err)
+
+		require.Len(t, result.Products, 2)
+	})
+
+	t.Run("the price facet counts the variant prices once per range", func(t *testing.T) {
+		result, err := productsService.Search([]primitive.ObjectID{commerceID}, nil, nil, 10)
+		require.NoError(t, err)
+
+		countByMin := map[float64]int{}
+
+		for _, value := range result.Facets.Prices {
+			countByMin[value.Min] = value.Count
+		}
+
+		require.Equal(t, 2, countByMin[0])
+		require.Equal(t, 1, countByMin[10])
+	})
+}
+
+func TestIntegrationProductImport(t *testing.T) {
+	config.Init("config_tests.yml")
+
+	shouldDropDb := true
+	database.Init(&shouldDropDb)
+
+	commercesService := commerces.NewCommercesService()
+	productsService := products.NewProductsService()
+	stockService := stock.NewStockService(productsService, commercesService)
+
+	userID := primitive.NewObjectID()
+	commerceID := primitive.NewObjectID()
+	ref := "CIDRE"
+
+	existingProduct, err := productsService.Create(commerceID.Hex(), model.NewProduct{
+		ExternalRef: &ref,
+		Name:        "Cidre",
+		Price:       4,
+		Unit:        "bouteille",
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, stockService.SetTracking(existingProduct, nil, true, 0))
+	require.NoError(t, stockService.Adjust(existingProduct, nil, 12, nil, userID))
+
+	rows := [][]string{
+		{"externalRef", "name", "price", "unit"},
+		{"CIDRE", "", "4.5", ""},
+		{"GALETTE", "Galette", "2", "pièce"},
+	}
+
+	t.Run("a dry run writes nothing", func(t *testing.T) {
+		report, err := productsService.Import(commerceID, rows, nil, true, userID)
+		require.NoError(t, err)
+		require.Equal(t, 1, report.Created)
+		require.Equal(t, 1, report.Updated)
+
+		databaseProducts, err := productsService.GetForCommerce(commerceID.Hex())
+		require.NoError(t, err)
+		require.Len(t, databaseProducts, 1)
+	})
+
+	t.Run("the import creates and updates the products in one write", func(t *testing.T) {
+		_, err := productsService.Import(commerceID, rows, nil, false, userID)
+		require.NoError(t, err)
+
+		databaseProducts, err := productsService.GetForCommerce(commerceID.Hex())
+		require.NoError(t, err)
+		require.Len(t, databaseProducts, 2)
+
+		databaseProduct, err := productsService.GetById(existingProduct.ID.Hex())
+		require.NoError(t, err)
+		require.Equal(t, "Cidre", databaseProduct.Name)
+		require.Equal(t, 4.5, databaseProduct.Price)
+		require.Equal(t, 12, databaseProduct.Stock.Quantity)
+		require.Len(t, databaseProduct.PriceHistory, 1)
+	})
+}
+
+func TestIntegrationProductPriceHistory(t *testing.T) {
+	config.Init("config_tests.yml")
+
+	shouldDropDb := true
+	database.Init(&shouldDropDb)
+
+	productsService := products.NewProductsService()
+
+	userID := primitive.NewObjectID()
+	product, err := productsService.Create(primitive.NewObjectID().Hex(), model.NewProduct{
+		Name:  "Fraises",
+		Price: 8,
+		Unit:  "barquette",
+	})
+	require.NoError(t, err)
+
+	getProduct := func() *products.Product {
+		databaseProduct, err := productsService.GetById(product.ID.Hex())
+		require.NoError(t, err)
+
+		return databaseProduct
+	}
+
+	t.Run("an update without a new price records nothing", func(t *testing.T) {
+		changes := getProduct()
+		changes.Name = "Fraises de Plougastel"
+		require.NoError(t, productsService.Update(changes, nil, userID))
+
+		require.Empty(t, getProduct().PriceHistory)
+	})
+
+	t.Run("each new price is recorded with its author", func(t *testing.T) {
+		changes := getProduct()
+		changes.Price = 10
+		require.NoError(t, productsService.Update(changes, nil, userID))
+
+		changes = getProduct()
+		changes.Price = 9
+		require.NoError(t, productsService.Update(changes, nil, userID))
+
+		databaseProduct := getProduct()
+		require.Len(t, databaseProduct.PriceHistory, 2)
+		require.Equal(t, 8.0, databaseProduct.PriceHistory[0].PreviousPrice)
+		require.Equal(t, 10.0, databaseProduct.PriceHistory[0].Price)
+		require.Equal(t, userID, databaseProduct.PriceHistory[0].UserID)
+		require.Equal(t, 10.0, databaseProduct.PriceHistory[1].PreviousPrice)
+		require.Equal(t, 9.0, databaseProduct.PriceHistory[1].Price)
+
+		require.Equal(t, 8.0, databaseProduct.GetLowestRecentPrice(time.Now()))
+	})
+}
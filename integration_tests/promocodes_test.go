@@ -0,0 +1,76 @@
+package integrationtests_tests
+
+import (
+	"testing"
+	"time"
+
+	"chemin-du-local.bzh/graphql/graph/model"
+	"chemin-du-local.bzh/graphql/internal/config"
+	"chemin-du-local.bzh/graphql/internal/database"
+	"chemin-du-local.bzh/graphql/internal/promocodes"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestIntegrationPromoCodes(t *testing.T) {
+	config.Init("config_tests.yml")
+
+	shouldDropDb := true
+	database.Init(&shouldDropDb)
+
+	promoCodesService := promocodes.NewPromoCodesService()
+
+	maxUsesPerUser := 1
+	input := model.NewPromoCode{
+		Code:           "bienvenue",
+		Type:           model.PromoCodeTypeFixedAmount,
+		Value:          5,
+		StartDate:      time.Now().AddDate(0, 0, -1),
+		EndDate:        time.Now().AddDate(0, 0, 1),
+		MaxUsesPerUser: &maxUsesPerUser,
+	}
+
+	promoCode, err := promoCodesService.Create(nil, input)
+	require.NoError(t, err)
+
+	t.Run("a code can only be created once", func(t *testing.T) {
+		input.Code = "Bienvenue "
+		_, err := promoCodesService.Create(nil, input)
+
+		require.IsType(t, &promocodes.PromoCodeAlreadyExistsError{}, err)
+	})
+
+	t.Run("the usage limit per user is enforced on redeem", func(t *testing.T) {
+		userID := primitive.NewObjectID()
+		commandID := primitive.NewObjectID()
+
+		// Deux lectures du code avant la première utilisation
+		staleCode := *promoCode
+
+		require.NoError(t, promoCodesService.Redeem(promoCode, userID, commandID))
+
+		err := promoCodesService.Redeem(&staleCode, userID, primitive.NewObjectID())
+		require.IsType(t, &promocodes.PromoCodeUsageLimitError{}, err)
+
+		require.NoError(t, promoCodesService.Redeem(&staleCode, primitive.NewObjectID(), primitive.NewObjectID()))
+	})
+
+	t.Run("a released usage can be redeemed again", func(t *testing.T) {
+		userID := primitive.NewObjectID()
+		commandID := primitive.NewObjectID()
+
+		require.NoError(t, promoCodesService.Redeem(promoCode, userID, commandID))
+		require.NoError(t, promoCodesService.Release(promoCode.Code, commandID))
+		require.NoError(t, promoCodesService.Release(promoCode.Code, commandID))
+
+		databasePromoCode, err := promoCodesService.GetById(promoCode.ID.Hex())
+		require.NoError(t, err)
+		// Seules les deux utilisations du test précédent restent
+		require.Equal(t, 2, databasePromoCode.UsageCount)
+
+		userUsageCount, err := promoCodesService.CountUserUsages(promoCode.Code, userID)
+		require.NoError(t, err)
+		require.Equal(t, 0, userUsageCount)
+		require.NoError(t, promoCodesService.Redeem(databasePromoCode, userID, primitive.NewObjectID()))
+	})
+}
@@ -0,0 +1,126 @@
+package integrationtests_tests
+
+import (
+	"testing"
+	"time"
+
+	"chemin-du-local.bzh/graphql/graph/model"
+	"chemin-du-local.bzh/graphql/internal/config"
+	"chemin-du-local.bzh/graphql/internal/database"
+	"chemin-du-local.bzh/graphql/internal/products"
+	"chemin-du-local.bzh/graphql/internal/services/commands"
+	"chemin-du-local.bzh/graphql/internal/services/paniers"
+	"chemin-du-local.bzh/graphql/internal/statistics"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestIntegrationStatistics(t *testing.T) {
+	config.Init("config_tests.yml")
+
+	shouldDropDb := true
+	database.Init(&shouldDropDb)
+
+	productsService := products.NewProductsService()
+	paniersService := paniers.NewPaniersService(productsService)
+	statisticsService := statistics.NewStatisticsService(productsService, paniersService)
+
+	commerceID := primitive.NewObjectID()
+
+	// Les prix ont augmenté depuis les commandes
+	product, err := productsService.Create(commerceID.Hex(), model.NewProduct{
+		Name:  "Pommes",
+		Price: 5,
+		Unit:  "kg",
+	})
+	require.NoError(t, err)
+
+	panier := paniers.Panier{
+		ID:         primitive.NewObjectID(),
+		CommerceID: commerceID,
+		Name:       "Panier de la semaine",
+		Price:      30,
+	}
+	_, err = database.CollectionPaniers.InsertOne(database.MongoContext, panier)
+	require.NoError(t, err)
+
+	pickupDate := time.Date(2026, 3, 10, 10, 0, 0, 0, time.UTC)
+	commerceCommand := commands.CommerceCommand{
+		ID:         primitive.NewObjectID(),
+		CommerceID: commerceID,
+		PickupDate: pickupDate,
+		Price:      4193,
+		Status:     commands.COMMERCE_COMMAND_STATUS_DONE,
+	}
+	_, err = database.CollectionCommerceCommand.InsertOne(database.MongoContext, commerceCommand)
+	require.NoError(t, err)
+
+	// Des commandes abandonnées ou refusées, qui ne comptent pas
+	for _, paymentStatus := range []string{commands.COMMERCE_COMMAND_PAYMENT_STATUS_PENDING, commands.COMMERCE_COMMAND_PAYMENT_STATUS_FAILED} {
+		_, err = database.CollectionCommerceCommand.InsertOne(database.MongoContext, commands.CommerceCommand{
+			ID:            primitive.NewObjectID(),
+			CommerceID:    commerceID,
+			PickupDate:    pickupDate,
+			Price:         2000,
+			Status:        commands.COMMERCE_COMMAND_STATUS_IN_PROGRESS,
+			PaymentStatus: paymentStatus,
+		})
+		require.NoError(t, err)
+	}
+
+	actualQuantity := 1.2
+	_, err = database.CollectionCCCommand.InsertOne(database.MongoContext, commands.CCCommand{
+		ID:                primitive.NewObjectID(),
+		CommerceCommandID: commerceCommand.ID,
+		Products: []commands.CCProduct{
+			// 1,5 kg estimés à 3€ le kg, 1,2 kg pesés
+			{
+				ID:             primitive.NewObjectID(),
+				ProductID:      product.ID,
+				Quantity:       1.5,
+				UnitPrice:      3,
+				Name:           "Pommes",
+				Price:          3,
+				IsWeighed:      true,
+				ActualQuantity: &actualQuantity,
+			},
+			// Une ligne antérieure aux instantanés, au prix actuel
+			{
+				ID:        primitive.NewObjectID(),
+				ProductID: product.ID,
+				Quantity:  1,
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	for _, discount := range []float64{3.33, 3.34} {
+		_, err = database.CollectionPanierCommands.InsertOne(database.MongoContext, commands.PanierCommand{
+			ID:                primitive.NewObjectID(),
+			CommerceCommandID: commerceCommand.ID,
+			PanierID:          panier.ID,
+			Name:              panier.Name,
+			Price:             20,
+			Discount:          discount,
+		})
+		require.NoError(t, err)
+	}
+
+	t.Run("the revenue of the rankings is the one of the orders", func(t *testing.T) {
+		result, err := statisticsService.GetForCommerce(commerceID.Hex(), model.StatisticsPeriod{
+			From: pickupDate.AddDate(0, 0, -1),
+			To:   pickupDate.AddDate(0, 0, 1),
+		}, model.StatisticsGranularityDay)
+		require.NoError(t, err)
+
+		require.Equal(t, 1, result.OrderCount)
+		require.Equal(t, 41.93, result.Revenue)
+
+		require.Len(t, result.TopProducts, 1)
+		require.Equal(t, 8.6, result.TopProducts[0].Revenue)
+
+		require.Len(t, result.TopPaniers, 1)
+		require.Equal(t, 2, result.TopPaniers[0].Quantity)
+		require.Equal(t, 33.33, result.TopPaniers[0].Revenue)
+	})
+}
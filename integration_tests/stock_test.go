@@ -0,0 +1,75 @@
+package integrationtests_tests
+
+import (
+	"testing"
+
+	"chemin-du-local.bzh/graphql/graph/model"
+	"chemin-du-local.bzh/graphql/internal/commerces"
+	"chemin-du-local.bzh/graphql/internal/config"
+	"chemin-du-local.bzh/graphql/internal/database"
+	"chemin-du-local.bzh/graphql/internal/products"
+	"chemin-du-local.bzh/graphql/internal/stock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestIntegrationStock(t *testing.T) {
+	config.Init("config_tests.yml")
+
+	shouldDropDb := true
+	database.Init(&shouldDropDb)
+
+	commercesService := commerces.NewCommercesService()
+	productsService := products.NewProductsService()
+	stockService := stock.NewStockService(productsService, commercesService)
+
+	userID := primitive.NewObjectID()
+	product, err := productsService.Create(primitive.NewObjectID().Hex(), model.NewProduct{
+		Name:  "Pommes",
+		Price: 3.5,
+		Unit:  "kg",
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, stockService.SetTracking(product, nil, true, 0))
+	require.NoError(t, stockService.Adjust(product, nil, 10, nil, userID))
+
+	getQuantity := func() int {
+		databaseProduct, err := productsService.GetById(product.ID.Hex())
+		require.NoError(t, err)
+
+		return databaseProduct.Stock.Quantity
+	}
+
+	t.Run("a released order gives its stock back once", func(t *testing.T) {
+		commerceCommandID := primitive.NewObjectID()
+
+		err := stockService.Reserve(commerceCommandID, []stock.StockItem{
+			{ProductID: product.ID, Quantity: 3},
+		})
+		require.NoError(t, err)
+		require.Equal(t, 7, getQuantity())
+
+		require.NoError(t, stockService.Release(commerceCommandID))
+		require.NoError(t, stockService.Release(commerceCommandID))
+		require.Equal(t, 10, getQuantity())
+	})
+
+	t.Run("updating a product keeps the stock reserved since it was read", func(t *testing.T) {
+		staleProduct, err := productsService.GetById(product.ID.Hex())
+		require.NoError(t, err)
+
+		err = stockService.Reserve(primitive.NewObjectID(), []stock.StockItem{
+			{ProductID: product.ID, Quantity: 4},
+		})
+		require.NoError(t, err)
+
+		staleProduct.Name = "Pommes à couteau"
+		require.NoError(t, productsService.Update(staleProduct, nil, userID))
+
+		databaseProduct, err := productsService.GetById(product.ID.Hex())
+		require.NoError(t, err)
+		require.Equal(t, "Pommes à couteau", databaseProduct.Name)
+		require.Equal(t, 6, databaseProduct.Stock.Quantity)
+	})
+}
@@ -1,7 +1,12 @@
 package address
 
 import (
+	"encoding/json"
+	"strings"
+
 	"chemin-du-local.bzh/graphql/graph/model"
+	"chemin-du-local.bzh/graphql/internal/helper"
+	"chemin-du-local.bzh/graphql/pkg/geojson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -12,9 +17,18 @@ type Address struct {
 	OptionalRoute *string            `bson:"optionalRoute"`
 	PostalCode    *string            `bson:"postalCode"`
 	City          *string            `bson:"city"`
+	Location      *geojson.GeoJSON   `bson:"location,omitempty"`
 }
 
 func (address *Address) ToModel() *model.Address {
+	var latitude *float64
+	var longitude *float64
+
+	if address.Location != nil && len(address.Location.Coordinates) == 2 {
+		longitude = &address.Location.Coordinates[0]
+		latitude = &address.Location.Coordinates[1]
+	}
+
 	return &model.Address{
 		ID:            address.ID.Hex(),
 		Number:        address.Number,
@@ -22,5 +36,90 @@ func (address *Address) ToModel() *model.Address {
 		OptionalRoute: address.OptionalRoute,
 		PostalCode:    address.PostalCode,
 		City:          address.City,
+		Latitude:      latitude,
+		Longitude:     longitude,
+	}
+}
+
+// Crée une adresse à partir de l'input GraphQL, la position n'étant
+// renseignée que si la latitude et la longitude sont fournies
+func FromInput(input *model.NewAddress) Address {
+	databaseAddress := Address{
+		ID:            primitive.NewObjectID(),
+		Number:        input.Number,
+		Route:         input.Route,
+		OptionalRoute: input.OptionalRoute,
+		PostalCode:    input.PostalCode,
+		City:          input.City,
+	}
+
+	if input.Latitude != nil && input.Longitude != nil {
+		location := geojson.NewPoint(*input.Longitude, *input.Latitude)
+		databaseAddress.Location = &location
+	}
+
+	return databaseAddress
+}
+
+// Applique les changements d'une adresse. La position n'est modifiée que
+// si la latitude et la longitude sont toutes deux fournies.
+func (address *Address) ApplyChanges(changes map[string]interface{}) error {
+	latitude, hasLatitude := toFloat(changes["latitude"])
+	longitude, hasLongitude := toFloat(changes["longitude"])
+
+	otherChanges := map[string]interface{}{}
+
+	for key, value := range changes {
+		if key != "latitude" && key != "longitude" {
+			otherChanges[key] = value
+		}
 	}
+
+	err := helper.ApplyChanges(otherChanges, address)
+
+	if err != nil {
+		return err
+	}
+
+	if hasLatitude && hasLongitude {
+		location := geojson.NewPoint(longitude, latitude)
+		address.Location = &location
+	}
+
+	return nil
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch castedValue := value.(type) {
+	case json.Number:
+		result, err := castedValue.Float64()
+		return result, err == nil
+	case float64:
+		return castedValue, true
+	case int64:
+		return float64(castedValue), true
+	case int:
+		return float64(castedValue), true
+	}
+
+	return 0, false
+}
+
+// Adresse sur une ligne, les parties absentes étant ignorées
+func (address *Address) Format() string {
+	parts := []string{}
+
+	for _, part := range []*string{
+		address.Number,
+		address.Route,
+		address.OptionalRoute,
+		address.PostalCode,
+		address.City,
+	} {
+		if part != nil && strings.TrimSpace(*part) != "" {
+			parts = append(parts, strings.TrimSpace(*part))
+		}
+	}
+
+	return strings.Join(parts, " ")
 }
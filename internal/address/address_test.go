@@ -0,0 +1,65 @@
+package address
+
+import (
+	"encoding/json"
+	"testing"
+
+	"chemin-du-local.bzh/graphql/graph/model"
+	"github.com/stretchr/testify/require"
+)
+
+// Tests sur la création d'une adresse
+func TestFromInput(t *testing.T) {
+	city := "Le Rheu"
+
+	t.Run("the position is saved as longitude, latitude", func(t *testing.T) {
+		latitude := 48.0931
+		longitude := -1.7779
+
+		address := FromInput(&model.NewAddress{City: &city, Latitude: &latitude, Longitude: &longitude})
+
+		require.Equal(t, []float64{-1.7779, 48.0931}, address.Location.Coordinates)
+		require.Equal(t, &latitude, address.ToModel().Latitude)
+		require.Equal(t, &longitude, address.ToModel().Longitude)
+	})
+
+	t.Run("an address without latitude has no position", func(t *testing.T) {
+		longitude := -1.7779
+
+		address := FromInput(&model.NewAddress{City: &city, Longitude: &longitude})
+
+		require.Nil(t, address.Location)
+	})
+}
+
+// Tests sur les changements d'une adresse
+func TestAddress_ApplyChanges(t *testing.T) {
+	route := "Rue Nationale"
+	city := "Le Rheu"
+
+	t.Run("the latitude and longitude change the position", func(t *testing.T) {
+		address := Address{Route: &route, City: &city}
+
+		err := address.ApplyChanges(map[string]interface{}{
+			"city":      "Rennes",
+			"latitude":  json.Number("48.1113"),
+			"longitude": -1.68,
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, "Rennes", *address.City)
+		require.Equal(t, "Rue Nationale", *address.Route)
+		require.Equal(t, []float64{-1.68, 48.1113}, address.Location.Coordinates)
+	})
+
+	t.Run("a latitude alone keeps the position", func(t *testing.T) {
+		address := FromInput(&model.NewAddress{City: &city})
+
+		err := address.ApplyChanges(map[string]interface{}{
+			"latitude": json.Number("48.1113"),
+		})
+
+		require.NoError(t, err)
+		require.Nil(t, address.Location)
+	})
+}
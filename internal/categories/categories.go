@@ -0,0 +1,686 @@
+package categories
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"chemin-du-local.bzh/graphql/graph/model"
+	"chemin-du-local.bzh/graphql/internal/config"
+	"chemin-du-local.bzh/graphql/internal/database"
+	"github.com/99designs/gqlgen/graphql"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Une catégorie de produits d'un commerce. Les produits référencent leurs
+// catégories par identifiant et gardent une copie de leur nom, utilisée
+// par la recherche, les filtres et les promotions, que ce service tient à
+// jour.
+//
+// Le nom normalisé est unique dans le commerce, ce que garantit un index.
+type Category struct {
+	ID             primitive.ObjectID `bson:"_id"`
+	CommerceID     primitive.ObjectID `bson:"commerceID"`
+	Name           string             `bson:"name"`
+	NormalizedName string             `bson:"normalizedName"`
+	Position       int                `bson:"position"`
+	Description    *string            `bson:"description"`
+	HasImage       bool               `bson:"hasImage"`
+}
+
+func (category *Category) ToModel() *model.Category {
+	return &model.Category{
+		ID:          category.ID.Hex(),
+		CommerceID:  category.CommerceID.Hex(),
+		Name:        category.Name,
+		Position:    category.Position,
+		Description: category.Description,
+		HasImage:    category.HasImage,
+	}
+}
+
+// Deux noms ne différant que par la casse ou les espaces désignent la
+// même catégorie
+func NormalizeName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+func IDs(categories []Category) []primitive.ObjectID {
+	ids := []primitive.ObjectID{}
+
+	for _, category := range categories {
+		ids = append(ids, category.ID)
+	}
+
+	return ids
+}
+
+func Names(categories []Category) []string {
+	names := []string{}
+
+	for _, category := range categories {
+		names = append(names, category.Name)
+	}
+
+	return names
+}
+
+// Service
+
+type categoriesService struct{}
+
+type CategoriesService interface {
+	Create(commerceID primitive.ObjectID, input model.NewCategory) (*Category, error)
+	Update(category *Category, changes model.ChangesCategory) error
+	Rename(category *Category, name string) error
+	Reorder(commerceID primitive.ObjectID, categoryIDs []string) ([]Category, error)
+	Merge(target *Category, sourceIDs []string) error
+	ResolveNames(commerceID primitive.ObjectID, names []string) ([]Category, error)
+	SyncProductCategories(filter interface{}) error
+	MigrateProductCategories() error
+	GetById(id string) (*Category, error)
+	GetByIds(commerceID primitive.ObjectID, ids []string) ([]Category, error)
+	GetForCommerce(commerceID primitive.ObjectID) ([]Category, error)
+	GetFiltered(filter interface{}, opts *options.FindOptions) ([]Category, error)
+}
+
+func NewCategoriesService() *categoriesService {
+	return &categoriesService{}
+}
+
+// Créateur de base de données
+
+// La nouvelle catégorie est placée après les autres
+func (c *categoriesService) Create(commerceID primitive.ObjectID, input model.NewCategory) (*Category, error) {
+	name := strings.Join(strings.Fields(input.Name), " ")
+
+	if name == "" {
+		return nil, &InvalidCategoryNameError{}
+	}
+
+	existingCategories, err := c.GetForCommerce(commerceID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if findByName(existingCategories, name) != nil {
+		return nil, &CategoryAlreadyExistsError{}
+	}
+
+	databaseCategory := Category{
+		ID:             primitive.NewObjectID(),
+		CommerceID:     commerceID,
+		Name:           name,
+		NormalizedName: NormalizeName(name),
+		Position:       len(existingCategories),
+		Description:    input.Description,
+		HasImage:       input.Image != nil,
+	}
+
+	_, err = database.CollectionCategories.InsertOne(database.MongoContext, databaseCategory)
+
+	// La même catégorie a pu être créée entre temps
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, &CategoryAlreadyExistsError{}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Image != nil {
+		err = saveImage(databaseCategory.ID, input.Image)
+
+		if err != nil {
+			return &databaseCategory, err
+		}
+	}
+
+	return &databaseCategory, nil
+}
+
+// Renvoie les catégories portant ces noms, dans le même ordre, en créant
+// celles qui n'existent pas encore dans le commerce
+func (c *categoriesService) ResolveNames(commerceID primitive.ObjectID, names []string) ([]Category, error) {
+	existingCategories, err := c.GetForCommerce(commerceID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return c.resolveNames(commerceID, &existingCategories, names)
+}
+
+func (c *categoriesService) resolveNames(commerceID primitive.ObjectID, existingCategories *[]Category, names []string) ([]Category, error) {
+	result := []Category{}
+
+	for _, name := range names {
+		category := findByName(*existingCategories, name)
+
+		if category == nil {
+			createdCategory, err := c.Create(commerceID, model.NewCategory{Name: name})
+
+			if _, ok := err.(*InvalidCategoryNameError); ok {
+				continue
+			}
+
+			// Créée par une autre requête depuis la lecture des
+			// catégories du commerce : c'est celle-ci qui est utilisée
+			if _, ok := err.(*CategoryAlreadyExistsError); ok {
+				createdCategory, err = c.getByName(commerceID, name)
+			}
+
+			if err != nil {
+				return nil, err
+			}
+
+			*existingCategories = append(*existingCategories, *createdCategory)
+			category = createdCategory
+		}
+
+		if findByName(result, category.Name) == nil {
+			result = append(result, *category)
+		}
+	}
+
+	return result, nil
+}
+
+// Mise à jour de la base de données
+
+func (c *categoriesService) Update(category *Category, changes model.ChangesCategory) error {
+	if changes.Description != nil {
+		category.Description = changes.Description
+
+		if *changes.Description == "" {
+			category.Description = nil
+		}
+	}
+
+	if changes.Image != nil {
+		err := saveImage(category.ID, changes.Image)
+
+		if err != nil {
+			return err
+		}
+
+		category.HasImage = true
+	}
+
+	_, err := database.CollectionCategories.UpdateOne(
+		database.MongoContext,
+		bson.M{"_id": category.ID},
+		bson.M{"$set": bson.M{
+			"description": category.Description,
+			"hasImage":    category.HasImage,
+		}},
+	)
+
+	return err
+}
+
+// Le nouveau nom est reporté sur les produits et les promotions du
+// commerce qui portent sur la catégorie
+func (c *categoriesService) Rename(category *Category, name string) error {
+	name = strings.Join(strings.Fields(name), " ")
+
+	if name == "" {
+		return &InvalidCategoryNameError{}
+	}
+
+	if name == category.Name {
+		return nil
+	}
+
+	existingCategories, err := c.GetForCommerce(category.CommerceID)
+
+	if err != nil {
+		return err
+	}
+
+	existingCategory := findByName(existingCategories, name)
+
+	// Seul un changement de casse peut garder le nom d'une catégorie
+	// existante
+	if existingCategory != nil && existingCategory.ID != category.ID {
+		return &CategoryAlreadyExistsError{}
+	}
+
+	_, err = database.CollectionCategories.UpdateOne(
+		database.MongoContext,
+		bson.M{"_id": category.ID},
+		bson.M{"$set": bson.M{
+			"name":           name,
+			"normalizedName": NormalizeName(name),
+		}},
+	)
+
+	if mongo.IsDuplicateKeyError(err) {
+		return &CategoryAlreadyExistsError{}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// Le nom est remplacé à sa place dans la liste de chaque produit
+	_, err = database.CollectionProducts.UpdateMany(
+		database.MongoContext,
+		bson.M{"commerceID": category.CommerceID, "categoryIDs": category.ID},
+		bson.M{"$set": bson.M{"categories.$[name]": name}},
+		options.Update().SetArrayFilters(options.ArrayFilters{
+			Filters: []interface{}{bson.M{"name": category.Name}},
+		}),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	err = renamePromotions(category.CommerceID, category.Name, name)
+
+	if err != nil {
+		return err
+	}
+
+	category.Name = name
+
+	return nil
+}
+
+// Les positions sont données par l'ordre de la liste, qui doit contenir
+// toutes les catégories du commerce
+func (c *categoriesService) Reorder(commerceID primitive.ObjectID, categoryIDs []string) ([]Category, error) {
+	existingCategories, err := c.GetForCommerce(commerceID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	orderedCategories, err := orderCategories(existingCategories, categoryIDs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for position := range orderedCategories {
+		category := &orderedCategories[position]
+
+		if category.Position == position {
+			continue
+		}
+
+		_, err := database.CollectionCategories.UpdateOne(
+			database.MongoContext,
+			bson.M{"_id": category.ID},
+			bson.M{"$set": bson.M{"position": position}},
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		category.Position = position
+	}
+
+	return orderedCategories, nil
+}
+
+// Rattache les produits et les promotions des catégories fusionnées à la
+// catégorie conservée, puis supprime les catégories fusionnées
+func (c *categoriesService) Merge(target *Category, sourceIDs []string) error {
+	sources, err := c.GetByIds(target.CommerceID, sourceIDs)
+
+	if _, ok := err.(*CategoryNotFoundError); ok {
+		return &InvalidCategoryMergeError{}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	for _, source := range sources {
+		if source.ID == target.ID {
+			return &InvalidCategoryMergeError{}
+		}
+	}
+
+	sourceObjectIDs := IDs(sources)
+	sourceNames := Names(sources)
+
+	// Deux mises à jour, MongoDB ne permettant pas d'ajouter et de
+	// retirer des éléments d'un même tableau en une seule
+	_, err = database.CollectionProducts.UpdateMany(
+		database.MongoContext,
+		bson.M{"commerceID": target.CommerceID, "categoryIDs": bson.M{"$in": sourceObjectIDs}},
+		bson.M{"$addToSet": bson.M{
+			"categoryIDs": target.ID,
+			"categories":  target.Name,
+		}},
+	)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = database.CollectionProducts.UpdateMany(
+		database.MongoContext,
+		bson.M{"commerceID": target.CommerceID, "categoryIDs": bson.M{"$in": sourceObjectIDs}},
+		bson.M{"$pull": bson.M{
+			"categoryIDs": bson.M{"$in": sourceObjectIDs},
+			"categories":  bson.M{"$in": sourceNames},
+		}},
+	)
+
+	if err != nil {
+		return err
+	}
+
+	for _, source := range sources {
+		err = renamePromotions(target.CommerceID, source.Name, target.Name)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = database.CollectionCategories.DeleteMany(
+		database.MongoContext,
+		bson.M{"_id": bson.M{"$in": sourceObjectIDs}},
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return c.compactPositions(target.CommerceID)
+}
+
+// Renumérote les catégories du commerce à partir de 0, sans trou
+func (c *categoriesService) compactPositions(commerceID primitive.ObjectID) error {
+	existingCategories, err := c.GetForCommerce(commerceID)
+
+	if err != nil {
+		return err
+	}
+
+	for position, category := range existingCategories {
+		if category.Position == position {
+			continue
+		}
+
+		_, err := database.CollectionCategories.UpdateOne(
+			database.MongoContext,
+			bson.M{"_id": category.ID},
+			bson.M{"$set": bson.M{"position": position}},
+		)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Fait correspondre les catégories des produits visés aux noms qu'ils
+// portent : les catégories manquantes sont créées, et les noms prennent
+// l'orthographe de la catégorie
+func (c *categoriesService) SyncProductCategories(filter interface{}) error {
+	opts := options.Find().SetProjection(bson.M{
+		"commerceID":  1,
+		"categories":  1,
+		"categoryIDs": 1,
+	})
+
+	cursor, err := database.CollectionProducts.Find(database.MongoContext, filter, opts)
+
+	if err != nil {
+		return err
+	}
+
+	commercesCategories := map[primitive.ObjectID]*[]Category{}
+
+	for cursor.Next(database.MongoContext) {
+		var product struct {
+			ID          primitive.ObjectID   `bson:"_id"`
+			CommerceID  primitive.ObjectID   `bson:"commerceID"`
+			Categories  []string             `bson:"categories"`
+			CategoryIDs []primitive.ObjectID `bson:"categoryIDs"`
+		}
+
+		err := cursor.Decode(&product)
+
+		if err != nil {
+			return err
+		}
+
+		existingCategories, ok := commercesCategories[product.CommerceID]
+
+		if !ok {
+			databaseCategories, err := c.GetForCommerce(product.CommerceID)
+
+			if err != nil {
+				return err
+			}
+
+			existingCategories = &databaseCategories
+			commercesCategories[product.CommerceID] = existingCategories
+		}
+
+		productCategories, err := c.resolveNames(product.CommerceID, existingCategories, product.Categories)
+
+		if err != nil {
+			return err
+		}
+
+		_, err = database.CollectionProducts.UpdateOne(
+			database.MongoContext,
+			bson.M{"_id": product.ID},
+			bson.M{"$set": bson.M{
+				"categoryIDs": IDs(productCategories),
+				"categories":  Names(productCategories),
+			}},
+		)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+// Les produits créés avant les catégories n'ont que des noms de
+// catégorie : les catégories de leur commerce sont créées à partir de ces
+// noms
+func (c *categoriesService) MigrateProductCategories() error {
+	return c.SyncProductCategories(bson.M{"categoryIDs": bson.M{"$exists": false}})
+}
+
+// Getter de base de données
+
+func (c *categoriesService) getByName(commerceID primitive.ObjectID, name string) (*Category, error) {
+	databaseCategories, err := c.GetFiltered(bson.M{
+		"commerceID":     commerceID,
+		"normalizedName": NormalizeName(name),
+	}, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(databaseCategories) == 0 {
+		return nil, &CategoryNotFoundError{}
+	}
+
+	return &databaseCategories[0], nil
+}
+
+func (c *categoriesService) GetById(id string) (*Category, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	databaseCategories, err := c.GetFiltered(bson.M{"_id": objectID}, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(databaseCategories) == 0 {
+		return nil, nil
+	}
+
+	return &databaseCategories[0], nil
+}
+
+// Renvoie les catégories dans l'ordre des identifiants, qui doivent tous
+// désigner une catégorie du commerce
+func (c *categoriesService) GetByIds(commerceID primitive.ObjectID, ids []string) ([]Category, error) {
+	objectIDs := []primitive.ObjectID{}
+
+	for _, id := range ids {
+		objectID, err := primitive.ObjectIDFromHex(id)
+
+		if err != nil {
+			return nil, err
+		}
+
+		objectIDs = append(objectIDs, objectID)
+	}
+
+	databaseCategories, err := c.GetFiltered(bson.M{
+		"_id":        bson.M{"$in": objectIDs},
+		"commerceID": commerceID,
+	}, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := []Category{}
+
+	for _, objectID := range objectIDs {
+		found := false
+
+		for _, category := range databaseCategories {
+			if category.ID == objectID {
+				result = append(result, category)
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, &CategoryNotFoundError{}
+		}
+	}
+
+	return result, nil
+}
+
+func (c *categoriesService) GetForCommerce(commerceID primitive.ObjectID) ([]Category, error) {
+	opts := options.Find().SetSort(bson.D{
+		primitive.E{Key: "position", Value: 1},
+		primitive.E{Key: "name", Value: 1},
+	})
+
+	return c.GetFiltered(bson.M{"commerceID": commerceID}, opts)
+}
+
+func (c *categoriesService) GetFiltered(filter interface{}, opts *options.FindOptions) ([]Category, error) {
+	databaseCategories := []Category{}
+
+	cursor, err := database.CollectionCategories.Find(database.MongoContext, filter, opts)
+
+	if err != nil {
+		return databaseCategories, err
+	}
+
+	for cursor.Next(database.MongoContext) {
+		var category Category
+
+		err := cursor.Decode(&category)
+
+		if err != nil {
+			return databaseCategories, err
+		}
+
+		databaseCategories = append(databaseCategories, category)
+	}
+
+	return databaseCategories, nil
+}
+
+// Utilitaires
+
+// Range les catégories du commerce dans l'ordre des identifiants, qui
+// doivent désigner chacune d'elles une seule fois
+func orderCategories(existingCategories []Category, categoryIDs []string) ([]Category, error) {
+	if len(categoryIDs) != len(existingCategories) {
+		return nil, &InvalidCategoryOrderError{}
+	}
+
+	categoriesByID := map[string]Category{}
+
+	for _, category := range existingCategories {
+		categoriesByID[category.ID.Hex()] = category
+	}
+
+	orderedCategories := []Category{}
+
+	for _, id := range categoryIDs {
+		category, ok := categoriesByID[id]
+
+		if !ok {
+			return nil, &InvalidCategoryOrderError{}
+		}
+
+		// Une catégorie donnée deux fois
+		delete(categoriesByID, id)
+		orderedCategories = append(orderedCategories, category)
+	}
+
+	return orderedCategories, nil
+}
+
+func findByName(categories []Category, name string) *Category {
+	normalizedName := NormalizeName(name)
+
+	for i := range categories {
+		if NormalizeName(categories[i].Name) == normalizedName {
+			return &categories[i]
+		}
+	}
+
+	return nil
+}
+
+// Les promotions désignent leur catégorie par son nom
+func renamePromotions(commerceID primitive.ObjectID, previousName string, name string) error {
+	_, err := database.CollectionPromotions.UpdateMany(
+		database.MongoContext,
+		bson.M{"commerceID": commerceID, "category": previousName},
+		bson.M{"$set": bson.M{"category": name}},
+	)
+
+	return err
+}
+
+func saveImage(categoryID primitive.ObjectID, image *graphql.Upload) error {
+	buffer := &bytes.Buffer{}
+	buffer.ReadFrom(image.File)
+
+	folderPath := config.Cfg.Paths.Static + "/categories"
+	os.MkdirAll(folderPath, os.ModePerm)
+
+	return ioutil.WriteFile(folderPath+"/"+categoryID.Hex()+".jpg", buffer.Bytes(), 0644)
+}
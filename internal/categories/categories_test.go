@@ -0,0 +1,56 @@
+package categories
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNormalizeName(t *testing.T) {
+	require.Equal(t, "fruits de saison", NormalizeName("  Fruits   de Saison "))
+	require.Equal(t, NormalizeName("Légumes"), NormalizeName("LÉGUMES"))
+}
+
+func TestOrderCategories(t *testing.T) {
+	existingCategories := []Category{
+		{ID: primitive.NewObjectID(), Name: "Fruits", Position: 0},
+		{ID: primitive.NewObjectID(), Name: "Légumes", Position: 1},
+		{ID: primitive.NewObjectID(), Name: "Cidres", Position: 2},
+	}
+
+	fruitsID := existingCategories[0].ID.Hex()
+	legumesID := existingCategories[1].ID.Hex()
+	cidresID := existingCategories[2].ID.Hex()
+
+	t.Run("the categories follow the given order", func(t *testing.T) {
+		orderedCategories, err := orderCategories(existingCategories, []string{cidresID, fruitsID, legumesID})
+
+		require.NoError(t, err)
+		require.Equal(t, []string{"Cidres", "Fruits", "Légumes"}, Names(orderedCategories))
+	})
+
+	t.Run("a missing category is refused", func(t *testing.T) {
+		_, err := orderCategories(existingCategories, []string{cidresID, fruitsID})
+
+		require.IsType(t, &InvalidCategoryOrderError{}, err)
+	})
+
+	t.Run("a category given twice is refused", func(t *testing.T) {
+		_, err := orderCategories(existingCategories, []string{cidresID, fruitsID, fruitsID})
+
+		require.IsType(t, &InvalidCategoryOrderError{}, err)
+	})
+
+	t.Run("a category of another commerce is refused", func(t *testing.T) {
+		_, err := orderCategories(existingCategories, []string{cidresID, fruitsID, primitive.NewObjectID().Hex()})
+
+		require.IsType(t, &InvalidCategoryOrderError{}, err)
+	})
+
+	t.Run("an invalid identifier is refused", func(t *testing.T) {
+		_, err := orderCategories(existingCategories, []string{cidresID, fruitsID, "légumes"})
+
+		require.IsType(t, &InvalidCategoryOrderError{}, err)
+	})
+}
@@ -0,0 +1,27 @@
+package categories
+
+type CategoryNotFoundError struct{}
+type CategoryAlreadyExistsError struct{}
+type InvalidCategoryNameError struct{}
+type InvalidCategoryOrderError struct{}
+type InvalidCategoryMergeError struct{}
+
+func (m *CategoryNotFoundError) Error() string {
+	return "la catégorie n'a pas été trouvée"
+}
+
+func (m *CategoryAlreadyExistsError) Error() string {
+	return "une catégorie de ce nom existe déjà dans le commerce, les deux peuvent être fusionnées"
+}
+
+func (m *InvalidCategoryNameError) Error() string {
+	return "le nom de la catégorie ne peut pas être vide"
+}
+
+func (m *InvalidCategoryOrderError) Error() string {
+	return "le nouvel ordre doit contenir chacune des catégories du commerce une seule fois"
+}
+
+func (m *InvalidCategoryMergeError) Error() string {
+	return "les catégories fusionnées doivent appartenir au même commerce que la catégorie conservée"
+}
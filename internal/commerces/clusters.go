@@ -0,0 +1,315 @@
+package commerces
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"chemin-du-local.bzh/graphql/graph/model"
+	"chemin-du-local.bzh/graphql/pkg/geojson"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// À partir de ce niveau de zoom, les commerces sont renvoyés
+// individuellement. En dessous, les commerces proches sont regroupés.
+const CLUSTER_MAX_ZOOM = 13
+
+// Chaque tuile est découpée en clusterGridSize x clusterGridSize cases,
+// les commerces d'une même case formant un groupe
+const clusterGridSize = 4
+
+// Nombre de tuiles maximum calculées pour une requête. Au-delà, on
+// utilise des tuiles d'un niveau de zoom inférieur.
+const maxTilesPerQuery = 64
+
+// En dessous de ce niveau, une tuile couvre plus d'un hémisphère, ce que
+// MongoDB ne sait pas interroger
+const minTileZoom = 2
+
+const tileCacheDuration = 5 * time.Minute
+
+// Nombre de tuiles maximum gardées en cache, chaque niveau de zoom
+// multipliant par quatre le nombre de tuiles possibles
+const maxCachedTiles = 4096
+
+type CommerceCluster struct {
+	Latitude  float64
+	Longitude float64
+	Count     int
+	Bounds    geojson.BoundingBox
+}
+
+func (cluster *CommerceCluster) ToModel() *model.CommerceCluster {
+	return &model.CommerceCluster{
+		Latitude:  cluster.Latitude,
+		Longitude: cluster.Longitude,
+		Count:     cluster.Count,
+		Bounds: &model.Bounds{
+			West:  cluster.Bounds.West,
+			South: cluster.Bounds.South,
+			East:  cluster.Bounds.East,
+			North: cluster.Bounds.North,
+		},
+	}
+}
+
+// Cache des tuiles
+
+type tileContent struct {
+	commerces []Commerce
+	clusters  []CommerceCluster
+	expiresAt time.Time
+}
+
+type tilesCache struct {
+	sync.RWMutex
+	tiles    map[string]tileContent
+	maxTiles int
+}
+
+var commercesTilesCache = &tilesCache{
+	tiles:    map[string]tileContent{},
+	maxTiles: maxCachedTiles,
+}
+
+func (cache *tilesCache) get(key string) (tileContent, bool) {
+	cache.RLock()
+	defer cache.RUnlock()
+
+	content, ok := cache.tiles[key]
+
+	if !ok || time.Now().After(content.expiresAt) {
+		return tileContent{}, false
+	}
+
+	return content, true
+}
+
+func (cache *tilesCache) set(key string, content tileContent) {
+	cache.Lock()
+	defer cache.Unlock()
+
+	now := time.Now()
+
+	if _, ok := cache.tiles[key]; !ok && len(cache.tiles) >= cache.maxTiles {
+		cache.evict(now)
+	}
+
+	content.expiresAt = now.Add(tileCacheDuration)
+	cache.tiles[key] = content
+}
+
+// Libère de la place dans le cache plein : les tuiles expirées sont
+// retirées, ou à défaut la tuile la plus ancienne
+func (cache *tilesCache) evict(now time.Time) {
+	oldestKey := ""
+	var oldestExpiresAt time.Time
+
+	for key, content := range cache.tiles {
+		if now.After(content.expiresAt) {
+			delete(cache.tiles, key)
+			continue
+		}
+
+		if oldestKey == "" || content.expiresAt.Before(oldestExpiresAt) {
+			oldestKey = key
+			oldestExpiresAt = content.expiresAt
+		}
+	}
+
+	if len(cache.tiles) >= cache.maxTiles {
+		delete(cache.tiles, oldestKey)
+	}
+}
+
+// Vidé à chaque modification d'un commerce pour que la carte ne montre
+// pas un commerce fermé ou déplacé pendant toute la durée du cache
+func (cache *tilesCache) clear() {
+	cache.Lock()
+	defer cache.Unlock()
+
+	cache.tiles = map[string]tileContent{}
+}
+
+// Calcul des tuiles
+
+// Renvoie les commerces et groupes de commerces des tuiles couvrant le
+// rectangle. Les tuiles débordent du rectangle, ce qui permet au client
+// d'afficher les alentours lorsqu'il déplace la carte.
+func (c *commercesService) GetInBounds(box geojson.BoundingBox, zoom int) ([]Commerce, []CommerceCluster, error) {
+	if box.West >= box.East || box.South >= box.North {
+		return nil, nil, &InvalidBoundingBoxError{}
+	}
+
+	box.South = math.Max(box.South, -geojson.MaxTileLatitude)
+	box.North = math.Min(box.North, geojson.MaxTileLatitude)
+
+	tileZoom := maxInt(minInt(zoom, CLUSTER_MAX_ZOOM), minTileZoom)
+	tiles := geojson.TilesForBoundingBox(box, tileZoom)
+
+	for len(tiles) > maxTilesPerQuery && tileZoom > minTileZoom {
+		tileZoom--
+		tiles = geojson.TilesForBoundingBox(box, tileZoom)
+	}
+
+	if len(tiles) > maxTilesPerQuery {
+		return nil, nil, &InvalidBoundingBoxError{}
+	}
+
+	commerces := []Commerce{}
+	clusters := []CommerceCluster{}
+
+	for _, tile := range tiles {
+		content, err := c.getTile(tile, zoom >= CLUSTER_MAX_ZOOM)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		commerces = append(commerces, content.commerces...)
+		clusters = append(clusters, content.clusters...)
+	}
+
+	return commerces, clusters, nil
+}
+
+func (c *commercesService) getTile(tile geojson.Tile, individual bool) (tileContent, error) {
+	key := tile.Key()
+
+	if individual {
+		key += "/commerces"
+	}
+
+	if content, ok := commercesTilesCache.get(key); ok {
+		return content, nil
+	}
+
+	tileBox := tile.BoundingBox()
+
+	// Le polygone est élargi pour ne manquer aucun commerce proche du
+	// bord, l'appartenance à la tuile est ensuite vérifiée exactement
+	filter := bson.M{
+		"$and": []bson.M{
+			statusFilter(nil),
+			{
+				"addressGeo": bson.M{
+					"$geoWithin": bson.M{
+						"$geometry": tileBox.Pad(0.05).Polygon(),
+					},
+				},
+			},
+		},
+	}
+
+	databaseCommerces, err := c.GetFiltered(filter, nil)
+
+	if err != nil {
+		return tileContent{}, err
+	}
+
+	tileCommerces := []Commerce{}
+
+	for _, commerce := range databaseCommerces {
+		if len(commerce.AddressGeo.Coordinates) < 2 {
+			continue
+		}
+
+		if tileBox.Contains(commerce.AddressGeo.Coordinates[0], commerce.AddressGeo.Coordinates[1]) {
+			tileCommerces = append(tileCommerces, commerce)
+		}
+	}
+
+	content := tileContent{
+		commerces: tileCommerces,
+		clusters:  []CommerceCluster{},
+	}
+
+	if !individual {
+		content = clusterCommerces(tileBox, tileCommerces)
+	}
+
+	commercesTilesCache.set(key, content)
+
+	return content, nil
+}
+
+// Regroupe les commerces par case de la grille. Un commerce seul dans sa
+// case est renvoyé tel quel.
+func clusterCommerces(tileBox geojson.BoundingBox, commerces []Commerce) tileContent {
+	cellWidth := (tileBox.East - tileBox.West) / clusterGridSize
+	cellHeight := (tileBox.North - tileBox.South) / clusterGridSize
+
+	cells := map[int][]Commerce{}
+	cellsOrder := []int{}
+
+	for _, commerce := range commerces {
+		column := minInt(int((commerce.AddressGeo.Coordinates[0]-tileBox.West)/cellWidth), clusterGridSize-1)
+		row := minInt(int((commerce.AddressGeo.Coordinates[1]-tileBox.South)/cellHeight), clusterGridSize-1)
+		cell := row*clusterGridSize + column
+
+		if _, ok := cells[cell]; !ok {
+			cellsOrder = append(cellsOrder, cell)
+		}
+
+		cells[cell] = append(cells[cell], commerce)
+	}
+
+	content := tileContent{
+		commerces: []Commerce{},
+		clusters:  []CommerceCluster{},
+	}
+
+	for _, cell := range cellsOrder {
+		cellCommerces := cells[cell]
+
+		if len(cellCommerces) == 1 {
+			content.commerces = append(content.commerces, cellCommerces[0])
+			continue
+		}
+
+		cluster := CommerceCluster{
+			Count: len(cellCommerces),
+			Bounds: geojson.BoundingBox{
+				West:  180,
+				South: 90,
+				East:  -180,
+				North: -90,
+			},
+		}
+
+		for _, commerce := range cellCommerces {
+			longitude := commerce.AddressGeo.Coordinates[0]
+			latitude := commerce.AddressGeo.Coordinates[1]
+
+			cluster.Longitude += longitude
+			cluster.Latitude += latitude
+			cluster.Bounds.West = math.Min(cluster.Bounds.West, longitude)
+			cluster.Bounds.South = math.Min(cluster.Bounds.South, latitude)
+			cluster.Bounds.East = math.Max(cluster.Bounds.East, longitude)
+			cluster.Bounds.North = math.Max(cluster.Bounds.North, latitude)
+		}
+
+		cluster.Longitude /= float64(cluster.Count)
+		cluster.Latitude /= float64(cluster.Count)
+
+		content.clusters = append(content.clusters, cluster)
+	}
+
+	return content
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,113 @@
+package commerces
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"chemin-du-local.bzh/graphql/pkg/geojson"
+	"github.com/stretchr/testify/require"
+)
+
+// Tests sur le cache des tuiles
+func TestTilesCache(t *testing.T) {
+	t.Run("an expired tile is not returned", func(t *testing.T) {
+		cache := &tilesCache{
+			tiles:    map[string]tileContent{},
+			maxTiles: 10,
+		}
+
+		cache.set("2/1/1", tileContent{})
+
+		_, ok := cache.get("2/1/1")
+		require.True(t, ok)
+
+		cache.tiles["2/1/1"] = tileContent{expiresAt: time.Now().Add(-time.Second)}
+
+		_, ok = cache.get("2/1/1")
+		require.False(t, ok)
+	})
+
+	t.Run("the cache never holds more than its maximum", func(t *testing.T) {
+		cache := &tilesCache{
+			tiles:    map[string]tileContent{},
+			maxTiles: 10,
+		}
+
+		for i := 0; i < 25; i++ {
+			cache.set(fmt.Sprintf("13/%d/0", i), tileContent{})
+		}
+
+		require.Len(t, cache.tiles, 10)
+
+		// Les tuiles les plus récentes sont conservées
+		_, ok := cache.get("13/24/0")
+		require.True(t, ok)
+	})
+
+	t.Run("expired tiles are evicted first", func(t *testing.T) {
+		cache := &tilesCache{
+			tiles:    map[string]tileContent{},
+			maxTiles: 3,
+		}
+
+		cache.set("a", tileContent{})
+		cache.set("b", tileContent{})
+		cache.set("c", tileContent{})
+		cache.tiles["b"] = tileContent{expiresAt: time.Now().Add(-time.Second)}
+
+		cache.set("d", tileContent{})
+
+		require.Len(t, cache.tiles, 3)
+		require.Contains(t, cache.tiles, "a")
+		require.NotContains(t, cache.tiles, "b")
+	})
+
+	t.Run("updating a cached tile does not evict another one", func(t *testing.T) {
+		cache := &tilesCache{
+			tiles:    map[string]tileContent{},
+			maxTiles: 2,
+		}
+
+		cache.set("a", tileContent{})
+		cache.set("b", tileContent{})
+		cache.set("b", tileContent{})
+
+		require.Len(t, cache.tiles, 2)
+	})
+}
+
+// Tests sur le regroupement des commerces d'une tuile
+func TestClusterCommerces(t *testing.T) {
+	tileBox := geojson.BoundingBox{
+		West:  0,
+		South: 0,
+		East:  4,
+		North: 4,
+	}
+
+	commerces := []Commerce{
+		{Name: "Seul", AddressGeo: geojson.NewPoint(0.5, 0.5)},
+		{Name: "Groupé 1", AddressGeo: geojson.NewPoint(3.2, 3.2)},
+		{Name: "Groupé 2", AddressGeo: geojson.NewPoint(3.6, 3.8)},
+	}
+
+	content := clusterCommerces(tileBox, commerces)
+
+	require.Len(t, content.commerces, 1)
+	require.Equal(t, "Seul", content.commerces[0].Name)
+
+	require.Len(t, content.clusters, 1)
+	require.Equal(t, 2, content.clusters[0].Count)
+	require.InDelta(t, 3.4, content.clusters[0].Longitude, 0.0001)
+	require.InDelta(t, 3.5, content.clusters[0].Latitude, 0.0001)
+	require.Equal(t, geojson.BoundingBox{West: 3.2, South: 3.2, East: 3.6, North: 3.8}, content.clusters[0].Bounds)
+}
+
+func TestGetInBounds_InvalidBoundingBox(t *testing.T) {
+	service := NewCommercesService()
+
+	_, _, err := service.GetInBounds(geojson.BoundingBox{West: 2, South: 47, East: -4, North: 49}, 8)
+
+	require.IsType(t, &InvalidBoundingBoxError{}, err)
+}
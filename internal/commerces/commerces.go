@@ -15,6 +15,7 @@ import (
 	"chemin-du-local.bzh/graphql/internal/database"
 	"chemin-du-local.bzh/graphql/internal/services/servicesinfo"
 	"chemin-du-local.bzh/graphql/pkg/geojson"
+	"chemin-du-local.bzh/graphql/pkg/utils"
 	"github.com/99designs/gqlgen/graphql"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -22,68 +23,232 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// Cycle de vie d'un commerce
+const COMMERCE_STATUS_DRAFT = "DRAFT"
+const COMMERCE_STATUS_PENDING_VALIDATION = "PENDING_VALIDATION"
+const COMMERCE_STATUS_ACTIVE = "ACTIVE"
+const COMMERCE_STATUS_SUSPENDED = "SUSPENDED"
+const COMMERCE_STATUS_CLOSED = "CLOSED"
+
+// Nombre de nouvelles tentatives lorsque le slug choisi à la création est
+// pris entre-temps par un autre commerce
+const maxSlugAttempts = 3
+
 type Commerce struct {
-	ID                                  primitive.ObjectID  `bson:"_id"`
-	StorekeeperID                       primitive.ObjectID  `bson:"storekeeperID"`
-	Siret                               string              `bson:"siret"`
-	Name                                string              `bson:"name"`
-	Description                         string              `bson:"description"`
-	StorekeeperWord                     string              `bson:"storekeeperWord"`
-	Address                             address.Address     `bson:"address"`
-	AddressGeo                          geojson.GeoJSON     `bson:"addressGeo"`
-	Phone                               string              `bson:"phone"`
-	Email                               string              `bson:"email"`
-	IBANOwner                           *string             `bson:"ibanOwner"`
-	IBAN                                *string             `bson:"iban"`
-	BIC                                 *string             `bson:"bic"`
-	Facebook                            *string             `bson:"facebook"`
-	Twitter                             *string             `bson:"twitter"`
-	Instagram                           *string             `bson:"instagram"`
-	BusinessHours                       model.BusinessHours `bson:"businesHours"`
-	ClickAndCollectHours                model.BusinessHours `bson:"clickAndCollectHours"`
-	Services                            []string            `bson:"services"`
-	ProductsAvailableForClickAndCollect []string            `bson:"productsAvailableForClickAndCollect"`
-	StripID                             *string             `bson:"stripeID"`
-	DefaultPaymentMethodID              *string             `bson:"defaultPaymentMethodID"`
-	LastBilledDate                      *time.Time          `bson:"lastBilledDate"`
-	Balance                             float64             `bson:"balance"`
-	DueBalanceClickAndCollectC          float64             `bson:"dueBalanceClickAndCollectC"`
-	DueBalanceClickAndCollectM          float64             `bson:"dueBalanceClickAndCollectM"`
-	DueBalancePaniersC                  float64             `bson:"dueBalancePaniersC"`
-	DueBalancePaniersM                  float64             `bson:"dueBalancePaniersM"`
-	Transferts                          []model.Transfert   `bson:"transferts"`
+	ID                                  primitive.ObjectID      `bson:"_id"`
+	StorekeeperID                       primitive.ObjectID      `bson:"storekeeperID"`
+	Siret                               string                  `bson:"siret"`
+	Status                              string                  `bson:"status"`
+	ClosedAt                            *time.Time              `bson:"closedAt"`
+	Name                                string                  `bson:"name"`
+	Slug                                string                  `bson:"slug"`
+	SlugHistory                         []string                `bson:"slugHistory"`
+	Description                         string                  `bson:"description"`
+	StorekeeperWord                     string                  `bson:"storekeeperWord"`
+	Address                             address.Address         `bson:"address"`
+	AddressGeo                          geojson.GeoJSON         `bson:"addressGeo"`
+	Phone                               string                  `bson:"phone"`
+	Email                               string                  `bson:"email"`
+	IBANOwner                           *string                 `bson:"ibanOwner"`
+	IBAN                                *string                 `bson:"iban"`
+	BIC                                 *string                 `bson:"bic"`
+	Facebook                            *string                 `bson:"facebook"`
+	Twitter                             *string                 `bson:"twitter"`
+	Instagram                           *string                 `bson:"instagram"`
+	BusinessHours                       model.BusinessHours     `bson:"businesHours"`
+	ClickAndCollectHours                model.BusinessHours     `bson:"clickAndCollectHours"`
+	Vacation                            *model.CommerceVacation `bson:"vacation"`
+	Services                            []string                `bson:"services"`
+	ProductsAvailableForClickAndCollect []string                `bson:"productsAvailableForClickAndCollect"`
+	VariantsAvailableForClickAndCollect []string                `bson:"variantsAvailableForClickAndCollect"`
+	StripID                             *string                 `bson:"stripeID"`
+	DefaultPaymentMethodID              *string                 `bson:"defaultPaymentMethodID"`
+	LastBilledDate                      *time.Time              `bson:"lastBilledDate"`
+	Balance                             float64                 `bson:"balance"`
+	DueBalanceClickAndCollectC          float64                 `bson:"dueBalanceClickAndCollectC"`
+	DueBalanceClickAndCollectM          float64                 `bson:"dueBalanceClickAndCollectM"`
+	DueBalancePaniersC                  float64                 `bson:"dueBalancePaniersC"`
+	DueBalancePaniersM                  float64                 `bson:"dueBalancePaniersM"`
+	Transferts                          []model.Transfert       `bson:"transferts"`
+	AcceptsGiftCards                    bool                    `bson:"acceptsGiftCards"`
 }
 
 func (commerce *Commerce) ToModel() *model.Commerce {
 	return &model.Commerce{
-		ID:                         commerce.ID.Hex(),
-		StorekeeperID:              commerce.StorekeeperID.Hex(),
-		Siret:                      commerce.Siret,
-		Name:                       commerce.Name,
-		Description:                commerce.Description,
-		StorekeeperWord:            commerce.StorekeeperWord,
-		Address:                    *commerce.Address.ToModel(),
-		Latitude:                   commerce.AddressGeo.Coordinates[1],
-		Longitude:                  commerce.AddressGeo.Coordinates[0],
-		Phone:                      commerce.Phone,
-		Email:                      commerce.Email,
-		IBANOwner:                  commerce.IBANOwner,
-		IBAN:                       commerce.IBAN,
-		BIC:                        commerce.BIC,
-		Facebook:                   commerce.Facebook,
-		Twitter:                    commerce.Twitter,
-		Instagram:                  commerce.Instagram,
-		BusinessHours:              commerce.BusinessHours,
-		ClickAndCollectHours:       commerce.ClickAndCollectHours,
-		Services:                   commerce.Services,
-		LastBilledDate:             commerce.LastBilledDate,
-		Balance:                    commerce.Balance,
-		DueBalanceClickAndCollectC: commerce.DueBalanceClickAndCollectC,
-		DueBalanceClickAndCollectM: commerce.DueBalanceClickAndCollectM,
-		DueBalancePaniersC:         commerce.DueBalanceClickAndCollectC,
-		DueBalancePaniersM:         commerce.DueBalanceClickAndCollectM,
-		Transferts:                 commerce.Transferts,
+		ID:                                  commerce.ID.Hex(),
+		StorekeeperID:                       commerce.StorekeeperID.Hex(),
+		Siret:                               commerce.Siret,
+		Status:                              model.CommerceStatus(commerce.GetStatus()),
+		ClosedAt:                            commerce.ClosedAt,
+		Name:                                commerce.Name,
+		Slug:                                commerce.Slug,
+		Description:                         commerce.Description,
+		StorekeeperWord:                     commerce.StorekeeperWord,
+		Address:                             *commerce.Address.ToModel(),
+		Latitude:                            commerce.AddressGeo.Coordinates[1],
+		Longitude:                           commerce.AddressGeo.Coordinates[0],
+		Phone:                               commerce.Phone,
+		Email:                               commerce.Email,
+		IBANOwner:                           commerce.IBANOwner,
+		IBAN:                                commerce.IBAN,
+		BIC:                                 commerce.BIC,
+		Facebook:                            commerce.Facebook,
+		Twitter:                             commerce.Twitter,
+		Instagram:                           commerce.Instagram,
+		BusinessHours:                       commerce.BusinessHours,
+		ClickAndCollectHours:                commerce.ClickAndCollectHours,
+		Vacation:                            commerce.Vacation,
+		IsOnVacation:                        commerce.IsOnVacation(time.Now()),
+		Services:                            commerce.Services,
+		VariantsAvailableForClickAndCollect: commerce.VariantsAvailableForClickAndCollect,
+		LastBilledDate:                      commerce.LastBilledDate,
+		Balance:                             commerce.Balance,
+		DueBalanceClickAndCollectC:          commerce.DueBalanceClickAndCollectC,
+		DueBalanceClickAndCollectM:          commerce.DueBalanceClickAndCollectM,
+		DueBalancePaniersC:                  commerce.DueBalanceClickAndCollectC,
+		DueBalancePaniersM:                  commerce.DueBalanceClickAndCollectM,
+		Transferts:                          commerce.Transferts,
+		AcceptsGiftCards:                    commerce.AcceptsGiftCards,
+	}
+}
+
+// Les commerces créés avant l'ajout du statut sont considérés actifs
+func (commerce *Commerce) GetStatus() string {
+	if commerce.Status == "" {
+		return COMMERCE_STATUS_ACTIVE
+	}
+
+	return commerce.Status
+}
+
+func (commerce *Commerce) IsActive() bool {
+	return commerce.GetStatus() == COMMERCE_STATUS_ACTIVE
+}
+
+// Une déclinaison est disponible en Click&Collect si elle est proposée
+// seule ou si tout son produit l'est
+func (commerce *Commerce) IsAvailableForClickAndCollect(productID string, variantID *string) bool {
+	for _, availableProductID := range commerce.ProductsAvailableForClickAndCollect {
+		if availableProductID == productID {
+			return true
+		}
+	}
+
+	if variantID == nil {
+		return false
+	}
+
+	for _, availableVariantID := range commerce.VariantsAvailableForClickAndCollect {
+		if availableVariantID == *variantID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Identifiants des services proposés aux clients (CLICKANDCOLLECT,
+// PANIERS), sans le mode de facturation ni les services résiliés
+func (commerce *Commerce) PublicServices() []string {
+	services := []string{}
+
+	for _, service := range commerce.Services {
+		if strings.HasSuffix(service, "_REMOVE") {
+			continue
+		}
+
+		serviceID := strings.Split(service, "_")[0]
+		alreadyAdded := false
+
+		for _, addedService := range services {
+			alreadyAdded = alreadyAdded || addedService == serviceID
+		}
+
+		if !alreadyAdded {
+			services = append(services, serviceID)
+		}
 	}
+
+	return services
+}
+
+// Résilie les services du commerce de la même manière qu'une
+// suppression manuelle : les services au mois restent facturés jusqu'à
+// la fin de la période en cours, ceux à la consommation sont retirés
+// une fois la consommation déjà due facturée
+func (commerce *Commerce) CancelServices() {
+	services := []string{}
+
+	for _, service := range commerce.Services {
+		serviceParts := strings.Split(service, "_")
+
+		if len(serviceParts) < 2 {
+			continue
+		}
+
+		if serviceParts[1] == "M" {
+			services = append(services, serviceParts[0]+"_M_REMOVE")
+			continue
+		}
+
+		// Le service à la consommation (historiquement T) reste présent
+		// pour que la prochaine facturation prélève ce qui est encore dû
+		if commerce.dueConsumption(serviceParts[0]) > 0 {
+			services = append(services, serviceParts[0]+"_C_REMOVE")
+		}
+	}
+
+	commerce.Services = services
+}
+
+// Montant de la consommation encore due pour le service donné
+func (commerce *Commerce) dueConsumption(serviceID string) float64 {
+	switch serviceID {
+	case "CLICKANDCOLLECT":
+		return commerce.DueBalanceClickAndCollectC
+	case "PANIERS":
+		return commerce.DueBalancePaniersC
+	default:
+		return 0
+	}
+}
+
+// Indique si le commerce est en congés à la date donnée. La date de fin
+// est incluse : un commerce en congés jusqu'au 15 l'est toute la journée
+// du 15.
+func (commerce *Commerce) IsOnVacation(date time.Time) bool {
+	if commerce.Vacation == nil {
+		return false
+	}
+
+	endOfVacation := commerce.Vacation.EndDate.AddDate(0, 0, 1)
+
+	return !date.Before(commerce.Vacation.StartDate) && date.Before(endOfVacation)
+}
+
+// Calcule le nombre de jours de congés compris entre deux dates,
+// utilisé pour proratiser la facturation des services mensuels
+func (commerce *Commerce) VacationDaysBetween(from time.Time, to time.Time) int {
+	if commerce.Vacation == nil {
+		return 0
+	}
+
+	start := commerce.Vacation.StartDate
+	end := commerce.Vacation.EndDate.AddDate(0, 0, 1)
+
+	if start.Before(from) {
+		start = from
+	}
+
+	if end.After(to) {
+		end = to
+	}
+
+	if !end.After(start) {
+		return 0
+	}
+
+	return int(math.Round(end.Sub(start).Hours() / 24))
 }
 
 func (commerce Commerce) IsLast(c CommercesService) bool {
@@ -113,11 +278,20 @@ type commercesService struct{}
 type CommercesService interface {
 	Create(input model.NewCommerce, storekeeperID primitive.ObjectID) (*Commerce, error)
 	Update(changes *Commerce, image *graphql.Upload, profilePicture *graphql.Upload) error
-	UpdateBalancesForOrder(commerceID string, price int, priceClickAndCollect float64, pricePaniers float64) error
+	UpdateBalancesForOrder(commerceID string, price int, priceClickAndCollect float64, pricePaniers float64, platformFundedAmount float64) error
+	Close(commerce *Commerce) error
+	ChangeSlug(commerce *Commerce, slug string) error
+	GenerateMissingSlugs() error
+	FixSwappedCoordinates() error
+	LocateAddresses() error
 	GetAll() ([]Commerce, error)
 	GetById(id string) (*Commerce, error)
+	GetBySlug(slug string) (*Commerce, error)
 	GetForUser(userID string) (*Commerce, error)
 	GetPaginated(startValue *string, first int, filter *model.CommerceFilter) ([]Commerce, int, error)
+	GetInBounds(box geojson.BoundingBox, zoom int) ([]Commerce, []CommerceCluster, error)
+	GetActive(postalCode *string, box *geojson.BoundingBox) ([]Commerce, error)
+	GetActiveNear(latitude float64, longitude float64, radius *float64) ([]Commerce, error)
 	GetFiltered(filter interface{}, opts *options.FindOptions) ([]Commerce, error)
 }
 
@@ -152,6 +326,14 @@ func (c *commercesService) Create(input model.NewCommerce, storekeeperID primiti
 		clickAndCollectHours = *input.ClickAndCollectHours.ToModel()
 	}
 
+	city := ""
+
+	if input.Address.City != nil {
+		city = *input.Address.City
+	}
+
+	addressGeo := geojson.NewPoint(input.Longitude, input.Latitude)
+
 	databaseCommerce := Commerce{
 		ID:              commerceObjectID,
 		StorekeeperID:   storekeeperID,
@@ -166,11 +348,10 @@ func (c *commercesService) Create(input model.NewCommerce, storekeeperID primiti
 			OptionalRoute: input.Address.OptionalRoute,
 			PostalCode:    input.Address.PostalCode,
 			City:          input.Address.City,
+			Location:      &addressGeo,
 		},
-		AddressGeo: geojson.GeoJSON{
-			Type:        "Point",
-			Coordinates: []float64{input.Latitude, input.Longitude},
-		},
+		AddressGeo:           addressGeo,
+		Status:               COMMERCE_STATUS_PENDING_VALIDATION,
 		Phone:                input.Phone,
 		Email:                input.Email,
 		Facebook:             input.Facebook,
@@ -180,10 +361,27 @@ func (c *commercesService) Create(input model.NewCommerce, storekeeperID primiti
 		ClickAndCollectHours: clickAndCollectHours,
 	}
 
-	_, err := database.CollectionCommerces.InsertOne(database.MongoContext, databaseCommerce)
+	// Un autre commerce peut prendre le même slug entre la vérification
+	// et l'insertion, l'index unique le refuse alors et on en cherche un
+	// nouveau
+	for attempt := 0; ; attempt++ {
+		slug, err := c.uniqueSlug(utils.Slugify(input.Name+" "+city), commerceObjectID)
 
-	if err != nil {
-		return nil, err
+		if err != nil {
+			return nil, err
+		}
+
+		databaseCommerce.Slug = slug
+
+		_, err = database.CollectionCommerces.InsertOne(database.MongoContext, databaseCommerce)
+
+		if err == nil {
+			break
+		}
+
+		if !mongo.IsDuplicateKeyError(err) || attempt >= maxSlugAttempts {
+			return nil, err
+		}
 	}
 
 	// Le header
@@ -237,6 +435,14 @@ func (c *commercesService) Update(changes *Commerce, image *graphql.Upload, prof
 
 	_, err := database.CollectionCommerces.ReplaceOne(database.MongoContext, filter, changes)
 
+	if mongo.IsDuplicateKeyError(err) {
+		return &SlugAlreadyUsedError{Slug: changes.Slug}
+	}
+
+	if err == nil {
+		commercesTilesCache.clear()
+	}
+
 	// Le header
 	if image != nil {
 		fileData := image.File
@@ -276,7 +482,10 @@ func (c *commercesService) Update(changes *Commerce, image *graphql.Upload, prof
 	return err
 }
 
-func (c *commercesService) UpdateBalancesForOrder(commerceID string, price int, priceClickAndCollect float64, pricePaniers float64) error {
+// La remise d'un code promo pris en charge par la plateforme et le montant
+// payé par carte cadeau sont reversés au commerce en plus du montant payé
+// par le client
+func (c *commercesService) UpdateBalancesForOrder(commerceID string, price int, priceClickAndCollect float64, pricePaniers float64, platformFundedAmount float64) error {
 	commerce, err := c.GetById(commerceID)
 
 	if err != nil {
@@ -303,13 +512,255 @@ func (c *commercesService) UpdateBalancesForOrder(commerceID string, price int,
 		}
 	}
 
-	commerce.Balance = commerce.Balance + (float64(price) / 100)
+	commerce.Balance = commerce.Balance + (float64(price) / 100) + platformFundedAmount
 
 	err = c.Update(commerce, nil, nil)
 
 	return err
 }
 
+// Ferme définitivement le commerce. Le solde doit avoir été reversé
+// au commerçant avant la fermeture.
+func (c *commercesService) Close(commerce *Commerce) error {
+	if commerce.GetStatus() == COMMERCE_STATUS_CLOSED {
+		return &CommerceAlreadyClosedError{}
+	}
+
+	if commerce.Balance != 0 {
+		return &CommerceBalanceNotEmptyError{Balance: commerce.Balance}
+	}
+
+	now := time.Now()
+
+	commerce.CancelServices()
+	commerce.Status = COMMERCE_STATUS_CLOSED
+	commerce.ClosedAt = &now
+	commerce.Vacation = nil
+
+	return c.Update(commerce, nil, nil)
+}
+
+// Slugs
+
+// Indique si le slug est pris par un autre commerce, en tant que slug
+// actuel ou ancien : les anciens slugs restent réservés pour que les
+// liens déjà partagés continuent de fonctionner
+func (c *commercesService) isSlugTaken(slug string, commerceID primitive.ObjectID) (bool, error) {
+	filter := bson.M{
+		"_id": bson.M{
+			"$ne": commerceID,
+		},
+		"$or": []bson.M{
+			{"slug": slug},
+			{"slugHistory": slug},
+		},
+	}
+
+	count, err := database.CollectionCommerces.CountDocuments(database.MongoContext, filter)
+
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// En cas de collision, on ajoute un numéro : ty-breizh-quimper-2
+func (c *commercesService) uniqueSlug(base string, commerceID primitive.ObjectID) (string, error) {
+	if base == "" {
+		base = "commerce"
+	}
+
+	slug := base
+
+	for i := 2; ; i++ {
+		taken, err := c.isSlugTaken(slug, commerceID)
+
+		if err != nil {
+			return "", err
+		}
+
+		if !taken {
+			return slug, nil
+		}
+
+		slug = base + "-" + strconv.Itoa(i)
+	}
+}
+
+// Change le slug choisi par le commerçant, l'ancien étant gardé dans
+// l'historique. Le changement est enregistré par Update.
+func (c *commercesService) ChangeSlug(commerce *Commerce, slug string) error {
+	slug = utils.Slugify(slug)
+
+	if slug == "" {
+		return &InvalidSlugError{}
+	}
+
+	if slug == commerce.Slug {
+		return nil
+	}
+
+	taken, err := c.isSlugTaken(slug, commerce.ID)
+
+	if err != nil {
+		return err
+	}
+
+	if taken {
+		return &SlugAlreadyUsedError{Slug: slug}
+	}
+
+	history := []string{}
+
+	for _, oldSlug := range commerce.SlugHistory {
+		if oldSlug != slug {
+			history = append(history, oldSlug)
+		}
+	}
+
+	if commerce.Slug != "" {
+		history = append(history, commerce.Slug)
+	}
+
+	commerce.Slug = slug
+	commerce.SlugHistory = history
+
+	return nil
+}
+
+// Donne un slug aux commerces créés avant leur ajout
+func (c *commercesService) GenerateMissingSlugs() error {
+	filter := bson.M{
+		"$or": []bson.M{
+			{"slug": bson.M{"$exists": false}},
+			{"slug": ""},
+		},
+	}
+
+	databaseCommerces, err := c.GetFiltered(filter, nil)
+
+	if err != nil {
+		return err
+	}
+
+	for i := range databaseCommerces {
+		commerce := &databaseCommerces[i]
+		city := ""
+
+		if commerce.Address.City != nil {
+			city = *commerce.Address.City
+		}
+
+		slug, err := c.uniqueSlug(utils.Slugify(commerce.Name+" "+city), commerce.ID)
+
+		if err != nil {
+			return err
+		}
+
+		_, err = database.CollectionCommerces.UpdateOne(
+			database.MongoContext,
+			bson.M{"_id": commerce.ID},
+			bson.M{"$set": bson.M{"slug": slug}},
+		)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Les commerces créés avant la correction de Create ont leur position
+// enregistrée en [latitude, longitude] au lieu de [longitude, latitude]
+func (c *commercesService) FixSwappedCoordinates() error {
+	databaseCommerces, err := c.GetFiltered(bson.M{}, nil)
+
+	if err != nil {
+		return err
+	}
+
+	for _, commerce := range databaseCommerces {
+		if !hasSwappedCoordinates(commerce.AddressGeo) {
+			continue
+		}
+
+		coordinates := commerce.AddressGeo.Coordinates
+
+		_, err = database.CollectionCommerces.UpdateOne(
+			database.MongoContext,
+			bson.M{"_id": commerce.ID},
+			bson.M{"$set": bson.M{"addressGeo": geojson.NewPoint(coordinates[0], coordinates[1])}},
+		)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Les adresses des commerces créés avant l'ajout de leur position
+// reprennent celle du commerce
+func (c *commercesService) LocateAddresses() error {
+	databaseCommerces, err := c.GetFiltered(bson.M{"address.location": bson.M{"$exists": false}}, nil)
+
+	if err != nil {
+		return err
+	}
+
+	for _, commerce := range databaseCommerces {
+		if len(commerce.AddressGeo.Coordinates) != 2 {
+			continue
+		}
+
+		_, err = database.CollectionCommerces.UpdateOne(
+			database.MongoContext,
+			bson.M{"_id": commerce.ID},
+			bson.M{"$set": bson.M{"address.location": commerce.AddressGeo}},
+		)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// En France métropolitaine la latitude (41 à 51) est toujours supérieure
+// à la longitude (-5 à 10) : un point dont la première coordonnée est la
+// plus grande a été enregistré à l'envers
+func hasSwappedCoordinates(point geojson.GeoJSON) bool {
+	return len(point.Coordinates) == 2 && point.Coordinates[0] > point.Coordinates[1]
+}
+
+// Seuls les commerces actifs sont listés, sauf demande explicite.
+// Les commerces sans statut datent d'avant son ajout et sont actifs.
+func statusFilter(statuses []model.CommerceStatus) bson.M {
+	values := []interface{}{COMMERCE_STATUS_ACTIVE, nil}
+
+	if len(statuses) > 0 {
+		values = []interface{}{}
+
+		for _, status := range statuses {
+			values = append(values, status.String())
+
+			if status == model.CommerceStatusActive {
+				values = append(values, nil)
+			}
+		}
+	}
+
+	return bson.M{
+		"status": bson.M{
+			"$in": values,
+		},
+	}
+}
+
 // Getter de base de données
 
 func (c *commercesService) GetAll() ([]Commerce, error) {
@@ -345,6 +796,35 @@ func (c *commercesService) GetById(id string) (*Commerce, error) {
 	return &commerces[0], nil
 }
 
+// Retrouve un commerce par son slug actuel ou par un ancien slug
+func (c *commercesService) GetBySlug(slug string) (*Commerce, error) {
+	filter := bson.M{
+		"$or": []bson.M{
+			{"slug": slug},
+			{"slugHistory": slug},
+		},
+	}
+
+	commerces, err := c.GetFiltered(filter, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(commerces) == 0 {
+		return nil, nil
+	}
+
+	// Le slug actuel est prioritaire sur l'historique
+	for i := range commerces {
+		if commerces[i].Slug == slug {
+			return &commerces[i], nil
+		}
+	}
+
+	return &commerces[0], nil
+}
+
 func (c *commercesService) GetForUser(userID string) (*Commerce, error) {
 	userObjectId, err := primitive.ObjectIDFromHex(userID)
 
@@ -409,6 +889,21 @@ func (c *commercesService) GetPaginated(startValue *string, first int, filter *m
 		finalFilter = bson.M{}
 	}
 
+	var statuses []model.CommerceStatus
+
+	if filter != nil {
+		statuses = filter.Status
+	}
+
+	statusFilter := statusFilter(statuses)
+
+	finalFilter = bson.M{
+		"$and": []bson.M{
+			finalFilter,
+			statusFilter,
+		},
+	}
+
 	if filter != nil && filter.NearLatitude != nil && filter.NearLongitude != nil {
 		maxDistance := 20000.0
 
@@ -467,6 +962,7 @@ func (c *commercesService) GetPaginated(startValue *string, first int, filter *m
 		pipeline = append(pipeline, matchStage)
 	}
 
+	pipeline = append(pipeline, bson.D{{Key: "$match", Value: statusFilter}})
 	pipeline = append(pipeline, countStage)
 
 	cursor, err := database.CollectionCommerces.Aggregate(database.MongoContext, pipeline)
@@ -489,6 +985,58 @@ func (c *commercesService) GetPaginated(startValue *string, first int, filter *m
 	return result, count, err
 }
 
+// Commerces actifs, éventuellement filtrés par code postal ou par zone
+func (c *commercesService) GetActive(postalCode *string, box *geojson.BoundingBox) ([]Commerce, error) {
+	filters := []bson.M{
+		statusFilter(nil),
+	}
+
+	if postalCode != nil {
+		filters = append(filters, bson.M{
+			"address.postalCode": *postalCode,
+		})
+	}
+
+	if box != nil {
+		filters = append(filters, bson.M{
+			"addressGeo": bson.M{
+				"$geoWithin": bson.M{
+					"$geometry": box.Polygon(),
+				},
+			},
+		})
+	}
+
+	opts := options.Find().SetSort(bson.M{"name": 1})
+
+	return c.GetFiltered(bson.M{"$and": filters}, opts)
+}
+
+// Les commerces actifs autour d'un point, du plus proche au plus éloigné
+func (c *commercesService) GetActiveNear(latitude float64, longitude float64, radius *float64) ([]Commerce, error) {
+	maxDistance := 20000.0
+
+	if radius != nil {
+		maxDistance = *radius
+	}
+
+	filter := bson.M{
+		"$and": []bson.M{
+			statusFilter(nil),
+			{
+				"addressGeo": bson.M{
+					"$near": bson.M{
+						"$geometry":    geojson.NewPoint(longitude, latitude),
+						"$maxDistance": maxDistance,
+					},
+				},
+			},
+		},
+	}
+
+	return c.GetFiltered(filter, nil)
+}
+
 func (c *commercesService) GetFiltered(filter interface{}, opts *options.FindOptions) ([]Commerce, error) {
 	commerces := []Commerce{}
 
@@ -0,0 +1,32 @@
+package commerces
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommerce_CancelServices(t *testing.T) {
+	t.Run("monthly services stay billed until the end of the period", func(t *testing.T) {
+		commerce := Commerce{
+			Services: []string{"CLICKANDCOLLECT_M", "PANIERS_T"},
+		}
+
+		commerce.CancelServices()
+
+		require.Equal(t, []string{"CLICKANDCOLLECT_M_REMOVE"}, commerce.Services)
+	})
+
+	t.Run("consumption services stay until what is owed is billed", func(t *testing.T) {
+		commerce := Commerce{
+			Services:                   []string{"CLICKANDCOLLECT_T", "PANIERS_C"},
+			DueBalanceClickAndCollectC: 12.5,
+		}
+
+		commerce.CancelServices()
+
+		require.Equal(t, []string{"CLICKANDCOLLECT_C_REMOVE"}, commerce.Services)
+		require.Empty(t, commerce.PublicServices())
+		require.Equal(t, 12.5, commerce.DueBalanceClickAndCollectC)
+	})
+}
@@ -0,0 +1,23 @@
+package commerces
+
+import (
+	"testing"
+
+	"chemin-du-local.bzh/graphql/pkg/geojson"
+	"github.com/stretchr/testify/require"
+)
+
+// Tests sur la détection des positions enregistrées à l'envers
+func TestHasSwappedCoordinates(t *testing.T) {
+	t.Run("a point saved as longitude, latitude is kept", func(t *testing.T) {
+		require.False(t, hasSwappedCoordinates(geojson.NewPoint(-1.7779691219329834, 48.09312057495117)))
+	})
+
+	t.Run("a point saved as latitude, longitude is detected", func(t *testing.T) {
+		require.True(t, hasSwappedCoordinates(geojson.NewPoint(48.09312057495117, -1.7779691219329834)))
+	})
+
+	t.Run("a commerce without position is ignored", func(t *testing.T) {
+		require.False(t, hasSwappedCoordinates(geojson.GeoJSON{}))
+	})
+}
@@ -1,7 +1,27 @@
 package commerces
 
+import "fmt"
+
 type CommerceErrorNotFound struct{}
 type NoCommerceForUserError struct{}
+type CommerceOnVacationError struct {
+	Name    string
+	Message string
+}
+type InvalidVacationError struct{}
+type CommerceNotActiveError struct {
+	Name string
+}
+type CommerceAlreadyClosedError struct{}
+type CommerceBalanceNotEmptyError struct {
+	Balance float64
+}
+type CommerceCommandsInProgressError struct{}
+type InvalidBoundingBoxError struct{}
+type InvalidSlugError struct{}
+type SlugAlreadyUsedError struct {
+	Slug string
+}
 
 func (m *CommerceErrorNotFound) Error() string {
 	return "Le commerce n'a pas été trouvé"
@@ -10,3 +30,43 @@ func (m *CommerceErrorNotFound) Error() string {
 func (m *NoCommerceForUserError) Error() string {
 	return "aucun commerce trouvé pour l'utilisateur"
 }
+
+func (m *CommerceOnVacationError) Error() string {
+	if m.Message != "" {
+		return "le commerce " + m.Name + " est en congés : " + m.Message
+	}
+
+	return "le commerce " + m.Name + " est en congés"
+}
+
+func (m *InvalidVacationError) Error() string {
+	return "la date de fin des congés doit être après la date de début"
+}
+
+func (m *CommerceNotActiveError) Error() string {
+	return "le commerce " + m.Name + " n'accepte pas de commandes"
+}
+
+func (m *CommerceAlreadyClosedError) Error() string {
+	return "le commerce est déjà fermé"
+}
+
+func (m *CommerceBalanceNotEmptyError) Error() string {
+	return fmt.Sprintf("le commerce ne peut pas être fermé tant que son solde n'est pas nul (%.2f€)", m.Balance)
+}
+
+func (m *CommerceCommandsInProgressError) Error() string {
+	return "le commerce ne peut pas être fermé tant que des commandes sont en cours"
+}
+
+func (m *InvalidBoundingBoxError) Error() string {
+	return "la zone de la carte demandée est invalide ou trop grande"
+}
+
+func (m *InvalidSlugError) Error() string {
+	return "l'adresse du commerce doit contenir au moins une lettre ou un chiffre"
+}
+
+func (m *SlugAlreadyUsedError) Error() string {
+	return "l'adresse " + m.Slug + " est déjà utilisée par un autre commerce"
+}
@@ -33,6 +33,10 @@ type Config struct {
 	Paths struct {
 		Static string `yaml:"static"`
 	} `yaml:"paths"`
+	Urls struct {
+		Website string `yaml:"website"`
+		Api     string `yaml:"api"`
+	} `yaml:"urls"`
 	Database struct {
 		Name             string `yaml:"name"`
 		ConnectionString string `yaml:"connectionString"`
@@ -45,6 +49,17 @@ type Config struct {
 			CCCommands       string `yaml:"cccommands"`
 			PanierCommands   string `yaml:"paniercommands"`
 			Paniers          string `yaml:"paniers"`
+			Sirene           string `yaml:"sirene"`
+			DeliveryZones    string `yaml:"deliveryzones"`
+			Markets          string `yaml:"markets"`
+			PickupPoints     string `yaml:"pickuppoints"`
+			StockMovements   string `yaml:"stockmovements"`
+			Promotions       string `yaml:"promotions"`
+			PromoCodes       string `yaml:"promocodes"`
+			PromoCodeUsages  string `yaml:"promocodeusages"`
+			GiftCards        string `yaml:"giftcards"`
+			Categories       string `yaml:"categories"`
+			Migrations       string `yaml:"migrations"`
 		} `yaml:"collections"`
 	} `yaml:"database"`
 }
@@ -90,6 +105,9 @@ func InitFromEnv() {
 
 	Cfg.Paths.Static = os.Getenv("PATH_STATIC")
 
+	Cfg.Urls.Website = os.Getenv("URL_WEBSITE")
+	Cfg.Urls.Api = os.Getenv("URL_API")
+
 	Cfg.Database.Name = os.Getenv("DATABASE_NAME")
 	Cfg.Database.ConnectionString = os.Getenv("CONNECTION_STRING")
 	Cfg.Database.Collections.Users = os.Getenv("COLLECTION_USERS")
@@ -100,6 +118,17 @@ func InitFromEnv() {
 	Cfg.Database.Collections.CCCommands = os.Getenv("COLLECTION_CCCOMMANDS")
 	Cfg.Database.Collections.PanierCommands = os.Getenv("COLLECTION_PANIERCOMMANDS")
 	Cfg.Database.Collections.Paniers = os.Getenv("COLLECTION_PANIERS")
+	Cfg.Database.Collections.Sirene = os.Getenv("COLLECTION_SIRENE")
+	Cfg.Database.Collections.DeliveryZones = os.Getenv("COLLECTION_DELIVERYZONES")
+	Cfg.Database.Collections.Markets = os.Getenv("COLLECTION_MARKETS")
+	Cfg.Database.Collections.PickupPoints = os.Getenv("COLLECTION_PICKUPPOINTS")
+	Cfg.Database.Collections.StockMovements = os.Getenv("COLLECTION_STOCKMOVEMENTS")
+	Cfg.Database.Collections.Promotions = os.Getenv("COLLECTION_PROMOTIONS")
+	Cfg.Database.Collections.PromoCodes = os.Getenv("COLLECTION_PROMOCODES")
+	Cfg.Database.Collections.PromoCodeUsages = os.Getenv("COLLECTION_PROMOCODEUSAGES")
+	Cfg.Database.Collections.GiftCards = os.Getenv("COLLECTION_GIFTCARDS")
+	Cfg.Database.Collections.Categories = os.Getenv("COLLECTION_CATEGORIES")
+	Cfg.Database.Collections.Migrations = os.Getenv("COLLECTION_MIGRATIONS")
 
 	fmt.Println("Config initialized")
 }
@@ -0,0 +1,111 @@
+package database
+
+import (
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Index géographique sur le champ donné, nécessaire aux requêtes $near
+func geoIndex(field string) mongo.IndexModel {
+	return mongo.IndexModel{
+		Keys: bson.D{
+			primitive.E{
+				Key:   field,
+				Value: "2dsphere",
+			},
+		},
+	}
+}
+
+// Index unique sur le champ donné, les documents où il est vide ou
+// absent n'étant pas concernés
+func uniqueIndex(field string) mongo.IndexModel {
+	return mongo.IndexModel{
+		Keys: bson.D{
+			primitive.E{
+				Key:   field,
+				Value: 1,
+			},
+		},
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{
+			field: bson.M{
+				"$type": "string",
+				"$gt":   "",
+			},
+		}),
+	}
+}
+
+// Crée les index dont dépendent les requêtes de l'API. La création est
+// sans effet lorsque l'index existe déjà.
+func createIndexes() {
+	indexes := map[*mongo.Collection][]mongo.IndexModel{
+		CollectionMarkets: {
+			geoIndex("addressGeo"),
+		},
+		CollectionCommerces: {
+			uniqueIndex("slug"),
+			geoIndex("addressGeo"),
+		},
+		// Recherche de produits, une collection n'ayant qu'un index texte
+		CollectionProducts: {
+			{
+				Keys: bson.D{
+					primitive.E{Key: "name", Value: "text"},
+					primitive.E{Key: "description", Value: "text"},
+					primitive.E{Key: "tags", Value: "text"},
+					primitive.E{Key: "categories", Value: "text"},
+				},
+				Options: options.Index().SetDefaultLanguage("french"),
+			},
+		},
+		CollectionPickupPoints: {
+			geoIndex("addressGeo"),
+		},
+		// L'import de la base SIRENE met à jour les établissements par
+		// SIRET
+		CollectionSirene: {
+			{
+				Keys:    bson.D{primitive.E{Key: "siret", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+		},
+		CollectionPromoCodes: {
+			uniqueIndex("code"),
+		},
+		// Les utilisations d'un code sont comptées par client
+		CollectionPromoCodeUsages: {
+			{
+				Keys: bson.D{
+					primitive.E{Key: "code", Value: 1},
+					primitive.E{Key: "userID", Value: 1},
+				},
+			},
+		},
+		CollectionGiftCards: {
+			uniqueIndex("code"),
+		},
+		// Deux catégories d'un commerce ne peuvent pas porter le même nom
+		CollectionCategories: {
+			{
+				Keys: bson.D{
+					primitive.E{Key: "commerceID", Value: 1},
+					primitive.E{Key: "normalizedName", Value: 1},
+				},
+				Options: options.Index().SetUnique(true),
+			},
+		},
+	}
+
+	for collection, models := range indexes {
+		_, err := collection.Indexes().CreateMany(MongoContext, models)
+
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+}
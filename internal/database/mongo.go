@@ -20,6 +20,17 @@ var CollectionCommerceCommand *mongo.Collection
 var CollectionCCCommand *mongo.Collection
 var CollectionPanierCommands *mongo.Collection
 var CollectionPaniers *mongo.Collection
+var CollectionSirene *mongo.Collection
+var CollectionDeliveryZones *mongo.Collection
+var CollectionMarkets *mongo.Collection
+var CollectionPickupPoints *mongo.Collection
+var CollectionStockMovements *mongo.Collection
+var CollectionPromotions *mongo.Collection
+var CollectionPromoCodes *mongo.Collection
+var CollectionPromoCodeUsages *mongo.Collection
+var CollectionGiftCards *mongo.Collection
+var CollectionCategories *mongo.Collection
+var CollectionMigrations *mongo.Collection
 
 // Initialise la base de données à partir des informations données
 // dans la configuration
@@ -56,6 +67,17 @@ func Init(shouldDrop *bool) {
 	cccommandeCollectionName := config.Cfg.Database.Collections.CCCommands
 	panierCommandsCollectionName := config.Cfg.Database.Collections.PanierCommands
 	panierCollectionName := config.Cfg.Database.Collections.Paniers
+	sireneCollectionName := config.Cfg.Database.Collections.Sirene
+	deliveryZonesCollectionName := config.Cfg.Database.Collections.DeliveryZones
+	marketsCollectionName := config.Cfg.Database.Collections.Markets
+	pickupPointsCollectionName := config.Cfg.Database.Collections.PickupPoints
+	stockMovementsCollectionName := config.Cfg.Database.Collections.StockMovements
+	promotionsCollectionName := config.Cfg.Database.Collections.Promotions
+	promoCodesCollectionName := config.Cfg.Database.Collections.PromoCodes
+	promoCodeUsagesCollectionName := config.Cfg.Database.Collections.PromoCodeUsages
+	giftCardsCollectionName := config.Cfg.Database.Collections.GiftCards
+	categoriesCollectionName := config.Cfg.Database.Collections.Categories
+	migrationsCollectionName := config.Cfg.Database.Collections.Migrations
 
 	CollectionUsers = client.Database(databaseName).Collection(usersCollectionName)
 	CollectionCommerces = client.Database(databaseName).Collection(commercesCollectionName)
@@ -65,9 +87,22 @@ func Init(shouldDrop *bool) {
 	CollectionCCCommand = client.Database(databaseName).Collection(cccommandeCollectionName)
 	CollectionPanierCommands = client.Database(databaseName).Collection(panierCommandsCollectionName)
 	CollectionPaniers = client.Database(databaseName).Collection(panierCollectionName)
+	CollectionSirene = client.Database(databaseName).Collection(sireneCollectionName)
+	CollectionDeliveryZones = client.Database(databaseName).Collection(deliveryZonesCollectionName)
+	CollectionMarkets = client.Database(databaseName).Collection(marketsCollectionName)
+	CollectionPickupPoints = client.Database(databaseName).Collection(pickupPointsCollectionName)
+	CollectionStockMovements = client.Database(databaseName).Collection(stockMovementsCollectionName)
+	CollectionPromotions = client.Database(databaseName).Collection(promotionsCollectionName)
+	CollectionPromoCodes = client.Database(databaseName).Collection(promoCodesCollectionName)
+	CollectionPromoCodeUsages = client.Database(databaseName).Collection(promoCodeUsagesCollectionName)
+	CollectionGiftCards = client.Database(databaseName).Collection(giftCardsCollectionName)
+	CollectionCategories = client.Database(databaseName).Collection(categoriesCollectionName)
+	CollectionMigrations = client.Database(databaseName).Collection(migrationsCollectionName)
 
 	// Si on veut vider la bdd à l'initialisation, on le fait
 	if shouldDrop != nil && *shouldDrop {
 		client.Database(databaseName).Drop(MongoContext)
 	}
+
+	createIndexes()
 }
@@ -0,0 +1,268 @@
+package deliveryzones
+
+import (
+	"time"
+
+	"chemin-du-local.bzh/graphql/graph/model"
+	"chemin-du-local.bzh/graphql/internal/database"
+	"chemin-du-local.bzh/graphql/pkg/geojson"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Nombre de côtés du polygone utilisé pour approximer une zone circulaire
+const circleSides = 64
+
+type DeliveryZone struct {
+	ID            primitive.ObjectID  `bson:"_id"`
+	CommerceID    primitive.ObjectID  `bson:"commerceID"`
+	Name          string              `bson:"name"`
+	Type          string              `bson:"type"`
+	Area          geojson.Polygon     `bson:"area"`
+	Center        *geojson.GeoJSON    `bson:"center"`
+	Radius        *float64            `bson:"radius"`
+	Fee           float64             `bson:"fee"`
+	MinimumOrder  float64             `bson:"minimumOrder"`
+	DeliveryHours model.BusinessHours `bson:"deliveryHours"`
+}
+
+func (zone *DeliveryZone) ToModel() *model.DeliveryZone {
+	result := model.DeliveryZone{
+		ID:            zone.ID.Hex(),
+		Name:          zone.Name,
+		Type:          model.DeliveryZoneType(zone.Type),
+		Fee:           zone.Fee,
+		MinimumOrder:  zone.MinimumOrder,
+		DeliveryHours: &zone.DeliveryHours,
+	}
+
+	if zone.Type == model.DeliveryZoneTypeRadius.String() && zone.Center != nil {
+		result.Longitude = &zone.Center.Coordinates[0]
+		result.Latitude = &zone.Center.Coordinates[1]
+		result.Radius = zone.Radius
+	} else if len(zone.Area.Coordinates) > 0 {
+		result.Polygon = zone.Area.Coordinates[0]
+	}
+
+	return &result
+}
+
+// Indique si la date donnée tombe dans l'un des créneaux de livraison
+// de la zone. Une zone sans aucun créneau accepte toutes les dates.
+func (zone *DeliveryZone) IsDeliveryTime(date time.Time) bool {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if len(zone.DeliveryHours.ForDay(day)) > 0 {
+			return zone.DeliveryHours.IsOpenAt(date)
+		}
+	}
+
+	return true
+}
+
+// Service
+
+type deliveryZonesService struct{}
+
+type DeliveryZonesService interface {
+	Create(commerceID primitive.ObjectID, input model.NewDeliveryZone) (*DeliveryZone, error)
+	Delete(id string) error
+	GetById(id string) (*DeliveryZone, error)
+	GetForCommerce(commerceID string) ([]DeliveryZone, error)
+	GetForLocation(commerceID string, longitude float64, latitude float64) (*DeliveryZone, error)
+	GetFiltered(filter interface{}, opts *options.FindOptions) ([]DeliveryZone, error)
+}
+
+func NewDeliveryZonesService() *deliveryZonesService {
+	return &deliveryZonesService{}
+}
+
+// Créateur de base de données
+
+func (d *deliveryZonesService) Create(commerceID primitive.ObjectID, input model.NewDeliveryZone) (*DeliveryZone, error) {
+	if input.Fee < 0 || (input.MinimumOrder != nil && *input.MinimumOrder < 0) {
+		return nil, &InvalidDeliveryZoneAmountError{}
+	}
+
+	databaseZone := DeliveryZone{
+		ID:         primitive.NewObjectID(),
+		CommerceID: commerceID,
+		Name:       input.Name,
+		Type:       input.Type.String(),
+		Fee:        input.Fee,
+	}
+
+	if input.MinimumOrder != nil {
+		databaseZone.MinimumOrder = *input.MinimumOrder
+	}
+
+	if input.DeliveryHours != nil {
+		databaseZone.DeliveryHours = *input.DeliveryHours.ToModel()
+	}
+
+	switch input.Type {
+	case model.DeliveryZoneTypeRadius:
+		if input.Latitude == nil || input.Longitude == nil || input.Radius == nil || *input.Radius <= 0 {
+			return nil, &InvalidDeliveryZoneError{}
+		}
+
+		center := geojson.NewPoint(*input.Longitude, *input.Latitude)
+		databaseZone.Center = &center
+		databaseZone.Radius = input.Radius
+		databaseZone.Area = geojson.NewCircle(*input.Longitude, *input.Latitude, *input.Radius, circleSides)
+	case model.DeliveryZoneTypePolygon:
+		if len(input.Polygon) < 3 {
+			return nil, &InvalidDeliveryZoneError{}
+		}
+
+		for _, point := range input.Polygon {
+			if len(point) != 2 {
+				return nil, &InvalidDeliveryZoneError{}
+			}
+		}
+
+		databaseZone.Area = geojson.NewPolygon(input.Polygon)
+	}
+
+	_, err := database.CollectionDeliveryZones.InsertOne(database.MongoContext, databaseZone)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &databaseZone, nil
+}
+
+// Suppression en base de données
+
+func (d *deliveryZonesService) Delete(id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+
+	if err != nil {
+		return err
+	}
+
+	filter := bson.D{
+		primitive.E{
+			Key:   "_id",
+			Value: objectID,
+		},
+	}
+
+	_, err = database.CollectionDeliveryZones.DeleteOne(database.MongoContext, filter)
+
+	return err
+}
+
+// Getter de base de données
+
+func (d *deliveryZonesService) GetById(id string) (*DeliveryZone, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.D{
+		primitive.E{
+			Key:   "_id",
+			Value: objectID,
+		},
+	}
+
+	zones, err := d.GetFiltered(filter, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(zones) == 0 {
+		return nil, nil
+	}
+
+	return &zones[0], nil
+}
+
+func (d *deliveryZonesService) GetForCommerce(commerceID string) ([]DeliveryZone, error) {
+	commerceObjectID, err := primitive.ObjectIDFromHex(commerceID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.D{
+		primitive.E{
+			Key:   "commerceID",
+			Value: commerceObjectID,
+		},
+	}
+
+	return d.GetFiltered(filter, nil)
+}
+
+// Retrouve la zone du commerce contenant le point donné. Si plusieurs
+// zones se chevauchent, la moins chère est retenue.
+func (d *deliveryZonesService) GetForLocation(commerceID string, longitude float64, latitude float64) (*DeliveryZone, error) {
+	commerceObjectID, err := primitive.ObjectIDFromHex(commerceID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{
+		"commerceID": commerceObjectID,
+		"area": bson.M{
+			"$geoIntersects": bson.M{
+				"$geometry": geojson.NewPoint(longitude, latitude),
+			},
+		},
+	}
+
+	opts := options.Find()
+	opts.SetSort(bson.D{
+		primitive.E{
+			Key: "fee", Value: 1,
+		},
+	})
+	opts.SetLimit(1)
+
+	zones, err := d.GetFiltered(filter, opts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(zones) == 0 {
+		return nil, nil
+	}
+
+	return &zones[0], nil
+}
+
+func (d *deliveryZonesService) GetFiltered(filter interface{}, opts *options.FindOptions) ([]DeliveryZone, error) {
+	zones := []DeliveryZone{}
+
+	cursor, err := database.CollectionDeliveryZones.Find(database.MongoContext, filter, opts)
+
+	if err != nil {
+		return zones, err
+	}
+
+	for cursor.Next(database.MongoContext) {
+		var zone DeliveryZone
+
+		err := cursor.Decode(&zone)
+
+		if err != nil {
+			return zones, err
+		}
+
+		zones = append(zones, zone)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return zones, err
+	}
+
+	return zones, nil
+}
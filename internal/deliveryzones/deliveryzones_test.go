@@ -0,0 +1,62 @@
+package deliveryzones
+
+import (
+	"testing"
+	"time"
+
+	"chemin-du-local.bzh/graphql/graph/model"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestDeliveryZone_IsDeliveryTime(t *testing.T) {
+	t.Run("a zone without schedules accepts every date", func(t *testing.T) {
+		zone := DeliveryZone{}
+
+		require.True(t, zone.IsDeliveryTime(time.Now()))
+	})
+
+	t.Run("the schedules are read in French time", func(t *testing.T) {
+		zone := DeliveryZone{
+			DeliveryHours: model.BusinessHours{
+				Saturday: []*model.Schedule{
+					{
+						Opening: "09:00",
+						Closing: "12:00",
+					},
+				},
+			},
+		}
+
+		// Le 17 octobre 2026 est un samedi, à UTC+2
+		require.True(t, zone.IsDeliveryTime(time.Date(2026, 10, 17, 7, 30, 0, 0, time.UTC)))
+		require.False(t, zone.IsDeliveryTime(time.Date(2026, 10, 17, 11, 0, 0, 0, time.UTC)))
+		require.False(t, zone.IsDeliveryTime(time.Date(2026, 10, 18, 8, 0, 0, 0, time.UTC)))
+	})
+}
+
+func TestDeliveryZonesService_Create(t *testing.T) {
+	deliveryZonesService := NewDeliveryZonesService()
+	negative := -5.0
+
+	t.Run("a negative fee is refused", func(t *testing.T) {
+		_, err := deliveryZonesService.Create(primitive.NewObjectID(), model.NewDeliveryZone{
+			Name: "Centre-ville",
+			Type: model.DeliveryZoneTypePolygon,
+			Fee:  -1,
+		})
+
+		require.IsType(t, &InvalidDeliveryZoneAmountError{}, err)
+	})
+
+	t.Run("a negative minimum order is refused", func(t *testing.T) {
+		_, err := deliveryZonesService.Create(primitive.NewObjectID(), model.NewDeliveryZone{
+			Name:         "Centre-ville",
+			Type:         model.DeliveryZoneTypePolygon,
+			Fee:          3,
+			MinimumOrder: &negative,
+		})
+
+		require.IsType(t, &InvalidDeliveryZoneAmountError{}, err)
+	})
+}
@@ -0,0 +1,46 @@
+package deliveryzones
+
+import "fmt"
+
+type DeliveryZoneNotFoundError struct{}
+type InvalidDeliveryZoneError struct{}
+type InvalidDeliveryZoneAmountError struct{}
+type AddressNotDeliverableError struct{}
+type DeliveryAddressRequiredError struct{}
+type DeliveryAddressWithoutLocationError struct{}
+type DeliveryTimeUnavailableError struct{}
+type MinimumOrderNotReachedError struct {
+	MinimumOrder float64
+}
+
+func (m *DeliveryZoneNotFoundError) Error() string {
+	return "la zone de livraison n'a pas été trouvée"
+}
+
+func (m *InvalidDeliveryZoneError) Error() string {
+	return "une zone doit être un polygone d'au moins trois points ou un cercle de rayon positif"
+}
+
+func (m *InvalidDeliveryZoneAmountError) Error() string {
+	return "les frais de livraison et le minimum de commande ne peuvent pas être négatifs"
+}
+
+func (m *AddressNotDeliverableError) Error() string {
+	return "l'adresse de livraison n'est desservie par aucune zone du commerce"
+}
+
+func (m *DeliveryAddressRequiredError) Error() string {
+	return "une adresse de livraison est nécessaire"
+}
+
+func (m *DeliveryAddressWithoutLocationError) Error() string {
+	return "l'adresse de livraison n'a pas de coordonnées"
+}
+
+func (m *DeliveryTimeUnavailableError) Error() string {
+	return "la date de livraison est en dehors des créneaux de la zone"
+}
+
+func (m *MinimumOrderNotReachedError) Error() string {
+	return fmt.Sprintf("le minimum de commande pour la livraison est de %.2f€", m.MinimumOrder)
+}
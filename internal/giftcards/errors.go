@@ -0,0 +1,32 @@
+package giftcards
+
+import "fmt"
+
+type GiftCardNotFoundError struct{}
+type GiftCardNotUsableError struct{}
+type GiftCardInsufficientBalanceError struct{}
+type GiftCardNotAcceptedError struct{}
+type InvalidGiftCardAmountError struct {
+	Min float64
+	Max float64
+}
+
+func (m *GiftCardNotFoundError) Error() string {
+	return "la carte cadeau n'existe pas"
+}
+
+func (m *GiftCardNotUsableError) Error() string {
+	return "la carte cadeau n'est pas active ou a expiré"
+}
+
+func (m *GiftCardInsufficientBalanceError) Error() string {
+	return "le solde de la carte cadeau est insuffisant"
+}
+
+func (m *GiftCardNotAcceptedError) Error() string {
+	return "aucun commerce du panier n'accepte la carte cadeau"
+}
+
+func (m *InvalidGiftCardAmountError) Error() string {
+	return fmt.Sprintf("le montant d'une carte cadeau doit être compris entre %.2f€ et %.2f€", m.Min, m.Max)
+}
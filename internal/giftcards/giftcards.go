@@ -0,0 +1,453 @@
+package giftcards
+
+import (
+	"crypto/rand"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+
+	"chemin-du-local.bzh/graphql/graph/model"
+	"chemin-du-local.bzh/graphql/internal/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const GIFT_CARD_STATUS_PENDING = "PENDING"
+const GIFT_CARD_STATUS_ACTIVE = "ACTIVE"
+
+const GIFT_CARD_TRANSACTION_PURCHASE = "PURCHASE"
+const GIFT_CARD_TRANSACTION_REDEMPTION = "REDEMPTION"
+const GIFT_CARD_TRANSACTION_REFUND = "REFUND"
+const GIFT_CARD_TRANSACTION_WEIGHING_REFUND = "WEIGHING_REFUND"
+
+// Montants en euros acceptés à l'achat
+const GIFT_CARD_MIN_AMOUNT = 10.0
+const GIFT_CARD_MAX_AMOUNT = 500.0
+
+// Une carte cadeau est valable un an après son achat
+const giftCardValidityYears = 1
+
+// Sans les caractères que l'on confond à la lecture (0/O, 1/I)
+const codeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// Les montants sont en centimes, comme le prix des commandes
+type GiftCard struct {
+	ID              primitive.ObjectID    `bson:"_id"`
+	Code            string                `bson:"code"`
+	PurchaserID     primitive.ObjectID    `bson:"purchaserID"`
+	RecipientName   *string               `bson:"recipientName"`
+	RecipientEmail  *string               `bson:"recipientEmail"`
+	Message         *string               `bson:"message"`
+	InitialAmount   int                   `bson:"initialAmount"`
+	Balance         int                   `bson:"balance"`
+	Status          string                `bson:"status"`
+	CreationDate    time.Time             `bson:"creationDate"`
+	ExpiryDate      time.Time             `bson:"expiryDate"`
+	PaymentIntentID *string               `bson:"paymentIntentID"`
+	Transactions    []GiftCardTransaction `bson:"transactions"`
+}
+
+type GiftCardTransaction struct {
+	Type              string              `bson:"type"`
+	Amount            int                 `bson:"amount"`
+	CommerceID        *primitive.ObjectID `bson:"commerceID"`
+	CommerceCommandID *primitive.ObjectID `bson:"commerceCommandID"`
+	Date              time.Time           `bson:"date"`
+}
+
+func (card *GiftCard) ToModel() *model.GiftCard {
+	transactions := []*model.GiftCardTransaction{}
+
+	for _, transaction := range card.Transactions {
+		transactions = append(transactions, transaction.ToModel())
+	}
+
+	return &model.GiftCard{
+		ID:             card.ID.Hex(),
+		Code:           card.Code,
+		RecipientName:  card.RecipientName,
+		RecipientEmail: card.RecipientEmail,
+		Message:        card.Message,
+		InitialAmount:  float64(card.InitialAmount) / 100,
+		Balance:        float64(card.Balance) / 100,
+		Status:         model.GiftCardStatus(card.Status),
+		IsUsable:       card.IsUsable(time.Now()),
+		CreationDate:   card.CreationDate,
+		ExpiryDate:     card.ExpiryDate,
+		Transactions:   transactions,
+	}
+}
+
+func (transaction *GiftCardTransaction) ToModel() *model.GiftCardTransaction {
+	var commerceID *string
+
+	if transaction.CommerceID != nil {
+		commerceIDValue := transaction.CommerceID.Hex()
+		commerceID = &commerceIDValue
+	}
+
+	var commerceCommandID *string
+
+	if transaction.CommerceCommandID != nil {
+		commerceCommandIDValue := transaction.CommerceCommandID.Hex()
+		commerceCommandID = &commerceCommandIDValue
+	}
+
+	return &model.GiftCardTransaction{
+		Type:              model.GiftCardTransactionType(transaction.Type),
+		Amount:            float64(transaction.Amount) / 100,
+		CommerceID:        commerceID,
+		CommerceCommandID: commerceCommandID,
+		Date:              transaction.Date,
+	}
+}
+
+// Une carte est utilisable une fois payée et jusqu'à son expiration
+func (card *GiftCard) IsUsable(now time.Time) bool {
+	return card.Status == GIFT_CARD_STATUS_ACTIVE && now.Before(card.ExpiryDate) && card.Balance > 0
+}
+
+// Montant restant à payer pour la commande d'un commerce, en centimes
+type BasketPart struct {
+	Amount           int
+	AcceptsGiftCards bool
+}
+
+// Répartit le solde de la carte entre les commandes des commerces qui
+// l'acceptent, dans l'ordre du panier. Le reste est payé par carte
+// bancaire.
+func (card *GiftCard) Allocate(parts []BasketPart, now time.Time) ([]int, error) {
+	if !card.IsUsable(now) {
+		return nil, &GiftCardNotUsableError{}
+	}
+
+	amounts := make([]int, len(parts))
+	remainingBalance := card.Balance
+	accepted := false
+
+	for index, part := range parts {
+		if !part.AcceptsGiftCards {
+			continue
+		}
+
+		accepted = true
+		amount := part.Amount
+
+		if amount > remainingBalance {
+			amount = remainingBalance
+		}
+
+		amounts[index] = amount
+		remainingBalance = remainingBalance - amount
+	}
+
+	if !accepted {
+		return nil, &GiftCardNotAcceptedError{}
+	}
+
+	return amounts, nil
+}
+
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Un code de la forme CDL-XXXX-XXXX-XXXX
+func generateCode() (string, error) {
+	builder := strings.Builder{}
+	builder.WriteString("CDL")
+
+	for i := 0; i < 12; i++ {
+		if i%4 == 0 {
+			builder.WriteString("-")
+		}
+
+		index, err := rand.Int(rand.Reader, big.NewInt(int64(len(codeAlphabet))))
+
+		if err != nil {
+			return "", err
+		}
+
+		builder.WriteByte(codeAlphabet[index.Int64()])
+	}
+
+	return builder.String(), nil
+}
+
+// Service
+
+type giftCardsService struct{}
+
+type GiftCardsService interface {
+	Create(purchaserID primitive.ObjectID, input model.NewGiftCard) (*GiftCard, error)
+	Activate(card *GiftCard, paymentIntentID string) error
+	Redeem(card *GiftCard, amount int, commerceID primitive.ObjectID, commerceCommandID primitive.ObjectID) error
+	Refund(cardID primitive.ObjectID, amount int, commerceID primitive.ObjectID, commerceCommandID primitive.ObjectID) error
+	RefundWeighing(cardID primitive.ObjectID, amount int, commerceID primitive.ObjectID, commerceCommandID primitive.ObjectID) error
+	GetById(id string) (*GiftCard, error)
+	GetByCode(code string) (*GiftCard, error)
+	GetForPurchaser(purchaserID primitive.ObjectID) ([]GiftCard, error)
+	GetFiltered(filter interface{}, opts *options.FindOptions) ([]GiftCard, error)
+}
+
+func NewGiftCardsService() *giftCardsService {
+	return &giftCardsService{}
+}
+
+// Créateur de base de données
+
+// La carte est créée en attente de paiement, elle n'est utilisable
+// qu'une fois activée
+func (g *giftCardsService) Create(purchaserID primitive.ObjectID, input model.NewGiftCard) (*GiftCard, error) {
+	if input.Amount < GIFT_CARD_MIN_AMOUNT || input.Amount > GIFT_CARD_MAX_AMOUNT {
+		return nil, &InvalidGiftCardAmountError{Min: GIFT_CARD_MIN_AMOUNT, Max: GIFT_CARD_MAX_AMOUNT}
+	}
+
+	now := time.Now()
+	amount := int(math.Round(input.Amount * 100))
+
+	databaseCard := GiftCard{
+		ID:             primitive.NewObjectID(),
+		PurchaserID:    purchaserID,
+		RecipientName:  input.RecipientName,
+		RecipientEmail: input.RecipientEmail,
+		Message:        input.Message,
+		InitialAmount:  amount,
+		Balance:        0,
+		Status:         GIFT_CARD_STATUS_PENDING,
+		CreationDate:   now,
+		ExpiryDate:     now.AddDate(giftCardValidityYears, 0, 0),
+		Transactions:   []GiftCardTransaction{},
+	}
+
+	// L'index unique sur le code écarte un code déjà attribué, y compris
+	// à une carte créée au même moment : un autre code est alors tiré
+	for {
+		code, err := generateCode()
+
+		if err != nil {
+			return nil, err
+		}
+
+		databaseCard.Code = code
+
+		_, err = database.CollectionGiftCards.InsertOne(database.MongoContext, databaseCard)
+
+		if mongo.IsDuplicateKeyError(err) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &databaseCard, nil
+	}
+}
+
+// Mise à jour de la base de données
+
+// Crédite la carte une fois le paiement confirmé par Stripe. Une carte
+// déjà active n'est pas créditée une seconde fois.
+func (g *giftCardsService) Activate(card *GiftCard, paymentIntentID string) error {
+	transaction := GiftCardTransaction{
+		Type:   GIFT_CARD_TRANSACTION_PURCHASE,
+		Amount: card.InitialAmount,
+		Date:   time.Now(),
+	}
+
+	result, err := database.CollectionGiftCards.UpdateOne(
+		database.MongoContext,
+		bson.M{"_id": card.ID, "status": GIFT_CARD_STATUS_PENDING},
+		bson.M{
+			"$set": bson.M{
+				"status":          GIFT_CARD_STATUS_ACTIVE,
+				"balance":         card.InitialAmount,
+				"paymentIntentID": paymentIntentID,
+			},
+			"$push": bson.M{"transactions": transaction},
+		},
+	)
+
+	if err != nil || result.MatchedCount == 0 {
+		return err
+	}
+
+	card.Status = GIFT_CARD_STATUS_ACTIVE
+	card.Balance = card.InitialAmount
+	card.PaymentIntentID = &paymentIntentID
+	card.Transactions = append(card.Transactions, transaction)
+
+	return nil
+}
+
+// Débite la carte pour la commande d'un commerce. Le solde est vérifié
+// par la requête elle-même, pour que deux commandes simultanées ne
+// puissent pas le dépasser.
+func (g *giftCardsService) Redeem(card *GiftCard, amount int, commerceID primitive.ObjectID, commerceCommandID primitive.ObjectID) error {
+	now := time.Now()
+	transaction := GiftCardTransaction{
+		Type:              GIFT_CARD_TRANSACTION_REDEMPTION,
+		Amount:            amount,
+		CommerceID:        &commerceID,
+		CommerceCommandID: &commerceCommandID,
+		Date:              now,
+	}
+
+	filter := bson.M{
+		"_id":        card.ID,
+		"status":     GIFT_CARD_STATUS_ACTIVE,
+		"expiryDate": bson.M{"$gt": now},
+		"balance":    bson.M{"$gte": amount},
+	}
+
+	result, err := database.CollectionGiftCards.UpdateOne(
+		database.MongoContext,
+		filter,
+		bson.M{
+			"$inc":  bson.M{"balance": -amount},
+			"$push": bson.M{"transactions": transaction},
+		},
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return &GiftCardInsufficientBalanceError{}
+	}
+
+	card.Balance = card.Balance - amount
+	card.Transactions = append(card.Transactions, transaction)
+
+	return nil
+}
+
+// Recrédite la carte quand la commande d'un commerce est annulée. Une
+// commande n'est remboursée qu'une fois, et seulement si la carte a
+// effectivement été débitée pour elle.
+func (g *giftCardsService) Refund(cardID primitive.ObjectID, amount int, commerceID primitive.ObjectID, commerceCommandID primitive.ObjectID) error {
+	return g.refund(GIFT_CARD_TRANSACTION_REFUND, cardID, amount, commerceID, commerceCommandID)
+}
+
+// Recrédite la carte de l'écart entre le montant estimé et le montant
+// réel d'une commande pesée. Une commande n'est pesée qu'une fois, et
+// peut encore être remboursée de ce qui reste si elle est annulée.
+func (g *giftCardsService) RefundWeighing(cardID primitive.ObjectID, amount int, commerceID primitive.ObjectID, commerceCommandID primitive.ObjectID) error {
+	return g.refund(GIFT_CARD_TRANSACTION_WEIGHING_REFUND, cardID, amount, commerceID, commerceCommandID)
+}
+
+func (g *giftCardsService) refund(transactionType string, cardID primitive.ObjectID, amount int, commerceID primitive.ObjectID, commerceCommandID primitive.ObjectID) error {
+	transaction := GiftCardTransaction{
+		Type:              transactionType,
+		Amount:            amount,
+		CommerceID:        &commerceID,
+		CommerceCommandID: &commerceCommandID,
+		Date:              time.Now(),
+	}
+
+	filter := bson.M{
+		"_id": cardID,
+		"$and": bson.A{
+			bson.M{
+				"transactions": bson.M{
+					"$elemMatch": bson.M{
+						"type":              GIFT_CARD_TRANSACTION_REDEMPTION,
+						"commerceCommandID": commerceCommandID,
+					},
+				},
+			},
+			bson.M{
+				"transactions": bson.M{
+					"$not": bson.M{
+						"$elemMatch": bson.M{
+							"type":              transactionType,
+							"commerceCommandID": commerceCommandID,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	_, err := database.CollectionGiftCards.UpdateOne(
+		database.MongoContext,
+		filter,
+		bson.M{
+			"$inc":  bson.M{"balance": amount},
+			"$push": bson.M{"transactions": transaction},
+		},
+	)
+
+	return err
+}
+
+// Getter de base de données
+
+func (g *giftCardsService) GetById(id string) (*GiftCard, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	cards, err := g.GetFiltered(bson.M{"_id": objectID}, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cards) == 0 {
+		return nil, nil
+	}
+
+	return &cards[0], nil
+}
+
+func (g *giftCardsService) GetByCode(code string) (*GiftCard, error) {
+	cards, err := g.GetFiltered(bson.M{"code": NormalizeCode(code)}, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cards) == 0 {
+		return nil, nil
+	}
+
+	return &cards[0], nil
+}
+
+func (g *giftCardsService) GetForPurchaser(purchaserID primitive.ObjectID) ([]GiftCard, error) {
+	opts := options.Find().SetSort(bson.M{"creationDate": -1})
+
+	return g.GetFiltered(bson.M{"purchaserID": purchaserID}, opts)
+}
+
+func (g *giftCardsService) GetFiltered(filter interface{}, opts *options.FindOptions) ([]GiftCard, error) {
+	cards := []GiftCard{}
+
+	cursor, err := database.CollectionGiftCards.Find(database.MongoContext, filter, opts)
+
+	if err != nil {
+		return cards, err
+	}
+
+	for cursor.Next(database.MongoContext) {
+		var card GiftCard
+
+		err := cursor.Decode(&card)
+
+		if err != nil {
+			return cards, err
+		}
+
+		cards = append(cards, card)
+	}
+
+	return cards, nil
+}
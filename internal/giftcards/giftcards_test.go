@@ -0,0 +1,86 @@
+package giftcards
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllocate(t *testing.T) {
+	now := time.Date(2022, 10, 12, 14, 0, 0, 0, time.UTC)
+
+	newGiftCard := func(balance int) GiftCard {
+		return GiftCard{
+			Code:       "CDL-ABCD-EFGH-JKLM",
+			Balance:    balance,
+			Status:     GIFT_CARD_STATUS_ACTIVE,
+			ExpiryDate: now.AddDate(1, 0, 0),
+		}
+	}
+
+	t.Run("the balance is used in the order of the basket", func(t *testing.T) {
+		card := newGiftCard(3000)
+
+		amounts, err := card.Allocate([]BasketPart{
+			{Amount: 2000, AcceptsGiftCards: true},
+			{Amount: 2000, AcceptsGiftCards: true},
+		}, now)
+
+		require.NoError(t, err)
+		require.Equal(t, []int{2000, 1000}, amounts)
+	})
+
+	t.Run("commerces refusing gift cards are skipped", func(t *testing.T) {
+		card := newGiftCard(3000)
+
+		amounts, err := card.Allocate([]BasketPart{
+			{Amount: 2000, AcceptsGiftCards: false},
+			{Amount: 1500, AcceptsGiftCards: true},
+		}, now)
+
+		require.NoError(t, err)
+		require.Equal(t, []int{0, 1500}, amounts)
+	})
+
+	t.Run("a basket without any commerce accepting gift cards is refused", func(t *testing.T) {
+		card := newGiftCard(3000)
+
+		_, err := card.Allocate([]BasketPart{
+			{Amount: 2000, AcceptsGiftCards: false},
+		}, now)
+
+		require.IsType(t, &GiftCardNotAcceptedError{}, err)
+	})
+
+	t.Run("an expired card is refused", func(t *testing.T) {
+		card := newGiftCard(3000)
+
+		_, err := card.Allocate([]BasketPart{
+			{Amount: 2000, AcceptsGiftCards: true},
+		}, now.AddDate(2, 0, 0))
+
+		require.IsType(t, &GiftCardNotUsableError{}, err)
+	})
+
+	t.Run("a card awaiting payment is refused", func(t *testing.T) {
+		card := newGiftCard(3000)
+		card.Status = GIFT_CARD_STATUS_PENDING
+
+		_, err := card.Allocate([]BasketPart{
+			{Amount: 2000, AcceptsGiftCards: true},
+		}, now)
+
+		require.IsType(t, &GiftCardNotUsableError{}, err)
+	})
+
+	t.Run("an empty card is refused", func(t *testing.T) {
+		card := newGiftCard(0)
+
+		_, err := card.Allocate([]BasketPart{
+			{Amount: 2000, AcceptsGiftCards: true},
+		}, now)
+
+		require.IsType(t, &GiftCardNotUsableError{}, err)
+	})
+}
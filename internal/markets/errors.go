@@ -0,0 +1,17 @@
+package markets
+
+type MarketNotFoundError struct{}
+type CommerceNotInMarketError struct{}
+type MarketClosedError struct{}
+
+func (m *MarketNotFoundError) Error() string {
+	return "le marché n'a pas été trouvé"
+}
+
+func (m *CommerceNotInMarketError) Error() string {
+	return "le commerce ne participe pas à ce marché"
+}
+
+func (m *MarketClosedError) Error() string {
+	return "le marché ne se tient pas à la date de retrait choisie"
+}
@@ -0,0 +1,272 @@
+package markets
+
+import (
+	"time"
+
+	"chemin-du-local.bzh/graphql/graph/model"
+	"chemin-du-local.bzh/graphql/internal/address"
+	"chemin-du-local.bzh/graphql/internal/database"
+	"chemin-du-local.bzh/graphql/pkg/geojson"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Rayon de recherche par défaut des marchés, en mètres
+const defaultRadius = 20000.0
+
+type Market struct {
+	ID          primitive.ObjectID   `bson:"_id"`
+	Name        string               `bson:"name"`
+	Description string               `bson:"description"`
+	Address     address.Address      `bson:"address"`
+	AddressGeo  geojson.GeoJSON      `bson:"addressGeo"`
+	Schedule    model.BusinessHours  `bson:"schedule"`
+	CommercesID []primitive.ObjectID `bson:"commercesID"`
+}
+
+func (market *Market) ToModel() *model.Market {
+	commercesID := []string{}
+
+	for _, commerceID := range market.CommercesID {
+		commercesID = append(commercesID, commerceID.Hex())
+	}
+
+	return &model.Market{
+		ID:          market.ID.Hex(),
+		Name:        market.Name,
+		Description: market.Description,
+		Address:     *market.Address.ToModel(),
+		Latitude:    market.AddressGeo.Coordinates[1],
+		Longitude:   market.AddressGeo.Coordinates[0],
+		Schedule:    market.Schedule,
+		CommercesID: commercesID,
+	}
+}
+
+func (market *Market) HasCommerce(commerceID primitive.ObjectID) bool {
+	for _, marketCommerceID := range market.CommercesID {
+		if marketCommerceID == commerceID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Indique si le marché se tient à la date donnée
+func (market *Market) IsOpenAt(date time.Time) bool {
+	return market.Schedule.IsOpenAt(date)
+}
+
+// Service
+
+type marketsService struct{}
+
+type MarketsService interface {
+	Create(input model.NewMarket) (*Market, error)
+	Update(changes *Market) error
+	AddCommerce(marketID string, commerceID primitive.ObjectID) (*Market, error)
+	RemoveCommerce(marketID string, commerceID primitive.ObjectID) (*Market, error)
+	GetById(id string) (*Market, error)
+	GetForCommerce(commerceID string) ([]Market, error)
+	GetNear(latitude float64, longitude float64, radius *float64) ([]Market, error)
+	GetFiltered(filter interface{}, opts *options.FindOptions) ([]Market, error)
+}
+
+func NewMarketsService() *marketsService {
+	return &marketsService{}
+}
+
+// Créateur de base de données
+
+func (m *marketsService) Create(input model.NewMarket) (*Market, error) {
+	description := ""
+
+	if input.Description != nil {
+		description = *input.Description
+	}
+
+	databaseMarket := Market{
+		ID:          primitive.NewObjectID(),
+		Name:        input.Name,
+		Description: description,
+		Address:     address.FromInput(input.Address),
+		AddressGeo:  geojson.NewPoint(input.Longitude, input.Latitude),
+		Schedule:    *input.Schedule.ToModel(),
+		CommercesID: []primitive.ObjectID{},
+	}
+
+	_, err := database.CollectionMarkets.InsertOne(database.MongoContext, databaseMarket)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &databaseMarket, nil
+}
+
+// Mise à jour de la base de données
+
+func (m *marketsService) Update(changes *Market) error {
+	filter := bson.D{
+		primitive.E{
+			Key:   "_id",
+			Value: changes.ID,
+		},
+	}
+
+	_, err := database.CollectionMarkets.ReplaceOne(database.MongoContext, filter, changes)
+
+	return err
+}
+
+func (m *marketsService) AddCommerce(marketID string, commerceID primitive.ObjectID) (*Market, error) {
+	databaseMarket, err := m.GetById(marketID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if databaseMarket == nil {
+		return nil, &MarketNotFoundError{}
+	}
+
+	if databaseMarket.HasCommerce(commerceID) {
+		return databaseMarket, nil
+	}
+
+	databaseMarket.CommercesID = append(databaseMarket.CommercesID, commerceID)
+
+	err = m.Update(databaseMarket)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return databaseMarket, nil
+}
+
+func (m *marketsService) RemoveCommerce(marketID string, commerceID primitive.ObjectID) (*Market, error) {
+	databaseMarket, err := m.GetById(marketID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if databaseMarket == nil {
+		return nil, &MarketNotFoundError{}
+	}
+
+	commercesID := []primitive.ObjectID{}
+
+	for _, marketCommerceID := range databaseMarket.CommercesID {
+		if marketCommerceID != commerceID {
+			commercesID = append(commercesID, marketCommerceID)
+		}
+	}
+
+	databaseMarket.CommercesID = commercesID
+
+	err = m.Update(databaseMarket)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return databaseMarket, nil
+}
+
+// Getter de base de données
+
+func (m *marketsService) GetById(id string) (*Market, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.D{
+		primitive.E{
+			Key:   "_id",
+			Value: objectID,
+		},
+	}
+
+	markets, err := m.GetFiltered(filter, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(markets) == 0 {
+		return nil, nil
+	}
+
+	return &markets[0], nil
+}
+
+func (m *marketsService) GetForCommerce(commerceID string) ([]Market, error) {
+	commerceObjectID, err := primitive.ObjectIDFromHex(commerceID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.D{
+		primitive.E{
+			Key:   "commercesID",
+			Value: commerceObjectID,
+		},
+	}
+
+	return m.GetFiltered(filter, nil)
+}
+
+// Les marchés autour d'un point, du plus proche au plus éloigné
+func (m *marketsService) GetNear(latitude float64, longitude float64, radius *float64) ([]Market, error) {
+	maxDistance := defaultRadius
+
+	if radius != nil {
+		maxDistance = *radius
+	}
+
+	filter := bson.M{
+		"addressGeo": bson.M{
+			"$near": bson.M{
+				"$geometry":    geojson.NewPoint(longitude, latitude),
+				"$maxDistance": maxDistance,
+			},
+		},
+	}
+
+	return m.GetFiltered(filter, nil)
+}
+
+func (m *marketsService) GetFiltered(filter interface{}, opts *options.FindOptions) ([]Market, error) {
+	markets := []Market{}
+
+	cursor, err := database.CollectionMarkets.Find(database.MongoContext, filter, opts)
+
+	if err != nil {
+		return markets, err
+	}
+
+	for cursor.Next(database.MongoContext) {
+		var market Market
+
+		err := cursor.Decode(&market)
+
+		if err != nil {
+			return markets, err
+		}
+
+		markets = append(markets, market)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return markets, err
+	}
+
+	return markets, nil
+}
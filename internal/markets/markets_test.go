@@ -0,0 +1,61 @@
+package markets
+
+import (
+	"testing"
+	"time"
+
+	"chemin-du-local.bzh/graphql/graph/model"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMarket_HasCommerce(t *testing.T) {
+	commerceID := primitive.NewObjectID()
+
+	market := Market{
+		CommercesID: []primitive.ObjectID{primitive.NewObjectID(), commerceID},
+	}
+
+	require.True(t, market.HasCommerce(commerceID))
+	require.False(t, market.HasCommerce(primitive.NewObjectID()))
+}
+
+func TestMarket_IsOpenAt(t *testing.T) {
+	market := Market{
+		Schedule: model.BusinessHours{
+			Saturday: []*model.Schedule{
+				{
+					Opening: "08:00",
+					Closing: "13:00",
+				},
+			},
+		},
+	}
+
+	paris, err := time.LoadLocation(model.BusinessHoursTimezone)
+	require.NoError(t, err)
+
+	// Le 17 octobre 2026 est un samedi
+	require.True(t, market.IsOpenAt(time.Date(2026, 10, 17, 8, 0, 0, 0, paris)))
+	require.True(t, market.IsOpenAt(time.Date(2026, 10, 17, 13, 0, 0, 0, paris)))
+	require.False(t, market.IsOpenAt(time.Date(2026, 10, 17, 13, 1, 0, 0, paris)))
+	require.False(t, market.IsOpenAt(time.Date(2026, 10, 18, 10, 0, 0, 0, paris)))
+
+	// Une date envoyée en UTC est lue à l'heure française, UTC+2 en été
+	require.True(t, market.IsOpenAt(time.Date(2026, 10, 17, 6, 30, 0, 0, time.UTC)))
+	require.False(t, market.IsOpenAt(time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)))
+
+	// Le samedi à 23h30 UTC est déjà dimanche en France
+	sundayMarket := Market{
+		Schedule: model.BusinessHours{
+			Sunday: []*model.Schedule{
+				{
+					Opening: "00:00",
+					Closing: "02:00",
+				},
+			},
+		},
+	}
+
+	require.True(t, sundayMarket.IsOpenAt(time.Date(2026, 10, 17, 23, 30, 0, 0, time.UTC)))
+}
@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	"time"
+
+	"chemin-du-local.bzh/graphql/internal/database"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Une migration des données déjà appliquée, identifiée par son nom
+type migration struct {
+	Name      string    `bson:"_id"`
+	AppliedAt time.Time `bson:"appliedAt"`
+}
+
+// Applique la migration si elle ne l'a pas encore été. Elle n'est notée
+// comme appliquée que si elle a réussi, et sera donc retentée au prochain
+// démarrage en cas d'erreur.
+func Run(name string, apply func() error) error {
+	count, err := database.CollectionMigrations.CountDocuments(database.MongoContext, bson.M{"_id": name})
+
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return nil
+	}
+
+	err = apply()
+
+	if err != nil {
+		return err
+	}
+
+	_, err = database.CollectionMigrations.InsertOne(database.MongoContext, migration{
+		Name:      name,
+		AppliedAt: time.Now(),
+	})
+
+	return err
+}
@@ -133,6 +133,29 @@ func (_m *CCCommandsService) GetProducts(cccommandID string) ([]*model.CCProduct
 	return r0, r1
 }
 
+// SetActualQuantities provides a mock function with given fields: commerceCommandID, weights
+func (_m *CCCommandsService) SetActualQuantities(commerceCommandID string, weights []*model.ActualWeightInput) ([]commands.CCCommand, error) {
+	ret := _m.Called(commerceCommandID, weights)
+
+	var r0 []commands.CCCommand
+	if rf, ok := ret.Get(0).(func(string, []*model.ActualWeightInput) []commands.CCCommand); ok {
+		r0 = rf(commerceCommandID, weights)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]commands.CCCommand)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, []*model.ActualWeightInput) error); ok {
+		r1 = rf(commerceCommandID, weights)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 type mockConstructorTestingTNewCCCommandsService interface {
 	mock.TestingT
 	Cleanup(func())
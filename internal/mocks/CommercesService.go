@@ -12,6 +12,8 @@ import (
 
 	options "go.mongodb.org/mongo-driver/mongo/options"
 
+	geojson "chemin-du-local.bzh/graphql/pkg/geojson"
+
 	primitive "go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -20,6 +22,34 @@ type CommercesService struct {
 	mock.Mock
 }
 
+// ChangeSlug provides a mock function with given fields: commerce, slug
+func (_m *CommercesService) ChangeSlug(commerce *commerces.Commerce, slug string) error {
+	ret := _m.Called(commerce, slug)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*commerces.Commerce, string) error); ok {
+		r0 = rf(commerce, slug)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Close provides a mock function with given fields: commerce
+func (_m *CommercesService) Close(commerce *commerces.Commerce) error {
+	ret := _m.Called(commerce)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*commerces.Commerce) error); ok {
+		r0 = rf(commerce)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Create provides a mock function with given fields: input, storekeeperID
 func (_m *CommercesService) Create(input model.NewCommerce, storekeeperID primitive.ObjectID) (*commerces.Commerce, error) {
 	ret := _m.Called(input, storekeeperID)
@@ -43,6 +73,80 @@ func (_m *CommercesService) Create(input model.NewCommerce, storekeeperID primit
 	return r0, r1
 }
 
+// FixSwappedCoordinates provides a mock function with given fields:
+func (_m *CommercesService) FixSwappedCoordinates() error {
+	ret := _m.Called()
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GenerateMissingSlugs provides a mock function with given fields:
+func (_m *CommercesService) GenerateMissingSlugs() error {
+	ret := _m.Called()
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetActive provides a mock function with given fields: postalCode, box
+func (_m *CommercesService) GetActive(postalCode *string, box *geojson.BoundingBox) ([]commerces.Commerce, error) {
+	ret := _m.Called(postalCode, box)
+
+	var r0 []commerces.Commerce
+	if rf, ok := ret.Get(0).(func(*string, *geojson.BoundingBox) []commerces.Commerce); ok {
+		r0 = rf(postalCode, box)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]commerces.Commerce)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*string, *geojson.BoundingBox) error); ok {
+		r1 = rf(postalCode, box)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetActiveNear provides a mock function with given fields: latitude, longitude, radius
+func (_m *CommercesService) GetActiveNear(latitude float64, longitude float64, radius *float64) ([]commerces.Commerce, error) {
+	ret := _m.Called(latitude, longitude, radius)
+
+	var r0 []commerces.Commerce
+	if rf, ok := ret.Get(0).(func(float64, float64, *float64) []commerces.Commerce); ok {
+		r0 = rf(latitude, longitude, radius)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]commerces.Commerce)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(float64, float64, *float64) error); ok {
+		r1 = rf(latitude, longitude, radius)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetAll provides a mock function with given fields:
 func (_m *CommercesService) GetAll() ([]commerces.Commerce, error) {
 	ret := _m.Called()
@@ -89,6 +193,29 @@ func (_m *CommercesService) GetById(id string) (*commerces.Commerce, error) {
 	return r0, r1
 }
 
+// GetBySlug provides a mock function with given fields: slug
+func (_m *CommercesService) GetBySlug(slug string) (*commerces.Commerce, error) {
+	ret := _m.Called(slug)
+
+	var r0 *commerces.Commerce
+	if rf, ok := ret.Get(0).(func(string) *commerces.Commerce); ok {
+		r0 = rf(slug)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*commerces.Commerce)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(slug)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetFiltered provides a mock function with given fields: filter, opts
 func (_m *CommercesService) GetFiltered(filter interface{}, opts *options.FindOptions) ([]commerces.Commerce, error) {
 	ret := _m.Called(filter, opts)
@@ -135,6 +262,38 @@ func (_m *CommercesService) GetForUser(userID string) (*commerces.Commerce, erro
 	return r0, r1
 }
 
+// GetInBounds provides a mock function with given fields: box, zoom
+func (_m *CommercesService) GetInBounds(box geojson.BoundingBox, zoom int) ([]commerces.Commerce, []commerces.CommerceCluster, error) {
+	ret := _m.Called(box, zoom)
+
+	var r0 []commerces.Commerce
+	if rf, ok := ret.Get(0).(func(geojson.BoundingBox, int) []commerces.Commerce); ok {
+		r0 = rf(box, zoom)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]commerces.Commerce)
+		}
+	}
+
+	var r1 []commerces.CommerceCluster
+	if rf, ok := ret.Get(1).(func(geojson.BoundingBox, int) []commerces.CommerceCluster); ok {
+		r1 = rf(box, zoom)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]commerces.CommerceCluster)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(geojson.BoundingBox, int) error); ok {
+		r2 = rf(box, zoom)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetPaginated provides a mock function with given fields: startValue, first, filter
 func (_m *CommercesService) GetPaginated(startValue *string, first int, filter *model.CommerceFilter) ([]commerces.Commerce, int, error) {
 	ret := _m.Called(startValue, first, filter)
@@ -165,6 +324,20 @@ func (_m *CommercesService) GetPaginated(startValue *string, first int, filter *
 	return r0, r1, r2
 }
 
+// LocateAddresses provides a mock function with given fields:
+func (_m *CommercesService) LocateAddresses() error {
+	ret := _m.Called()
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Update provides a mock function with given fields: changes, image, profilePicture
 func (_m *CommercesService) Update(changes *commerces.Commerce, image *graphql.Upload, profilePicture *graphql.Upload) error {
 	ret := _m.Called(changes, image, profilePicture)
@@ -179,13 +352,13 @@ func (_m *CommercesService) Update(changes *commerces.Commerce, image *graphql.U
 	return r0
 }
 
-// UpdateBalancesForOrder provides a mock function with given fields: commerceID, price, priceClickAndCollect, pricePaniers
-func (_m *CommercesService) UpdateBalancesForOrder(commerceID string, price int, priceClickAndCollect float64, pricePaniers float64) error {
-	ret := _m.Called(commerceID, price, priceClickAndCollect, pricePaniers)
+// UpdateBalancesForOrder provides a mock function with given fields: commerceID, price, priceClickAndCollect, pricePaniers, platformFundedAmount
+func (_m *CommercesService) UpdateBalancesForOrder(commerceID string, price int, priceClickAndCollect float64, pricePaniers float64, platformFundedAmount float64) error {
+	ret := _m.Called(commerceID, price, priceClickAndCollect, pricePaniers, platformFundedAmount)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, int, float64, float64) error); ok {
-		r0 = rf(commerceID, price, priceClickAndCollect, pricePaniers)
+	if rf, ok := ret.Get(0).(func(string, int, float64, float64, float64) error); ok {
+		r0 = rf(commerceID, price, priceClickAndCollect, pricePaniers, platformFundedAmount)
 	} else {
 		r0 = ret.Error(0)
 	}
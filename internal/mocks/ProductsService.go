@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	time "time"
+
 	graphql "github.com/99designs/gqlgen/graphql"
 	mock "github.com/stretchr/testify/mock"
 
@@ -10,6 +12,8 @@ import (
 
 	options "go.mongodb.org/mongo-driver/mongo/options"
 
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+
 	products "chemin-du-local.bzh/graphql/internal/products"
 )
 
@@ -41,6 +45,89 @@ func (_m *ProductsService) Create(commerceID string, input model.NewProduct) (*p
 	return r0, r1
 }
 
+// CreateVariant provides a mock function with given fields: product, input
+func (_m *ProductsService) CreateVariant(product *products.Product, input model.NewProductVariant) (*products.ProductVariant, error) {
+	ret := _m.Called(product, input)
+
+	var r0 *products.ProductVariant
+	if rf, ok := ret.Get(0).(func(*products.Product, model.NewProductVariant) *products.ProductVariant); ok {
+		r0 = rf(product, input)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*products.ProductVariant)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*products.Product, model.NewProductVariant) error); ok {
+		r1 = rf(product, input)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Delete provides a mock function with given fields: product
+func (_m *ProductsService) Delete(product *products.Product) error {
+	ret := _m.Called(product)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*products.Product) error); ok {
+		r0 = rf(product)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteVariant provides a mock function with given fields: product, variantID
+func (_m *ProductsService) DeleteVariant(product *products.Product, variantID primitive.ObjectID) (*products.ProductVariant, error) {
+	ret := _m.Called(product, variantID)
+
+	var r0 *products.ProductVariant
+	if rf, ok := ret.Get(0).(func(*products.Product, primitive.ObjectID) *products.ProductVariant); ok {
+		r0 = rf(product, variantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*products.ProductVariant)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*products.Product, primitive.ObjectID) error); ok {
+		r1 = rf(product, variantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Export provides a mock function with given fields: commerceID, format
+func (_m *ProductsService) Export(commerceID primitive.ObjectID, format model.CatalogueFormat) ([]byte, error) {
+	ret := _m.Called(commerceID, format)
+
+	var r0 []byte
+	if rf, ok := ret.Get(0).(func(primitive.ObjectID, model.CatalogueFormat) []byte); ok {
+		r0 = rf(commerceID, format)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(primitive.ObjectID, model.CatalogueFormat) error); ok {
+		r1 = rf(commerceID, format)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetById provides a mock function with given fields: id
 func (_m *ProductsService) GetById(id string) (*products.Product, error) {
 	ret := _m.Called(id)
@@ -64,6 +151,29 @@ func (_m *ProductsService) GetById(id string) (*products.Product, error) {
 	return r0, r1
 }
 
+// GetCategoriesForCommerces provides a mock function with given fields: commerceIDs
+func (_m *ProductsService) GetCategoriesForCommerces(commerceIDs []primitive.ObjectID) (map[primitive.ObjectID][]string, error) {
+	ret := _m.Called(commerceIDs)
+
+	var r0 map[primitive.ObjectID][]string
+	if rf, ok := ret.Get(0).(func([]primitive.ObjectID) map[primitive.ObjectID][]string); ok {
+		r0 = rf(commerceIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[primitive.ObjectID][]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func([]primitive.ObjectID) error); ok {
+		r1 = rf(commerceIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetFiltered provides a mock function with given fields: filter, opts
 func (_m *ProductsService) GetFiltered(filter interface{}, opts *options.FindOptions) ([]products.Product, error) {
 	ret := _m.Called(filter, opts)
@@ -133,13 +243,59 @@ func (_m *ProductsService) GetPaginated(commerceID string, startValue *string, f
 	return r0, r1
 }
 
-// Update provides a mock function with given fields: changes, image
-func (_m *ProductsService) Update(changes *products.Product, image *graphql.Upload) error {
-	ret := _m.Called(changes, image)
+// Import provides a mock function with given fields: commerceID, rows, mapping, dryRun, userID
+func (_m *ProductsService) Import(commerceID primitive.ObjectID, rows [][]string, mapping []*model.ProductColumnMapping, dryRun bool, userID primitive.ObjectID) (*products.ProductImportReport, error) {
+	ret := _m.Called(commerceID, rows, mapping, dryRun, userID)
+
+	var r0 *products.ProductImportReport
+	if rf, ok := ret.Get(0).(func(primitive.ObjectID, [][]string, []*model.ProductColumnMapping, bool, primitive.ObjectID) *products.ProductImportReport); ok {
+		r0 = rf(commerceID, rows, mapping, dryRun, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*products.ProductImportReport)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(primitive.ObjectID, [][]string, []*model.ProductColumnMapping, bool, primitive.ObjectID) error); ok {
+		r1 = rf(commerceID, rows, mapping, dryRun, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Search provides a mock function with given fields: commerceIDs, filter, startValue, first
+func (_m *ProductsService) Search(commerceIDs []primitive.ObjectID, filter *model.ProductSearchFilter, startValue *string, first int) (*products.ProductSearchResult, error) {
+	ret := _m.Called(commerceIDs, filter, startValue, first)
+
+	var r0 *products.ProductSearchResult
+	if rf, ok := ret.Get(0).(func([]primitive.ObjectID, *model.ProductSearchFilter, *string, int) *products.ProductSearchResult); ok {
+		r0 = rf(commerceIDs, filter, startValue, first)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*products.ProductSearchResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func([]primitive.ObjectID, *model.ProductSearchFilter, *string, int) error); ok {
+		r1 = rf(commerceIDs, filter, startValue, first)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetSeasons provides a mock function with given fields: product, inputs
+func (_m *ProductsService) SetSeasons(product *products.Product, inputs []*model.ProductSeasonInput) error {
+	ret := _m.Called(product, inputs)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*products.Product, *graphql.Upload) error); ok {
-		r0 = rf(changes, image)
+	if rf, ok := ret.Get(0).(func(*products.Product, []*model.ProductSeasonInput) error); ok {
+		r0 = rf(product, inputs)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -147,6 +303,57 @@ func (_m *ProductsService) Update(changes *products.Product, image *graphql.Uplo
 	return r0
 }
 
+// SetStatus provides a mock function with given fields: product, status, publishAt, unpublishAt
+func (_m *ProductsService) SetStatus(product *products.Product, status model.ProductStatus, publishAt *time.Time, unpublishAt *time.Time) error {
+	ret := _m.Called(product, status, publishAt, unpublishAt)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*products.Product, model.ProductStatus, *time.Time, *time.Time) error); ok {
+		r0 = rf(product, status, publishAt, unpublishAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: changes, image, userID
+func (_m *ProductsService) Update(changes *products.Product, image *graphql.Upload, userID primitive.ObjectID) error {
+	ret := _m.Called(changes, image, userID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*products.Product, *graphql.Upload, primitive.ObjectID) error); ok {
+		r0 = rf(changes, image, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateVariant provides a mock function with given fields: product, variantID, changes
+func (_m *ProductsService) UpdateVariant(product *products.Product, variantID primitive.ObjectID, changes model.ChangesProductVariant) (*products.ProductVariant, error) {
+	ret := _m.Called(product, variantID, changes)
+
+	var r0 *products.ProductVariant
+	if rf, ok := ret.Get(0).(func(*products.Product, primitive.ObjectID, model.ChangesProductVariant) *products.ProductVariant); ok {
+		r0 = rf(product, variantID, changes)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*products.ProductVariant)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*products.Product, primitive.ObjectID, model.ChangesProductVariant) error); ok {
+		r1 = rf(product, variantID, changes)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 type mockConstructorTestingTNewProductsService interface {
 	mock.TestingT
 	Cleanup(func())
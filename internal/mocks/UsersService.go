@@ -143,6 +143,20 @@ func (_m *UsersService) GetUserById(id string) (*users.User, error) {
 	return r0, r1
 }
 
+// LocateAddresses provides a mock function with given fields:
+func (_m *UsersService) LocateAddresses() error {
+	ret := _m.Called()
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Update provides a mock function with given fields: changes
 func (_m *UsersService) Update(changes *users.User) error {
 	ret := _m.Called(changes)
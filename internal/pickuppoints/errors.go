@@ -0,0 +1,24 @@
+package pickuppoints
+
+type PickupPointNotFoundError struct{}
+type NoDaysError struct{}
+type PickupPointUnavailableError struct {
+	CommerceName string
+}
+type PickupPointFulfilmentError struct{}
+
+func (m *PickupPointNotFoundError) Error() string {
+	return "le point relais n'a pas été trouvé"
+}
+
+func (m *NoDaysError) Error() string {
+	return "au moins un jour de dépôt doit être choisi"
+}
+
+func (m *PickupPointUnavailableError) Error() string {
+	return "le commerce " + m.CommerceName + " ne dépose pas de commandes au point relais à la date choisie"
+}
+
+func (m *PickupPointFulfilmentError) Error() string {
+	return "une commande déposée en point relais ne peut pas être livrée ni retirée sur un marché"
+}
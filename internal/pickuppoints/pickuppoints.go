@@ -0,0 +1,338 @@
+package pickuppoints
+
+import (
+	"strings"
+	"time"
+
+	"chemin-du-local.bzh/graphql/graph/model"
+	"chemin-du-local.bzh/graphql/internal/address"
+	"chemin-du-local.bzh/graphql/internal/database"
+	"chemin-du-local.bzh/graphql/pkg/geojson"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Rayon de recherche par défaut des points relais, en mètres
+const defaultRadius = 20000.0
+
+type PickupPoint struct {
+	ID           primitive.ObjectID    `bson:"_id"`
+	Name         string                `bson:"name"`
+	Address      address.Address       `bson:"address"`
+	AddressGeo   geojson.GeoJSON       `bson:"addressGeo"`
+	OpeningHours model.BusinessHours   `bson:"openingHours"`
+	ManagerID    *primitive.ObjectID   `bson:"managerID"`
+	Commerces    []PickupPointCommerce `bson:"commerces"`
+}
+
+type PickupPointCommerce struct {
+	CommerceID primitive.ObjectID `bson:"commerceID"`
+	Days       []string           `bson:"days"`
+}
+
+func (pickupPoint *PickupPoint) ToModel() *model.PickupPoint {
+	var managerID *string
+
+	if pickupPoint.ManagerID != nil {
+		managerIDValue := pickupPoint.ManagerID.Hex()
+		managerID = &managerIDValue
+	}
+
+	commerces := []*model.PickupPointCommerce{}
+
+	for _, commerce := range pickupPoint.Commerces {
+		days := []model.Weekday{}
+
+		for _, day := range commerce.Days {
+			days = append(days, model.Weekday(day))
+		}
+
+		commerces = append(commerces, &model.PickupPointCommerce{
+			CommerceID: commerce.CommerceID.Hex(),
+			Days:       days,
+		})
+	}
+
+	return &model.PickupPoint{
+		ID:           pickupPoint.ID.Hex(),
+		Name:         pickupPoint.Name,
+		Address:      *pickupPoint.Address.ToModel(),
+		Latitude:     pickupPoint.AddressGeo.Coordinates[1],
+		Longitude:    pickupPoint.AddressGeo.Coordinates[0],
+		OpeningHours: pickupPoint.OpeningHours,
+		ManagerID:    managerID,
+		Commerces:    commerces,
+	}
+}
+
+// Indique si le commerce dépose ses commandes au point relais le jour
+// de la date donnée, et si le point relais est alors ouvert
+func (pickupPoint *PickupPoint) AcceptsCommerceAt(commerceID primitive.ObjectID, date time.Time) bool {
+	if !pickupPoint.OpeningHours.IsOpenAt(date) {
+		return false
+	}
+
+	weekday := strings.ToUpper(date.Weekday().String())
+
+	for _, commerce := range pickupPoint.Commerces {
+		if commerce.CommerceID != commerceID {
+			continue
+		}
+
+		for _, day := range commerce.Days {
+			if day == weekday {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Service
+
+type pickupPointsService struct{}
+
+type PickupPointsService interface {
+	Create(input model.NewPickupPoint) (*PickupPoint, error)
+	Update(changes *PickupPoint) error
+	SetCommerce(pickupPointID string, commerceID primitive.ObjectID, days []model.Weekday) (*PickupPoint, error)
+	RemoveCommerce(pickupPointID string, commerceID primitive.ObjectID) (*PickupPoint, error)
+	GetById(id string) (*PickupPoint, error)
+	GetForManager(managerID string) (*PickupPoint, error)
+	GetNear(latitude float64, longitude float64, radius *float64) ([]PickupPoint, error)
+	GetFiltered(filter interface{}, opts *options.FindOptions) ([]PickupPoint, error)
+}
+
+func NewPickupPointsService() *pickupPointsService {
+	return &pickupPointsService{}
+}
+
+// Créateur de base de données
+
+func (p *pickupPointsService) Create(input model.NewPickupPoint) (*PickupPoint, error) {
+	databasePickupPoint := PickupPoint{
+		ID:           primitive.NewObjectID(),
+		Name:         input.Name,
+		Address:      address.FromInput(input.Address),
+		AddressGeo:   geojson.NewPoint(input.Longitude, input.Latitude),
+		OpeningHours: *input.OpeningHours.ToModel(),
+		Commerces:    []PickupPointCommerce{},
+	}
+
+	if input.ManagerID != nil {
+		managerID, err := primitive.ObjectIDFromHex(*input.ManagerID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		databasePickupPoint.ManagerID = &managerID
+	}
+
+	_, err := database.CollectionPickupPoints.InsertOne(database.MongoContext, databasePickupPoint)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &databasePickupPoint, nil
+}
+
+// Mise à jour de la base de données
+
+func (p *pickupPointsService) Update(changes *PickupPoint) error {
+	filter := bson.D{
+		primitive.E{
+			Key:   "_id",
+			Value: changes.ID,
+		},
+	}
+
+	_, err := database.CollectionPickupPoints.ReplaceOne(database.MongoContext, filter, changes)
+
+	return err
+}
+
+// Ajoute le commerce au point relais, ou met à jour ses jours de dépôt
+// s'il y est déjà
+func (p *pickupPointsService) SetCommerce(pickupPointID string, commerceID primitive.ObjectID, days []model.Weekday) (*PickupPoint, error) {
+	databasePickupPoint, err := p.GetById(pickupPointID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if databasePickupPoint == nil {
+		return nil, &PickupPointNotFoundError{}
+	}
+
+	if len(days) == 0 {
+		return nil, &NoDaysError{}
+	}
+
+	daysString := []string{}
+
+	for _, day := range days {
+		daysString = append(daysString, day.String())
+	}
+
+	found := false
+
+	for index, commerce := range databasePickupPoint.Commerces {
+		if commerce.CommerceID == commerceID {
+			databasePickupPoint.Commerces[index].Days = daysString
+			found = true
+		}
+	}
+
+	if !found {
+		databasePickupPoint.Commerces = append(databasePickupPoint.Commerces, PickupPointCommerce{
+			CommerceID: commerceID,
+			Days:       daysString,
+		})
+	}
+
+	err = p.Update(databasePickupPoint)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return databasePickupPoint, nil
+}
+
+func (p *pickupPointsService) RemoveCommerce(pickupPointID string, commerceID primitive.ObjectID) (*PickupPoint, error) {
+	databasePickupPoint, err := p.GetById(pickupPointID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if databasePickupPoint == nil {
+		return nil, &PickupPointNotFoundError{}
+	}
+
+	commerces := []PickupPointCommerce{}
+
+	for _, commerce := range databasePickupPoint.Commerces {
+		if commerce.CommerceID != commerceID {
+			commerces = append(commerces, commerce)
+		}
+	}
+
+	databasePickupPoint.Commerces = commerces
+
+	err = p.Update(databasePickupPoint)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return databasePickupPoint, nil
+}
+
+// Getter de base de données
+
+func (p *pickupPointsService) GetById(id string) (*PickupPoint, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.D{
+		primitive.E{
+			Key:   "_id",
+			Value: objectID,
+		},
+	}
+
+	pickupPoints, err := p.GetFiltered(filter, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pickupPoints) == 0 {
+		return nil, nil
+	}
+
+	return &pickupPoints[0], nil
+}
+
+func (p *pickupPointsService) GetForManager(managerID string) (*PickupPoint, error) {
+	managerObjectID, err := primitive.ObjectIDFromHex(managerID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.D{
+		primitive.E{
+			Key:   "managerID",
+			Value: managerObjectID,
+		},
+	}
+
+	pickupPoints, err := p.GetFiltered(filter, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pickupPoints) == 0 {
+		return nil, nil
+	}
+
+	return &pickupPoints[0], nil
+}
+
+// Les points relais autour d'un point, du plus proche au plus éloigné
+func (p *pickupPointsService) GetNear(latitude float64, longitude float64, radius *float64) ([]PickupPoint, error) {
+	maxDistance := defaultRadius
+
+	if radius != nil {
+		maxDistance = *radius
+	}
+
+	filter := bson.M{
+		"addressGeo": bson.M{
+			"$near": bson.M{
+				"$geometry":    geojson.NewPoint(longitude, latitude),
+				"$maxDistance": maxDistance,
+			},
+		},
+	}
+
+	return p.GetFiltered(filter, nil)
+}
+
+func (p *pickupPointsService) GetFiltered(filter interface{}, opts *options.FindOptions) ([]PickupPoint, error) {
+	pickupPoints := []PickupPoint{}
+
+	cursor, err := database.CollectionPickupPoints.Find(database.MongoContext, filter, opts)
+
+	if err != nil {
+		return pickupPoints, err
+	}
+
+	for cursor.Next(database.MongoContext) {
+		var pickupPoint PickupPoint
+
+		err := cursor.Decode(&pickupPoint)
+
+		if err != nil {
+			return pickupPoints, err
+		}
+
+		pickupPoints = append(pickupPoints, pickupPoint)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return pickupPoints, err
+	}
+
+	return pickupPoints, nil
+}
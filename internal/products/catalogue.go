@@ -0,0 +1,628 @@
+package products
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"log"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"chemin-du-local.bzh/graphql/graph/model"
+	"chemin-du-local.bzh/graphql/internal/database"
+	"chemin-du-local.bzh/graphql/pkg/xlsx"
+	"github.com/99designs/gqlgen/graphql"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Import et export du catalogue d'un commerce sous forme de tableau. Les
+// en-têtes par défaut sont ceux de l'export, ce qui permet de réimporter
+// un fichier exporté puis modifié.
+
+// Séparateur des listes (tags, allergènes, catégories) dans une cellule
+const catalogueListSeparator = "|"
+
+// Un tableur interprète comme une formule une cellule CSV commençant par
+// l'un de ces caractères
+const catalogueFormulaPrefixes = "=+-@\t\r"
+
+// Les colonnes dans l'ordre de l'export
+var catalogueFields = []model.ProductCatalogueField{
+	model.ProductCatalogueFieldExternalRef,
+	model.ProductCatalogueFieldName,
+	model.ProductCatalogueFieldDescription,
+	model.ProductCatalogueFieldPrice,
+	model.ProductCatalogueFieldUnit,
+	model.ProductCatalogueFieldPerUnitQuantity,
+	model.ProductCatalogueFieldPerUnitQuantityUnit,
+	model.ProductCatalogueFieldTva,
+	model.ProductCatalogueFieldIsBreton,
+	model.ProductCatalogueFieldHasGluten,
+	model.ProductCatalogueFieldTags,
+	model.ProductCatalogueFieldAllergens,
+	model.ProductCatalogueFieldCategories,
+}
+
+var catalogueHeaders = map[model.ProductCatalogueField]string{
+	model.ProductCatalogueFieldExternalRef:         "externalRef",
+	model.ProductCatalogueFieldName:                "name",
+	model.ProductCatalogueFieldDescription:         "description",
+	model.ProductCatalogueFieldPrice:               "price",
+	model.ProductCatalogueFieldUnit:                "unit",
+	model.ProductCatalogueFieldPerUnitQuantity:     "perUnitQuantity",
+	model.ProductCatalogueFieldPerUnitQuantityUnit: "perUnitQuantityUnit",
+	model.ProductCatalogueFieldTva:                 "tva",
+	model.ProductCatalogueFieldIsBreton:            "isBreton",
+	model.ProductCatalogueFieldHasGluten:           "hasGluten",
+	model.ProductCatalogueFieldTags:                "tags",
+	model.ProductCatalogueFieldAllergens:           "allergens",
+	model.ProductCatalogueFieldCategories:          "categories",
+}
+
+type ProductImportError struct {
+	Line    int
+	Column  *string
+	Message string
+}
+
+type ProductImportReport struct {
+	DryRun    bool
+	LineCount int
+	Created   int
+	Updated   int
+	Errors    []ProductImportError
+}
+
+func (report *ProductImportReport) ToModel() *model.ProductImportReport {
+	errors := []*model.ProductImportError{}
+
+	for _, importError := range report.Errors {
+		errors = append(errors, &model.ProductImportError{
+			Line:    importError.Line,
+			Column:  importError.Column,
+			Message: importError.Message,
+		})
+	}
+
+	return &model.ProductImportReport{
+		DryRun:    report.DryRun,
+		LineCount: report.LineCount,
+		Created:   report.Created,
+		Updated:   report.Updated,
+		Errors:    errors,
+	}
+}
+
+func (report *ProductImportReport) addError(line int, column string, message string) {
+	var columnValue *string
+
+	if column != "" {
+		columnValue = &column
+	}
+
+	report.Errors = append(report.Errors, ProductImportError{
+		Line:    line,
+		Column:  columnValue,
+		Message: message,
+	})
+}
+
+// Une ligne valide du fichier, à créer ou à mettre à jour
+type catalogueLine struct {
+	product Product
+	// Les colonnes renseignées, seules modifiées lors d'une mise à jour
+	fields map[model.ProductCatalogueField]bool
+}
+
+// Lecture des fichiers
+
+// Renvoie les lignes d'un fichier CSV ou Excel, selon son extension
+func ReadCatalogueFile(file graphql.Upload) ([][]string, error) {
+	data, err := io.ReadAll(file.File)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.ToLower(filepath.Ext(file.Filename)) == ".xlsx" {
+		return xlsx.ReadRows(data)
+	}
+
+	// Les tableurs français séparent les colonnes par des points-virgules
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	firstLine := string(data)
+
+	if index := strings.IndexByte(firstLine, '\n'); index >= 0 {
+		firstLine = firstLine[:index]
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+
+	if strings.Count(firstLine, ";") > strings.Count(firstLine, ",") {
+		reader.Comma = ';'
+	}
+
+	rows, err := reader.ReadAll()
+
+	if err != nil {
+		return nil, &InvalidCatalogueFileError{}
+	}
+
+	for _, row := range rows {
+		for index := range row {
+			row[index] = unescapeCatalogueCell(row[index])
+		}
+	}
+
+	return rows, nil
+}
+
+// Neutralise une formule par une apostrophe, retirée à l'import pour
+// qu'un fichier exporté puisse être réimporté tel quel
+func escapeCatalogueCell(value string) string {
+	if value != "" && strings.ContainsRune(catalogueFormulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+
+	return value
+}
+
+func unescapeCatalogueCell(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune(catalogueFormulaPrefixes, rune(value[1])) {
+		return value[1:]
+	}
+
+	return value
+}
+
+// Import
+
+// Valide toutes les lignes avant d'écrire quoi que ce soit : tant qu'une
+// ligne est invalide, ou en simulation, rien n'est importé
+func (p *productsService) Import(commerceID primitive.ObjectID, rows [][]string, mapping []*model.ProductColumnMapping, dryRun bool, userID primitive.ObjectID) (*ProductImportReport, error) {
+	report := ProductImportReport{
+		DryRun: dryRun,
+		Errors: []ProductImportError{},
+	}
+
+	if len(rows) == 0 {
+		report.addError(1, "", "le fichier est vide")
+		return &report, nil
+	}
+
+	columns, err := catalogueColumns(rows[0], mapping)
+
+	if err != nil {
+		report.addError(1, "", err.Error())
+		return &report, nil
+	}
+
+	existingProducts, err := p.GetFiltered(bson.M{"commerceID": commerceID}, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	productsByRef := map[string]*Product{}
+
+	for i := range existingProducts {
+		productsByRef[existingProducts[i].catalogueRef()] = &existingProducts[i]
+	}
+
+	lines := []catalogueLine{}
+	seenRefs := map[string]int{}
+
+	for index, row := range rows[1:] {
+		lineNumber := index + 2
+
+		if isEmptyRow(row) {
+			continue
+		}
+
+		report.LineCount++
+
+		line, lineErrors := parseCatalogueLine(lineNumber, row, rows[0], columns)
+
+		if len(lineErrors) > 0 {
+			report.Errors = append(report.Errors, lineErrors...)
+			continue
+		}
+
+		ref := *line.product.ExternalRef
+
+		if firstLine, ok := seenRefs[ref]; ok {
+			report.addError(lineNumber, catalogueHeaders[model.ProductCatalogueFieldExternalRef], "la référence est déjà utilisée à la ligne "+strconv.Itoa(firstLine))
+			continue
+		}
+
+		seenRefs[ref] = lineNumber
+
+		if productsByRef[ref] == nil {
+			if missing := missingRequiredField(line); missing != "" {
+				report.addError(lineNumber, missing, "cette colonne est obligatoire pour un nouveau produit")
+				continue
+			}
+
+			report.Created++
+		} else {
+			report.Updated++
+		}
+
+		lines = append(lines, line)
+	}
+
+	if dryRun || len(report.Errors) > 0 {
+		return &report, nil
+	}
+
+	err = writeCatalogueLines(commerceID, lines, productsByRef, userID, time.Now())
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
+
+// Les produits sont tous préparés avant d'être écrits en une seule
+// opération. Sans transaction possible, un échec en cours d'écriture
+// est annulé en remettant les produits dans leur état précédent.
+func writeCatalogueLines(commerceID primitive.ObjectID, lines []catalogueLine, productsByRef map[string]*Product, userID primitive.ObjectID, now time.Time) error {
+	writes := []mongo.WriteModel{}
+	createdIDs := []primitive.ObjectID{}
+	previousProducts := []Product{}
+
+	for _, line := range lines {
+		existingProduct := productsByRef[*line.product.ExternalRef]
+
+		if existingProduct == nil {
+			product, err := newProduct(commerceID, line.product.toNewProduct())
+
+			if err != nil {
+				return err
+			}
+
+			createdIDs = append(createdIDs, product.ID)
+			writes = append(writes, mongo.NewInsertOneModel().SetDocument(product))
+
+			continue
+		}
+
+		previousProducts = append(previousProducts, *existingProduct)
+
+		existingProduct.ExternalRef = line.product.ExternalRef
+		applyCatalogueLine(existingProduct, line)
+		existingProduct.recordPriceChange(previousProducts[len(previousProducts)-1].Price, userID, now)
+
+		document, err := updateDocument(existingProduct)
+
+		if err != nil {
+			return err
+		}
+
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": existingProduct.ID}).
+			SetUpdate(bson.M{"$set": document}))
+	}
+
+	if len(writes) == 0 {
+		return nil
+	}
+
+	_, err := database.CollectionProducts.BulkWrite(database.MongoContext, writes)
+
+	if err != nil {
+		rollbackCatalogueLines(createdIDs, previousProducts)
+
+		return err
+	}
+
+	return nil
+}
+
+func rollbackCatalogueLines(createdIDs []primitive.ObjectID, previousProducts []Product) {
+	_, err := database.CollectionProducts.DeleteMany(database.MongoContext, bson.M{"_id": bson.M{"$in": createdIDs}})
+
+	if err != nil {
+		log.Printf("rollbackCatalogueLines: %v", err)
+	}
+
+	for i := range previousProducts {
+		document, err := updateDocument(&previousProducts[i])
+
+		if err == nil {
+			_, err = database.CollectionProducts.UpdateOne(database.MongoContext, bson.M{"_id": previousProducts[i].ID}, bson.M{"$set": document})
+		}
+
+		if err != nil {
+			log.Printf("rollbackCatalogueLines: %v", err)
+		}
+	}
+}
+
+// Associe chaque colonne du fichier à un champ. Sans correspondance
+// donnée, les en-têtes de l'export sont utilisés.
+func catalogueColumns(header []string, mapping []*model.ProductColumnMapping) (map[int]model.ProductCatalogueField, error) {
+	fieldsByHeader := map[string]model.ProductCatalogueField{}
+
+	if len(mapping) == 0 {
+		for field, name := range catalogueHeaders {
+			fieldsByHeader[strings.ToLower(name)] = field
+		}
+	} else {
+		for _, columnMapping := range mapping {
+			fieldsByHeader[strings.ToLower(strings.TrimSpace(columnMapping.Column))] = columnMapping.Field
+		}
+	}
+
+	columns := map[int]model.ProductCatalogueField{}
+	hasRef := false
+
+	for index, name := range header {
+		field, ok := fieldsByHeader[strings.ToLower(strings.TrimSpace(name))]
+
+		if !ok {
+			continue
+		}
+
+		columns[index] = field
+		hasRef = hasRef || field == model.ProductCatalogueFieldExternalRef
+	}
+
+	if !hasRef {
+		return nil, &MissingExternalRefColumnError{}
+	}
+
+	return columns, nil
+}
+
+func parseCatalogueLine(lineNumber int, row []string, header []string, columns map[int]model.ProductCatalogueField) (catalogueLine, []ProductImportError) {
+	line := catalogueLine{
+		fields: map[model.ProductCatalogueField]bool{},
+	}
+	report := ProductImportReport{}
+
+	for index, field := range columns {
+		if index >= len(row) {
+			continue
+		}
+
+		value := strings.TrimSpace(row[index])
+
+		// Une cellule vide ne modifie pas le produit existant
+		if value == "" {
+			continue
+		}
+
+		err := setCatalogueField(&line.product, field, value)
+
+		if err != nil {
+			report.addError(lineNumber, header[index], err.Error())
+			continue
+		}
+
+		line.fields[field] = true
+	}
+
+	if !line.fields[model.ProductCatalogueFieldExternalRef] && len(report.Errors) == 0 {
+		report.addError(lineNumber, catalogueHeaders[model.ProductCatalogueFieldExternalRef], "la référence est obligatoire")
+	}
+
+	return line, report.Errors
+}
+
+func setCatalogueField(product *Product, field model.ProductCatalogueField, value string) error {
+	var err error
+
+	switch field {
+	case model.ProductCatalogueFieldExternalRef:
+		product.ExternalRef = &value
+	case model.ProductCatalogueFieldName:
+		product.Name = value
+	case model.ProductCatalogueFieldDescription:
+		product.Description = value
+	case model.ProductCatalogueFieldPrice:
+		product.Price, err = parseCataloguePositiveFloat(value)
+	case model.ProductCatalogueFieldUnit:
+		product.Unit = value
+	case model.ProductCatalogueFieldPerUnitQuantity:
+		product.PerUnitQuantity, err = parseCataloguePositiveFloat(value)
+	case model.ProductCatalogueFieldPerUnitQuantityUnit:
+		product.PerUnitQuantityUnit = value
+	case model.ProductCatalogueFieldTva:
+		product.Tva, err = parseCataloguePositiveFloat(value)
+	case model.ProductCatalogueFieldIsBreton:
+		product.IsBreton, err = parseCatalogueBool(value)
+	case model.ProductCatalogueFieldHasGluten:
+		product.HasGluten, err = parseCatalogueBool(value)
+	case model.ProductCatalogueFieldTags:
+		product.Tags = parseCatalogueList(value)
+	case model.ProductCatalogueFieldAllergens:
+		product.Allergens = parseCatalogueList(value)
+	case model.ProductCatalogueFieldCategories:
+		product.Categories = parseCatalogueList(value)
+	}
+
+	return err
+}
+
+// Accepte la virgule décimale des tableurs français
+func parseCataloguePositiveFloat(value string) (float64, error) {
+	number, err := strconv.ParseFloat(strings.Replace(value, ",", ".", 1), 64)
+
+	if err != nil || number < 0 {
+		return 0, &InvalidCatalogueValueError{Value: value}
+	}
+
+	return number, nil
+}
+
+func parseCatalogueBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "true", "vrai", "oui", "yes", "1", "x":
+		return true, nil
+	case "false", "faux", "non", "no", "0":
+		return false, nil
+	}
+
+	return false, &InvalidCatalogueValueError{Value: value}
+}
+
+func parseCatalogueList(value string) []string {
+	result := []string{}
+
+	for _, item := range strings.Split(value, catalogueListSeparator) {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+
+	return result
+}
+
+func isEmptyRow(row []string) bool {
+	for _, value := range row {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+func missingRequiredField(line catalogueLine) string {
+	for _, field := range []model.ProductCatalogueField{model.ProductCatalogueFieldName, model.ProductCatalogueFieldPrice} {
+		if !line.fields[field] {
+			return catalogueHeaders[field]
+		}
+	}
+
+	return ""
+}
+
+func applyCatalogueLine(product *Product, line catalogueLine) {
+	for field := range line.fields {
+		switch field {
+		case model.ProductCatalogueFieldName:
+			product.Name = line.product.Name
+		case model.ProductCatalogueFieldDescription:
+			product.Description = line.product.Description
+		case model.ProductCatalogueFieldPrice:
+			product.Price = line.product.Price
+		case model.ProductCatalogueFieldUnit:
+			product.Unit = line.product.Unit
+		case model.ProductCatalogueFieldPerUnitQuantity:
+			product.PerUnitQuantity = line.product.PerUnitQuantity
+		case model.ProductCatalogueFieldPerUnitQuantityUnit:
+			product.PerUnitQuantityUnit = line.product.PerUnitQuantityUnit
+		case model.ProductCatalogueFieldTva:
+			product.Tva = line.product.Tva
+		case model.ProductCatalogueFieldIsBreton:
+			product.IsBreton = line.product.IsBreton
+		case model.ProductCatalogueFieldHasGluten:
+			product.HasGluten = line.product.HasGluten
+		case model.ProductCatalogueFieldTags:
+			product.Tags = line.product.Tags
+		case model.ProductCatalogueFieldAllergens:
+			product.Allergens = line.product.Allergens
+		case model.ProductCatalogueFieldCategories:
+			product.Categories = line.product.Categories
+		}
+	}
+}
+
+// Les produits créés sans référence sont exportés avec leur identifiant,
+// qu'ils garderont comme référence une fois réimportés
+func (product *Product) catalogueRef() string {
+	if product.ExternalRef != nil {
+		return *product.ExternalRef
+	}
+
+	return product.ID.Hex()
+}
+
+func (product *Product) toNewProduct() model.NewProduct {
+	categories := product.Categories
+
+	if categories == nil {
+		categories = []string{}
+	}
+
+	return model.NewProduct{
+		ExternalRef:         product.ExternalRef,
+		Name:                product.Name,
+		Description:         product.Description,
+		Price:               product.Price,
+		Unit:                product.Unit,
+		PerUnitQuantity:     product.PerUnitQuantity,
+		PerUnitQuantityUnit: product.PerUnitQuantityUnit,
+		Tva:                 product.Tva,
+		IsBreton:            product.IsBreton,
+		HasGluten:           product.HasGluten,
+		Tags:                product.Tags,
+		Allergens:           product.Allergens,
+		Categories:          categories,
+	}
+}
+
+// Export
+
+func (p *productsService) Export(commerceID primitive.ObjectID, format model.CatalogueFormat) ([]byte, error) {
+	databaseProducts, err := p.GetFiltered(bson.M{"commerceID": commerceID}, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	header := []string{}
+
+	for _, field := range catalogueFields {
+		header = append(header, catalogueHeaders[field])
+	}
+
+	rows := [][]string{header}
+
+	for _, product := range databaseProducts {
+		rows = append(rows, []string{
+			product.catalogueRef(),
+			product.Name,
+			product.Description,
+			strconv.FormatFloat(product.Price, 'f', -1, 64),
+			product.Unit,
+			strconv.FormatFloat(product.PerUnitQuantity, 'f', -1, 64),
+			product.PerUnitQuantityUnit,
+			strconv.FormatFloat(product.Tva, 'f', -1, 64),
+			strconv.FormatBool(product.IsBreton),
+			strconv.FormatBool(product.HasGluten),
+			strings.Join(product.Tags, catalogueListSeparator),
+			strings.Join(product.Allergens, catalogueListSeparator),
+			strings.Join(product.Categories, catalogueListSeparator),
+		})
+	}
+
+	if format == model.CatalogueFormatXlsx {
+		return xlsx.WriteRows("Produits", rows)
+	}
+
+	for _, row := range rows {
+		for index := range row {
+			row[index] = escapeCatalogueCell(row[index])
+		}
+	}
+
+	buffer := &bytes.Buffer{}
+	writer := csv.NewWriter(buffer)
+
+	err = writer.WriteAll(rows)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
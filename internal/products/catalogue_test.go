@@ -0,0 +1,35 @@
+package products
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEscapeCatalogueCell(t *testing.T) {
+	for _, value := range []string{"=1+1", "+33", "-2", "@SUM(A1)", "\tREF"} {
+		require.Equal(t, "'"+value, escapeCatalogueCell(value))
+		require.Equal(t, value, unescapeCatalogueCell(escapeCatalogueCell(value)))
+	}
+
+	require.Equal(t, "Galette", escapeCatalogueCell("Galette"))
+	require.Equal(t, "", escapeCatalogueCell(""))
+	require.Equal(t, "'Galette", unescapeCatalogueCell("'Galette"))
+}
+
+func TestReadCatalogueFile(t *testing.T) {
+	file := graphql.Upload{
+		File:     bytes.NewReader([]byte("externalRef;name\n'-REF;'=Galette\n")),
+		Filename: "catalogue.csv",
+	}
+
+	rows, err := ReadCatalogueFile(file)
+
+	require.NoError(t, err)
+	require.Equal(t, [][]string{
+		{"externalRef", "name"},
+		{"-REF", "=Galette"},
+	}, rows)
+}
@@ -10,3 +10,79 @@ func (m *MustSpecifyCommerceIDError) Error() string {
 func (m *ProductNotFoundError) Error() string {
 	return "le produit n'a pas été trouvé"
 }
+
+type ProductVariantNotFoundError struct{}
+type InvalidProductVariantError struct{}
+type ProductVariantRequiredError struct{}
+type InvalidCatalogueFileError struct{}
+type InvalidPublicationDatesError struct{}
+type ProductAlreadyOrderedError struct{}
+type ProductNotAvailableError struct {
+	ProductName string
+}
+type MissingExternalRefColumnError struct{}
+type InvalidFirstError struct{}
+type InvalidCatalogueValueError struct {
+	Value string
+}
+
+func (m *ProductVariantNotFoundError) Error() string {
+	return "la déclinaison n'a pas été trouvée"
+}
+
+func (m *InvalidProductVariantError) Error() string {
+	return "une déclinaison doit avoir un nom et un prix positif"
+}
+
+func (m *ProductVariantRequiredError) Error() string {
+	return "vous devez choisir une déclinaison pour ce produit"
+}
+
+func (m *InvalidCatalogueFileError) Error() string {
+	return "le fichier doit être au format CSV ou Excel (.xlsx)"
+}
+
+func (m *MissingExternalRefColumnError) Error() string {
+	return "le fichier doit contenir une colonne de référence"
+}
+
+func (m *InvalidCatalogueValueError) Error() string {
+	return "la valeur \"" + m.Value + "\" est invalide"
+}
+
+func (m *InvalidPublicationDatesError) Error() string {
+	return "la date de publication doit précéder la date de dépublication"
+}
+
+func (m *ProductAlreadyOrderedError) Error() string {
+	return "ce produit a déjà été commandé, il peut seulement être archivé"
+}
+
+func (m *ProductNotAvailableError) Error() string {
+	return "le produit " + m.ProductName + " n'est plus disponible"
+}
+
+type InvalidProductSeasonError struct{}
+type ProductOutOfSeasonError struct {
+	ProductName string
+}
+
+func (m *InvalidProductSeasonError) Error() string {
+	return "une saison doit avoir soit un mois de début et de fin, soit une date de début et de fin dans l'ordre"
+}
+
+func (m *ProductOutOfSeasonError) Error() string {
+	return "le produit " + m.ProductName + " n'est pas de saison"
+}
+
+func (m *InvalidFirstError) Error() string {
+	return "le nombre de produits demandés ne peut pas être négatif"
+}
+
+type InvalidOrderQuantityError struct {
+	ProductName string
+}
+
+func (m *InvalidOrderQuantityError) Error() string {
+	return "la quantité commandée du produit " + m.ProductName + " n'est pas valide"
+}
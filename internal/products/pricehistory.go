@@ -0,0 +1,61 @@
+package products
+
+import (
+	"time"
+
+	"chemin-du-local.bzh/graphql/graph/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Période sur laquelle est calculé le prix de référence d'une promotion,
+// le prix le plus bas pratiqué avant la remise
+const LOWEST_PRICE_PERIOD_DAYS = 30
+
+// Un changement du prix du produit, avec l'utilisateur qui l'a fait
+type ProductPriceChange struct {
+	PreviousPrice float64            `bson:"previousPrice"`
+	Price         float64            `bson:"price"`
+	UserID        primitive.ObjectID `bson:"userID"`
+	Date          time.Time          `bson:"date"`
+}
+
+func (change *ProductPriceChange) ToModel() *model.ProductPriceChange {
+	return &model.ProductPriceChange{
+		PreviousPrice: change.PreviousPrice,
+		Price:         change.Price,
+		UserID:        change.UserID.Hex(),
+		Date:          change.Date,
+	}
+}
+
+// Le prix le plus bas pratiqué depuis la date donnée, prix actuel
+// compris. Un prix remplacé après cette date l'était encore à cette date.
+func (product *Product) LowestPriceSince(date time.Time) float64 {
+	lowestPrice := product.Price
+
+	for _, change := range product.PriceHistory {
+		if change.Date.After(date) && change.PreviousPrice < lowestPrice {
+			lowestPrice = change.PreviousPrice
+		}
+	}
+
+	return lowestPrice
+}
+
+func (product *Product) GetLowestRecentPrice(now time.Time) float64 {
+	return product.LowestPriceSince(now.AddDate(0, 0, -LOWEST_PRICE_PERIOD_DAYS))
+}
+
+// Ajoute le changement à l'historique si le prix a été modifié
+func (product *Product) recordPriceChange(previousPrice float64, userID primitive.ObjectID, now time.Time) {
+	if product.Price == previousPrice {
+		return
+	}
+
+	product.PriceHistory = append(product.PriceHistory, ProductPriceChange{
+		PreviousPrice: previousPrice,
+		Price:         product.Price,
+		UserID:        userID,
+		Date:          now,
+	})
+}
@@ -0,0 +1,81 @@
+package products
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestLowestPriceSince(t *testing.T) {
+	since := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	newProduct := func(changeDate time.Time) Product {
+		return Product{
+			Price: 10,
+			PriceHistory: []ProductPriceChange{
+				{PreviousPrice: 8, Price: 10, Date: changeDate},
+			},
+		}
+	}
+
+	t.Run("a price replaced after the date was still charged at the date", func(t *testing.T) {
+		product := newProduct(since.Add(time.Second))
+
+		require.Equal(t, 8.0, product.LowestPriceSince(since))
+	})
+
+	t.Run("a price replaced exactly at the date is not counted", func(t *testing.T) {
+		product := newProduct(since)
+
+		require.Equal(t, 10.0, product.LowestPriceSince(since))
+	})
+
+	t.Run("a price replaced before the date is not counted", func(t *testing.T) {
+		product := newProduct(since.Add(-time.Second))
+
+		require.Equal(t, 10.0, product.LowestPriceSince(since))
+	})
+
+	t.Run("the current price counts when it is the lowest", func(t *testing.T) {
+		product := Product{
+			Price: 6,
+			PriceHistory: []ProductPriceChange{
+				{PreviousPrice: 8, Price: 6, Date: since.AddDate(0, 0, 10)},
+			},
+		}
+
+		require.Equal(t, 6.0, product.LowestPriceSince(since))
+	})
+
+	t.Run("the lowest recent price covers the last thirty days", func(t *testing.T) {
+		now := since.AddDate(0, 0, LOWEST_PRICE_PERIOD_DAYS)
+		recentChange := newProduct(since.Add(time.Second))
+		oldChange := newProduct(since)
+
+		require.Equal(t, 8.0, recentChange.GetLowestRecentPrice(now))
+		require.Equal(t, 10.0, oldChange.GetLowestRecentPrice(now))
+	})
+}
+
+func TestRecordPriceChange(t *testing.T) {
+	userID := primitive.NewObjectID()
+	now := time.Now()
+
+	t.Run("an unchanged price is not recorded", func(t *testing.T) {
+		product := Product{Price: 10}
+		product.recordPriceChange(10, userID, now)
+
+		require.Empty(t, product.PriceHistory)
+	})
+
+	t.Run("a new price is recorded with its author", func(t *testing.T) {
+		product := Product{Price: 12}
+		product.recordPriceChange(10, userID, now)
+
+		require.Equal(t, []ProductPriceChange{
+			{PreviousPrice: 10, Price: 12, UserID: userID, Date: now},
+		}, product.PriceHistory)
+	})
+}
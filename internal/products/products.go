@@ -3,7 +3,10 @@ package products
 import (
 	"bytes"
 	"io/ioutil"
+	"math"
 	"os"
+	"sort"
+	"time"
 
 	"chemin-du-local.bzh/graphql/graph/model"
 	"chemin-du-local.bzh/graphql/internal/config"
@@ -15,40 +18,137 @@ import (
 )
 
 type Product struct {
-	ID                  primitive.ObjectID `bson:"_id"`
-	CommerceID          primitive.ObjectID `bson:"commerceID"`
-	Name                string             `bson:"name"`
-	Description         string             `bson:"description"`
-	Price               float64            `bson:"price"`
-	Unit                string             `bson:"unit"`
-	PerUnitQuantity     float64            `bson:"perUnitQuantity"`
-	PerUnitQuantityUnit string             `bson:"perUnitQuantityUnit"`
-	Tva                 float64            `bson:"tva"`
-	IsBreton            bool               `bson:"isBreton"`
-	Tags                []string           `bson:"tags"`
-	HasGluten           bool               `bson:"hasGlutted"`
-	Allergens           []string           `bson:"allergens"`
-	Categories          []string           `bson:"categories"`
+	ID                  primitive.ObjectID   `bson:"_id"`
+	CommerceID          primitive.ObjectID   `bson:"commerceID"`
+	ExternalRef         *string              `bson:"externalRef"`
+	Name                string               `bson:"name"`
+	Description         string               `bson:"description"`
+	Price               float64              `bson:"price"`
+	Unit                string               `bson:"unit"`
+	PerUnitQuantity     float64              `bson:"perUnitQuantity"`
+	PerUnitQuantityUnit string               `bson:"perUnitQuantityUnit"`
+	Tva                 float64              `bson:"tva"`
+	IsBreton            bool                 `bson:"isBreton"`
+	Tags                []string             `bson:"tags"`
+	HasGluten           bool                 `bson:"hasGlutted"`
+	IsWeighed           bool                 `bson:"isWeighed"`
+	Allergens           []string             `bson:"allergens"`
+	Categories          []string             `bson:"categories"`
+	CategoryIDs         []primitive.ObjectID `bson:"categoryIDs"`
+	Stock               *ProductStock        `bson:"stock"`
+	Variants            []ProductVariant     `bson:"variants"`
+	Status              string               `bson:"status"`
+	PublishAt           *time.Time           `bson:"publishAt"`
+	UnpublishAt         *time.Time           `bson:"unpublishAt"`
+	Seasons             []ProductSeason      `bson:"seasons"`
+	PriceHistory        []ProductPriceChange `bson:"priceHistory"`
+}
+
+// Le stock est facultatif : sans suivi, le produit est toujours disponible
+type ProductStock struct {
+	Quantity          int  `bson:"quantity"`
+	LowStockThreshold int  `bson:"lowStockThreshold"`
+	LowStockAlertSent bool `bson:"lowStockAlertSent"`
+}
+
+func (stock *ProductStock) IsLow() bool {
+	return stock.Quantity <= stock.LowStockThreshold
+}
+
+func (stock *ProductStock) ToModel() *model.ProductStock {
+	return &model.ProductStock{
+		Quantity:          stock.Quantity,
+		LowStockThreshold: stock.LowStockThreshold,
+		IsLow:             stock.IsLow(),
+	}
 }
 
 func (product *Product) ToModel() *model.Product {
+	var stock *model.ProductStock
+
+	categoryIDs := []string{}
+
+	for _, categoryID := range product.CategoryIDs {
+		categoryIDs = append(categoryIDs, categoryID.Hex())
+	}
+
+	if product.Stock != nil {
+		stock = product.Stock.ToModel()
+	}
+
+	variants := []*model.ProductVariant{}
+
+	for i := range product.Variants {
+		variants = append(variants, product.Variants[i].ToModel(product))
+	}
+
+	seasons := []*model.ProductSeason{}
+
+	for i := range product.Seasons {
+		seasons = append(seasons, product.Seasons[i].ToModel())
+	}
+
 	return &model.Product{
-		ID:                  product.ID.Hex(),
-		Name:                product.Name,
-		Description:         product.Description,
-		Price:               product.Price,
-		Unit:                product.Unit,
-		PerUnitQuantity:     product.PerUnitQuantity,
-		PerUnitQuantityUnit: product.PerUnitQuantityUnit,
-		Tva:                 product.Tva,
-		IsBreton:            product.IsBreton,
-		HasGluten:           product.HasGluten,
-		Tags:                product.Tags,
-		Allergens:           product.Allergens,
-		Categories:          product.Categories,
+		ID:                    product.ID.Hex(),
+		CommerceID:            product.CommerceID.Hex(),
+		ExternalRef:           product.ExternalRef,
+		Name:                  product.Name,
+		Description:           product.Description,
+		Price:                 product.Price,
+		OriginalPrice:         product.Price,
+		Unit:                  product.Unit,
+		PerUnitQuantity:       product.PerUnitQuantity,
+		PerUnitQuantityUnit:   product.PerUnitQuantityUnit,
+		Tva:                   product.Tva,
+		IsBreton:              product.IsBreton,
+		HasGluten:             product.HasGluten,
+		IsWeighed:             product.IsWeighed,
+		Tags:                  product.Tags,
+		Allergens:             product.Allergens,
+		Categories:            product.Categories,
+		CategoryIDs:           categoryIDs,
+		Stock:                 stock,
+		IsOutOfStock:          product.IsOutOfStock(),
+		Variants:              variants,
+		Status:                model.ProductStatus(product.GetStatus()),
+		PublishAt:             product.PublishAt,
+		UnpublishAt:           product.UnpublishAt,
+		IsVisible:             product.IsVisible(time.Now()),
+		Seasons:               seasons,
+		SeasonStatus:          product.GetSeasonStatus(time.Now()),
+		LowestPriceLast30Days: product.GetLowestRecentPrice(time.Now()),
 	}
 }
 
+// Un produit au poids se commande en quantité estimée, les autres à
+// l'unité
+func (product *Product) IsValidOrderQuantity(quantity float64) bool {
+	if quantity <= 0 || math.IsInf(quantity, 0) || math.IsNaN(quantity) {
+		return false
+	}
+
+	return product.IsWeighed || quantity == math.Trunc(quantity)
+}
+
+// Un produit décliné n'est épuisé que si toutes ses déclinaisons le sont
+func (product *Product) IsOutOfStock() bool {
+	if product.Stock != nil && product.Stock.Quantity <= 0 {
+		return true
+	}
+
+	if len(product.Variants) == 0 {
+		return false
+	}
+
+	for i := range product.Variants {
+		if !product.Variants[i].IsOutOfStock() {
+			return false
+		}
+	}
+
+	return true
+}
+
 func (product Product) IsLast(productsService ProductsService) bool {
 	filter := bson.D{
 		primitive.E{
@@ -80,11 +180,21 @@ type productsService struct{}
 
 type ProductsService interface {
 	Create(commerceID string, input model.NewProduct) (*Product, error)
-	Update(changes *Product, image *graphql.Upload) error
+	Update(changes *Product, image *graphql.Upload, userID primitive.ObjectID) error
 	GetById(id string) (*Product, error)
 	GetForCommerce(commerceID string) ([]Product, error)
 	GetPaginated(commerceID string, startValue *string, first int, filters *model.ProductFilter) ([]Product, error)
 	GetFiltered(filter interface{}, opts *options.FindOptions) ([]Product, error)
+	GetCategoriesForCommerces(commerceIDs []primitive.ObjectID) (map[primitive.ObjectID][]string, error)
+	CreateVariant(product *Product, input model.NewProductVariant) (*ProductVariant, error)
+	UpdateVariant(product *Product, variantID primitive.ObjectID, changes model.ChangesProductVariant) (*ProductVariant, error)
+	DeleteVariant(product *Product, variantID primitive.ObjectID) (*ProductVariant, error)
+	Search(commerceIDs []primitive.ObjectID, filter *model.ProductSearchFilter, startValue *string, first int) (*ProductSearchResult, error)
+	Import(commerceID primitive.ObjectID, rows [][]string, mapping []*model.ProductColumnMapping, dryRun bool, userID primitive.ObjectID) (*ProductImportReport, error)
+	Export(commerceID primitive.ObjectID, format model.CatalogueFormat) ([]byte, error)
+	SetStatus(product *Product, status model.ProductStatus, publishAt *time.Time, unpublishAt *time.Time) error
+	Delete(product *Product) error
+	SetSeasons(product *Product, inputs []*model.ProductSeasonInput) error
 }
 
 func NewProductsService() *productsService {
@@ -100,22 +210,10 @@ func (p *productsService) Create(commerceID string, input model.NewProduct) (*Pr
 		return nil, err
 	}
 
-	productObjectID := primitive.NewObjectID()
-	databaseProduct := Product{
-		ID:                  productObjectID,
-		CommerceID:          commerceObjectID,
-		Name:                input.Name,
-		Description:         input.Description,
-		Price:               input.Price,
-		Unit:                input.Unit,
-		PerUnitQuantity:     input.PerUnitQuantity,
-		PerUnitQuantityUnit: input.PerUnitQuantityUnit,
-		Tva:                 input.Tva,
-		IsBreton:            input.IsBreton,
-		HasGluten:           input.HasGluten,
-		Tags:                input.Tags,
-		Allergens:           input.Allergens,
-		Categories:          input.Categories,
+	databaseProduct, err := newProduct(commerceObjectID, input)
+
+	if err != nil {
+		return nil, err
 	}
 
 	_, err = database.CollectionProducts.InsertOne(database.MongoContext, databaseProduct)
@@ -134,19 +232,133 @@ func (p *productsService) Create(commerceID string, input model.NewProduct) (*Pr
 
 		folderPath := config.Cfg.Paths.Static + "/products"
 		os.MkdirAll(folderPath, os.ModePerm)
-		err := ioutil.WriteFile(folderPath+"/"+productObjectID.Hex()+".jpg", data, 0644)
+		err := ioutil.WriteFile(folderPath+"/"+databaseProduct.ID.Hex()+".jpg", data, 0644)
+
+		if err != nil {
+			return databaseProduct, err
+		}
+	}
+
+	return databaseProduct, nil
+}
+
+// Construit un nouveau produit sans l'enregistrer
+func newProduct(commerceObjectID primitive.ObjectID, input model.NewProduct) (*Product, error) {
+	variants := []ProductVariant{}
+
+	for _, variantInput := range input.Variants {
+		variant, err := newVariant(*variantInput)
+
+		if err != nil {
+			return nil, err
+		}
+
+		variants = append(variants, *variant)
+	}
+
+	status := PRODUCT_STATUS_PUBLISHED
+
+	if input.Status != nil {
+		status = string(*input.Status)
+	}
+
+	if input.PublishAt != nil && input.UnpublishAt != nil && !input.PublishAt.Before(*input.UnpublishAt) {
+		return nil, &InvalidPublicationDatesError{}
+	}
+
+	seasons, err := newProductSeasons(input.Seasons)
+
+	if err != nil {
+		return nil, err
+	}
+
+	isWeighed := false
+
+	if input.IsWeighed != nil {
+		isWeighed = *input.IsWeighed
+	}
+
+	// Les catégories ont été résolues par l'appelant, les noms
+	// correspondent aux identifiants
+	categoryIDs := []primitive.ObjectID{}
+
+	for _, categoryID := range input.CategoryIDs {
+		categoryObjectID, err := primitive.ObjectIDFromHex(categoryID)
 
 		if err != nil {
-			return &databaseProduct, err
+			return nil, err
 		}
+
+		categoryIDs = append(categoryIDs, categoryObjectID)
 	}
 
-	return &databaseProduct, nil
+	return &Product{
+		ID:                  primitive.NewObjectID(),
+		CommerceID:          commerceObjectID,
+		ExternalRef:         input.ExternalRef,
+		Name:                input.Name,
+		Description:         input.Description,
+		Price:               input.Price,
+		Unit:                input.Unit,
+		PerUnitQuantity:     input.PerUnitQuantity,
+		PerUnitQuantityUnit: input.PerUnitQuantityUnit,
+		Tva:                 input.Tva,
+		IsBreton:            input.IsBreton,
+		HasGluten:           input.HasGluten,
+		IsWeighed:           isWeighed,
+		Tags:                input.Tags,
+		Allergens:           input.Allergens,
+		Categories:          input.Categories,
+		CategoryIDs:         categoryIDs,
+		Variants:            variants,
+		Status:              status,
+		PublishAt:           input.PublishAt,
+		UnpublishAt:         input.UnpublishAt,
+		Seasons:             seasons,
+	}, nil
 }
 
 // Mise à jour de la base de données
 
-func (p *productsService) Update(changes *Product, image *graphql.Upload) error {
+// Le stock, y compris celui des déclinaisons, est modifié par ses
+// propres opérations atomiques : la mise à jour du produit ne doit pas
+// écraser une commande ou un ajustement passés entre temps
+var fieldsNotUpdated = []string{"_id", "stock", "variants"}
+
+func updateDocument(changes *Product) (bson.M, error) {
+	data, err := bson.Marshal(changes)
+
+	if err != nil {
+		return nil, err
+	}
+
+	document := bson.M{}
+	err = bson.Unmarshal(data, &document)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, field := range fieldsNotUpdated {
+		delete(document, field)
+	}
+
+	return document, nil
+}
+
+// Les changements de prix sont conservés dans l'historique du produit,
+// avec l'utilisateur qui les a faits
+func (p *productsService) Update(changes *Product, image *graphql.Upload, userID primitive.ObjectID) error {
+	previousProduct, err := p.GetById(changes.ID.Hex())
+
+	if err != nil {
+		return err
+	}
+
+	if previousProduct != nil {
+		changes.recordPriceChange(previousProduct.Price, userID, time.Now())
+	}
+
 	filter := bson.D{
 		primitive.E{
 			Key:   "_id",
@@ -154,7 +366,13 @@ func (p *productsService) Update(changes *Product, image *graphql.Upload) error
 		},
 	}
 
-	_, err := database.CollectionProducts.ReplaceOne(database.MongoContext, filter, changes)
+	document, err := updateDocument(changes)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = database.CollectionProducts.UpdateOne(database.MongoContext, filter, bson.M{"$set": document})
 
 	if image != nil {
 		fileData := image.File
@@ -267,6 +485,8 @@ func (p *productsService) GetPaginated(commerceID string, startValue *string, fi
 		}
 	}
 
+	var statuses []model.ProductStatus
+
 	if filters != nil {
 		if filters.Category != nil {
 			finalFilter = append(finalFilter, primitive.E{
@@ -274,14 +494,90 @@ func (p *productsService) GetPaginated(commerceID string, startValue *string, fi
 				Value: filters.Category,
 			})
 		}
+
+		statuses = filters.Status
+	}
+
+	conditions := []bson.M{productStatusFilter(statuses)}
+
+	if filters != nil && filters.InSeason != nil {
+		if *filters.InSeason {
+			conditions = append(conditions, InSeasonFilter(time.Now()))
+		} else {
+			conditions = append(conditions, OutOfSeasonFilter(time.Now()))
+		}
 	}
 
+	finalFilter = append(finalFilter, primitive.E{
+		Key:   "$and",
+		Value: conditions,
+	})
+
 	opts := options.Find()
 	opts.SetLimit(int64(first))
 
 	return p.GetFiltered(finalFilter, opts)
 }
 
+// Renvoie les catégories des produits de chaque commerce
+func (p *productsService) GetCategoriesForCommerces(commerceIDs []primitive.ObjectID) (map[primitive.ObjectID][]string, error) {
+	result := map[primitive.ObjectID][]string{}
+
+	pipeline := []bson.M{
+		{
+			"$match": bson.M{
+				"$and": []bson.M{
+					{
+						"commerceID": bson.M{
+							"$in": commerceIDs,
+						},
+					},
+					VisibleFilter(time.Now()),
+				},
+			},
+		},
+		{
+			"$unwind": "$categories",
+		},
+		{
+			"$group": bson.M{
+				"_id": "$commerceID",
+				"categories": bson.M{
+					"$addToSet": "$categories",
+				},
+			},
+		},
+	}
+
+	cursor, err := database.CollectionProducts.Aggregate(database.MongoContext, pipeline)
+
+	if err != nil {
+		return result, err
+	}
+
+	for cursor.Next(database.MongoContext) {
+		var group struct {
+			CommerceID primitive.ObjectID `bson:"_id"`
+			Categories []string           `bson:"categories"`
+		}
+
+		err := cursor.Decode(&group)
+
+		if err != nil {
+			return result, err
+		}
+
+		sort.Strings(group.Categories)
+		result[group.CommerceID] = group.Categories
+	}
+
+	if err := cursor.Err(); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
 func (p *productsService) GetFiltered(filter interface{}, opts *options.FindOptions) ([]Product, error) {
 	products := []Product{}
 
@@ -0,0 +1,23 @@
+package products
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsValidOrderQuantity(t *testing.T) {
+	unitProduct := Product{Name: "Cidre"}
+	weighedProduct := Product{Name: "Pommes", IsWeighed: true}
+
+	require.True(t, unitProduct.IsValidOrderQuantity(2))
+	require.False(t, unitProduct.IsValidOrderQuantity(1.5))
+	require.False(t, unitProduct.IsValidOrderQuantity(0))
+	require.False(t, unitProduct.IsValidOrderQuantity(-1))
+
+	require.True(t, weighedProduct.IsValidOrderQuantity(0.35))
+	require.False(t, weighedProduct.IsValidOrderQuantity(0))
+	require.False(t, weighedProduct.IsValidOrderQuantity(math.NaN()))
+	require.False(t, weighedProduct.IsValidOrderQuantity(math.Inf(1)))
+}
@@ -0,0 +1,378 @@
+package products
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"chemin-du-local.bzh/graphql/graph/model"
+	"chemin-du-local.bzh/graphql/internal/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Nombre maximal de produits renvoyés par page de recherche
+const SEARCH_MAX_FIRST = 100
+
+// Bornes des tranches de prix des facettes, en euros
+var priceFacetBoundaries = []float64{0, 5, 10, 20, 50}
+
+type ProductSearchResult struct {
+	Products    []Product
+	HasNextPage bool
+	TotalCount  int
+	Facets      ProductSearchFacets
+}
+
+type FacetValue struct {
+	Value string
+	Count int
+}
+
+type BooleanFacetValue struct {
+	Value bool
+	Count int
+}
+
+type PriceFacetValue struct {
+	Min   float64
+	Max   *float64
+	Count int
+}
+
+type ProductSearchFacets struct {
+	IsBreton  []BooleanFacetValue
+	HasGluten []BooleanFacetValue
+	Allergens []FacetValue
+	Tags      []FacetValue
+	Prices    []PriceFacetValue
+}
+
+func (facets *ProductSearchFacets) ToModel() *model.ProductSearchFacets {
+	result := model.ProductSearchFacets{
+		IsBreton:  []*model.BooleanFacetValue{},
+		HasGluten: []*model.BooleanFacetValue{},
+		Allergens: []*model.FacetValue{},
+		Tags:      []*model.FacetValue{},
+		Prices:    []*model.PriceFacetValue{},
+	}
+
+	for _, value := range facets.IsBreton {
+		result.IsBreton = append(result.IsBreton, &model.BooleanFacetValue{Value: value.Value, Count: value.Count})
+	}
+
+	for _, value := range facets.HasGluten {
+		result.HasGluten = append(result.HasGluten, &model.BooleanFacetValue{Value: value.Value, Count: value.Count})
+	}
+
+	for _, value := range facets.Allergens {
+		result.Allergens = append(result.Allergens, &model.FacetValue{Value: value.Value, Count: value.Count})
+	}
+
+	for _, value := range facets.Tags {
+		result.Tags = append(result.Tags, &model.FacetValue{Value: value.Value, Count: value.Count})
+	}
+
+	for _, value := range facets.Prices {
+		result.Prices = append(result.Prices, &model.PriceFacetValue{Min: value.Min, Max: value.Max, Count: value.Count})
+	}
+
+	return &result
+}
+
+// Recherche parmi les produits des commerces donnés. Les produits sont
+// triés par identifiant pour que le curseur reste stable d'une page à
+// l'autre.
+func (p *productsService) Search(commerceIDs []primitive.ObjectID, filter *model.ProductSearchFilter, startValue *string, first int) (*ProductSearchResult, error) {
+	if first < 0 {
+		return nil, &InvalidFirstError{}
+	}
+
+	if first > SEARCH_MAX_FIRST {
+		first = SEARCH_MAX_FIRST
+	}
+
+	searchFilter := productSearchFilter(commerceIDs, filter)
+	pageFilter := searchFilter
+
+	if startValue != nil {
+		objectID, err := primitive.ObjectIDFromHex(*startValue)
+
+		if err != nil {
+			return nil, err
+		}
+
+		pageFilter = bson.M{
+			"$and": []bson.M{
+				searchFilter,
+				{"_id": bson.M{"$gt": objectID}},
+			},
+		}
+	}
+
+	// Un produit de plus pour savoir s'il reste une page
+	opts := options.Find().
+		SetSort(bson.M{"_id": 1}).
+		SetLimit(int64(first + 1))
+
+	databaseProducts, err := p.GetFiltered(pageFilter, opts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := ProductSearchResult{
+		Products: databaseProducts,
+	}
+
+	if len(databaseProducts) > first {
+		result.Products = databaseProducts[:first]
+		result.HasNextPage = true
+	}
+
+	result.TotalCount, result.Facets, err = p.getSearchFacets(searchFilter)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func productSearchFilter(commerceIDs []primitive.ObjectID, filter *model.ProductSearchFilter) bson.M {
+	conditions := []bson.M{
+		{
+			"commerceID": bson.M{
+				"$in": commerceIDs,
+			},
+		},
+		VisibleFilter(time.Now()),
+		// Un produit hors saison ne peut pas être commandé
+		InSeasonFilter(time.Now()),
+	}
+
+	if filter == nil {
+		return bson.M{"$and": conditions}
+	}
+
+	// Passe par l'index texte des produits (nom, description, tags et
+	// catégories)
+	if filter.Query != nil && strings.TrimSpace(*filter.Query) != "" {
+		conditions = append(conditions, bson.M{
+			"$text": bson.M{"$search": *filter.Query},
+		})
+	}
+
+	if filter.IsBreton != nil {
+		conditions = append(conditions, bson.M{"isBreton": *filter.IsBreton})
+	}
+
+	if filter.HasGluten != nil {
+		conditions = append(conditions, bson.M{"hasGlutted": *filter.HasGluten})
+	}
+
+	if len(filter.WithoutAllergens) > 0 {
+		conditions = append(conditions, bson.M{"allergens": bson.M{"$nin": filter.WithoutAllergens}})
+	}
+
+	if len(filter.Tags) > 0 {
+		conditions = append(conditions, bson.M{"tags": bson.M{"$all": filter.Tags}})
+	}
+
+	if filter.MinPrice != nil || filter.MaxPrice != nil {
+		priceRange := bson.M{}
+
+		if filter.MinPrice != nil {
+			priceRange["$gte"] = *filter.MinPrice
+		}
+
+		if filter.MaxPrice != nil {
+			priceRange["$lte"] = *filter.MaxPrice
+		}
+
+		conditions = append(conditions, bson.M{
+			"$or": []bson.M{
+				{"price": priceRange},
+				{"variants": bson.M{"$elemMatch": bson.M{"price": priceRange}}},
+			},
+		})
+	}
+
+	return bson.M{"$and": conditions}
+}
+
+// Facettes
+
+type facetCount struct {
+	Value interface{} `bson:"_id"`
+	Count int         `bson:"count"`
+}
+
+func (p *productsService) getSearchFacets(searchFilter bson.M) (int, ProductSearchFacets, error) {
+	facets := ProductSearchFacets{}
+
+	pipeline := []bson.M{
+		{
+			"$match": searchFilter,
+		},
+		{
+			"$facet": bson.M{
+				"total": []bson.M{
+					{"$count": "count"},
+				},
+				"isBreton": []bson.M{
+					{"$group": bson.M{"_id": "$isBreton", "count": bson.M{"$sum": 1}}},
+				},
+				"hasGluten": []bson.M{
+					{"$group": bson.M{"_id": "$hasGlutted", "count": bson.M{"$sum": 1}}},
+				},
+				"allergens": []bson.M{
+					{"$unwind": "$allergens"},
+					{"$group": bson.M{"_id": "$allergens", "count": bson.M{"$sum": 1}}},
+				},
+				"tags": []bson.M{
+					{"$unwind": "$tags"},
+					{"$group": bson.M{"_id": "$tags", "count": bson.M{"$sum": 1}}},
+				},
+				// Comme pour le filtre, un produit compte dans chaque
+				// tranche où se trouve son prix ou celui d'une déclinaison
+				"prices": []bson.M{
+					{"$project": bson.M{
+						"prices": bson.M{"$setUnion": []interface{}{
+							[]string{"$price"},
+							bson.M{"$ifNull": []interface{}{"$variants.price", []interface{}{}}},
+						}},
+					}},
+					{"$unwind": "$prices"},
+					{"$bucket": bson.M{
+						"groupBy":    "$prices",
+						"boundaries": priceFacetBoundaries,
+						"default":    "other",
+						"output":     bson.M{"products": bson.M{"$addToSet": "$_id"}},
+					}},
+					{"$project": bson.M{"count": bson.M{"$size": "$products"}}},
+				},
+			},
+		},
+	}
+
+	cursor, err := database.CollectionProducts.Aggregate(database.MongoContext, pipeline)
+
+	if err != nil {
+		return 0, facets, err
+	}
+
+	var results []struct {
+		Total     []facetCount `bson:"total"`
+		IsBreton  []facetCount `bson:"isBreton"`
+		HasGluten []facetCount `bson:"hasGluten"`
+		Allergens []facetCount `bson:"allergens"`
+		Tags      []facetCount `bson:"tags"`
+		Prices    []facetCount `bson:"prices"`
+	}
+
+	err = cursor.All(database.MongoContext, &results)
+
+	if err != nil || len(results) == 0 {
+		return 0, facets, err
+	}
+
+	totalCount := 0
+
+	if len(results[0].Total) > 0 {
+		totalCount = results[0].Total[0].Count
+	}
+
+	facets.IsBreton = booleanFacet(results[0].IsBreton)
+	facets.HasGluten = booleanFacet(results[0].HasGluten)
+	facets.Allergens = stringFacet(results[0].Allergens)
+	facets.Tags = stringFacet(results[0].Tags)
+	facets.Prices = priceFacet(results[0].Prices)
+
+	return totalCount, facets, nil
+}
+
+// Un champ absent des anciens produits compte comme faux
+func booleanFacet(counts []facetCount) []BooleanFacetValue {
+	countByValue := map[bool]int{}
+
+	for _, count := range counts {
+		value, _ := count.Value.(bool)
+		countByValue[value] += count.Count
+	}
+
+	result := []BooleanFacetValue{}
+
+	for _, value := range []bool{true, false} {
+		if countByValue[value] > 0 {
+			result = append(result, BooleanFacetValue{Value: value, Count: countByValue[value]})
+		}
+	}
+
+	return result
+}
+
+// Les valeurs les plus fréquentes d'abord
+func stringFacet(counts []facetCount) []FacetValue {
+	result := []FacetValue{}
+
+	for _, count := range counts {
+		value, ok := count.Value.(string)
+
+		if !ok || value == "" {
+			continue
+		}
+
+		result = append(result, FacetValue{Value: value, Count: count.Count})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+
+		return result[i].Value < result[j].Value
+	})
+
+	return result
+}
+
+// Les produits au-delà de la dernière borne forment la dernière tranche,
+// sans maximum
+func priceFacet(counts []facetCount) []PriceFacetValue {
+	result := []PriceFacetValue{}
+	countByMin := map[float64]int{}
+	overflowCount := 0
+
+	for _, count := range counts {
+		switch value := count.Value.(type) {
+		case float64:
+			countByMin[value] += count.Count
+		case int32:
+			countByMin[float64(value)] += count.Count
+		case int64:
+			countByMin[float64(value)] += count.Count
+		default:
+			overflowCount += count.Count
+		}
+	}
+
+	for index, boundary := range priceFacetBoundaries[:len(priceFacetBoundaries)-1] {
+		if countByMin[boundary] == 0 {
+			continue
+		}
+
+		max := priceFacetBoundaries[index+1]
+		result = append(result, PriceFacetValue{Min: boundary, Max: &max, Count: countByMin[boundary]})
+	}
+
+	if overflowCount > 0 {
+		result = append(result, PriceFacetValue{
+			Min:   priceFacetBoundaries[len(priceFacetBoundaries)-1],
+			Count: overflowCount,
+		})
+	}
+
+	return result
+}
@@ -0,0 +1,33 @@
+package products
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearch_NegativeFirst(t *testing.T) {
+	p := NewProductsService()
+
+	_, err := p.Search(nil, nil, nil, -1)
+
+	require.IsType(t, &InvalidFirstError{}, err)
+}
+
+func TestPriceFacet(t *testing.T) {
+	result := priceFacet([]facetCount{
+		{Value: int32(0), Count: 2},
+		{Value: float64(10), Count: 1},
+		{Value: "other", Count: 4},
+	})
+
+	require.Len(t, result, 3)
+	require.Equal(t, 0.0, result[0].Min)
+	require.Equal(t, 5.0, *result[0].Max)
+	require.Equal(t, 2, result[0].Count)
+	require.Equal(t, 10.0, result[1].Min)
+	require.Equal(t, 20.0, *result[1].Max)
+	require.Equal(t, 50.0, result[2].Min)
+	require.Nil(t, result[2].Max)
+	require.Equal(t, 4, result[2].Count)
+}
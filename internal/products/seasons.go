@@ -0,0 +1,184 @@
+package products
+
+import (
+	"time"
+
+	"chemin-du-local.bzh/graphql/graph/model"
+	"chemin-du-local.bzh/graphql/internal/database"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Une période de disponibilité d'un produit de saison : soit une plage
+// de mois qui revient chaque année, et qui peut passer le nouvel an
+// (d'octobre à mars pour les huîtres), soit une plage de dates précises.
+// Les dates sont des jours, le jour de fin étant compris en entier.
+type ProductSeason struct {
+	StartMonth *int       `bson:"startMonth"`
+	EndMonth   *int       `bson:"endMonth"`
+	StartDate  *time.Time `bson:"startDate"`
+	EndDate    *time.Time `bson:"endDate"`
+	// Les mois couverts par la plage, pour pouvoir filtrer en base
+	Months []int `bson:"months"`
+}
+
+func NewProductSeason(input model.ProductSeasonInput) (*ProductSeason, error) {
+	hasMonths := input.StartMonth != nil || input.EndMonth != nil
+	hasDates := input.StartDate != nil || input.EndDate != nil
+
+	if hasMonths == hasDates {
+		return nil, &InvalidProductSeasonError{}
+	}
+
+	if hasMonths {
+		if input.StartMonth == nil || input.EndMonth == nil || !isMonth(*input.StartMonth) || !isMonth(*input.EndMonth) {
+			return nil, &InvalidProductSeasonError{}
+		}
+
+		months := []int{}
+
+		for month := *input.StartMonth; ; month = month%12 + 1 {
+			months = append(months, month)
+
+			if month == *input.EndMonth {
+				break
+			}
+		}
+
+		return &ProductSeason{
+			StartMonth: input.StartMonth,
+			EndMonth:   input.EndMonth,
+			Months:     months,
+		}, nil
+	}
+
+	if input.StartDate == nil || input.EndDate == nil || input.EndDate.Before(*input.StartDate) {
+		return nil, &InvalidProductSeasonError{}
+	}
+
+	startDate := startOfDay(*input.StartDate)
+	endDate := startOfDay(*input.EndDate)
+
+	return &ProductSeason{
+		StartDate: &startDate,
+		EndDate:   &endDate,
+		Months:    []int{},
+	}, nil
+}
+
+func isMonth(month int) bool {
+	return month >= 1 && month <= 12
+}
+
+func startOfDay(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+}
+
+func (season *ProductSeason) Includes(date time.Time) bool {
+	if season.StartDate != nil && season.EndDate != nil {
+		return !date.Before(*season.StartDate) && date.Before(season.EndDate.AddDate(0, 0, 1))
+	}
+
+	for _, month := range season.Months {
+		if month == int(date.Month()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (season *ProductSeason) ToModel() *model.ProductSeason {
+	return &model.ProductSeason{
+		StartMonth: season.StartMonth,
+		EndMonth:   season.EndMonth,
+		StartDate:  season.StartDate,
+		EndDate:    season.EndDate,
+	}
+}
+
+// Un produit sans saison est disponible toute l'année
+func (product *Product) IsInSeason(date time.Time) bool {
+	if len(product.Seasons) == 0 {
+		return true
+	}
+
+	for i := range product.Seasons {
+		if product.Seasons[i].Includes(date) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (product *Product) GetSeasonStatus(date time.Time) model.ProductSeasonStatus {
+	if len(product.Seasons) == 0 {
+		return model.ProductSeasonStatusAllYear
+	}
+
+	if product.IsInSeason(date) {
+		return model.ProductSeasonStatusInSeason
+	}
+
+	return model.ProductSeasonStatusOutOfSeason
+}
+
+// Le même critère que IsInSeason, pour les requêtes
+func InSeasonFilter(date time.Time) bson.M {
+	return bson.M{
+		"$or": []bson.M{
+			{"seasons": nil},
+			{"seasons": bson.M{"$size": 0}},
+			{"seasons": bson.M{"$elemMatch": bson.M{
+				"$or": []bson.M{
+					{"months": int(date.Month())},
+					{"startDate": bson.M{"$lte": date}, "endDate": bson.M{"$gt": date.AddDate(0, 0, -1)}},
+				},
+			}}},
+		},
+	}
+}
+
+func OutOfSeasonFilter(date time.Time) bson.M {
+	return bson.M{
+		"$nor": []bson.M{
+			InSeasonFilter(date),
+		},
+	}
+}
+
+func newProductSeasons(inputs []*model.ProductSeasonInput) ([]ProductSeason, error) {
+	seasons := []ProductSeason{}
+
+	for _, input := range inputs {
+		season, err := NewProductSeason(*input)
+
+		if err != nil {
+			return nil, err
+		}
+
+		seasons = append(seasons, *season)
+	}
+
+	return seasons, nil
+}
+
+// Mise à jour de la base de données
+
+func (p *productsService) SetSeasons(product *Product, inputs []*model.ProductSeasonInput) error {
+	seasons, err := newProductSeasons(inputs)
+
+	if err != nil {
+		return err
+	}
+
+	product.Seasons = seasons
+
+	_, err = database.CollectionProducts.UpdateOne(
+		database.MongoContext,
+		bson.M{"_id": product.ID},
+		bson.M{"$set": bson.M{"seasons": product.Seasons}},
+	)
+
+	return err
+}
@@ -0,0 +1,89 @@
+package products
+
+import (
+	"testing"
+	"time"
+
+	"chemin-du-local.bzh/graphql/graph/model"
+	"github.com/stretchr/testify/require"
+)
+
+func intPointer(value int) *int {
+	return &value
+}
+
+func timePointer(value time.Time) *time.Time {
+	return &value
+}
+
+func TestNewProductSeason(t *testing.T) {
+	t.Run("a month range can wrap around the new year", func(t *testing.T) {
+		season, err := NewProductSeason(model.ProductSeasonInput{
+			StartMonth: intPointer(10),
+			EndMonth:   intPointer(3),
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, []int{10, 11, 12, 1, 2, 3}, season.Months)
+		require.True(t, season.Includes(time.Date(2023, time.January, 15, 12, 0, 0, 0, time.UTC)))
+		require.False(t, season.Includes(time.Date(2023, time.June, 15, 12, 0, 0, 0, time.UTC)))
+	})
+
+	t.Run("a month range from december to january", func(t *testing.T) {
+		season, err := NewProductSeason(model.ProductSeasonInput{
+			StartMonth: intPointer(12),
+			EndMonth:   intPointer(1),
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, []int{12, 1}, season.Months)
+	})
+
+	t.Run("a single month", func(t *testing.T) {
+		season, err := NewProductSeason(model.ProductSeasonInput{
+			StartMonth: intPointer(5),
+			EndMonth:   intPointer(5),
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, []int{5}, season.Months)
+	})
+
+	t.Run("a range ending on the month before its start covers the whole year", func(t *testing.T) {
+		season, err := NewProductSeason(model.ProductSeasonInput{
+			StartMonth: intPointer(4),
+			EndMonth:   intPointer(3),
+		})
+
+		require.NoError(t, err)
+		require.Len(t, season.Months, 12)
+	})
+
+	t.Run("invalid seasons are refused", func(t *testing.T) {
+		for _, input := range []model.ProductSeasonInput{
+			{},
+			{StartMonth: intPointer(0), EndMonth: intPointer(3)},
+			{StartMonth: intPointer(10), EndMonth: intPointer(13)},
+			{StartMonth: intPointer(10)},
+			{StartMonth: intPointer(10), EndMonth: intPointer(3), StartDate: timePointer(time.Now())},
+			{StartDate: timePointer(time.Now()), EndDate: timePointer(time.Now().AddDate(0, 0, -1))},
+		} {
+			_, err := NewProductSeason(input)
+
+			require.IsType(t, &InvalidProductSeasonError{}, err)
+		}
+	})
+}
+
+func TestProductSeason_Includes(t *testing.T) {
+	season, err := NewProductSeason(model.ProductSeasonInput{
+		StartDate: timePointer(time.Date(2022, time.June, 1, 0, 0, 0, 0, time.UTC)),
+		EndDate:   timePointer(time.Date(2022, time.June, 30, 0, 0, 0, 0, time.UTC)),
+	})
+	require.NoError(t, err)
+
+	require.False(t, season.Includes(time.Date(2022, time.May, 31, 23, 59, 0, 0, time.UTC)))
+	require.True(t, season.Includes(time.Date(2022, time.June, 1, 0, 0, 0, 0, time.UTC)))
+	require.True(t, season.Includes(time.Date(2022, time.June, 30, 18, 0, 0, 0, time.UTC)))
+	require.False(t, season.Includes(time.Date(2022, time.July, 1, 0, 0, 0, 0, time.UTC)))
+}
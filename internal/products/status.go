@@ -0,0 +1,127 @@
+package products
+
+import (
+	"os"
+	"time"
+
+	"chemin-du-local.bzh/graphql/graph/model"
+	"chemin-du-local.bzh/graphql/internal/config"
+	"chemin-du-local.bzh/graphql/internal/database"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const PRODUCT_STATUS_DRAFT = "DRAFT"
+const PRODUCT_STATUS_PUBLISHED = "PUBLISHED"
+const PRODUCT_STATUS_ARCHIVED = "ARCHIVED"
+
+// Les produits créés avant les statuts n'en ont pas et sont publiés
+func (product *Product) GetStatus() string {
+	if product.Status == "" {
+		return PRODUCT_STATUS_PUBLISHED
+	}
+
+	return product.Status
+}
+
+// Un produit est visible des clients s'il est publié, ou si sa date de
+// publication est passée, et tant que sa date de dépublication ne l'est
+// pas. Un produit archivé n'est jamais visible.
+func (product *Product) IsVisible(now time.Time) bool {
+	if product.GetStatus() == PRODUCT_STATUS_ARCHIVED {
+		return false
+	}
+
+	if product.UnpublishAt != nil && !now.Before(*product.UnpublishAt) {
+		return false
+	}
+
+	if product.PublishAt != nil {
+		return !now.Before(*product.PublishAt)
+	}
+
+	return product.GetStatus() == PRODUCT_STATUS_PUBLISHED
+}
+
+// Le même critère que IsVisible, pour les requêtes
+func VisibleFilter(now time.Time) bson.M {
+	return bson.M{
+		"$and": []bson.M{
+			{"status": bson.M{"$ne": PRODUCT_STATUS_ARCHIVED}},
+			{
+				"$or": []bson.M{
+					{"publishAt": bson.M{"$lte": now}},
+					{"publishAt": nil, "status": bson.M{"$in": []interface{}{PRODUCT_STATUS_PUBLISHED, nil}}},
+				},
+			},
+			{
+				"$or": []bson.M{
+					{"unpublishAt": nil},
+					{"unpublishAt": bson.M{"$gt": now}},
+				},
+			},
+		},
+	}
+}
+
+// Sans statut demandé, seuls les produits visibles sont renvoyés
+func productStatusFilter(statuses []model.ProductStatus) bson.M {
+	if len(statuses) == 0 {
+		return VisibleFilter(time.Now())
+	}
+
+	values := []interface{}{}
+
+	for _, status := range statuses {
+		values = append(values, string(status))
+
+		if string(status) == PRODUCT_STATUS_PUBLISHED {
+			values = append(values, nil)
+		}
+	}
+
+	return bson.M{"status": bson.M{"$in": values}}
+}
+
+// Mise à jour de la base de données
+
+func (p *productsService) SetStatus(product *Product, status model.ProductStatus, publishAt *time.Time, unpublishAt *time.Time) error {
+	if publishAt != nil && unpublishAt != nil && !publishAt.Before(*unpublishAt) {
+		return &InvalidPublicationDatesError{}
+	}
+
+	product.Status = string(status)
+	product.PublishAt = publishAt
+	product.UnpublishAt = unpublishAt
+
+	// Un produit archivé n'a plus de publication programmée
+	if product.Status == PRODUCT_STATUS_ARCHIVED {
+		product.PublishAt = nil
+		product.UnpublishAt = nil
+	}
+
+	_, err := database.CollectionProducts.UpdateOne(
+		database.MongoContext,
+		bson.M{"_id": product.ID},
+		bson.M{"$set": bson.M{
+			"status":      product.Status,
+			"publishAt":   product.PublishAt,
+			"unpublishAt": product.UnpublishAt,
+		}},
+	)
+
+	return err
+}
+
+// Suppression définitive, l'appelant ayant vérifié que le produit n'a
+// jamais été commandé
+func (p *productsService) Delete(product *Product) error {
+	_, err := database.CollectionProducts.DeleteOne(database.MongoContext, bson.M{"_id": product.ID})
+
+	if err != nil {
+		return err
+	}
+
+	os.Remove(config.Cfg.Paths.Static + "/products/" + product.ID.Hex() + ".jpg")
+
+	return nil
+}
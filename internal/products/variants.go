@@ -0,0 +1,176 @@
+package products
+
+import (
+	"strings"
+
+	"chemin-du-local.bzh/graphql/graph/model"
+	"chemin-du-local.bzh/graphql/internal/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Une déclinaison d'un produit (taille, poids, format...) avec son
+// propre prix et, si besoin, son propre stock
+type ProductVariant struct {
+	ID              primitive.ObjectID `bson:"_id"`
+	Name            string             `bson:"name"`
+	Price           float64            `bson:"price"`
+	PerUnitQuantity float64            `bson:"perUnitQuantity"`
+	Sku             *string            `bson:"sku"`
+	Stock           *ProductStock      `bson:"stock"`
+}
+
+// Le produit est nécessaire pour retrouver les promotions de la
+// déclinaison
+func (variant *ProductVariant) ToModel(product *Product) *model.ProductVariant {
+	var stock *model.ProductStock
+
+	if variant.Stock != nil {
+		stock = variant.Stock.ToModel()
+	}
+
+	return &model.ProductVariant{
+		ID:              variant.ID.Hex(),
+		ProductID:       product.ID.Hex(),
+		CommerceID:      product.CommerceID.Hex(),
+		Categories:      product.Categories,
+		Name:            variant.Name,
+		Price:           variant.Price,
+		PerUnitQuantity: variant.PerUnitQuantity,
+		Sku:             variant.Sku,
+		Stock:           stock,
+		IsOutOfStock:    variant.IsOutOfStock(),
+	}
+}
+
+func (variant *ProductVariant) IsOutOfStock() bool {
+	return variant.Stock != nil && variant.Stock.Quantity <= 0
+}
+
+func (product *Product) GetVariant(variantID primitive.ObjectID) *ProductVariant {
+	for i := range product.Variants {
+		if product.Variants[i].ID == variantID {
+			return &product.Variants[i]
+		}
+	}
+
+	return nil
+}
+
+func newVariant(input model.NewProductVariant) (*ProductVariant, error) {
+	if strings.TrimSpace(input.Name) == "" || input.Price < 0 || input.PerUnitQuantity < 0 {
+		return nil, &InvalidProductVariantError{}
+	}
+
+	return &ProductVariant{
+		ID:              primitive.NewObjectID(),
+		Name:            strings.TrimSpace(input.Name),
+		Price:           input.Price,
+		PerUnitQuantity: input.PerUnitQuantity,
+		Sku:             input.Sku,
+	}, nil
+}
+
+// Mise à jour de la base de données
+
+// Les déclinaisons sont modifiées une à une pour ne pas écraser le stock
+// décrémenté entre-temps par une commande, la mise à jour du produit ne
+// les enregistrant jamais
+func (p *productsService) CreateVariant(product *Product, input model.NewProductVariant) (*ProductVariant, error) {
+	variant, err := newVariant(input)
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = database.CollectionProducts.UpdateOne(
+		database.MongoContext,
+		bson.M{"_id": product.ID},
+		bson.M{"$push": bson.M{"variants": variant}},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	product.Variants = append(product.Variants, *variant)
+
+	return variant, nil
+}
+
+func (p *productsService) UpdateVariant(product *Product, variantID primitive.ObjectID, changes model.ChangesProductVariant) (*ProductVariant, error) {
+	variant := product.GetVariant(variantID)
+
+	if variant == nil {
+		return nil, &ProductVariantNotFoundError{}
+	}
+
+	if changes.Name != nil {
+		variant.Name = strings.TrimSpace(*changes.Name)
+	}
+
+	if changes.Price != nil {
+		variant.Price = *changes.Price
+	}
+
+	if changes.PerUnitQuantity != nil {
+		variant.PerUnitQuantity = *changes.PerUnitQuantity
+	}
+
+	if changes.Sku != nil {
+		variant.Sku = changes.Sku
+	}
+
+	if variant.Name == "" || variant.Price < 0 || variant.PerUnitQuantity < 0 {
+		return nil, &InvalidProductVariantError{}
+	}
+
+	_, err := database.CollectionProducts.UpdateOne(
+		database.MongoContext,
+		bson.M{"_id": product.ID, "variants._id": variantID},
+		bson.M{"$set": bson.M{
+			"variants.$.name":            variant.Name,
+			"variants.$.price":           variant.Price,
+			"variants.$.perUnitQuantity": variant.PerUnitQuantity,
+			"variants.$.sku":             variant.Sku,
+		}},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return variant, nil
+}
+
+func (p *productsService) DeleteVariant(product *Product, variantID primitive.ObjectID) (*ProductVariant, error) {
+	variant := product.GetVariant(variantID)
+
+	if variant == nil {
+		return nil, &ProductVariantNotFoundError{}
+	}
+
+	deletedVariant := *variant
+
+	_, err := database.CollectionProducts.UpdateOne(
+		database.MongoContext,
+		bson.M{"_id": product.ID},
+		bson.M{"$pull": bson.M{"variants": bson.M{"_id": variantID}}},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	variants := []ProductVariant{}
+
+	for _, productVariant := range product.Variants {
+		if productVariant.ID != variantID {
+			variants = append(variants, productVariant)
+		}
+	}
+
+	product.Variants = variants
+
+	return &deletedVariant, nil
+}
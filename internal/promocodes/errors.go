@@ -0,0 +1,46 @@
+package promocodes
+
+import "fmt"
+
+type PromoCodeNotFoundError struct{}
+type PromoCodeAlreadyExistsError struct{}
+type InvalidPromoCodeError struct{}
+type PromoCodeExpiredError struct{}
+type PromoCodeUsageLimitError struct{}
+type PromoCodeFirstOrderOnlyError struct{}
+type PromoCodeNotApplicableError struct{}
+type PromoCodeMinimumBasketError struct {
+	MinimumBasket float64
+}
+
+func (m *PromoCodeNotFoundError) Error() string {
+	return "le code promo n'existe pas"
+}
+
+func (m *PromoCodeAlreadyExistsError) Error() string {
+	return "ce code promo existe déjà"
+}
+
+func (m *InvalidPromoCodeError) Error() string {
+	return "le code promo doit avoir une valeur positive, un pourcentage ne dépassant pas 100, et une date de début précédant sa date de fin"
+}
+
+func (m *PromoCodeExpiredError) Error() string {
+	return "le code promo n'est pas valable à cette date"
+}
+
+func (m *PromoCodeUsageLimitError) Error() string {
+	return "le code promo a atteint sa limite d'utilisation"
+}
+
+func (m *PromoCodeFirstOrderOnlyError) Error() string {
+	return "le code promo n'est valable que pour une première commande"
+}
+
+func (m *PromoCodeNotApplicableError) Error() string {
+	return "le code promo ne concerne aucun commerce du panier"
+}
+
+func (m *PromoCodeMinimumBasketError) Error() string {
+	return fmt.Sprintf("le code promo nécessite un panier d'au moins %.2f€", m.MinimumBasket)
+}
@@ -0,0 +1,475 @@
+package promocodes
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"chemin-du-local.bzh/graphql/graph/model"
+	"chemin-du-local.bzh/graphql/internal/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Un code promo saisi par le client à la commande. Sans commerce, le
+// code est valable sur toute la plateforme. Ses utilisations sont
+// enregistrées à part, seul leur nombre étant gardé sur le code.
+type PromoCode struct {
+	ID             primitive.ObjectID  `bson:"_id"`
+	Code           string              `bson:"code"`
+	CommerceID     *primitive.ObjectID `bson:"commerceID"`
+	Type           string              `bson:"type"`
+	Value          float64             `bson:"value"`
+	MinimumBasket  float64             `bson:"minimumBasket"`
+	StartDate      time.Time           `bson:"startDate"`
+	EndDate        time.Time           `bson:"endDate"`
+	MaxUses        *int                `bson:"maxUses"`
+	MaxUsesPerUser *int                `bson:"maxUsesPerUser"`
+	FirstOrderOnly bool                `bson:"firstOrderOnly"`
+	FundedBy       string              `bson:"fundedBy"`
+	UsageCount     int                 `bson:"usageCount"`
+}
+
+type PromoCodeUsage struct {
+	ID        primitive.ObjectID `bson:"_id"`
+	Code      string             `bson:"code"`
+	UserID    primitive.ObjectID `bson:"userID"`
+	CommandID primitive.ObjectID `bson:"commandID"`
+	Date      time.Time          `bson:"date"`
+}
+
+func (code *PromoCode) ToModel() *model.PromoCode {
+	var commerceID *string
+
+	if code.CommerceID != nil {
+		commerceIDValue := code.CommerceID.Hex()
+		commerceID = &commerceIDValue
+	}
+
+	return &model.PromoCode{
+		ID:             code.ID.Hex(),
+		Code:           code.Code,
+		CommerceID:     commerceID,
+		Type:           model.PromoCodeType(code.Type),
+		Value:          code.Value,
+		MinimumBasket:  code.MinimumBasket,
+		StartDate:      code.StartDate,
+		EndDate:        code.EndDate,
+		MaxUses:        code.MaxUses,
+		MaxUsesPerUser: code.MaxUsesPerUser,
+		FirstOrderOnly: code.FirstOrderOnly,
+		FundedBy:       model.PromoCodeFunding(code.FundedBy),
+		UsageCount:     code.UsageCount,
+	}
+}
+
+// Les codes sont saisis sans tenir compte de la casse
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func (code *PromoCode) IsPlatformFunded() bool {
+	return code.FundedBy == model.PromoCodeFundingPlatform.String()
+}
+
+// Montant des produits et paniers commandés à un commerce, livraison non
+// comprise
+type BasketPart struct {
+	CommerceID primitive.ObjectID
+	Amount     float64
+}
+
+// Vérifie que le code peut être utilisé sur ce panier et renvoie la
+// remise de chaque commerce, répartie au prorata de leur montant. Un
+// code propre à un commerce ne porte que sur sa part du panier.
+// userUsageCount est le nombre de fois où le client a déjà utilisé le
+// code.
+func (code *PromoCode) Apply(userUsageCount int, isFirstOrder bool, parts []BasketPart, now time.Time) ([]float64, error) {
+	if now.Before(code.StartDate) || now.After(code.EndDate) {
+		return nil, &PromoCodeExpiredError{}
+	}
+
+	if code.MaxUses != nil && code.UsageCount >= *code.MaxUses {
+		return nil, &PromoCodeUsageLimitError{}
+	}
+
+	if code.MaxUsesPerUser != nil && userUsageCount >= *code.MaxUsesPerUser {
+		return nil, &PromoCodeUsageLimitError{}
+	}
+
+	if code.FirstOrderOnly && !isFirstOrder {
+		return nil, &PromoCodeFirstOrderOnlyError{}
+	}
+
+	eligibleIndexes := []int{}
+	eligibleTotal := 0.0
+
+	for index, part := range parts {
+		if code.CommerceID == nil || *code.CommerceID == part.CommerceID {
+			eligibleIndexes = append(eligibleIndexes, index)
+			eligibleTotal = eligibleTotal + part.Amount
+		}
+	}
+
+	if len(eligibleIndexes) == 0 || eligibleTotal <= 0 {
+		return nil, &PromoCodeNotApplicableError{}
+	}
+
+	if eligibleTotal < code.MinimumBasket {
+		return nil, &PromoCodeMinimumBasketError{MinimumBasket: code.MinimumBasket}
+	}
+
+	discount := code.Value
+
+	if code.Type == model.PromoCodeTypePercentage.String() {
+		discount = eligibleTotal * code.Value / 100
+	}
+
+	// La répartition se fait en centimes, le dernier commerce recevant
+	// le reste des arrondis
+	remainingCents := int(math.Round(math.Min(discount, eligibleTotal) * 100))
+	discounts := make([]float64, len(parts))
+
+	for position, index := range eligibleIndexes {
+		cents := remainingCents
+
+		if position < len(eligibleIndexes)-1 {
+			cents = int(math.Round(float64(remainingCents) * parts[index].Amount / eligibleTotal))
+			remainingCents = remainingCents - cents
+			eligibleTotal = eligibleTotal - parts[index].Amount
+		}
+
+		if cents > int(math.Round(parts[index].Amount*100)) {
+			cents = int(math.Round(parts[index].Amount * 100))
+		}
+
+		discounts[index] = float64(cents) / 100
+	}
+
+	return discounts, nil
+}
+
+// Service
+
+type promoCodesService struct{}
+
+type PromoCodesService interface {
+	Create(commerceID *primitive.ObjectID, input model.NewPromoCode) (*PromoCode, error)
+	Delete(id string) error
+	GetById(id string) (*PromoCode, error)
+	GetByCode(code string) (*PromoCode, error)
+	GetForCommerce(commerceID *primitive.ObjectID) ([]PromoCode, error)
+	GetFiltered(filter interface{}, opts *options.FindOptions) ([]PromoCode, error)
+	CountUserUsages(code string, userID primitive.ObjectID) (int, error)
+	Redeem(code *PromoCode, userID primitive.ObjectID, commandID primitive.ObjectID) error
+	Release(code string, commandID primitive.ObjectID) error
+	MigrateUsages() error
+}
+
+func NewPromoCodesService() *promoCodesService {
+	return &promoCodesService{}
+}
+
+// Créateur de base de données
+
+func (p *promoCodesService) Create(commerceID *primitive.ObjectID, input model.NewPromoCode) (*PromoCode, error) {
+	code := NormalizeCode(input.Code)
+
+	if code == "" || input.Value <= 0 || !input.StartDate.Before(input.EndDate) {
+		return nil, &InvalidPromoCodeError{}
+	}
+
+	if input.Type == model.PromoCodeTypePercentage && input.Value > 100 {
+		return nil, &InvalidPromoCodeError{}
+	}
+
+	// Un commerce finance toujours ses propres codes
+	fundedBy := model.PromoCodeFundingPlatform
+
+	if commerceID != nil {
+		fundedBy = model.PromoCodeFundingCommerce
+	} else if input.FundedBy != nil {
+		fundedBy = *input.FundedBy
+	}
+
+	databasePromoCode := PromoCode{
+		ID:             primitive.NewObjectID(),
+		Code:           code,
+		CommerceID:     commerceID,
+		Type:           input.Type.String(),
+		Value:          input.Value,
+		StartDate:      input.StartDate,
+		EndDate:        input.EndDate,
+		MaxUses:        input.MaxUses,
+		MaxUsesPerUser: input.MaxUsesPerUser,
+		FundedBy:       fundedBy.String(),
+	}
+
+	if input.MinimumBasket != nil {
+		databasePromoCode.MinimumBasket = *input.MinimumBasket
+	}
+
+	if input.FirstOrderOnly != nil {
+		databasePromoCode.FirstOrderOnly = *input.FirstOrderOnly
+	}
+
+	// L'index unique sur le code départage deux créations simultanées
+	_, err := database.CollectionPromoCodes.InsertOne(database.MongoContext, databasePromoCode)
+
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, &PromoCodeAlreadyExistsError{}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &databasePromoCode, nil
+}
+
+// Mise à jour de la base de données
+
+// Enregistre l'utilisation du code. La limite globale est vérifiée par
+// la requête qui incrémente le compteur. La limite par client est
+// vérifiée une fois l'utilisation enregistrée, pour que deux commandes
+// simultanées du même client ne puissent pas la dépasser.
+func (p *promoCodesService) Redeem(code *PromoCode, userID primitive.ObjectID, commandID primitive.ObjectID) error {
+	filter := bson.M{"_id": code.ID}
+
+	if code.MaxUses != nil {
+		filter["usageCount"] = bson.M{"$lt": *code.MaxUses}
+	}
+
+	result, err := database.CollectionPromoCodes.UpdateOne(
+		database.MongoContext,
+		filter,
+		bson.M{"$inc": bson.M{"usageCount": 1}},
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return &PromoCodeUsageLimitError{}
+	}
+
+	usage := PromoCodeUsage{
+		ID:        primitive.NewObjectID(),
+		Code:      code.Code,
+		UserID:    userID,
+		CommandID: commandID,
+		Date:      time.Now(),
+	}
+
+	_, err = database.CollectionPromoCodeUsages.InsertOne(database.MongoContext, usage)
+
+	if err != nil {
+		p.decrementUsageCount(code.Code)
+		return err
+	}
+
+	if code.MaxUsesPerUser != nil {
+		userUsageCount, err := p.CountUserUsages(code.Code, userID)
+
+		if err == nil && userUsageCount > *code.MaxUsesPerUser {
+			err = &PromoCodeUsageLimitError{}
+		}
+
+		if err != nil {
+			database.CollectionPromoCodeUsages.DeleteOne(database.MongoContext, bson.M{"_id": usage.ID})
+			p.decrementUsageCount(code.Code)
+
+			return err
+		}
+	}
+
+	code.UsageCount++
+
+	return nil
+}
+
+// Rend l'utilisation du code faite par une commande qui n'a pas été
+// payée. Sans effet si la commande ne l'a pas utilisé ou l'a déjà rendu.
+func (p *promoCodesService) Release(code string, commandID primitive.ObjectID) error {
+	result, err := database.CollectionPromoCodeUsages.DeleteOne(
+		database.MongoContext,
+		bson.M{
+			"code":      code,
+			"commandID": commandID,
+		},
+	)
+
+	if err != nil || result.DeletedCount == 0 {
+		return err
+	}
+
+	return p.decrementUsageCount(code)
+}
+
+func (p *promoCodesService) decrementUsageCount(code string) error {
+	_, err := database.CollectionPromoCodes.UpdateOne(
+		database.MongoContext,
+		bson.M{"code": code},
+		bson.M{"$inc": bson.M{"usageCount": -1}},
+	)
+
+	return err
+}
+
+// Les utilisations étaient auparavant gardées dans le document du code,
+// qui grossissait sans limite
+func (p *promoCodesService) MigrateUsages() error {
+	cursor, err := database.CollectionPromoCodes.Find(database.MongoContext, bson.M{"usages": bson.M{"$exists": true}})
+
+	if err != nil {
+		return err
+	}
+
+	for cursor.Next(database.MongoContext) {
+		var legacyCode struct {
+			ID     primitive.ObjectID `bson:"_id"`
+			Code   string             `bson:"code"`
+			Usages []PromoCodeUsage   `bson:"usages"`
+		}
+
+		err := cursor.Decode(&legacyCode)
+
+		if err != nil {
+			return err
+		}
+
+		usages := []interface{}{}
+
+		for _, usage := range legacyCode.Usages {
+			usage.ID = primitive.NewObjectID()
+			usage.Code = legacyCode.Code
+			usages = append(usages, usage)
+		}
+
+		if len(usages) > 0 {
+			_, err = database.CollectionPromoCodeUsages.InsertMany(database.MongoContext, usages)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = database.CollectionPromoCodes.UpdateOne(
+			database.MongoContext,
+			bson.M{"_id": legacyCode.ID},
+			bson.M{"$unset": bson.M{"usages": ""}},
+		)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Suppression en base de données
+
+func (p *promoCodesService) Delete(id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+
+	if err != nil {
+		return err
+	}
+
+	databasePromoCode, err := p.GetById(id)
+
+	if err != nil || databasePromoCode == nil {
+		return err
+	}
+
+	_, err = database.CollectionPromoCodes.DeleteOne(database.MongoContext, bson.M{"_id": objectID})
+
+	if err != nil {
+		return err
+	}
+
+	// Un code recréé plus tard sous le même nom repart de zéro
+	_, err = database.CollectionPromoCodeUsages.DeleteMany(database.MongoContext, bson.M{"code": databasePromoCode.Code})
+
+	return err
+}
+
+// Getter de base de données
+
+func (p *promoCodesService) GetById(id string) (*PromoCode, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := p.GetFiltered(bson.M{"_id": objectID}, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(codes) == 0 {
+		return nil, nil
+	}
+
+	return &codes[0], nil
+}
+
+func (p *promoCodesService) GetByCode(code string) (*PromoCode, error) {
+	codes, err := p.GetFiltered(bson.M{"code": NormalizeCode(code)}, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(codes) == 0 {
+		return nil, nil
+	}
+
+	return &codes[0], nil
+}
+
+// Les codes d'un commerce, ou ceux de la plateforme sans commerce
+func (p *promoCodesService) GetForCommerce(commerceID *primitive.ObjectID) ([]PromoCode, error) {
+	opts := options.Find().SetSort(bson.M{"startDate": -1})
+
+	return p.GetFiltered(bson.M{"commerceID": commerceID}, opts)
+}
+
+func (p *promoCodesService) CountUserUsages(code string, userID primitive.ObjectID) (int, error) {
+	count, err := database.CollectionPromoCodeUsages.CountDocuments(database.MongoContext, bson.M{
+		"code":   code,
+		"userID": userID,
+	})
+
+	return int(count), err
+}
+
+func (p *promoCodesService) GetFiltered(filter interface{}, opts *options.FindOptions) ([]PromoCode, error) {
+	codes := []PromoCode{}
+
+	cursor, err := database.CollectionPromoCodes.Find(database.MongoContext, filter, opts)
+
+	if err != nil {
+		return codes, err
+	}
+
+	for cursor.Next(database.MongoContext) {
+		var code PromoCode
+
+		err := cursor.Decode(&code)
+
+		if err != nil {
+			return codes, err
+		}
+
+		codes = append(codes, code)
+	}
+
+	return codes, nil
+}
@@ -0,0 +1,170 @@
+package promocodes
+
+import (
+	"testing"
+	"time"
+
+	"chemin-du-local.bzh/graphql/graph/model"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func intPointer(value int) *int {
+	return &value
+}
+
+func TestApply(t *testing.T) {
+	now := time.Date(2022, 10, 12, 14, 0, 0, 0, time.UTC)
+	firstCommerceID := primitive.NewObjectID()
+	secondCommerceID := primitive.NewObjectID()
+
+	newPromoCode := func(codeType model.PromoCodeType, value float64) PromoCode {
+		return PromoCode{
+			ID:        primitive.NewObjectID(),
+			Code:      "BIENVENUE",
+			Type:      codeType.String(),
+			Value:     value,
+			StartDate: now.AddDate(0, 0, -1),
+			EndDate:   now.AddDate(0, 0, 1),
+		}
+	}
+
+	t.Run("a fixed discount is prorated between commerces", func(t *testing.T) {
+		code := newPromoCode(model.PromoCodeTypeFixedAmount, 10)
+
+		discounts, err := code.Apply(0, false, []BasketPart{
+			{CommerceID: firstCommerceID, Amount: 10},
+			{CommerceID: secondCommerceID, Amount: 20},
+		}, now)
+
+		require.NoError(t, err)
+		require.Equal(t, []float64{3.33, 6.67}, discounts)
+	})
+
+	t.Run("the shares add up to the discount to the cent", func(t *testing.T) {
+		code := newPromoCode(model.PromoCodeTypeFixedAmount, 1)
+
+		discounts, err := code.Apply(0, false, []BasketPart{
+			{CommerceID: firstCommerceID, Amount: 10},
+			{CommerceID: primitive.NewObjectID(), Amount: 10},
+			{CommerceID: secondCommerceID, Amount: 10},
+		}, now)
+
+		require.NoError(t, err)
+		require.Equal(t, []float64{0.33, 0.34, 0.33}, discounts)
+	})
+
+	t.Run("a percentage applies to the eligible total", func(t *testing.T) {
+		code := newPromoCode(model.PromoCodeTypePercentage, 10)
+
+		discounts, err := code.Apply(0, false, []BasketPart{
+			{CommerceID: firstCommerceID, Amount: 15},
+			{CommerceID: secondCommerceID, Amount: 25},
+		}, now)
+
+		require.NoError(t, err)
+		require.Equal(t, []float64{1.5, 2.5}, discounts)
+	})
+
+	t.Run("the discount never exceeds the basket", func(t *testing.T) {
+		code := newPromoCode(model.PromoCodeTypeFixedAmount, 50)
+
+		discounts, err := code.Apply(0, false, []BasketPart{
+			{CommerceID: firstCommerceID, Amount: 12},
+		}, now)
+
+		require.NoError(t, err)
+		require.Equal(t, []float64{12}, discounts)
+	})
+
+	t.Run("a commerce code only applies to its part", func(t *testing.T) {
+		code := newPromoCode(model.PromoCodeTypeFixedAmount, 5)
+		code.CommerceID = &secondCommerceID
+
+		discounts, err := code.Apply(0, false, []BasketPart{
+			{CommerceID: firstCommerceID, Amount: 10},
+			{CommerceID: secondCommerceID, Amount: 20},
+		}, now)
+
+		require.NoError(t, err)
+		require.Equal(t, []float64{0, 5}, discounts)
+	})
+
+	t.Run("a code of another commerce is refused", func(t *testing.T) {
+		code := newPromoCode(model.PromoCodeTypeFixedAmount, 5)
+		otherCommerceID := primitive.NewObjectID()
+		code.CommerceID = &otherCommerceID
+
+		_, err := code.Apply(0, false, []BasketPart{
+			{CommerceID: firstCommerceID, Amount: 10},
+		}, now)
+
+		require.IsType(t, &PromoCodeNotApplicableError{}, err)
+	})
+
+	t.Run("an expired code is refused", func(t *testing.T) {
+		code := newPromoCode(model.PromoCodeTypeFixedAmount, 5)
+
+		_, err := code.Apply(0, false, []BasketPart{
+			{CommerceID: firstCommerceID, Amount: 10},
+		}, now.AddDate(0, 0, 2))
+
+		require.IsType(t, &PromoCodeExpiredError{}, err)
+	})
+
+	t.Run("the minimum basket is checked on the eligible total", func(t *testing.T) {
+		code := newPromoCode(model.PromoCodeTypeFixedAmount, 5)
+		code.CommerceID = &firstCommerceID
+		code.MinimumBasket = 20
+
+		_, err := code.Apply(0, false, []BasketPart{
+			{CommerceID: firstCommerceID, Amount: 15},
+			{CommerceID: secondCommerceID, Amount: 30},
+		}, now)
+
+		require.IsType(t, &PromoCodeMinimumBasketError{}, err)
+	})
+
+	t.Run("the global usage limit is checked", func(t *testing.T) {
+		code := newPromoCode(model.PromoCodeTypeFixedAmount, 5)
+		code.MaxUses = intPointer(2)
+		code.UsageCount = 2
+
+		_, err := code.Apply(0, false, []BasketPart{
+			{CommerceID: firstCommerceID, Amount: 10},
+		}, now)
+
+		require.IsType(t, &PromoCodeUsageLimitError{}, err)
+	})
+
+	t.Run("the usage limit per user is checked", func(t *testing.T) {
+		code := newPromoCode(model.PromoCodeTypeFixedAmount, 5)
+		code.MaxUsesPerUser = intPointer(1)
+		code.UsageCount = 3
+
+		_, err := code.Apply(0, false, []BasketPart{
+			{CommerceID: firstCommerceID, Amount: 10},
+		}, now)
+		require.NoError(t, err)
+
+		_, err = code.Apply(1, false, []BasketPart{
+			{CommerceID: firstCommerceID, Amount: 10},
+		}, now)
+		require.IsType(t, &PromoCodeUsageLimitError{}, err)
+	})
+
+	t.Run("a first order code is refused to returning customers", func(t *testing.T) {
+		code := newPromoCode(model.PromoCodeTypeFixedAmount, 5)
+		code.FirstOrderOnly = true
+
+		_, err := code.Apply(0, true, []BasketPart{
+			{CommerceID: firstCommerceID, Amount: 10},
+		}, now)
+		require.NoError(t, err)
+
+		_, err = code.Apply(0, false, []BasketPart{
+			{CommerceID: firstCommerceID, Amount: 10},
+		}, now)
+		require.IsType(t, &PromoCodeFirstOrderOnlyError{}, err)
+	})
+}
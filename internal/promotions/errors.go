@@ -0,0 +1,22 @@
+package promotions
+
+type PromotionNotFoundError struct{}
+type InvalidPromotionError struct{}
+type InvalidPromotionDatesError struct{}
+type InvalidPromotionTargetError struct{}
+
+func (m *PromotionNotFoundError) Error() string {
+	return "la promotion n'a pas été trouvée"
+}
+
+func (m *InvalidPromotionError) Error() string {
+	return "la remise de la promotion est invalide pour son type"
+}
+
+func (m *InvalidPromotionDatesError) Error() string {
+	return "la date de début de la promotion doit précéder sa date de fin"
+}
+
+func (m *InvalidPromotionTargetError) Error() string {
+	return "la promotion doit préciser le produit, la catégorie ou le panier qu'elle concerne"
+}
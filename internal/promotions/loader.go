@@ -0,0 +1,72 @@
+package promotions
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var loaderCtxKey = contextKey{"promotionsLoader"}
+
+type contextKey struct {
+	name string
+}
+
+// Garde pour la durée d'une requête les promotions en cours de chaque
+// commerce, pour ne pas les relire pour chacun de ses produits et paniers
+type activePromotionsLoader struct {
+	service    PromotionsService
+	now        time.Time
+	mutex      sync.Mutex
+	promotions map[primitive.ObjectID][]Promotion
+}
+
+func Middleware(p PromotionsService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			loader := &activePromotionsLoader{
+				service:    p,
+				now:        time.Now(),
+				promotions: map[primitive.ObjectID][]Promotion{},
+			}
+
+			ctx := context.WithValue(r.Context(), loaderCtxKey, loader)
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func (loader *activePromotionsLoader) load(commerceID primitive.ObjectID) ([]Promotion, error) {
+	loader.mutex.Lock()
+	defer loader.mutex.Unlock()
+
+	if promotions, ok := loader.promotions[commerceID]; ok {
+		return promotions, nil
+	}
+
+	promotions, err := loader.service.GetActiveForCommerce(commerceID, loader.now)
+
+	if err != nil {
+		return nil, err
+	}
+
+	loader.promotions[commerceID] = promotions
+
+	return promotions, nil
+}
+
+// Promotions en cours d'un commerce, lues une seule fois par requête.
+// Hors requête HTTP, elles sont lues à chaque appel.
+func ActiveForCommerce(ctx context.Context, p PromotionsService, commerceID primitive.ObjectID) ([]Promotion, error) {
+	loader, _ := ctx.Value(loaderCtxKey).(*activePromotionsLoader)
+
+	if loader == nil {
+		return p.GetActiveForCommerce(commerceID, time.Now())
+	}
+
+	return loader.load(commerceID)
+}
@@ -0,0 +1,468 @@
+package promotions
+
+import (
+	"math"
+	"time"
+
+	"chemin-du-local.bzh/graphql/graph/model"
+	"chemin-du-local.bzh/graphql/internal/database"
+	"chemin-du-local.bzh/graphql/pkg/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Promotion struct {
+	ID             primitive.ObjectID  `bson:"_id"`
+	CommerceID     primitive.ObjectID  `bson:"commerceID"`
+	Name           string              `bson:"name"`
+	Type           string              `bson:"type"`
+	Target         string              `bson:"target"`
+	ProductID      *primitive.ObjectID `bson:"productID"`
+	Category       *string             `bson:"category"`
+	PanierID       *primitive.ObjectID `bson:"panierID"`
+	Value          float64             `bson:"value"`
+	BuyQuantity    int                 `bson:"buyQuantity"`
+	FreeQuantity   int                 `bson:"freeQuantity"`
+	QuantityBreaks []QuantityBreak     `bson:"quantityBreaks"`
+	StartDate      time.Time           `bson:"startDate"`
+	EndDate        time.Time           `bson:"endDate"`
+}
+
+type QuantityBreak struct {
+	MinQuantity int     `bson:"minQuantity"`
+	Percentage  float64 `bson:"percentage"`
+}
+
+func (promotion *Promotion) ToModel() *model.Promotion {
+	result := model.Promotion{
+		ID:             promotion.ID.Hex(),
+		Name:           promotion.Name,
+		Type:           model.PromotionType(promotion.Type),
+		Target:         model.PromotionTarget(promotion.Target),
+		Category:       promotion.Category,
+		Value:          promotion.Value,
+		QuantityBreaks: []*model.QuantityBreak{},
+		StartDate:      promotion.StartDate,
+		EndDate:        promotion.EndDate,
+		IsActive:       promotion.IsActive(time.Now()),
+	}
+
+	if promotion.ProductID != nil {
+		productID := promotion.ProductID.Hex()
+		result.ProductID = &productID
+	}
+
+	if promotion.PanierID != nil {
+		panierID := promotion.PanierID.Hex()
+		result.PanierID = &panierID
+	}
+
+	if promotion.Type == model.PromotionTypeBuyXGetY.String() {
+		result.BuyQuantity = &promotion.BuyQuantity
+		result.FreeQuantity = &promotion.FreeQuantity
+	}
+
+	for _, quantityBreak := range promotion.QuantityBreaks {
+		result.QuantityBreaks = append(result.QuantityBreaks, &model.QuantityBreak{
+			MinQuantity: quantityBreak.MinQuantity,
+			Percentage:  quantityBreak.Percentage,
+		})
+	}
+
+	return &result
+}
+
+func (promotion *Promotion) IsActive(now time.Time) bool {
+	return !now.Before(promotion.StartDate) && !now.After(promotion.EndDate)
+}
+
+func (promotion *Promotion) AppliesToProduct(productID primitive.ObjectID, categories []string) bool {
+	switch promotion.Target {
+	case model.PromotionTargetProduct.String():
+		return promotion.ProductID != nil && *promotion.ProductID == productID
+	case model.PromotionTargetCategory.String():
+		if promotion.Category == nil {
+			return false
+		}
+
+		for _, category := range categories {
+			if category == *promotion.Category {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (promotion *Promotion) AppliesToPanier(panierID primitive.ObjectID) bool {
+	return promotion.Target == model.PromotionTargetPanier.String() &&
+		promotion.PanierID != nil &&
+		*promotion.PanierID == panierID
+}
+
+// Remise en euros sur une ligne de commande, jamais supérieure au
+// montant de la ligne
+func (promotion *Promotion) Discount(unitPrice float64, quantity float64) float64 {
+	total := unitPrice * quantity
+	discount := 0.0
+
+	switch promotion.Type {
+	case model.PromotionTypePercentage.String():
+		discount = total * promotion.Value / 100
+	case model.PromotionTypeFixedAmount.String():
+		discount = promotion.Value * quantity
+	case model.PromotionTypeBuyXGetY.String():
+		// Pour "2 achetés, 1 offert", un produit sur trois est gratuit
+		groupSize := promotion.BuyQuantity + promotion.FreeQuantity
+		groups := math.Floor(quantity / float64(groupSize))
+		discount = groups * float64(promotion.FreeQuantity) * unitPrice
+	case model.PromotionTypeQuantityBreak.String():
+		percentage := 0.0
+
+		for _, quantityBreak := range promotion.QuantityBreaks {
+			if quantity >= float64(quantityBreak.MinQuantity) && quantityBreak.Percentage > percentage {
+				percentage = quantityBreak.Percentage
+			}
+		}
+
+		discount = total * percentage / 100
+	}
+
+	if discount > total {
+		discount = total
+	}
+
+	return math.Round(discount*100) / 100
+}
+
+// Les promotions ne se cumulent pas : la plus avantageuse pour le client
+// est retenue. Renvoie nil si aucune ne donne de remise.
+func Best(promotions []Promotion, unitPrice float64, quantity float64) (*Promotion, float64) {
+	var best *Promotion
+	bestDiscount := 0.0
+
+	for i := range promotions {
+		discount := promotions[i].Discount(unitPrice, quantity)
+
+		if discount > bestDiscount {
+			best = &promotions[i]
+			bestDiscount = discount
+		}
+	}
+
+	return best, bestDiscount
+}
+
+func ForProduct(promotions []Promotion, productID primitive.ObjectID, categories []string) []Promotion {
+	result := []Promotion{}
+
+	for _, promotion := range promotions {
+		if promotion.AppliesToProduct(productID, categories) {
+			result = append(result, promotion)
+		}
+	}
+
+	return result
+}
+
+func ForPanier(promotions []Promotion, panierID primitive.ObjectID) []Promotion {
+	result := []Promotion{}
+
+	for _, promotion := range promotions {
+		if promotion.AppliesToPanier(panierID) {
+			result = append(result, promotion)
+		}
+	}
+
+	return result
+}
+
+// Promotion appliquée à une commande. Le nom et la remise sont recopiés
+// pour que la commande reste juste si la promotion est supprimée.
+type AppliedPromotion struct {
+	PromotionID primitive.ObjectID  `bson:"promotionID"`
+	Name        string              `bson:"name"`
+	ProductID   *primitive.ObjectID `bson:"productID"`
+	VariantID   *primitive.ObjectID `bson:"variantID"`
+	PanierID    *primitive.ObjectID `bson:"panierID"`
+	Discount    float64             `bson:"discount"`
+}
+
+func NewAppliedPromotion(promotion *Promotion, discount float64) AppliedPromotion {
+	return AppliedPromotion{
+		PromotionID: promotion.ID,
+		Name:        promotion.Name,
+		Discount:    discount,
+	}
+}
+
+func AppliedPromotionFromInput(input model.NewAppliedPromotion) (*AppliedPromotion, error) {
+	promotionID, err := primitive.ObjectIDFromHex(input.PromotionID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	productID, err := utils.OptionalObjectID(input.ProductID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	variantID, err := utils.OptionalObjectID(input.VariantID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	panierID, err := utils.OptionalObjectID(input.PanierID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &AppliedPromotion{
+		PromotionID: promotionID,
+		Name:        input.Name,
+		ProductID:   productID,
+		VariantID:   variantID,
+		PanierID:    panierID,
+		Discount:    input.Discount,
+	}, nil
+}
+
+func (applied *AppliedPromotion) ToInput() *model.NewAppliedPromotion {
+	return &model.NewAppliedPromotion{
+		PromotionID: applied.PromotionID.Hex(),
+		Name:        applied.Name,
+		ProductID:   hexOrNil(applied.ProductID),
+		VariantID:   hexOrNil(applied.VariantID),
+		PanierID:    hexOrNil(applied.PanierID),
+		Discount:    applied.Discount,
+	}
+}
+
+func (applied *AppliedPromotion) ToModel() *model.AppliedPromotion {
+	return &model.AppliedPromotion{
+		PromotionID: applied.PromotionID.Hex(),
+		Name:        applied.Name,
+		ProductID:   hexOrNil(applied.ProductID),
+		VariantID:   hexOrNil(applied.VariantID),
+		PanierID:    hexOrNil(applied.PanierID),
+		Discount:    applied.Discount,
+	}
+}
+
+func hexOrNil(objectID *primitive.ObjectID) *string {
+	if objectID == nil {
+		return nil
+	}
+
+	value := objectID.Hex()
+
+	return &value
+}
+
+// Service
+
+type promotionsService struct{}
+
+type PromotionsService interface {
+	Create(commerceID primitive.ObjectID, input model.NewPromotion) (*Promotion, error)
+	Delete(id string) error
+	GetById(id string) (*Promotion, error)
+	GetForCommerce(commerceID string) ([]Promotion, error)
+	GetActiveForCommerce(commerceID primitive.ObjectID, now time.Time) ([]Promotion, error)
+	GetFiltered(filter interface{}, opts *options.FindOptions) ([]Promotion, error)
+}
+
+func NewPromotionsService() *promotionsService {
+	return &promotionsService{}
+}
+
+// Créateur de base de données
+
+func (p *promotionsService) Create(commerceID primitive.ObjectID, input model.NewPromotion) (*Promotion, error) {
+	if !input.StartDate.Before(input.EndDate) {
+		return nil, &InvalidPromotionDatesError{}
+	}
+
+	databasePromotion := Promotion{
+		ID:             primitive.NewObjectID(),
+		CommerceID:     commerceID,
+		Name:           input.Name,
+		Type:           input.Type.String(),
+		Target:         input.Target.String(),
+		QuantityBreaks: []QuantityBreak{},
+		StartDate:      input.StartDate,
+		EndDate:        input.EndDate,
+	}
+
+	switch input.Target {
+	case model.PromotionTargetProduct:
+		if input.ProductID == nil {
+			return nil, &InvalidPromotionTargetError{}
+		}
+
+		productID, err := primitive.ObjectIDFromHex(*input.ProductID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		databasePromotion.ProductID = &productID
+	case model.PromotionTargetCategory:
+		if input.Category == nil || *input.Category == "" {
+			return nil, &InvalidPromotionTargetError{}
+		}
+
+		databasePromotion.Category = input.Category
+	case model.PromotionTargetPanier:
+		if input.PanierID == nil {
+			return nil, &InvalidPromotionTargetError{}
+		}
+
+		panierID, err := primitive.ObjectIDFromHex(*input.PanierID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		databasePromotion.PanierID = &panierID
+	}
+
+	if input.Value != nil {
+		databasePromotion.Value = *input.Value
+	}
+
+	switch input.Type {
+	case model.PromotionTypePercentage:
+		if databasePromotion.Value <= 0 || databasePromotion.Value > 100 {
+			return nil, &InvalidPromotionError{}
+		}
+	case model.PromotionTypeFixedAmount:
+		if databasePromotion.Value <= 0 {
+			return nil, &InvalidPromotionError{}
+		}
+	case model.PromotionTypeBuyXGetY:
+		if input.BuyQuantity == nil || input.FreeQuantity == nil || *input.BuyQuantity <= 0 || *input.FreeQuantity <= 0 {
+			return nil, &InvalidPromotionError{}
+		}
+
+		databasePromotion.BuyQuantity = *input.BuyQuantity
+		databasePromotion.FreeQuantity = *input.FreeQuantity
+	case model.PromotionTypeQuantityBreak:
+		if len(input.QuantityBreaks) == 0 {
+			return nil, &InvalidPromotionError{}
+		}
+
+		for _, quantityBreak := range input.QuantityBreaks {
+			if quantityBreak.MinQuantity <= 0 || quantityBreak.Percentage <= 0 || quantityBreak.Percentage > 100 {
+				return nil, &InvalidPromotionError{}
+			}
+
+			databasePromotion.QuantityBreaks = append(databasePromotion.QuantityBreaks, QuantityBreak{
+				MinQuantity: quantityBreak.MinQuantity,
+				Percentage:  quantityBreak.Percentage,
+			})
+		}
+	}
+
+	_, err := database.CollectionPromotions.InsertOne(database.MongoContext, databasePromotion)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &databasePromotion, nil
+}
+
+// Suppression en base de données
+
+func (p *promotionsService) Delete(id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = database.CollectionPromotions.DeleteOne(database.MongoContext, bson.M{"_id": objectID})
+
+	return err
+}
+
+// Getter de base de données
+
+func (p *promotionsService) GetById(id string) (*Promotion, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	promotions, err := p.GetFiltered(bson.M{"_id": objectID}, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(promotions) == 0 {
+		return nil, nil
+	}
+
+	return &promotions[0], nil
+}
+
+// Les promotions en cours et à venir, les plus proches d'abord
+func (p *promotionsService) GetForCommerce(commerceID string) ([]Promotion, error) {
+	commerceObjectID, err := primitive.ObjectIDFromHex(commerceID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{
+		"commerceID": commerceObjectID,
+		"endDate":    bson.M{"$gte": time.Now()},
+	}
+
+	opts := options.Find().SetSort(bson.M{"startDate": 1})
+
+	return p.GetFiltered(filter, opts)
+}
+
+func (p *promotionsService) GetActiveForCommerce(commerceID primitive.ObjectID, now time.Time) ([]Promotion, error) {
+	filter := bson.M{
+		"commerceID": commerceID,
+		"startDate":  bson.M{"$lte": now},
+		"endDate":    bson.M{"$gte": now},
+	}
+
+	return p.GetFiltered(filter, nil)
+}
+
+func (p *promotionsService) GetFiltered(filter interface{}, opts *options.FindOptions) ([]Promotion, error) {
+	promotions := []Promotion{}
+
+	cursor, err := database.CollectionPromotions.Find(database.MongoContext, filter, opts)
+
+	if err != nil {
+		return promotions, err
+	}
+
+	for cursor.Next(database.MongoContext) {
+		var promotion Promotion
+
+		err := cursor.Decode(&promotion)
+
+		if err != nil {
+			return promotions, err
+		}
+
+		promotions = append(promotions, promotion)
+	}
+
+	return promotions, nil
+}
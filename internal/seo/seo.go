@@ -0,0 +1,325 @@
+package seo
+
+import (
+	"encoding/json"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"chemin-du-local.bzh/graphql/graph/model"
+	"chemin-du-local.bzh/graphql/internal/commerces"
+	"chemin-du-local.bzh/graphql/internal/config"
+	"chemin-du-local.bzh/graphql/internal/products"
+	"chemin-du-local.bzh/graphql/internal/services/paniers"
+)
+
+// Les métadonnées sont construites à partir des modèles existants pour
+// que le site puisse les injecter dans ses pages, les moteurs de
+// recherche et les aperçus de liens n'exécutant pas le JavaScript
+
+const schemaOrgContext = "https://schema.org"
+
+// Disponibilités d'une offre au sens de schema.org
+const AVAILABILITY_IN_STOCK = "https://schema.org/InStock"
+const AVAILABILITY_IN_STORE_ONLY = "https://schema.org/InStoreOnly"
+const AVAILABILITY_OUT_OF_STOCK = "https://schema.org/OutOfStock"
+
+var schemaOrgDays = map[time.Weekday]string{
+	time.Monday:    "https://schema.org/Monday",
+	time.Tuesday:   "https://schema.org/Tuesday",
+	time.Wednesday: "https://schema.org/Wednesday",
+	time.Thursday:  "https://schema.org/Thursday",
+	time.Friday:    "https://schema.org/Friday",
+	time.Saturday:  "https://schema.org/Saturday",
+	time.Sunday:    "https://schema.org/Sunday",
+}
+
+func CommerceURL(commerce *commerces.Commerce) string {
+	return commerceURL(commerce.ID.Hex(), commerce.Slug)
+}
+
+func commerceURL(id string, slug string) string {
+	if slug != "" {
+		return config.Cfg.Urls.Website + "/commerces/" + slug
+	}
+
+	return config.Cfg.Urls.Website + "/commerces/" + id
+}
+
+func ProductURL(product *products.Product) string {
+	return productURL(product.CommerceID.Hex(), product.ID.Hex())
+}
+
+func productURL(commerceID string, id string) string {
+	return config.Cfg.Urls.Website + "/commerces/" + commerceID + "/products/" + id
+}
+
+func PanierURL(panier *paniers.Panier) string {
+	return config.Cfg.Urls.Website + "/commerces/" + panier.CommerceID.Hex() + "/paniers/" + panier.ID.Hex()
+}
+
+func CommerceImageURL(commerce *commerces.Commerce) string {
+	return commerceImageURL(commerce.ID.Hex())
+}
+
+func commerceImageURL(id string) string {
+	return staticImageURL("commerces/" + id + "/header.jpg")
+}
+
+func ProductImageURL(product *products.Product) string {
+	return productImageURL(product.ID.Hex())
+}
+
+func productImageURL(id string) string {
+	return staticImageURL("products/" + id + ".jpg")
+}
+
+// Renvoie l'URL publique d'une image du dossier static, ou une chaîne
+// vide si elle n'a jamais été envoyée
+func staticImageURL(path string) string {
+	if _, err := os.Stat(config.Cfg.Paths.Static + "/" + path); err != nil {
+		return ""
+	}
+
+	return config.Cfg.Urls.Api + "/static/" + path
+}
+
+// LocalBusiness
+
+func CommerceJSONLD(commerce *model.Commerce) map[string]interface{} {
+	result := map[string]interface{}{
+		"@context":                  schemaOrgContext,
+		"@type":                     "LocalBusiness",
+		"@id":                       commerceURL(commerce.ID, commerce.Slug),
+		"url":                       commerceURL(commerce.ID, commerce.Slug),
+		"name":                      commerce.Name,
+		"description":               commerce.Description,
+		"telephone":                 commerce.Phone,
+		"email":                     commerce.Email,
+		"address":                   postalAddress(commerce.Address),
+		"openingHoursSpecification": openingHoursSpecification(commerce.BusinessHours),
+		"geo": map[string]interface{}{
+			"@type":     "GeoCoordinates",
+			"longitude": commerce.Longitude,
+			"latitude":  commerce.Latitude,
+		},
+	}
+
+	if image := commerceImageURL(commerce.ID); image != "" {
+		result["image"] = image
+	}
+
+	sameAs := []string{}
+
+	for _, link := range []*string{commerce.Facebook, commerce.Twitter, commerce.Instagram} {
+		if link != nil && *link != "" {
+			sameAs = append(sameAs, *link)
+		}
+	}
+
+	if len(sameAs) > 0 {
+		result["sameAs"] = sameAs
+	}
+
+	return result
+}
+
+func postalAddress(address model.Address) map[string]interface{} {
+	streetParts := []string{}
+
+	for _, part := range []*string{address.Number, address.Route, address.OptionalRoute} {
+		if part != nil && strings.TrimSpace(*part) != "" {
+			streetParts = append(streetParts, strings.TrimSpace(*part))
+		}
+	}
+
+	result := map[string]interface{}{
+		"@type":          "PostalAddress",
+		"streetAddress":  strings.Join(streetParts, " "),
+		"addressCountry": "FR",
+	}
+
+	if address.PostalCode != nil {
+		result["postalCode"] = *address.PostalCode
+	}
+
+	if address.City != nil {
+		result["addressLocality"] = *address.City
+	}
+
+	return result
+}
+
+// Un créneau de BusinessHours donne une spécification par jour
+func openingHoursSpecification(hours model.BusinessHours) []map[string]interface{} {
+	result := []map[string]interface{}{}
+
+	for _, day := range []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday} {
+		for _, schedule := range hours.ForDay(day) {
+			if schedule == nil {
+				continue
+			}
+
+			result = append(result, map[string]interface{}{
+				"@type":     "OpeningHoursSpecification",
+				"dayOfWeek": schemaOrgDays[day],
+				"opens":     schedule.Opening,
+				"closes":    schedule.Closing,
+			})
+		}
+	}
+
+	return result
+}
+
+// Product
+
+func ProductAvailability(product *products.Product, commerce *commerces.Commerce) string {
+	variantIDs := []string{}
+
+	for _, variant := range product.Variants {
+		variantIDs = append(variantIDs, variant.ID.Hex())
+	}
+
+	return availability(commerce, product.ID.Hex(), variantIDs, product.IsOutOfStock(), product.IsInSeason(time.Now()))
+}
+
+func VariantAvailability(product *products.Product, variant *products.ProductVariant, commerce *commerces.Commerce) string {
+	isOutOfStock := variant.IsOutOfStock() || (product.Stock != nil && product.Stock.Quantity <= 0)
+
+	return availability(commerce, product.ID.Hex(), []string{variant.ID.Hex()}, isOutOfStock, product.IsInSeason(time.Now()))
+}
+
+func productModelAvailability(product *model.Product, commerce *commerces.Commerce) string {
+	variantIDs := []string{}
+
+	for _, variant := range product.Variants {
+		variantIDs = append(variantIDs, variant.ID)
+	}
+
+	return availability(commerce, product.ID, variantIDs, product.IsOutOfStock, product.SeasonStatus != model.ProductSeasonStatusOutOfSeason)
+}
+
+func availability(commerce *commerces.Commerce, productID string, variantIDs []string, isOutOfStock bool, isInSeason bool) string {
+	if !commerce.IsActive() || commerce.IsOnVacation(time.Now()) {
+		return AVAILABILITY_OUT_OF_STOCK
+	}
+
+	if isOutOfStock || !isInSeason {
+		return AVAILABILITY_OUT_OF_STOCK
+	}
+
+	if commerce.IsAvailableForClickAndCollect(productID, nil) {
+		return AVAILABILITY_IN_STOCK
+	}
+
+	for i := range variantIDs {
+		if commerce.IsAvailableForClickAndCollect(productID, &variantIDs[i]) {
+			return AVAILABILITY_IN_STOCK
+		}
+	}
+
+	return AVAILABILITY_IN_STORE_ONLY
+}
+
+func ProductJSONLD(product *model.Product, commerce *commerces.Commerce) map[string]interface{} {
+	url := productURL(commerce.ID.Hex(), product.ID)
+
+	result := map[string]interface{}{
+		"@context":    schemaOrgContext,
+		"@type":       "Product",
+		"@id":         url,
+		"url":         url,
+		"name":        product.Name,
+		"description": product.Description,
+		"category":    strings.Join(product.Categories, ", "),
+		"offers": map[string]interface{}{
+			"@type":         "Offer",
+			"url":           url,
+			"price":         product.Price,
+			"priceCurrency": "EUR",
+			"availability":  productModelAvailability(product, commerce),
+			"seller": map[string]interface{}{
+				"@type": "LocalBusiness",
+				"@id":   CommerceURL(commerce),
+				"name":  commerce.Name,
+			},
+		},
+	}
+
+	if image := productImageURL(product.ID); image != "" {
+		result["image"] = image
+	}
+
+	return result
+}
+
+// Métadonnées complètes
+
+func CommerceMetadata(commerce *model.Commerce) (*model.SeoMetadata, error) {
+	jsonLD, err := json.Marshal(CommerceJSONLD(commerce))
+
+	if err != nil {
+		return nil, err
+	}
+
+	openGraph := []*model.MetaTag{
+		{Property: "og:type", Content: "business.business"},
+		{Property: "og:title", Content: commerce.Name},
+		{Property: "og:description", Content: commerce.Description},
+		{Property: "og:url", Content: commerceURL(commerce.ID, commerce.Slug)},
+		{Property: "og:locale", Content: "fr_FR"},
+	}
+
+	if image := commerceImageURL(commerce.ID); image != "" {
+		openGraph = append(openGraph, &model.MetaTag{Property: "og:image", Content: image})
+	}
+
+	if commerce.Address.City != nil {
+		openGraph = append(openGraph, &model.MetaTag{Property: "business:contact_data:locality", Content: *commerce.Address.City})
+	}
+
+	if commerce.Address.PostalCode != nil {
+		openGraph = append(openGraph, &model.MetaTag{Property: "business:contact_data:postal_code", Content: *commerce.Address.PostalCode})
+	}
+
+	return &model.SeoMetadata{
+		JSONLd:    string(jsonLD),
+		OpenGraph: openGraph,
+	}, nil
+}
+
+func ProductMetadata(product *model.Product, commerce *commerces.Commerce) (*model.SeoMetadata, error) {
+	jsonLD, err := json.Marshal(ProductJSONLD(product, commerce))
+
+	if err != nil {
+		return nil, err
+	}
+
+	availability := "instock"
+
+	if productModelAvailability(product, commerce) == AVAILABILITY_OUT_OF_STOCK {
+		availability = "oos"
+	}
+
+	openGraph := []*model.MetaTag{
+		{Property: "og:type", Content: "product"},
+		{Property: "og:title", Content: product.Name + " - " + commerce.Name},
+		{Property: "og:description", Content: product.Description},
+		{Property: "og:url", Content: productURL(commerce.ID.Hex(), product.ID)},
+		{Property: "og:locale", Content: "fr_FR"},
+		{Property: "product:price:amount", Content: strconv.FormatFloat(product.Price, 'f', 2, 64)},
+		{Property: "product:price:currency", Content: "EUR"},
+		{Property: "product:availability", Content: availability},
+	}
+
+	if image := productImageURL(product.ID); image != "" {
+		openGraph = append(openGraph, &model.MetaTag{Property: "og:image", Content: image})
+	}
+
+	return &model.SeoMetadata{
+		JSONLd:    string(jsonLD),
+		OpenGraph: openGraph,
+	}, nil
+}
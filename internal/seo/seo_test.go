@@ -0,0 +1,86 @@
+package seo
+
+import (
+	"encoding/json"
+	"testing"
+
+	"chemin-du-local.bzh/graphql/graph/model"
+	"chemin-du-local.bzh/graphql/internal/commerces"
+	"chemin-du-local.bzh/graphql/internal/products"
+	"chemin-du-local.bzh/graphql/pkg/geojson"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestProductAvailability(t *testing.T) {
+	product := products.Product{
+		ID:         primitive.NewObjectID(),
+		CommerceID: primitive.NewObjectID(),
+		Variants: []products.ProductVariant{
+			{ID: primitive.NewObjectID()},
+		},
+	}
+
+	t.Run("a product available for click and collect is in stock", func(t *testing.T) {
+		commerce := commerces.Commerce{
+			ProductsAvailableForClickAndCollect: []string{product.ID.Hex()},
+		}
+
+		require.Equal(t, AVAILABILITY_IN_STOCK, ProductAvailability(&product, &commerce))
+		require.Equal(t, AVAILABILITY_IN_STOCK, productModelAvailability(product.ToModel(), &commerce))
+	})
+
+	t.Run("a product with a variant available for click and collect is in stock", func(t *testing.T) {
+		commerce := commerces.Commerce{
+			VariantsAvailableForClickAndCollect: []string{product.Variants[0].ID.Hex()},
+		}
+
+		require.Equal(t, AVAILABILITY_IN_STOCK, ProductAvailability(&product, &commerce))
+		require.Equal(t, AVAILABILITY_IN_STOCK, productModelAvailability(product.ToModel(), &commerce))
+	})
+
+	t.Run("a product only sold in store", func(t *testing.T) {
+		commerce := commerces.Commerce{}
+
+		require.Equal(t, AVAILABILITY_IN_STORE_ONLY, ProductAvailability(&product, &commerce))
+		require.Equal(t, AVAILABILITY_IN_STORE_ONLY, productModelAvailability(product.ToModel(), &commerce))
+	})
+
+	t.Run("nothing is available from a closed commerce", func(t *testing.T) {
+		commerce := commerces.Commerce{
+			Status:                              commerces.COMMERCE_STATUS_CLOSED,
+			ProductsAvailableForClickAndCollect: []string{product.ID.Hex()},
+		}
+
+		require.Equal(t, AVAILABILITY_OUT_OF_STOCK, ProductAvailability(&product, &commerce))
+		require.Equal(t, AVAILABILITY_OUT_OF_STOCK, productModelAvailability(product.ToModel(), &commerce))
+	})
+}
+
+func TestCommerceMetadata(t *testing.T) {
+	city := "Rennes"
+	commerce := commerces.Commerce{
+		ID:         primitive.NewObjectID(),
+		Slug:       "mon-super-commerce",
+		Name:       "Mon Super Commerce",
+		AddressGeo: geojson.NewPoint(-1.68, 48.11),
+	}
+	commerce.Address.City = &city
+
+	metadata, err := CommerceMetadata(commerce.ToModel())
+
+	require.NoError(t, err)
+
+	var jsonLD map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(metadata.JSONLd), &jsonLD))
+
+	require.Equal(t, "LocalBusiness", jsonLD["@type"])
+	require.Equal(t, "Mon Super Commerce", jsonLD["name"])
+	require.Equal(t, CommerceURL(&commerce), jsonLD["url"])
+	require.Equal(t, map[string]interface{}{
+		"@type":     "GeoCoordinates",
+		"longitude": -1.68,
+		"latitude":  48.11,
+	}, jsonLD["geo"])
+	require.Contains(t, metadata.OpenGraph, &model.MetaTag{Property: "business:contact_data:locality", Content: "Rennes"})
+}
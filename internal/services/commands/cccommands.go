@@ -1,9 +1,13 @@
 package commands
 
 import (
+	"math"
+
 	"chemin-du-local.bzh/graphql/graph/model"
 	"chemin-du-local.bzh/graphql/internal/database"
 	"chemin-du-local.bzh/graphql/internal/products"
+	"chemin-du-local.bzh/graphql/internal/promotions"
+	"chemin-du-local.bzh/graphql/pkg/utils"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -15,10 +19,32 @@ type CCCommand struct {
 	Products          []CCProduct        `bson:"products"`
 }
 
+// Pour un produit vendu au poids, Quantity est la quantité estimée à la
+// commande et ActualQuantity celle pesée à la préparation.
+//
+// Le nom, le prix, l'unité, la TVA et la remise sont ceux du produit au
+// moment de la commande. Price est le prix unitaire avant remise et
+// UnitPrice celui réellement payé. Les commandes antérieures à cet
+// instantané ont un nom vide.
 type CCProduct struct {
-	ID        primitive.ObjectID `bson:"_id"`
-	ProductID primitive.ObjectID `bson:"productID"`
-	Quantity  int                `bson:"quantity"`
+	ID             primitive.ObjectID           `bson:"_id"`
+	ProductID      primitive.ObjectID           `bson:"productID"`
+	VariantID      *primitive.ObjectID          `bson:"variantID"`
+	Quantity       float64                      `bson:"quantity"`
+	UnitPrice      float64                      `bson:"unitPrice"`
+	IsWeighed      bool                         `bson:"isWeighed"`
+	ActualQuantity *float64                     `bson:"actualQuantity"`
+	Name           string                       `bson:"name"`
+	VariantName    *string                      `bson:"variantName"`
+	Price          float64                      `bson:"price"`
+	Unit           string                       `bson:"unit"`
+	Tva            float64                      `bson:"tva"`
+	Discount       float64                      `bson:"discount"`
+	Promotion      *promotions.AppliedPromotion `bson:"promotion"`
+}
+
+func (product *CCProduct) HasSnapshot() bool {
+	return product.Name != ""
 }
 
 func (cccommand *CCCommand) ToModel() *model.CCCommand {
@@ -27,6 +53,33 @@ func (cccommand *CCCommand) ToModel() *model.CCCommand {
 	}
 }
 
+// Calcule l'écart de prix entre les poids estimés et les poids réels des
+// lignes déjà pesées, et indique si toutes les lignes au poids l'ont été
+func WeightAdjustment(cccommands []CCCommand) (float64, bool) {
+	adjustment := 0
+	isComplete := true
+
+	for _, cccommand := range cccommands {
+		for _, product := range cccommand.Products {
+			if !product.IsWeighed {
+				continue
+			}
+
+			if product.ActualQuantity == nil {
+				isComplete = false
+				continue
+			}
+
+			// Arrondi au centime ligne par ligne, comme à la commande
+			estimated := int(math.Round(product.UnitPrice * product.Quantity * 100))
+			actual := int(math.Round(product.UnitPrice * *product.ActualQuantity * 100))
+			adjustment = adjustment + actual - estimated
+		}
+	}
+
+	return float64(adjustment) / 100, isComplete
+}
+
 // Service
 
 type ccCommandsService struct {
@@ -39,6 +92,7 @@ type CCCommandsService interface {
 	GetForCommmerceCommand(commerceCommandID string) ([]CCCommand, error)
 	GetFiltered(filter interface{}, opts *options.FindOptions) ([]CCCommand, error)
 	GetProducts(cccommandID string) ([]*model.CCProduct, error)
+	SetActualQuantities(commerceCommandID string, weights []*model.ActualWeightInput) ([]CCCommand, error)
 }
 
 func NewCCCommandsService(productsService products.ProductsService) *ccCommandsService {
@@ -58,11 +112,58 @@ func (c *ccCommandsService) Create(commerceCommandID primitive.ObjectID, input m
 			return nil, err
 		}
 
-		products = append(products, CCProduct{
+		variantObjectID, err := utils.OptionalObjectID(product.VariantID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		ccProduct := CCProduct{
 			ID:        primitive.NewObjectID(),
 			ProductID: productObjectID,
+			VariantID: variantObjectID,
 			Quantity:  product.Quantity,
-		})
+		}
+
+		if product.UnitPrice != nil {
+			ccProduct.UnitPrice = *product.UnitPrice
+		}
+
+		if product.IsWeighed != nil {
+			ccProduct.IsWeighed = *product.IsWeighed
+		}
+
+		if product.Name != nil {
+			ccProduct.Name = *product.Name
+		}
+
+		ccProduct.VariantName = product.VariantName
+
+		if product.Price != nil {
+			ccProduct.Price = *product.Price
+		}
+
+		if product.Unit != nil {
+			ccProduct.Unit = *product.Unit
+		}
+
+		if product.Tva != nil {
+			ccProduct.Tva = *product.Tva
+		}
+
+		if product.Discount != nil {
+			ccProduct.Discount = *product.Discount
+		}
+
+		if product.Promotion != nil {
+			ccProduct.Promotion, err = promotions.AppliedPromotionFromInput(*product.Promotion)
+
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		products = append(products, ccProduct)
 	}
 
 	databaseCCCommand := CCCommand{
@@ -80,6 +181,62 @@ func (c *ccCommandsService) Create(commerceCommandID primitive.ObjectID, input m
 	return &databaseCCCommand, nil
 }
 
+// Mise à jour de la base de données
+
+// Enregistre le poids réel des lignes au poids d'une commande. Une ligne
+// peut être repesée tant que le paiement n'a pas été réglé.
+func (c *ccCommandsService) SetActualQuantities(commerceCommandID string, weights []*model.ActualWeightInput) ([]CCCommand, error) {
+	cccommands, err := c.GetForCommmerceCommand(commerceCommandID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, weight := range weights {
+		if weight.ActualQuantity <= 0 {
+			return nil, &InvalidActualQuantityError{}
+		}
+
+		found := false
+
+		for i := range cccommands {
+			for j := range cccommands[i].Products {
+				product := &cccommands[i].Products[j]
+
+				if product.ID.Hex() != weight.CcProductID {
+					continue
+				}
+
+				if !product.IsWeighed {
+					return nil, &CCProductNotWeighedError{}
+				}
+
+				actualQuantity := weight.ActualQuantity
+				product.ActualQuantity = &actualQuantity
+				found = true
+			}
+		}
+
+		if !found {
+			return nil, &CCProductNotFoundError{}
+		}
+	}
+
+	for _, cccommand := range cccommands {
+		_, err := database.CollectionCCCommand.UpdateOne(
+			database.MongoContext,
+			bson.M{"_id": cccommand.ID},
+			bson.M{"$set": bson.M{"products": cccommand.Products}},
+		)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return cccommands, nil
+}
+
 // Getter de base de données
 
 func (c *ccCommandsService) GetById(id string) (*CCCommand, error) {
@@ -177,10 +334,50 @@ func (c *ccCommandsService) GetProducts(cccommandID string) ([]*model.CCProduct,
 		}
 
 		if databaseProduct != nil {
-			modelProducts = append(modelProducts, &model.CCProduct{
-				Quantity: product.Quantity,
-				Product:  databaseProduct.ToModel(),
-			})
+			var variant *model.ProductVariant
+
+			if product.VariantID != nil {
+				if databaseVariant := databaseProduct.GetVariant(*product.VariantID); databaseVariant != nil {
+					variant = databaseVariant.ToModel(databaseProduct)
+				}
+			}
+
+			var promotion *model.AppliedPromotion
+
+			if product.Promotion != nil {
+				promotion = product.Promotion.ToModel()
+			}
+
+			modelProduct := model.CCProduct{
+				ID:             product.ID.Hex(),
+				Quantity:       product.Quantity,
+				Product:        databaseProduct.ToModel(),
+				Variant:        variant,
+				Name:           product.Name,
+				VariantName:    product.VariantName,
+				Price:          product.Price,
+				Unit:           product.Unit,
+				Tva:            product.Tva,
+				Discount:       product.Discount,
+				Promotion:      promotion,
+				IsWeighed:      product.IsWeighed,
+				ActualQuantity: product.ActualQuantity,
+			}
+
+			// Sans instantané, on ne peut afficher que le produit actuel
+			if !product.HasSnapshot() {
+				modelProduct.Name = databaseProduct.Name
+				modelProduct.Price = databaseProduct.Price
+				modelProduct.Unit = databaseProduct.Unit
+				modelProduct.Tva = databaseProduct.Tva
+
+				if variant != nil {
+					modelProduct.VariantName = &variant.Name
+					modelProduct.Price = variant.Price
+				}
+			}
+
+			modelProducts = append(modelProducts, &modelProduct)
 		}
 	}
 
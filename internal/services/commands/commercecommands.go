@@ -1,11 +1,14 @@
 package commands
 
 import (
+	"math"
 	"time"
 
 	"chemin-du-local.bzh/graphql/graph/model"
+	"chemin-du-local.bzh/graphql/internal/address"
 	"chemin-du-local.bzh/graphql/internal/commerces"
 	"chemin-du-local.bzh/graphql/internal/database"
+	"chemin-du-local.bzh/graphql/internal/promotions"
 	"chemin-du-local.bzh/graphql/internal/users"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -17,27 +20,186 @@ const COMMERCE_COMMAND_STATUS_READY = "READY"
 const COMMERCE_COMMAND_STATUS_DONE = "DONE"
 const COMMERCE_COMMAND_STATUS_CANCELED = "CANCELED"
 
+func IsValidCommerceCommandStatus(status string) bool {
+	switch status {
+	case COMMERCE_COMMAND_STATUS_IN_PROGRESS, COMMERCE_COMMAND_STATUS_READY, COMMERCE_COMMAND_STATUS_DONE, COMMERCE_COMMAND_STATUS_CANCELED:
+		return true
+	}
+
+	return false
+}
+
+// Les commandes créées avant le suivi du paiement n'en ont pas
+const COMMERCE_COMMAND_PAYMENT_STATUS_PENDING = "PENDING"
+const COMMERCE_COMMAND_PAYMENT_STATUS_PAID = "PAID"
+const COMMERCE_COMMAND_PAYMENT_STATUS_FAILED = "FAILED"
+
+// Marge autorisée en plus du montant estimé des produits au poids, pour
+// couvrir un poids réel supérieur à l'estimation
+const WEIGHED_PRICE_AUTHORIZATION_MARGIN = 0.2
+
 type CommerceCommand struct {
-	ID                   primitive.ObjectID `bson:"_id"`
-	CommandID            primitive.ObjectID `bson:"commandID"`
-	CommerceID           primitive.ObjectID `bson:"commerceID"`
-	PickupDate           time.Time          `bson:"pickupDate"`
-	Price                int                `bson:"price"`
-	PricePaniers         float64            `bson:"pricePaniers"`
-	PriceClickAndCollect float64            `bson:"priceClickAndCollect"`
-	PaymentMethod        string             `bson:"paymentMethod"`
-	Status               string             `bson:"status"`
+	ID                   primitive.ObjectID            `bson:"_id"`
+	CommandID            primitive.ObjectID            `bson:"commandID"`
+	CommerceID           primitive.ObjectID            `bson:"commerceID"`
+	PickupDate           time.Time                     `bson:"pickupDate"`
+	Price                int                           `bson:"price"`
+	PricePaniers         float64                       `bson:"pricePaniers"`
+	PriceClickAndCollect float64                       `bson:"priceClickAndCollect"`
+	PaymentMethod        string                        `bson:"paymentMethod"`
+	Status               string                        `bson:"status"`
+	PaymentStatus        string                        `bson:"paymentStatus"`
+	FulfilmentMode       string                        `bson:"fulfilmentMode"`
+	DeliveryAddress      *address.Address              `bson:"deliveryAddress"`
+	DeliveryFee          float64                       `bson:"deliveryFee"`
+	PickupMarketID       *primitive.ObjectID           `bson:"pickupMarketID"`
+	PickupPointID        *primitive.ObjectID           `bson:"pickupPointID"`
+	Discount             float64                       `bson:"discount"`
+	Promotions           []promotions.AppliedPromotion `bson:"promotions"`
+	PromoCode            *string                       `bson:"promoCode"`
+	PromoCodeDiscount    float64                       `bson:"promoCodeDiscount"`
+	PromoCodeFundedBy    *string                       `bson:"promoCodeFundedBy"`
+	GiftCardID           *primitive.ObjectID           `bson:"giftCardID"`
+	GiftCardCode         *string                       `bson:"giftCardCode"`
+	GiftCardAmount       float64                       `bson:"giftCardAmount"`
+	PaymentIntentID      *string                       `bson:"paymentIntentID"`
+	WeighedPrice         float64                       `bson:"weighedPrice"`
+	WeightAdjustment     float64                       `bson:"weightAdjustment"`
+	WeighedAt            *time.Time                    `bson:"weighedAt"`
+	CanceledAt           *time.Time                    `bson:"canceledAt"`
 }
 
 func (command *CommerceCommand) ToModel() *model.CommerceCommand {
+	var deliveryAddress *model.Address
+
+	if command.DeliveryAddress != nil {
+		deliveryAddress = command.DeliveryAddress.ToModel()
+	}
+
+	// Les commandes créées avant la livraison sont toutes des retraits
+	fulfilmentMode := model.FulfilmentModePickup
+
+	if command.FulfilmentMode != "" {
+		fulfilmentMode = model.FulfilmentMode(command.FulfilmentMode)
+	}
+
+	var pickupMarketID *string
+
+	if command.PickupMarketID != nil {
+		pickupMarketIDValue := command.PickupMarketID.Hex()
+		pickupMarketID = &pickupMarketIDValue
+	}
+
+	var pickupPointID *string
+
+	if command.PickupPointID != nil {
+		pickupPointIDValue := command.PickupPointID.Hex()
+		pickupPointID = &pickupPointIDValue
+	}
+
+	var promoCodeFundedBy *model.PromoCodeFunding
+
+	if command.PromoCodeFundedBy != nil {
+		promoCodeFundedByValue := model.PromoCodeFunding(*command.PromoCodeFundedBy)
+		promoCodeFundedBy = &promoCodeFundedByValue
+	}
+
+	appliedPromotions := []*model.AppliedPromotion{}
+
+	for i := range command.Promotions {
+		appliedPromotions = append(appliedPromotions, command.Promotions[i].ToModel())
+	}
+
 	return &model.CommerceCommand{
-		ID:         command.ID.Hex(),
-		PickupDate: command.PickupDate,
-		Status:     command.Status,
-		Price:      float64(command.Price) / 100,
+		ID:              command.ID.Hex(),
+		PickupDate:      command.PickupDate,
+		Status:          command.Status,
+		Price:           float64(command.Price) / 100,
+		FulfilmentMode:  fulfilmentMode,
+		DeliveryAddress: deliveryAddress,
+		DeliveryFee:     command.DeliveryFee,
+		PickupMarketID:  pickupMarketID,
+		PickupPointID:   pickupPointID,
+		Discount:        command.Discount,
+		Promotions:      appliedPromotions,
+
+		PromoCode:         command.PromoCode,
+		PromoCodeDiscount: command.PromoCodeDiscount,
+		PromoCodeFundedBy: promoCodeFundedBy,
+
+		GiftCardCode:   command.GiftCardCode,
+		GiftCardAmount: command.GiftCardAmount,
+
+		WeightAdjustment:  command.WeightAdjustment,
+		IsAwaitingWeights: command.IsAwaitingWeights(),
 	}
 }
 
+// Les commandes créées avant le suivi du paiement passaient à DONE une
+// fois payées
+func (command *CommerceCommand) IsPaid() bool {
+	if command.PaymentStatus != "" {
+		return command.PaymentStatus == COMMERCE_COMMAND_PAYMENT_STATUS_PAID
+	}
+
+	return command.Status == COMMERCE_COMMAND_STATUS_DONE || command.Status == COMMERCE_COMMAND_STATUS_READY
+}
+
+// Conditions, à placer sous un $or, des commandes payées au sens de
+// IsPaid
+func PaidFilter() bson.A {
+	return bson.A{
+		bson.M{
+			"paymentStatus": COMMERCE_COMMAND_PAYMENT_STATUS_PAID,
+		},
+		bson.M{
+			"paymentStatus": bson.M{
+				"$in": bson.A{nil, ""},
+			},
+			"status": bson.M{
+				"$in": bson.A{
+					COMMERCE_COMMAND_STATUS_READY,
+					COMMERCE_COMMAND_STATUS_DONE,
+				},
+			},
+		},
+	}
+}
+
+// Le commerce est crédité au paiement, ou une fois les produits au poids
+// pesés
+func (command *CommerceCommand) IsCredited() bool {
+	return command.IsPaid() && !command.IsAwaitingWeights()
+}
+
+// Le montant final n'est connu qu'une fois les produits au poids pesés
+func (command *CommerceCommand) IsAwaitingWeights() bool {
+	return command.WeighedPrice > 0 && command.WeighedAt == nil
+}
+
+// Montant en centimes à autoriser au paiement, avec une marge sur les
+// produits au poids qui n'ont pas encore été pesés
+func (command *CommerceCommand) AuthorizedPrice() int {
+	if !command.IsAwaitingWeights() {
+		return command.Price
+	}
+
+	return command.Price + int(math.Round(command.WeighedPrice*100*WEIGHED_PRICE_AUTHORIZATION_MARGIN))
+}
+
+// Montant que la plateforme doit reverser au commerce : sa part du code
+// promo s'il est à la charge de la plateforme, et ce qui a été payé par
+// carte cadeau
+func (command *CommerceCommand) PlatformFundedAmount() float64 {
+	amount := command.GiftCardAmount
+
+	if command.PromoCodeFundedBy != nil && *command.PromoCodeFundedBy == model.PromoCodeFundingPlatform.String() {
+		amount = amount + command.PromoCodeDiscount
+	}
+
+	return amount
+}
+
 func (commerceCommand CommerceCommand) IsLast(commerceCommandsService CommerceCommandsService) bool {
 	filter := bson.D{{}}
 
@@ -106,6 +268,88 @@ func (c *commerceCommandsService) Create(input model.NewCommerceCommand, command
 		PricePaniers:         input.PricePaniers,
 		PaymentMethod:        input.PaymentMethod,
 		Status:               COMMERCE_COMMAND_STATUS_IN_PROGRESS,
+		PaymentStatus:        COMMERCE_COMMAND_PAYMENT_STATUS_PENDING,
+		FulfilmentMode:       model.FulfilmentModePickup.String(),
+		Promotions:           []promotions.AppliedPromotion{},
+	}
+
+	if input.FulfilmentMode != nil {
+		databaseCommerceCommand.FulfilmentMode = input.FulfilmentMode.String()
+	}
+
+	if input.DeliveryAddress != nil {
+		deliveryAddress := address.FromInput(input.DeliveryAddress)
+		databaseCommerceCommand.DeliveryAddress = &deliveryAddress
+	}
+
+	if input.DeliveryFee != nil {
+		databaseCommerceCommand.DeliveryFee = *input.DeliveryFee
+	}
+
+	if input.Discount != nil {
+		databaseCommerceCommand.Discount = *input.Discount
+	}
+
+	if input.WeighedPrice != nil {
+		databaseCommerceCommand.WeighedPrice = *input.WeighedPrice
+	}
+
+	for _, promotionInput := range input.Promotions {
+		appliedPromotion, err := promotions.AppliedPromotionFromInput(*promotionInput)
+
+		if err != nil {
+			return nil, err
+		}
+
+		databaseCommerceCommand.Promotions = append(databaseCommerceCommand.Promotions, *appliedPromotion)
+	}
+
+	if input.PromoCode != nil {
+		databaseCommerceCommand.PromoCode = input.PromoCode
+
+		if input.PromoCodeDiscount != nil {
+			databaseCommerceCommand.PromoCodeDiscount = *input.PromoCodeDiscount
+		}
+
+		if input.PromoCodeFundedBy != nil {
+			promoCodeFundedBy := input.PromoCodeFundedBy.String()
+			databaseCommerceCommand.PromoCodeFundedBy = &promoCodeFundedBy
+		}
+	}
+
+	if input.GiftCardID != nil {
+		giftCardID, err := primitive.ObjectIDFromHex(*input.GiftCardID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		databaseCommerceCommand.GiftCardID = &giftCardID
+		databaseCommerceCommand.GiftCardCode = input.GiftCardCode
+
+		if input.GiftCardAmount != nil {
+			databaseCommerceCommand.GiftCardAmount = *input.GiftCardAmount
+		}
+	}
+
+	if input.PickupMarketID != nil {
+		pickupMarketID, err := primitive.ObjectIDFromHex(*input.PickupMarketID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		databaseCommerceCommand.PickupMarketID = &pickupMarketID
+	}
+
+	if input.PickupPointID != nil {
+		pickupPointID, err := primitive.ObjectIDFromHex(*input.PickupPointID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		databaseCommerceCommand.PickupPointID = &pickupPointID
 	}
 
 	_, err = database.CollectionCommerceCommand.InsertOne(database.MongoContext, databaseCommerceCommand)
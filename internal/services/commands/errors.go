@@ -4,8 +4,16 @@ type MustSpecifyCommerceIDError struct{}
 type CommerceCommandNotFoundError struct{}
 type CommandNotFoundError struct{}
 
+type InvalidCommerceCommandStatusError struct{}
+type CommerceCommandCanceledError struct{}
+type CommerceCommandAlreadyWeighedError struct{}
+type PaymentNotSettleableError struct{}
+
 // Click & Collect
 type CCCommandNotFoundError struct{}
+type CCProductNotFoundError struct{}
+type CCProductNotWeighedError struct{}
+type InvalidActualQuantityError struct{}
 
 func (m *MustSpecifyCommerceIDError) Error() string {
 	return "vous devez préciser un identifiant de commerce"
@@ -19,8 +27,36 @@ func (m *CommandNotFoundError) Error() string {
 	return "La commande n'a pas été trouvée"
 }
 
+func (m *InvalidCommerceCommandStatusError) Error() string {
+	return "le statut de la commande n'existe pas"
+}
+
+func (m *CommerceCommandCanceledError) Error() string {
+	return "une commande annulée ne peut plus changer de statut"
+}
+
+func (m *CommerceCommandAlreadyWeighedError) Error() string {
+	return "les produits de la commande ont déjà été pesés et le paiement réglé"
+}
+
+func (m *PaymentNotSettleableError) Error() string {
+	return "le paiement de la commande a expiré ou n'a pas abouti, il ne peut pas être ajusté"
+}
+
 // Click & Collect
 
 func (m *CCCommandNotFoundError) Error() string {
 	return "la command n'a pas été trouvée"
 }
+
+func (m *CCProductNotFoundError) Error() string {
+	return "le produit n'appartient pas à la commande"
+}
+
+func (m *CCProductNotWeighedError) Error() string {
+	return "seuls les produits vendus au poids peuvent être pesés"
+}
+
+func (m *InvalidActualQuantityError) Error() string {
+	return "le poids réel doit être positif"
+}
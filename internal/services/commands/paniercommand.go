@@ -3,23 +3,44 @@ package commands
 import (
 	"chemin-du-local.bzh/graphql/graph/model"
 	"chemin-du-local.bzh/graphql/internal/database"
+	"chemin-du-local.bzh/graphql/internal/promotions"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// Le nom et le prix sont ceux du panier au moment de la commande. Les
+// commandes antérieures à cet instantané ont un nom vide.
 type PanierCommand struct {
-	ID                primitive.ObjectID `bson:"_id"`
-	CommerceCommandID primitive.ObjectID `bson:"commerceCommandID"`
-	PanierID          primitive.ObjectID `bson:"panierID"`
+	ID                primitive.ObjectID           `bson:"_id"`
+	CommerceCommandID primitive.ObjectID           `bson:"commerceCommandID"`
+	PanierID          primitive.ObjectID           `bson:"panierID"`
+	Name              string                       `bson:"name"`
+	Price             float64                      `bson:"price"`
+	Discount          float64                      `bson:"discount"`
+	Promotion         *promotions.AppliedPromotion `bson:"promotion"`
 }
 
 func (panierCommand *PanierCommand) ToModel() *model.PanierCommand {
+	var promotion *model.AppliedPromotion
+
+	if panierCommand.Promotion != nil {
+		promotion = panierCommand.Promotion.ToModel()
+	}
+
 	return &model.PanierCommand{
-		ID: panierCommand.ID.Hex(),
+		ID:        panierCommand.ID.Hex(),
+		Name:      panierCommand.Name,
+		Price:     panierCommand.Price,
+		Discount:  panierCommand.Discount,
+		Promotion: promotion,
 	}
 }
 
+func (panierCommand *PanierCommand) HasSnapshot() bool {
+	return panierCommand.Name != ""
+}
+
 // Service
 
 type panierCommandsService struct{}
@@ -50,6 +71,26 @@ func (p *panierCommandsService) Create(commerceCommandID primitive.ObjectID, inp
 		PanierID:          panierObjectID,
 	}
 
+	if input.Name != nil {
+		databasePanierCommand.Name = *input.Name
+	}
+
+	if input.Price != nil {
+		databasePanierCommand.Price = *input.Price
+	}
+
+	if input.Discount != nil {
+		databasePanierCommand.Discount = *input.Discount
+	}
+
+	if input.Promotion != nil {
+		databasePanierCommand.Promotion, err = promotions.AppliedPromotionFromInput(*input.Promotion)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	_, err = database.CollectionPanierCommands.InsertOne(database.MongoContext, databasePanierCommand)
 
 	if err != nil {
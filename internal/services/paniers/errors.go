@@ -5,3 +5,9 @@ type PanierNotFoundError struct{}
 func (m *PanierNotFoundError) Error() string {
 	return "le panier n'a pas été trouvé"
 }
+
+type InvalidReductionError struct{}
+
+func (m *InvalidReductionError) Error() string {
+	return "la réduction doit être comprise entre 0 et 100 %"
+}
@@ -3,6 +3,7 @@ package paniers
 import (
 	"bytes"
 	"io/ioutil"
+	"math"
 	"os"
 	"time"
 
@@ -25,7 +26,7 @@ type Panier struct {
 	Category    string             `bson:"category"`
 	Quantity    int                `bson:"quantity"`
 	Price       float64            `bson:"price"`
-	Reduction   float64            `bons:"reduction"`
+	Reduction   float64            `bson:"reduction"`
 	EndingDate  *time.Time         `bson:"endingDate"`
 	Products    []PanierProduct    `bson:"products"`
 }
@@ -38,18 +39,34 @@ type PanierProduct struct {
 
 func (panier *Panier) ToModel() *model.Panier {
 	return &model.Panier{
-		ID:          panier.ID.Hex(),
-		Name:        panier.Name,
-		Description: panier.Description,
-		Type:        panier.Type,
-		Category:    panier.Category,
-		Quantity:    panier.Quantity,
-		EndingDate:  panier.EndingDate,
-		Price:       panier.Price,
-		Reduction:   panier.Reduction,
+		ID:            panier.ID.Hex(),
+		CommerceID:    panier.CommerceID.Hex(),
+		Name:          panier.Name,
+		Description:   panier.Description,
+		Type:          panier.Type,
+		Category:      panier.Category,
+		Quantity:      panier.Quantity,
+		EndingDate:    panier.EndingDate,
+		Price:         panier.Price,
+		Reduction:     panier.Reduction,
+		OriginalPrice: panier.Price,
 	}
 }
 
+// Prix après la réduction du panier, un pourcentage permanent du prix
+// auquel s'ajoutent les promotions en cours
+func ReducedPrice(price float64, reduction float64) float64 {
+	return math.Round(price*(100-reduction)) / 100
+}
+
+func (panier *Panier) GetPrice() float64 {
+	return ReducedPrice(panier.Price, panier.Reduction)
+}
+
+func isValidReduction(reduction float64) bool {
+	return reduction >= 0 && reduction <= 100
+}
+
 func (panier Panier) IsLast(paniersService PaniersService) bool {
 	filter := bson.D{
 		primitive.E{
@@ -99,6 +116,10 @@ func NewPaniersService(productsService products.ProductsService) *paniersService
 // Créateur de base de données
 
 func (p *paniersService) Create(commerceID primitive.ObjectID, input model.NewPanier) (*Panier, error) {
+	if !isValidReduction(input.Reduction) {
+		return nil, &InvalidReductionError{}
+	}
+
 	products := []PanierProduct{}
 	for _, product := range input.Products {
 		productObjectID, err := primitive.ObjectIDFromHex(product.ProductID)
@@ -158,6 +179,10 @@ func (p *paniersService) Create(commerceID primitive.ObjectID, input model.NewPa
 // Mise à jour de la base de données
 
 func (p *paniersService) Update(changes *Panier, image *graphql.Upload) error {
+	if !isValidReduction(changes.Reduction) {
+		return &InvalidReductionError{}
+	}
+
 	filter := bson.D{
 		primitive.E{
 			Key:   "_id",
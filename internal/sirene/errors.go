@@ -0,0 +1,24 @@
+package sirene
+
+type InvalidSiretError struct{}
+type EstablishmentNotFoundError struct{}
+type EstablishmentClosedError struct{}
+type ImportMissingColumnError struct {
+	Column string
+}
+
+func (m *InvalidSiretError) Error() string {
+	return "le SIRET doit contenir 14 chiffres et une clé de contrôle valide"
+}
+
+func (m *EstablishmentNotFoundError) Error() string {
+	return "l'établissement n'a pas été trouvé dans la base SIRENE"
+}
+
+func (m *EstablishmentClosedError) Error() string {
+	return "l'établissement est fermé selon la base SIRENE"
+}
+
+func (m *ImportMissingColumnError) Error() string {
+	return "la colonne " + m.Column + " est absente du fichier SIRENE"
+}
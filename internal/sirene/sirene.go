@@ -0,0 +1,298 @@
+package sirene
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+
+	"chemin-du-local.bzh/graphql/graph/model"
+	"chemin-du-local.bzh/graphql/internal/address"
+	"chemin-du-local.bzh/graphql/internal/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// L'état administratif d'un établissement dans la base SIRENE :
+// A pour actif, F pour fermé
+const ESTABLISHMENT_STATE_ACTIVE = "A"
+const ESTABLISHMENT_STATE_CLOSED = "F"
+
+// Nombre de lignes envoyées à la base de données en une seule fois
+// lors de l'import
+const importBatchSize = 1000
+
+type Establishment struct {
+	ID                  primitive.ObjectID `bson:"_id,omitempty"`
+	Siret               string             `bson:"siret"`
+	Siren               string             `bson:"siren"`
+	Name                string             `bson:"name"`
+	Address             address.Address    `bson:"address"`
+	NafCode             string             `bson:"nafCode"`
+	AdministrativeState string             `bson:"administrativeState"`
+}
+
+func (establishment *Establishment) ToModel() *model.SiretLookup {
+	return &model.SiretLookup{
+		Siret:    establishment.Siret,
+		Siren:    establishment.Siren,
+		Name:     establishment.Name,
+		Address:  establishment.Address.ToModel(),
+		NafCode:  establishment.NafCode,
+		IsActive: establishment.IsActive(),
+	}
+}
+
+func (establishment *Establishment) IsActive() bool {
+	return establishment.AdministrativeState == ESTABLISHMENT_STATE_ACTIVE
+}
+
+// SIREN de La Poste, dont les établissements ne suivent pas la clé de
+// Luhn : la somme de leurs chiffres est un multiple de 5
+const laPosteSiren = "356000000"
+
+// Un SIRET est composé de 14 chiffres, le dernier étant une clé de
+// contrôle de Luhn
+func IsValidSiret(siret string) bool {
+	if len(siret) != 14 {
+		return false
+	}
+
+	luhnSum := 0
+	digitsSum := 0
+
+	for index := range siret {
+		digit := int(siret[len(siret)-1-index] - '0')
+
+		if digit < 0 || digit > 9 {
+			return false
+		}
+
+		digitsSum = digitsSum + digit
+
+		// Un chiffre sur deux est doublé en partant de la droite
+		if index%2 == 1 {
+			digit = digit * 2
+
+			if digit > 9 {
+				digit = digit - 9
+			}
+		}
+
+		luhnSum = luhnSum + digit
+	}
+
+	if luhnSum%10 == 0 {
+		return true
+	}
+
+	return strings.HasPrefix(siret, laPosteSiren) && digitsSum%5 == 0
+}
+
+// Service
+
+type sireneService struct{}
+
+type SireneService interface {
+	Import(reader io.Reader) (int, error)
+	GetBySiret(siret string) (*Establishment, error)
+	GetFiltered(filter interface{}, opts *options.FindOptions) ([]Establishment, error)
+}
+
+func NewSireneService() *sireneService {
+	return &sireneService{}
+}
+
+// Import
+
+// Importe un extrait CSV du stock des établissements SIRENE. Les colonnes
+// sont retrouvées à partir de l'entête du fichier, ce qui permet
+// d'utiliser aussi bien l'extrait complet de l'INSEE qu'un extrait
+// ne contenant que les colonnes utiles.
+func (s *sireneService) Import(reader io.Reader) (int, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.LazyQuotes = true
+	csvReader.FieldsPerRecord = -1
+
+	header, err := csvReader.Read()
+
+	if err != nil {
+		return 0, err
+	}
+
+	// Certains extraits sont séparés par des points-virgules
+	if len(header) == 1 && strings.Contains(header[0], ";") {
+		header = strings.Split(header[0], ";")
+		csvReader.Comma = ';'
+	}
+
+	columns := map[string]int{}
+
+	for index, column := range header {
+		columns[strings.TrimSpace(strings.TrimPrefix(column, "\ufeff"))] = index
+	}
+
+	for _, requiredColumn := range []string{"siret", "etatAdministratifEtablissement"} {
+		if _, ok := columns[requiredColumn]; !ok {
+			return 0, &ImportMissingColumnError{Column: requiredColumn}
+		}
+	}
+
+	value := func(record []string, column string) string {
+		index, ok := columns[column]
+
+		if !ok || index >= len(record) {
+			return ""
+		}
+
+		return strings.TrimSpace(record[index])
+	}
+
+	optionalValue := func(record []string, column string) *string {
+		result := value(record, column)
+
+		if result == "" {
+			return nil
+		}
+
+		return &result
+	}
+
+	imported := 0
+	writes := []mongo.WriteModel{}
+
+	for {
+		record, err := csvReader.Read()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return imported, err
+		}
+
+		siret := value(record, "siret")
+
+		if siret == "" {
+			continue
+		}
+
+		siren := value(record, "siren")
+
+		if siren == "" && len(siret) >= 9 {
+			siren = siret[0:9]
+		}
+
+		// Le nom peut se trouver dans plusieurs colonnes selon l'extrait
+		name := ""
+
+		for _, nameColumn := range []string{"denominationUniteLegale", "denominationUsuelleEtablissement", "enseigne1Etablissement"} {
+			name = value(record, nameColumn)
+
+			if name != "" {
+				break
+			}
+		}
+
+		number := strings.TrimSpace(value(record, "numeroVoieEtablissement") + " " + value(record, "indiceRepetitionEtablissement"))
+		route := strings.TrimSpace(value(record, "typeVoieEtablissement") + " " + value(record, "libelleVoieEtablissement"))
+
+		establishment := Establishment{
+			Siret: siret,
+			Siren: siren,
+			Name:  name,
+			Address: address.Address{
+				ID:            primitive.NewObjectID(),
+				Number:        &number,
+				Route:         &route,
+				OptionalRoute: optionalValue(record, "complementAdresseEtablissement"),
+				PostalCode:    optionalValue(record, "codePostalEtablissement"),
+				City:          optionalValue(record, "libelleCommuneEtablissement"),
+			},
+			NafCode:             value(record, "activitePrincipaleEtablissement"),
+			AdministrativeState: value(record, "etatAdministratifEtablissement"),
+		}
+
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"siret": siret}).
+			SetUpdate(bson.M{"$set": establishment}).
+			SetUpsert(true),
+		)
+
+		if len(writes) >= importBatchSize {
+			_, err := database.CollectionSirene.BulkWrite(database.MongoContext, writes)
+
+			if err != nil {
+				return imported, err
+			}
+
+			imported += len(writes)
+			writes = []mongo.WriteModel{}
+		}
+	}
+
+	if len(writes) > 0 {
+		_, err := database.CollectionSirene.BulkWrite(database.MongoContext, writes)
+
+		if err != nil {
+			return imported, err
+		}
+
+		imported += len(writes)
+	}
+
+	return imported, nil
+}
+
+// Getter de base de données
+
+func (s *sireneService) GetBySiret(siret string) (*Establishment, error) {
+	filter := bson.D{
+		primitive.E{
+			Key:   "siret",
+			Value: siret,
+		},
+	}
+
+	establishments, err := s.GetFiltered(filter, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(establishments) == 0 {
+		return nil, nil
+	}
+
+	return &establishments[0], nil
+}
+
+func (s *sireneService) GetFiltered(filter interface{}, opts *options.FindOptions) ([]Establishment, error) {
+	establishments := []Establishment{}
+
+	cursor, err := database.CollectionSirene.Find(database.MongoContext, filter, opts)
+
+	if err != nil {
+		return establishments, err
+	}
+
+	for cursor.Next(database.MongoContext) {
+		var establishment Establishment
+
+		err := cursor.Decode(&establishment)
+
+		if err != nil {
+			return establishments, err
+		}
+
+		establishments = append(establishments, establishment)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return establishments, err
+	}
+
+	return establishments, nil
+}
@@ -0,0 +1,19 @@
+package sirene
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsValidSiret(t *testing.T) {
+	require.True(t, IsValidSiret("73282932000074"))
+	require.True(t, IsValidSiret("12345678900015"))
+	// Établissement de La Poste, hors clé de Luhn
+	require.True(t, IsValidSiret("35600000012341"))
+
+	require.False(t, IsValidSiret("12345678900012"))
+	require.False(t, IsValidSiret("1234567890001A"))
+	require.False(t, IsValidSiret("1234567890001"))
+	require.False(t, IsValidSiret("35600000012342"))
+}
@@ -0,0 +1,7 @@
+package statistics
+
+type InvalidPeriodError struct{}
+
+func (m *InvalidPeriodError) Error() string {
+	return "la date de début de la période doit précéder la date de fin"
+}
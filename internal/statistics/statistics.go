@@ -0,0 +1,455 @@
+package statistics
+
+import (
+	"fmt"
+	"math"
+	"time"
+	_ "time/tzdata"
+
+	"chemin-du-local.bzh/graphql/graph/model"
+	"chemin-du-local.bzh/graphql/internal/config"
+	"chemin-du-local.bzh/graphql/internal/database"
+	"chemin-du-local.bzh/graphql/internal/products"
+	"chemin-du-local.bzh/graphql/internal/services/commands"
+	"chemin-du-local.bzh/graphql/internal/services/paniers"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Les commandes sont regroupées selon l'heure française, quel que soit
+// le fuseau du serveur
+const statisticsTimezone = "Europe/Paris"
+
+// Nombre de produits et de paniers renvoyés dans les classements
+const topLimit = 5
+
+// Service
+
+type statisticsService struct {
+	ProductsService products.ProductsService
+	PaniersService  paniers.PaniersService
+}
+
+type StatisticsService interface {
+	GetForCommerce(commerceID string, period model.StatisticsPeriod, granularity model.StatisticsGranularity) (*model.CommerceStatistics, error)
+}
+
+func NewStatisticsService(
+	productsService products.ProductsService,
+	paniersService paniers.PaniersService,
+) *statisticsService {
+	return &statisticsService{
+		ProductsService: productsService,
+		PaniersService:  paniersService,
+	}
+}
+
+// Calcul des statistiques
+
+func (s *statisticsService) GetForCommerce(commerceID string, period model.StatisticsPeriod, granularity model.StatisticsGranularity) (*model.CommerceStatistics, error) {
+	commerceObjectID, err := primitive.ObjectIDFromHex(commerceID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !period.From.Before(period.To) {
+		return nil, &InvalidPeriodError{}
+	}
+
+	location, err := time.LoadLocation(statisticsTimezone)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Toutes les commandes payées et non annulées du commerce sur la
+	// période : les commandes abandonnées ou dont le paiement a échoué ne
+	// comptent pas
+	periodFilter := bson.M{
+		"commerceID": commerceObjectID,
+		"status": bson.M{
+			"$ne": commands.COMMERCE_COMMAND_STATUS_CANCELED,
+		},
+		"$or": commands.PaidFilter(),
+		"pickupDate": bson.M{
+			"$gte": period.From,
+			"$lt":  period.To,
+		},
+	}
+
+	result := model.CommerceStatistics{
+		TopProducts: []*model.ProductStatistics{},
+		TopPaniers:  []*model.PanierStatistics{},
+		TimeSeries:  []*model.StatisticsPoint{},
+	}
+
+	// Le chiffre d'affaire et le nombre de commandes
+	var totals []struct {
+		Revenue    float64 `bson:"revenue"`
+		OrderCount int     `bson:"orderCount"`
+	}
+
+	err = aggregate(database.CollectionCommerceCommand, mongo.Pipeline{
+		{{Key: "$match", Value: periodFilter}},
+		{{Key: "$group", Value: bson.M{
+			"_id":        nil,
+			"revenue":    bson.M{"$sum": "$price"},
+			"orderCount": bson.M{"$sum": 1},
+		}}},
+	}, &totals)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(totals) > 0 {
+		// Les prix des commandes sont stockés en centimes
+		result.Revenue = totals[0].Revenue / 100
+		result.OrderCount = totals[0].OrderCount
+	}
+
+	if result.OrderCount > 0 {
+		result.AverageBasket = math.Round(result.Revenue/float64(result.OrderCount)*100) / 100
+	}
+
+	// La série temporelle
+	result.TimeSeries, err = s.getTimeSeries(periodFilter, period, granularity, location)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Les produits et paniers les plus vendus
+	commerceCommandIDs, err := database.CollectionCommerceCommand.Distinct(database.MongoContext, "_id", periodFilter)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(commerceCommandIDs) > 0 {
+		result.TopProducts, err = s.getTopProducts(commerceCommandIDs)
+
+		if err != nil {
+			return nil, err
+		}
+
+		result.TopPaniers, err = s.getTopPaniers(commerceCommandIDs)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Les nouveaux clients et les clients fidèles
+	result.NewCustomers, result.ReturningCustomers, err = getCustomers(commerceObjectID, period)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (s *statisticsService) getTimeSeries(
+	periodFilter bson.M,
+	period model.StatisticsPeriod,
+	granularity model.StatisticsGranularity,
+	location *time.Location,
+) ([]*model.StatisticsPoint, error) {
+	var buckets []struct {
+		Key        string  `bson:"_id"`
+		Revenue    float64 `bson:"revenue"`
+		OrderCount int     `bson:"orderCount"`
+	}
+
+	err := aggregate(database.CollectionCommerceCommand, mongo.Pipeline{
+		{{Key: "$match", Value: periodFilter}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{
+				"$dateToString": bson.M{
+					"format":   bucketFormat(granularity),
+					"date":     "$pickupDate",
+					"timezone": statisticsTimezone,
+				},
+			},
+			"revenue":    bson.M{"$sum": "$price"},
+			"orderCount": bson.M{"$sum": 1},
+		}}},
+	}, &buckets)
+
+	if err != nil {
+		return nil, err
+	}
+
+	bucketsByKey := map[string]int{}
+
+	for index, bucket := range buckets {
+		bucketsByKey[bucket.Key] = index
+	}
+
+	// On veut aussi les périodes sans commande pour pouvoir
+	// afficher directement un graphique
+	points := []*model.StatisticsPoint{}
+
+	for date := bucketStart(period.From.In(location), granularity); date.Before(period.To); date = nextBucket(date, granularity) {
+		point := model.StatisticsPoint{
+			Date: date,
+		}
+
+		if index, ok := bucketsByKey[bucketKey(date, granularity)]; ok {
+			point.Revenue = buckets[index].Revenue / 100
+			point.OrderCount = buckets[index].OrderCount
+		}
+
+		points = append(points, &point)
+	}
+
+	return points, nil
+}
+
+// Le chiffre d'affaire d'un produit est celui de ses lignes au prix payé
+// à la commande, et au poids réel pour les produits pesés. Seules les
+// lignes antérieures aux instantanés sont valorisées au prix actuel.
+func (s *statisticsService) getTopProducts(commerceCommandIDs []interface{}) ([]*model.ProductStatistics, error) {
+	var ranking []struct {
+		ProductID      primitive.ObjectID `bson:"_id"`
+		Quantity       float64            `bson:"quantity"`
+		Revenue        float64            `bson:"revenue"`
+		LegacyQuantity float64            `bson:"legacyQuantity"`
+	}
+
+	hasSnapshot := bson.M{"$gt": bson.A{"$products.name", ""}}
+
+	err := aggregate(database.CollectionCCCommand, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"commerceCommandID": bson.M{"$in": commerceCommandIDs},
+		}}},
+		{{Key: "$unwind", Value: "$products"}},
+		{{Key: "$group", Value: bson.M{
+			"_id":      "$products.productID",
+			"quantity": bson.M{"$sum": "$products.quantity"},
+			"revenue": bson.M{"$sum": bson.M{
+				"$cond": bson.A{
+					hasSnapshot,
+					bson.M{"$round": bson.A{
+						bson.M{"$multiply": bson.A{
+							"$products.unitPrice",
+							bson.M{"$ifNull": bson.A{"$products.actualQuantity", "$products.quantity"}},
+						}},
+						2,
+					}},
+					0,
+				},
+			}},
+			"legacyQuantity": bson.M{"$sum": bson.M{
+				"$cond": bson.A{hasSnapshot, 0, "$products.quantity"},
+			}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "quantity", Value: -1}}}},
+		{{Key: "$limit", Value: topLimit}},
+	}, &ranking)
+
+	if err != nil {
+		return nil, err
+	}
+
+	topProducts := []*model.ProductStatistics{}
+
+	for _, entry := range ranking {
+		databaseProduct, err := s.ProductsService.GetById(entry.ProductID.Hex())
+
+		if err != nil {
+			return nil, err
+		}
+
+		if databaseProduct == nil {
+			continue
+		}
+
+		topProducts = append(topProducts, &model.ProductStatistics{
+			Product:  databaseProduct.ToModel(),
+			Quantity: entry.Quantity,
+			Revenue:  math.Round((entry.Revenue+databaseProduct.Price*entry.LegacyQuantity)*100) / 100,
+		})
+	}
+
+	return topProducts, nil
+}
+
+// Comme pour les produits, les paniers sont valorisés au prix payé à la
+// commande, remise déduite
+func (s *statisticsService) getTopPaniers(commerceCommandIDs []interface{}) ([]*model.PanierStatistics, error) {
+	var ranking []struct {
+		PanierID       primitive.ObjectID `bson:"_id"`
+		Quantity       int                `bson:"quantity"`
+		Revenue        float64            `bson:"revenue"`
+		LegacyQuantity int                `bson:"legacyQuantity"`
+	}
+
+	hasSnapshot := bson.M{"$gt": bson.A{"$name", ""}}
+
+	err := aggregate(database.CollectionPanierCommands, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"commerceCommandID": bson.M{"$in": commerceCommandIDs},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":      "$panierID",
+			"quantity": bson.M{"$sum": 1},
+			"revenue": bson.M{"$sum": bson.M{
+				"$cond": bson.A{
+					hasSnapshot,
+					bson.M{"$subtract": bson.A{"$price", bson.M{"$ifNull": bson.A{"$discount", 0}}}},
+					0,
+				},
+			}},
+			"legacyQuantity": bson.M{"$sum": bson.M{
+				"$cond": bson.A{hasSnapshot, 0, 1},
+			}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "quantity", Value: -1}}}},
+		{{Key: "$limit", Value: topLimit}},
+	}, &ranking)
+
+	if err != nil {
+		return nil, err
+	}
+
+	topPaniers := []*model.PanierStatistics{}
+
+	for _, entry := range ranking {
+		databasePanier, err := s.PaniersService.GetById(entry.PanierID.Hex())
+
+		if err != nil {
+			return nil, err
+		}
+
+		if databasePanier == nil {
+			continue
+		}
+
+		topPaniers = append(topPaniers, &model.PanierStatistics{
+			Panier:   databasePanier.ToModel(),
+			Quantity: entry.Quantity,
+			Revenue:  math.Round((entry.Revenue+databasePanier.GetPrice()*float64(entry.LegacyQuantity))*100) / 100,
+		})
+	}
+
+	return topPaniers, nil
+}
+
+// Un client est nouveau si sa première commande dans le commerce a lieu
+// pendant la période, et fidèle s'il avait déjà commandé avant
+func getCustomers(commerceObjectID primitive.ObjectID, period model.StatisticsPeriod) (int, int, error) {
+	var customers []struct {
+		NewCustomers       int `bson:"newCustomers"`
+		ReturningCustomers int `bson:"returningCustomers"`
+	}
+
+	err := aggregate(database.CollectionCommerceCommand, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"commerceID": commerceObjectID,
+			"status": bson.M{
+				"$ne": commands.COMMERCE_COMMAND_STATUS_CANCELED,
+			},
+			"$or": commands.PaidFilter(),
+			"pickupDate": bson.M{
+				"$lt": period.To,
+			},
+		}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from":         config.Cfg.Database.Collections.Commands,
+			"localField":   "commandID",
+			"foreignField": "_id",
+			"as":           "command",
+		}}},
+		{{Key: "$unwind", Value: "$command"}},
+		{{Key: "$group", Value: bson.M{
+			"_id":        "$command.userID",
+			"firstOrder": bson.M{"$min": "$pickupDate"},
+			"lastOrder":  bson.M{"$max": "$pickupDate"},
+		}}},
+		{{Key: "$match", Value: bson.M{
+			"lastOrder": bson.M{"$gte": period.From},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id": nil,
+			"newCustomers": bson.M{"$sum": bson.M{
+				"$cond": bson.A{bson.M{"$gte": bson.A{"$firstOrder", period.From}}, 1, 0},
+			}},
+			"returningCustomers": bson.M{"$sum": bson.M{
+				"$cond": bson.A{bson.M{"$lt": bson.A{"$firstOrder", period.From}}, 1, 0},
+			}},
+		}}},
+	}, &customers)
+
+	if err != nil || len(customers) == 0 {
+		return 0, 0, err
+	}
+
+	return customers[0].NewCustomers, customers[0].ReturningCustomers, nil
+}
+
+// Utilitaires
+
+func aggregate(collection *mongo.Collection, pipeline mongo.Pipeline, results interface{}) error {
+	cursor, err := collection.Aggregate(database.MongoContext, pipeline)
+
+	if err != nil {
+		return err
+	}
+
+	return cursor.All(database.MongoContext, results)
+}
+
+// Le format utilisé par $dateToString pour regrouper les commandes.
+// Il doit correspondre à celui de bucketKey
+func bucketFormat(granularity model.StatisticsGranularity) string {
+	switch granularity {
+	case model.StatisticsGranularityWeek:
+		return "%G-W%V"
+	case model.StatisticsGranularityMonth:
+		return "%Y-%m"
+	default:
+		return "%Y-%m-%d"
+	}
+}
+
+func bucketKey(date time.Time, granularity model.StatisticsGranularity) string {
+	switch granularity {
+	case model.StatisticsGranularityWeek:
+		year, week := date.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case model.StatisticsGranularityMonth:
+		return date.Format("2006-01")
+	default:
+		return date.Format("2006-01-02")
+	}
+}
+
+func bucketStart(date time.Time, granularity model.StatisticsGranularity) time.Time {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+
+	switch granularity {
+	case model.StatisticsGranularityWeek:
+		// Les semaines commencent le lundi
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	case model.StatisticsGranularityMonth:
+		return time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
+	default:
+		return day
+	}
+}
+
+func nextBucket(date time.Time, granularity model.StatisticsGranularity) time.Time {
+	switch granularity {
+	case model.StatisticsGranularityWeek:
+		return date.AddDate(0, 0, 7)
+	case model.StatisticsGranularityMonth:
+		return date.AddDate(0, 1, 0)
+	default:
+		return date.AddDate(0, 0, 1)
+	}
+}
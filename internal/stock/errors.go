@@ -0,0 +1,31 @@
+package stock
+
+import "strconv"
+
+type InsufficientStockError struct {
+	ProductName string
+	Available   int
+}
+type StockNotTrackedError struct{}
+type InvalidStockQuantityError struct{}
+type InvalidFirstError struct{}
+
+func (m *InsufficientStockError) Error() string {
+	if m.Available <= 0 {
+		return "le produit " + m.ProductName + " est en rupture de stock"
+	}
+
+	return "il ne reste que " + strconv.Itoa(m.Available) + " unité(s) du produit " + m.ProductName
+}
+
+func (m *StockNotTrackedError) Error() string {
+	return "le stock de ce produit n'est pas suivi"
+}
+
+func (m *InvalidStockQuantityError) Error() string {
+	return "le stock ne peut pas être négatif"
+}
+
+func (m *InvalidFirstError) Error() string {
+	return "le nombre de mouvements demandés ne peut pas être négatif"
+}
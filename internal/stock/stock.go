@@ -0,0 +1,656 @@
+package stock
+
+import (
+	"fmt"
+	"time"
+
+	"chemin-du-local.bzh/graphql/graph/model"
+	"chemin-du-local.bzh/graphql/internal/commerces"
+	"chemin-du-local.bzh/graphql/internal/database"
+	"chemin-du-local.bzh/graphql/internal/products"
+	"chemin-du-local.bzh/graphql/pkg/notifications"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const STOCK_MOVEMENT_TYPE_RESERVATION = "RESERVATION"
+const STOCK_MOVEMENT_TYPE_RELEASE = "RELEASE"
+const STOCK_MOVEMENT_TYPE_ADJUSTMENT = "ADJUSTMENT"
+
+type StockMovement struct {
+	ID                primitive.ObjectID  `bson:"_id"`
+	ProductID         primitive.ObjectID  `bson:"productID"`
+	VariantID         *primitive.ObjectID `bson:"variantID"`
+	CommerceID        primitive.ObjectID  `bson:"commerceID"`
+	Type              string              `bson:"type"`
+	Quantity          int                 `bson:"quantity"`
+	QuantityAfter     int                 `bson:"quantityAfter"`
+	Reason            *string             `bson:"reason"`
+	CommerceCommandID *primitive.ObjectID `bson:"commerceCommandID"`
+	UserID            *primitive.ObjectID `bson:"userID"`
+	Date              time.Time           `bson:"date"`
+}
+
+func (movement *StockMovement) ToModel() *model.StockMovement {
+	var commerceCommandID *string
+
+	if movement.CommerceCommandID != nil {
+		commerceCommandIDValue := movement.CommerceCommandID.Hex()
+		commerceCommandID = &commerceCommandIDValue
+	}
+
+	var variantID *string
+
+	if movement.VariantID != nil {
+		variantIDValue := movement.VariantID.Hex()
+		variantID = &variantIDValue
+	}
+
+	return &model.StockMovement{
+		ID:                movement.ID.Hex(),
+		VariantID:         variantID,
+		Type:              model.StockMovementType(movement.Type),
+		Quantity:          movement.Quantity,
+		QuantityAfter:     movement.QuantityAfter,
+		Reason:            movement.Reason,
+		CommerceCommandID: commerceCommandID,
+		Date:              movement.Date,
+	}
+}
+
+func (movement *StockMovement) stockKey() string {
+	return stockKey(movement.ProductID, movement.VariantID)
+}
+
+func stockKey(productID primitive.ObjectID, variantID *primitive.ObjectID) string {
+	if variantID == nil {
+		return productID.Hex()
+	}
+
+	return productID.Hex() + "/" + variantID.Hex()
+}
+
+// Une ligne de commande à réserver
+type StockItem struct {
+	ProductID primitive.ObjectID
+	VariantID *primitive.ObjectID
+	Quantity  int
+}
+
+// Le stock visé : celui du produit ou celui de l'une de ses déclinaisons
+type stockTarget struct {
+	product *products.Product
+	variant *products.ProductVariant
+}
+
+func newStockTarget(product *products.Product, variantID *primitive.ObjectID) (stockTarget, error) {
+	target := stockTarget{product: product}
+
+	if variantID != nil {
+		target.variant = product.GetVariant(*variantID)
+
+		if target.variant == nil {
+			return target, &products.ProductVariantNotFoundError{}
+		}
+	}
+
+	return target, nil
+}
+
+func (target stockTarget) stock() *products.ProductStock {
+	if target.variant != nil {
+		return target.variant.Stock
+	}
+
+	return target.product.Stock
+}
+
+func (target stockTarget) setStock(stock *products.ProductStock) {
+	if target.variant != nil {
+		target.variant.Stock = stock
+		return
+	}
+
+	target.product.Stock = stock
+}
+
+func (target stockTarget) variantID() *primitive.ObjectID {
+	if target.variant == nil {
+		return nil
+	}
+
+	return &target.variant.ID
+}
+
+func (target stockTarget) name() string {
+	if target.variant != nil {
+		return target.product.Name + " (" + target.variant.Name + ")"
+	}
+
+	return target.product.Name
+}
+
+// Filtre sur le produit, les conditions portant sur le stock étant
+// appliquées à la déclinaison visée le cas échéant
+func (target stockTarget) filter(stockConditions bson.M) bson.M {
+	return stockFilter(target.product.ID, target.variantID(), stockConditions)
+}
+
+// Chemin d'un champ du stock dans le document produit
+func (target stockTarget) field(stockField string) string {
+	return stockPath(target.variantID(), stockField)
+}
+
+func stockFilter(productID primitive.ObjectID, variantID *primitive.ObjectID, stockConditions bson.M) bson.M {
+	if variantID == nil {
+		filter := bson.M{"_id": productID}
+
+		for key, value := range stockConditions {
+			filter[key] = value
+		}
+
+		return filter
+	}
+
+	variantConditions := bson.M{"_id": *variantID}
+
+	for key, value := range stockConditions {
+		variantConditions[key] = value
+	}
+
+	return bson.M{
+		"_id": productID,
+		"variants": bson.M{
+			"$elemMatch": variantConditions,
+		},
+	}
+}
+
+func stockPath(variantID *primitive.ObjectID, stockField string) string {
+	if variantID == nil {
+		return stockField
+	}
+
+	return "variants.$." + stockField
+}
+
+// Service
+
+type stockService struct {
+	ProductsService  products.ProductsService
+	CommercesService commerces.CommercesService
+}
+
+type StockService interface {
+	CheckAvailability(items []StockItem) error
+	Reserve(commerceCommandID primitive.ObjectID, items []StockItem) error
+	Release(commerceCommandID primitive.ObjectID) error
+	SetTracking(product *products.Product, variantID *primitive.ObjectID, tracked bool, lowStockThreshold int) error
+	Adjust(product *products.Product, variantID *primitive.ObjectID, quantity int, reason *string, userID primitive.ObjectID) error
+	GetMovements(productID string, first int) ([]StockMovement, error)
+	GetFiltered(filter interface{}, opts *options.FindOptions) ([]StockMovement, error)
+}
+
+func NewStockService(productsService products.ProductsService, commercesService commerces.CommercesService) *stockService {
+	return &stockService{
+		ProductsService:  productsService,
+		CommercesService: commercesService,
+	}
+}
+
+// Renvoie le produit à jour et le stock visé
+func (s *stockService) getTarget(productID primitive.ObjectID, variantID *primitive.ObjectID) (stockTarget, error) {
+	product, err := s.ProductsService.GetById(productID.Hex())
+
+	if err != nil {
+		return stockTarget{}, err
+	}
+
+	if product == nil {
+		return stockTarget{}, &products.ProductNotFoundError{}
+	}
+
+	return newStockTarget(product, variantID)
+}
+
+// Commandes
+
+// Vérifie, sans rien réserver, que le stock permet la commande. Sert à
+// refuser un panier avant d'avoir créé quoi que ce soit.
+func (s *stockService) CheckAvailability(items []StockItem) error {
+	for _, item := range items {
+		target, err := s.getTarget(item.ProductID, item.VariantID)
+
+		if err != nil {
+			return err
+		}
+
+		if target.stock() != nil && target.stock().Quantity < item.Quantity {
+			return &InsufficientStockError{
+				ProductName: target.name(),
+				Available:   target.stock().Quantity,
+			}
+		}
+	}
+
+	return nil
+}
+
+// Décrémente le stock des produits suivis. La décrémentation n'a lieu
+// que s'il reste assez de stock, ce qui évite de vendre deux fois la
+// même unité. Chaque réservation est enregistrée aussitôt, pour que
+// Release puisse la rendre. Si un produit manque, les réservations déjà
+// faites pour cette commande sont annulées.
+func (s *stockService) Reserve(commerceCommandID primitive.ObjectID, items []StockItem) error {
+	reserved := []StockItem{}
+	movementIDs := []primitive.ObjectID{}
+
+	for _, item := range items {
+		if item.Quantity <= 0 {
+			continue
+		}
+
+		filter := stockFilter(item.ProductID, item.VariantID, bson.M{
+			"stock":          bson.M{"$ne": nil},
+			"stock.quantity": bson.M{"$gte": item.Quantity},
+		})
+
+		var updatedProduct products.Product
+
+		err := database.CollectionProducts.FindOneAndUpdate(
+			database.MongoContext,
+			filter,
+			bson.M{"$inc": bson.M{stockPath(item.VariantID, "stock.quantity"): -item.Quantity}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&updatedProduct)
+
+		if err == mongo.ErrNoDocuments {
+			target, err := s.getTarget(item.ProductID, item.VariantID)
+
+			if err != nil {
+				s.rollback(reserved, movementIDs)
+				return err
+			}
+
+			// Produit sans suivi de stock
+			if target.stock() == nil {
+				continue
+			}
+
+			s.rollback(reserved, movementIDs)
+
+			return &InsufficientStockError{
+				ProductName: target.name(),
+				Available:   target.stock().Quantity,
+			}
+		}
+
+		if err != nil {
+			s.rollback(reserved, movementIDs)
+			return err
+		}
+
+		reserved = append(reserved, item)
+
+		target, err := newStockTarget(&updatedProduct, item.VariantID)
+
+		if err != nil {
+			s.rollback(reserved, movementIDs)
+			return err
+		}
+
+		movement := StockMovement{
+			ID:                primitive.NewObjectID(),
+			ProductID:         target.product.ID,
+			VariantID:         target.variantID(),
+			CommerceID:        target.product.CommerceID,
+			Type:              STOCK_MOVEMENT_TYPE_RESERVATION,
+			Quantity:          -item.Quantity,
+			QuantityAfter:     target.stock().Quantity,
+			CommerceCommandID: &commerceCommandID,
+			Date:              time.Now(),
+		}
+
+		_, err = database.CollectionStockMovements.InsertOne(database.MongoContext, movement)
+
+		if err != nil {
+			s.rollback(reserved, movementIDs)
+			return err
+		}
+
+		movementIDs = append(movementIDs, movement.ID)
+
+		s.alertIfLow(target)
+	}
+
+	return nil
+}
+
+// Rend le stock réservé et supprime les mouvements déjà enregistrés,
+// pour que Release ne rende pas une seconde fois
+func (s *stockService) rollback(reserved []StockItem, movementIDs []primitive.ObjectID) {
+	for _, item := range reserved {
+		database.CollectionProducts.UpdateOne(
+			database.MongoContext,
+			stockFilter(item.ProductID, item.VariantID, bson.M{}),
+			bson.M{"$inc": bson.M{stockPath(item.VariantID, "stock.quantity"): item.Quantity}},
+		)
+	}
+
+	if len(movementIDs) > 0 {
+		database.CollectionStockMovements.DeleteMany(
+			database.MongoContext,
+			bson.M{"_id": bson.M{"$in": movementIDs}},
+		)
+	}
+}
+
+// Remet en stock ce qui a été réservé pour une commande annulée. Les
+// mouvements déjà enregistrés permettent de ne rien libérer deux fois.
+func (s *stockService) Release(commerceCommandID primitive.ObjectID) error {
+	movements, err := s.GetFiltered(bson.M{"commerceCommandID": commerceCommandID}, nil)
+
+	if err != nil {
+		return err
+	}
+
+	reservedByItem := map[string]int{}
+	itemsOrder := []StockItem{}
+
+	for _, movement := range movements {
+		key := movement.stockKey()
+
+		if _, ok := reservedByItem[key]; !ok {
+			itemsOrder = append(itemsOrder, StockItem{
+				ProductID: movement.ProductID,
+				VariantID: movement.VariantID,
+			})
+		}
+
+		reservedByItem[key] -= movement.Quantity
+	}
+
+	for _, item := range itemsOrder {
+		quantity := reservedByItem[stockKey(item.ProductID, item.VariantID)]
+
+		if quantity <= 0 {
+			continue
+		}
+
+		_, err := database.CollectionProducts.UpdateOne(
+			database.MongoContext,
+			stockFilter(item.ProductID, item.VariantID, bson.M{"stock": bson.M{"$ne": nil}}),
+			bson.M{"$inc": bson.M{stockPath(item.VariantID, "stock.quantity"): quantity}},
+		)
+
+		if err != nil {
+			return err
+		}
+
+		target, err := s.getTarget(item.ProductID, item.VariantID)
+
+		// Le produit ou la déclinaison ont pu être supprimés depuis la
+		// commande
+		if _, ok := err.(*products.ProductNotFoundError); ok {
+			continue
+		}
+
+		if _, ok := err.(*products.ProductVariantNotFoundError); ok {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		// Le suivi a pu être désactivé depuis la commande
+		if target.stock() == nil {
+			continue
+		}
+
+		err = s.resetLowStockAlert(target)
+
+		if err != nil {
+			return err
+		}
+
+		_, err = database.CollectionStockMovements.InsertOne(database.MongoContext, StockMovement{
+			ID:                primitive.NewObjectID(),
+			ProductID:         target.product.ID,
+			VariantID:         target.variantID(),
+			CommerceID:        target.product.CommerceID,
+			Type:              STOCK_MOVEMENT_TYPE_RELEASE,
+			Quantity:          quantity,
+			QuantityAfter:     target.stock().Quantity,
+			CommerceCommandID: &commerceCommandID,
+			Date:              time.Now(),
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Gestion par le commerçant
+
+func (s *stockService) SetTracking(product *products.Product, variantID *primitive.ObjectID, tracked bool, lowStockThreshold int) error {
+	if lowStockThreshold < 0 {
+		return &InvalidStockQuantityError{}
+	}
+
+	target, err := newStockTarget(product, variantID)
+
+	if err != nil {
+		return err
+	}
+
+	stock := target.stock()
+
+	if !tracked {
+		stock = nil
+	} else if stock == nil {
+		stock = &products.ProductStock{
+			LowStockThreshold: lowStockThreshold,
+		}
+	} else {
+		stock.LowStockThreshold = lowStockThreshold
+		stock.LowStockAlertSent = stock.LowStockAlertSent && stock.IsLow()
+	}
+
+	target.setStock(stock)
+
+	_, err = database.CollectionProducts.UpdateOne(
+		database.MongoContext,
+		target.filter(bson.M{}),
+		bson.M{"$set": bson.M{target.field("stock"): stock}},
+	)
+
+	return err
+}
+
+// Ajoute (ou retire si négatif) une quantité au stock
+func (s *stockService) Adjust(product *products.Product, variantID *primitive.ObjectID, quantity int, reason *string, userID primitive.ObjectID) error {
+	target, err := newStockTarget(product, variantID)
+
+	if err != nil {
+		return err
+	}
+
+	if target.stock() == nil {
+		return &StockNotTrackedError{}
+	}
+
+	filter := target.filter(bson.M{
+		"stock":          bson.M{"$ne": nil},
+		"stock.quantity": bson.M{"$gte": -quantity},
+	})
+
+	result, err := database.CollectionProducts.UpdateOne(
+		database.MongoContext,
+		filter,
+		bson.M{"$inc": bson.M{target.field("stock.quantity"): quantity}},
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if result.ModifiedCount == 0 && quantity != 0 {
+		return &InvalidStockQuantityError{}
+	}
+
+	updatedTarget, err := s.getTarget(product.ID, variantID)
+
+	if err != nil {
+		return err
+	}
+
+	*product = *updatedTarget.product
+	target, err = newStockTarget(product, variantID)
+
+	if err != nil {
+		return err
+	}
+
+	// Le suivi du stock a pu être désactivé depuis
+	if target.stock() == nil {
+		return &StockNotTrackedError{}
+	}
+
+	_, err = database.CollectionStockMovements.InsertOne(database.MongoContext, StockMovement{
+		ID:            primitive.NewObjectID(),
+		ProductID:     product.ID,
+		VariantID:     target.variantID(),
+		CommerceID:    product.CommerceID,
+		Type:          STOCK_MOVEMENT_TYPE_ADJUSTMENT,
+		Quantity:      quantity,
+		QuantityAfter: target.stock().Quantity,
+		Reason:        reason,
+		UserID:        &userID,
+		Date:          time.Now(),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if target.stock().IsLow() {
+		s.alertIfLow(target)
+		return nil
+	}
+
+	return s.resetLowStockAlert(target)
+}
+
+// Alertes
+
+// Prévient le commerçant une seule fois par passage sous le seuil
+func (s *stockService) alertIfLow(target stockTarget) {
+	stock := target.stock()
+
+	if stock == nil || !stock.IsLow() || stock.LowStockAlertSent {
+		return
+	}
+
+	result, err := database.CollectionProducts.UpdateOne(
+		database.MongoContext,
+		target.filter(bson.M{"stock.lowStockAlertSent": false}),
+		bson.M{"$set": bson.M{target.field("stock.lowStockAlertSent"): true}},
+	)
+
+	// Une autre commande a déjà envoyé l'alerte
+	if err != nil || result.ModifiedCount == 0 {
+		return
+	}
+
+	stock.LowStockAlertSent = true
+
+	commerce, err := s.CommercesService.GetById(target.product.CommerceID.Hex())
+
+	if err != nil || commerce == nil {
+		return
+	}
+
+	err = notifications.SendMailLowStock(commerce.Name, commerce.Email, target.name(), stock.Quantity)
+
+	if err != nil {
+		fmt.Print("[Stock] ERREUR : Impossible d'envoyer l'alerte de stock bas ; ")
+		fmt.Println(err)
+	}
+}
+
+func (s *stockService) resetLowStockAlert(target stockTarget) error {
+	stock := target.stock()
+
+	if stock.IsLow() || !stock.LowStockAlertSent {
+		return nil
+	}
+
+	stock.LowStockAlertSent = false
+
+	_, err := database.CollectionProducts.UpdateOne(
+		database.MongoContext,
+		target.filter(bson.M{}),
+		bson.M{"$set": bson.M{target.field("stock.lowStockAlertSent"): false}},
+	)
+
+	return err
+}
+
+// Getter de base de données
+
+func (s *stockService) GetMovements(productID string, first int) ([]StockMovement, error) {
+	productObjectID, err := primitive.ObjectIDFromHex(productID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if first < 0 {
+		return nil, &InvalidFirstError{}
+	}
+
+	// Une limite nulle renverrait tout l'historique
+	if first == 0 {
+		return []StockMovement{}, nil
+	}
+
+	opts := options.Find().
+		SetSort(bson.M{"date": -1}).
+		SetLimit(int64(first))
+
+	return s.GetFiltered(bson.M{"productID": productObjectID}, opts)
+}
+
+func (s *stockService) GetFiltered(filter interface{}, opts *options.FindOptions) ([]StockMovement, error) {
+	movements := []StockMovement{}
+
+	cursor, err := database.CollectionStockMovements.Find(database.MongoContext, filter, opts)
+
+	if err != nil {
+		return movements, err
+	}
+
+	for cursor.Next(database.MongoContext) {
+		var movement StockMovement
+
+		err := cursor.Decode(&movement)
+
+		if err != nil {
+			return movements, err
+		}
+
+		movements = append(movements, movement)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return movements, err
+	}
+
+	return movements, nil
+}
@@ -10,6 +10,7 @@ import (
 	"chemin-du-local.bzh/graphql/internal/commerces"
 	"chemin-du-local.bzh/graphql/internal/database"
 	"chemin-du-local.bzh/graphql/internal/registeredpaymentmethod"
+	"chemin-du-local.bzh/graphql/pkg/mapshandler"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"golang.org/x/crypto/bcrypt"
@@ -17,6 +18,7 @@ import (
 
 const USERROLE_ADMIN = "ADMIN"
 const USERROLE_STOREKEEPER = "STOREKEEPER"
+const USERROLE_PICKUP_MANAGER = "PICKUP_MANAGER"
 const USERROLE_USER = "USER"
 
 // Type
@@ -70,6 +72,9 @@ func (user *User) HasRole(role model.Role) bool {
 	if user.Role == USERROLE_STOREKEEPER && (role == model.RoleUser || role == model.RoleStorekeeper) {
 		return true
 	}
+	if user.Role == USERROLE_PICKUP_MANAGER && (role == model.RoleUser || role == model.RolePickupManager) {
+		return true
+	}
 	if user.Role == USERROLE_ADMIN {
 		return true
 	}
@@ -91,6 +96,7 @@ type UsersService interface {
 	GetUserByEmail(email string) (*User, error)
 	GetFiltered(filter interface{}) ([]User, error)
 	Authenticate(login model.Login) bool
+	LocateAddresses() error
 }
 
 func NewUsersService(
@@ -115,14 +121,10 @@ func (u *userService) Create(input model.NewUser) (*User, error) {
 	addresses := []*address.Address{}
 
 	if input.Address != nil {
-		addresses = append(addresses, &address.Address{
-			ID:            addressID,
-			Number:        input.Address.Number,
-			Route:         input.Address.Route,
-			OptionalRoute: input.Address.OptionalRoute,
-			PostalCode:    input.Address.PostalCode,
-			City:          input.Address.City,
-		})
+		userAddress := address.FromInput(input.Address)
+		userAddress.ID = addressID
+
+		addresses = append(addresses, &userAddress)
 	}
 
 	// On a besoin de faire la conversion
@@ -175,6 +177,49 @@ func (u *userService) Update(changes *User) error {
 	return err
 }
 
+// Les adresses enregistrées avant l'ajout de leur position sont
+// géocodées. Une adresse introuvable reste sans position et ne pourra pas
+// servir à la livraison.
+func (u *userService) LocateAddresses() error {
+	databaseUsers, err := u.GetFiltered(bson.M{
+		"addresses": bson.M{"$elemMatch": bson.M{"location": bson.M{"$exists": false}}},
+	})
+
+	if err != nil {
+		return err
+	}
+
+	for i := range databaseUsers {
+		user := &databaseUsers[i]
+
+		for _, userAddress := range user.Addresses {
+			if userAddress.Location != nil || userAddress.Format() == "" {
+				continue
+			}
+
+			location, err := mapshandler.Geocode(userAddress.Format())
+
+			if err != nil {
+				return err
+			}
+
+			userAddress.Location = location
+		}
+
+		_, err = database.CollectionUsers.UpdateOne(
+			database.MongoContext,
+			bson.M{"_id": user.ID},
+			bson.M{"$set": bson.M{"addresses": user.Addresses}},
+		)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // Getter de base de données
 
 func (u *userService) GetAllUser() ([]User, error) {
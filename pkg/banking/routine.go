@@ -127,6 +127,16 @@ func getServicesPayment() {
 			continue
 		}
 
+		// Si le commerçant l'a demandé, les jours de congés de la
+		// période ne sont pas facturés pour les services mensuels
+		if commerce.Vacation != nil && commerce.Vacation.ProrateBilling {
+			pausedDays := commerce.VacationDaysBetween(*commerce.LastBilledDate, time.Now())
+			ratio := math.Max(0, 30-float64(pausedDays)) / 30
+
+			commerce.DueBalanceClickAndCollectM = commerce.DueBalanceClickAndCollectM * ratio
+			commerce.DueBalancePaniersM = commerce.DueBalancePaniersM * ratio
+		}
+
 		billedServicesString := ""
 		totalToBill := 0.0
 		// On doit faire chaque service individuellement
@@ -0,0 +1,40 @@
+package geojson
+
+// Les types de cette partie suivent la RFC 7946 et sont destinés à être
+// encodés en JSON pour être lus par des outils SIG
+
+type Feature struct {
+	Type       string                 `json:"type" bson:"type"`
+	ID         string                 `json:"id,omitempty" bson:"id,omitempty"`
+	Geometry   interface{}            `json:"geometry" bson:"geometry"`
+	Properties map[string]interface{} `json:"properties" bson:"properties"`
+}
+
+type FeatureCollection struct {
+	Type     string    `json:"type" bson:"type"`
+	Features []Feature `json:"features" bson:"features"`
+}
+
+func NewFeature(id string, geometry interface{}, properties map[string]interface{}) Feature {
+	if properties == nil {
+		properties = map[string]interface{}{}
+	}
+
+	return Feature{
+		Type:       "Feature",
+		ID:         id,
+		Geometry:   geometry,
+		Properties: properties,
+	}
+}
+
+func NewFeatureCollection(features []Feature) FeatureCollection {
+	if features == nil {
+		features = []Feature{}
+	}
+
+	return FeatureCollection{
+		Type:     "FeatureCollection",
+		Features: features,
+	}
+}
@@ -1,6 +1,71 @@
 package geojson
 
+import "math"
+
+// Rayon moyen de la Terre en mètres
+const earthRadius = 6378100.0
+
 type GeoJSON struct {
-	Type        string    `bson:"type"`
-	Coordinates []float64 `bson:"coordinates"`
+	Type        string    `json:"type" bson:"type"`
+	Coordinates []float64 `json:"coordinates" bson:"coordinates"`
+}
+
+// Un polygone est une liste d'anneaux, le premier étant le contour
+// extérieur. Chaque anneau doit être fermé (premier point = dernier point).
+type Polygon struct {
+	Type        string        `json:"type" bson:"type"`
+	Coordinates [][][]float64 `json:"coordinates" bson:"coordinates"`
+}
+
+func NewPoint(longitude float64, latitude float64) GeoJSON {
+	return GeoJSON{
+		Type:        "Point",
+		Coordinates: []float64{longitude, latitude},
+	}
+}
+
+// Crée un polygone à partir d'une liste de points [longitude, latitude].
+// L'anneau est fermé automatiquement si besoin.
+func NewPolygon(points [][]float64) Polygon {
+	ring := [][]float64{}
+	ring = append(ring, points...)
+
+	if len(ring) > 0 {
+		first := ring[0]
+		last := ring[len(ring)-1]
+
+		if first[0] != last[0] || first[1] != last[1] {
+			ring = append(ring, []float64{first[0], first[1]})
+		}
+	}
+
+	return Polygon{
+		Type:        "Polygon",
+		Coordinates: [][][]float64{ring},
+	}
+}
+
+// Approxime un cercle de rayon radius (en mètres) autour d'un point
+// par un polygone à sides côtés, MongoDB ne gérant pas les cercles
+// dans les requêtes $geoIntersects
+func NewCircle(longitude float64, latitude float64, radius float64, sides int) Polygon {
+	points := [][]float64{}
+	angularDistance := radius / earthRadius
+	latitudeRadians := latitude * math.Pi / 180
+	longitudeRadians := longitude * math.Pi / 180
+
+	for i := 0; i < sides; i++ {
+		bearing := 2 * math.Pi * float64(i) / float64(sides)
+
+		pointLatitude := math.Asin(math.Sin(latitudeRadians)*math.Cos(angularDistance) +
+			math.Cos(latitudeRadians)*math.Sin(angularDistance)*math.Cos(bearing))
+		pointLongitude := longitudeRadians + math.Atan2(
+			math.Sin(bearing)*math.Sin(angularDistance)*math.Cos(latitudeRadians),
+			math.Cos(angularDistance)-math.Sin(latitudeRadians)*math.Sin(pointLatitude),
+		)
+
+		points = append(points, []float64{pointLongitude * 180 / math.Pi, pointLatitude * 180 / math.Pi})
+	}
+
+	return NewPolygon(points)
 }
@@ -0,0 +1,135 @@
+package geojson
+
+import (
+	"fmt"
+	"math"
+)
+
+// Latitude maximale couverte par les tuiles de la projection Web Mercator
+const MaxTileLatitude = 85.05112878
+
+// Nombre de points ajoutés sur chaque côté d'un rectangle : MongoDB relie
+// les sommets d'un polygone par des géodésiques, qui s'écartent des
+// parallèles sur de grandes distances
+const boxEdgePoints = 8
+
+type BoundingBox struct {
+	West  float64
+	South float64
+	East  float64
+	North float64
+}
+
+// Le bord nord et le bord est sont exclus afin qu'un point situé à la
+// limite de deux tuiles n'appartienne qu'à une seule d'entre elles
+func (box BoundingBox) Contains(longitude float64, latitude float64) bool {
+	return longitude >= box.West && longitude < box.East &&
+		latitude >= box.South && latitude < box.North
+}
+
+// Agrandit le rectangle d'une fraction de sa taille de chaque côté
+func (box BoundingBox) Pad(ratio float64) BoundingBox {
+	longitudePadding := (box.East - box.West) * ratio
+	latitudePadding := (box.North - box.South) * ratio
+
+	return BoundingBox{
+		West:  math.Max(box.West-longitudePadding, -180),
+		South: math.Max(box.South-latitudePadding, -90),
+		East:  math.Min(box.East+longitudePadding, 180),
+		North: math.Min(box.North+latitudePadding, 90),
+	}
+}
+
+func (box BoundingBox) Polygon() Polygon {
+	points := [][]float64{}
+
+	for i := 0; i < boxEdgePoints; i++ {
+		ratio := float64(i) / boxEdgePoints
+		points = append(points, []float64{box.West + (box.East-box.West)*ratio, box.South})
+	}
+
+	for i := 0; i < boxEdgePoints; i++ {
+		ratio := float64(i) / boxEdgePoints
+		points = append(points, []float64{box.East, box.South + (box.North-box.South)*ratio})
+	}
+
+	for i := 0; i < boxEdgePoints; i++ {
+		ratio := float64(i) / boxEdgePoints
+		points = append(points, []float64{box.East - (box.East-box.West)*ratio, box.North})
+	}
+
+	for i := 0; i < boxEdgePoints; i++ {
+		ratio := float64(i) / boxEdgePoints
+		points = append(points, []float64{box.West, box.North - (box.North-box.South)*ratio})
+	}
+
+	return NewPolygon(points)
+}
+
+// Tuile de carte au format XYZ (celui de Google Maps et d'OpenStreetMap)
+type Tile struct {
+	X    int
+	Y    int
+	Zoom int
+}
+
+func NewTile(longitude float64, latitude float64, zoom int) Tile {
+	count := 1 << zoom
+	latitude = math.Max(math.Min(latitude, MaxTileLatitude), -MaxTileLatitude)
+	latitudeRadians := latitude * math.Pi / 180
+
+	x := int(math.Floor((longitude + 180) / 360 * float64(count)))
+	y := int(math.Floor((1 - math.Log(math.Tan(latitudeRadians)+1/math.Cos(latitudeRadians))/math.Pi) / 2 * float64(count)))
+
+	return Tile{
+		X:    clampTileCoordinate(x, count),
+		Y:    clampTileCoordinate(y, count),
+		Zoom: zoom,
+	}
+}
+
+func (tile Tile) Key() string {
+	return fmt.Sprintf("%d/%d/%d", tile.Zoom, tile.X, tile.Y)
+}
+
+func (tile Tile) BoundingBox() BoundingBox {
+	count := float64(int(1) << tile.Zoom)
+
+	return BoundingBox{
+		West:  float64(tile.X)/count*360 - 180,
+		South: tileLatitude(float64(tile.Y+1) / count),
+		East:  float64(tile.X+1)/count*360 - 180,
+		North: tileLatitude(float64(tile.Y) / count),
+	}
+}
+
+// Renvoie les tuiles couvrant le rectangle au niveau de zoom donné
+func TilesForBoundingBox(box BoundingBox, zoom int) []Tile {
+	northWest := NewTile(box.West, box.North, zoom)
+	southEast := NewTile(box.East, box.South, zoom)
+	tiles := []Tile{}
+
+	for x := northWest.X; x <= southEast.X; x++ {
+		for y := northWest.Y; y <= southEast.Y; y++ {
+			tiles = append(tiles, Tile{X: x, Y: y, Zoom: zoom})
+		}
+	}
+
+	return tiles
+}
+
+func tileLatitude(ratio float64) float64 {
+	return math.Atan(math.Sinh(math.Pi*(1-2*ratio))) * 180 / math.Pi
+}
+
+func clampTileCoordinate(value int, count int) int {
+	if value < 0 {
+		return 0
+	}
+
+	if value >= count {
+		return count - 1
+	}
+
+	return value
+}
@@ -0,0 +1,71 @@
+package mapshandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"chemin-du-local.bzh/graphql/internal/config"
+	"chemin-du-local.bzh/graphql/pkg/geojson"
+)
+
+type geocodeResponse struct {
+	Status  string `json:"status"`
+	Results []struct {
+		Geometry struct {
+			Location struct {
+				Lat float64 `json:"lat"`
+				Lng float64 `json:"lng"`
+			} `json:"location"`
+		} `json:"geometry"`
+	} `json:"results"`
+}
+
+// Position d'une adresse en France, nil si elle n'a pas été trouvée
+func Geocode(address string) (*geojson.GeoJSON, error) {
+	query := url.Values{}
+	query.Set("address", address)
+	query.Set("components", "country:FR")
+	query.Set("key", config.Cfg.Maps.Key)
+
+	resp, err := http.Get("https://maps.googleapis.com/maps/api/geocode/json?" + query.Encode())
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return parseGeocodeResponse(body)
+}
+
+func parseGeocodeResponse(body []byte) (*geojson.GeoJSON, error) {
+	var response geocodeResponse
+
+	err := json.Unmarshal(body, &response)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if response.Status == "ZERO_RESULTS" {
+		return nil, nil
+	}
+
+	if response.Status != "OK" || len(response.Results) == 0 {
+		return nil, fmt.Errorf("le géocodage a échoué : %s", response.Status)
+	}
+
+	location := response.Results[0].Geometry.Location
+	point := geojson.NewPoint(location.Lng, location.Lat)
+
+	return &point, nil
+}
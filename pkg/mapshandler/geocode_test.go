@@ -0,0 +1,36 @@
+package mapshandler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests sur la lecture des réponses du géocodage
+func TestParseGeocodeResponse(t *testing.T) {
+	t.Run("the first result is used as longitude, latitude", func(t *testing.T) {
+		point, err := parseGeocodeResponse([]byte(`{
+			"status": "OK",
+			"results": [
+				{"geometry": {"location": {"lat": 48.0931, "lng": -1.7779}}},
+				{"geometry": {"location": {"lat": 47.2, "lng": -1.5}}}
+			]
+		}`))
+
+		require.NoError(t, err)
+		require.Equal(t, []float64{-1.7779, 48.0931}, point.Coordinates)
+	})
+
+	t.Run("an unknown address has no position", func(t *testing.T) {
+		point, err := parseGeocodeResponse([]byte(`{"status": "ZERO_RESULTS", "results": []}`))
+
+		require.NoError(t, err)
+		require.Nil(t, point)
+	})
+
+	t.Run("an API error is returned", func(t *testing.T) {
+		_, err := parseGeocodeResponse([]byte(`{"status": "REQUEST_DENIED", "results": []}`))
+
+		require.Error(t, err)
+	})
+}
@@ -85,7 +85,14 @@ func SendMailCommandConfirmation(
 			if err != nil {
 				basketRecapString += "<li><strong>(x" + strconv.FormatInt(int64(product.Quantity), 10) + ")</strong> Inconnue</li>"
 			} else {
-				basketRecapString += "<li><strong>(x" + strconv.FormatInt(int64(product.Quantity), 10) + ")</strong> " +
+				quantity := "x" + strconv.FormatInt(int64(product.Quantity), 10)
+
+				// La quantité d'un produit au poids est une estimation
+				if databaseProduct.IsWeighed {
+					quantity = "env. " + strconv.FormatFloat(product.Quantity, 'f', -1, 64) + " " + databaseProduct.Unit
+				}
+
+				basketRecapString += "<li><strong>(" + quantity + ")</strong> " +
 					databaseProduct.Name + "</li>"
 			}
 		}
@@ -0,0 +1,38 @@
+package notifications
+
+import (
+	"fmt"
+	"html"
+	"time"
+)
+
+// Clients
+
+func SendMailGiftCard(
+	receiverName string,
+	receiverEmail string,
+	senderName string,
+	code string,
+	amount float64,
+	expiryDate time.Time,
+	message *string,
+) error {
+	content := "<p>Bonjour,</p>" +
+		"<p><strong>" + html.EscapeString(senderName) + "</strong> vous offre une carte cadeau de <strong>" +
+		fmt.Sprintf("%.2f€", amount) + "</strong> à utiliser chez les commerçants du Chemin du Local.</p>"
+
+	if message != nil && *message != "" {
+		content = content + "<p><em>" + html.EscapeString(*message) + "</em></p>"
+	}
+
+	content = content +
+		"<p>Votre code : <strong>" + code + "</strong></p>" +
+		"<p>Il est valable jusqu'au " + expiryDate.Format("02/01/2006") + ", à saisir lors du paiement de votre commande.</p>"
+
+	return sendHTMLMail(
+		receiverName,
+		receiverEmail,
+		"Une carte cadeau vous attend",
+		content,
+	)
+}
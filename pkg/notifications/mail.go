@@ -64,3 +64,22 @@ func sendMail(
 	_, err := sendgrid.API(request)
 	return err
 }
+
+// Pour les alertes internes qui n'ont pas de modèle SendGrid
+func sendHTMLMail(
+	receiverName string,
+	receiverEmail string,
+	subject string,
+	html string,
+) error {
+	from := mail.NewEmail("Le Chemin du Local", "contact@chemin-du-local.bzh")
+	to := mail.NewEmail(receiverName, receiverEmail)
+	m := mail.NewSingleEmail(from, subject, to, "", html)
+
+	request := sendgrid.GetRequest(config.Cfg.SendGrid.Key, "/v3/mail/send", "https://api.sendgrid.com")
+	request.Method = "POST"
+	request.Body = mail.GetRequestBody(m)
+
+	_, err := sendgrid.API(request)
+	return err
+}
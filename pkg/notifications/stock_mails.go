@@ -0,0 +1,27 @@
+package notifications
+
+import (
+	"html"
+	"strconv"
+)
+
+// Commerçants
+
+func SendMailLowStock(
+	receiverName string,
+	receiverEmail string,
+	productName string,
+	quantity int,
+) error {
+	content := "<p>Bonjour,</p>" +
+		"<p>Il ne reste plus que <strong>" + strconv.Itoa(quantity) + "</strong> unité(s) de <strong>" +
+		html.EscapeString(productName) + "</strong> en stock.</p>" +
+		"<p>Pensez à mettre à jour votre stock depuis votre espace commerçant.</p>"
+
+	return sendHTMLMail(
+		receiverName,
+		receiverEmail,
+		"Stock bas : "+productName,
+		content,
+	)
+}
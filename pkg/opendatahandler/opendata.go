@@ -0,0 +1,170 @@
+package opendatahandler
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"chemin-du-local.bzh/graphql/internal/commerces"
+	"chemin-du-local.bzh/graphql/internal/products"
+	"chemin-du-local.bzh/graphql/pkg/geojson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Les données changent peu, les collectivités peuvent garder l'export
+// une heure avant de revalider avec l'ETag
+const cacheMaxAge = 3600
+
+// Export des commerces actifs au format GeoJSON, encodé en JSON avec
+// son ETag
+func CommercesGeoJSON(
+	commercesService commerces.CommercesService,
+	productsService products.ProductsService,
+	postalCode *string,
+	box *geojson.BoundingBox,
+) ([]byte, string, error) {
+	if box != nil && (box.West >= box.East || box.South >= box.North) {
+		return nil, "", &commerces.InvalidBoundingBoxError{}
+	}
+
+	databaseCommerces, err := commercesService.GetActive(postalCode, box)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	commerceIDs := []primitive.ObjectID{}
+
+	for _, databaseCommerce := range databaseCommerces {
+		commerceIDs = append(commerceIDs, databaseCommerce.ID)
+	}
+
+	categories, err := productsService.GetCategoriesForCommerces(commerceIDs)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	features := []geojson.Feature{}
+
+	for _, databaseCommerce := range databaseCommerces {
+		commerceCategories := categories[databaseCommerce.ID]
+
+		if commerceCategories == nil {
+			commerceCategories = []string{}
+		}
+
+		properties := map[string]interface{}{
+			"name":         databaseCommerce.Name,
+			"description":  databaseCommerce.Description,
+			"address":      databaseCommerce.Address.Format(),
+			"postalCode":   databaseCommerce.Address.PostalCode,
+			"city":         databaseCommerce.Address.City,
+			"phone":        databaseCommerce.Phone,
+			"email":        databaseCommerce.Email,
+			"categories":   commerceCategories,
+			"services":     databaseCommerce.PublicServices(),
+			"openingHours": databaseCommerce.BusinessHours,
+		}
+
+		features = append(features, geojson.NewFeature(
+			databaseCommerce.ID.Hex(),
+			databaseCommerce.AddressGeo,
+			properties,
+		))
+	}
+
+	data, err := json.Marshal(geojson.NewFeatureCollection(features))
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	hash := sha1.Sum(data)
+
+	return data, "\"" + hex.EncodeToString(hash[:]) + "\"", nil
+}
+
+func HandleCommercesGeoJSON(
+	w http.ResponseWriter,
+	r *http.Request,
+	commercesService commerces.CommercesService,
+	productsService products.ProductsService,
+) {
+	if r.Method != "GET" {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	var postalCode *string
+	var box *geojson.BoundingBox
+
+	if value := r.URL.Query().Get("postalCode"); value != "" {
+		postalCode = &value
+	}
+
+	// bbox=ouest,sud,est,nord comme dans la RFC 7946
+	if value := r.URL.Query().Get("bbox"); value != "" {
+		parsedBox, err := parseBoundingBox(value)
+
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		box = parsedBox
+	}
+
+	data, etag, err := CommercesGeoJSON(commercesService, productsService, postalCode, box)
+
+	if _, ok := err.(*commerces.InvalidBoundingBoxError); ok {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(cacheMaxAge))
+
+	if strings.Contains(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/geo+json")
+	w.Write(data)
+}
+
+func parseBoundingBox(value string) (*geojson.BoundingBox, error) {
+	parts := strings.Split(value, ",")
+
+	if len(parts) != 4 {
+		return nil, &commerces.InvalidBoundingBoxError{}
+	}
+
+	coordinates := []float64{}
+
+	for _, part := range parts {
+		coordinate, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+
+		if err != nil {
+			return nil, &commerces.InvalidBoundingBoxError{}
+		}
+
+		coordinates = append(coordinates, coordinate)
+	}
+
+	return &geojson.BoundingBox{
+		West:  coordinates[0],
+		South: coordinates[1],
+		East:  coordinates[2],
+		North: coordinates[3],
+	}, nil
+}
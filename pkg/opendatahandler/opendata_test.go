@@ -0,0 +1,100 @@
+package opendatahandler
+
+import (
+	"encoding/json"
+	"testing"
+
+	"chemin-du-local.bzh/graphql/internal/commerces"
+	"chemin-du-local.bzh/graphql/internal/mocks"
+	"chemin-du-local.bzh/graphql/pkg/geojson"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Tests sur l'export GeoJSON des commerces
+func TestCommercesGeoJSON(t *testing.T) {
+	commerce := commerces.Commerce{
+		ID:         primitive.NewObjectID(),
+		Name:       "Crêperie du Port",
+		AddressGeo: geojson.NewPoint(-1.7779691219329834, 48.09312057495117),
+		Status:     commerces.COMMERCE_STATUS_ACTIVE,
+	}
+
+	newServices := func() (*mocks.CommercesService, *mocks.ProductsService) {
+		testCommercesService := new(mocks.CommercesService)
+		testProductsService := new(mocks.ProductsService)
+
+		testCommercesService.On("GetActive", mock.Anything, mock.Anything).Return([]commerces.Commerce{commerce}, nil)
+		testProductsService.On("GetCategoriesForCommerces", []primitive.ObjectID{commerce.ID}).Return(
+			map[primitive.ObjectID][]string{commerce.ID: {"Crêpes"}},
+			nil,
+		)
+
+		return testCommercesService, testProductsService
+	}
+
+	t.Run("features are positioned as longitude, latitude", func(t *testing.T) {
+		testCommercesService, testProductsService := newServices()
+		data, etag, err := CommercesGeoJSON(testCommercesService, testProductsService, nil, nil)
+
+		require.NoError(t, err)
+		require.NotEmpty(t, etag)
+
+		var collection struct {
+			Type     string `json:"type"`
+			Features []struct {
+				ID       string `json:"id"`
+				Geometry struct {
+					Type        string    `json:"type"`
+					Coordinates []float64 `json:"coordinates"`
+				} `json:"geometry"`
+				Properties map[string]interface{} `json:"properties"`
+			} `json:"features"`
+		}
+
+		require.NoError(t, json.Unmarshal(data, &collection))
+		require.Equal(t, "FeatureCollection", collection.Type)
+		require.Len(t, collection.Features, 1)
+		require.Equal(t, commerce.ID.Hex(), collection.Features[0].ID)
+		require.Equal(t, "Point", collection.Features[0].Geometry.Type)
+		require.Equal(t, []float64{-1.7779691219329834, 48.09312057495117}, collection.Features[0].Geometry.Coordinates)
+		require.Equal(t, "Crêperie du Port", collection.Features[0].Properties["name"])
+		require.Equal(t, []interface{}{"Crêpes"}, collection.Features[0].Properties["categories"])
+	})
+
+	t.Run("the etag only changes with the content", func(t *testing.T) {
+		testCommercesService, testProductsService := newServices()
+		_, firstEtag, _ := CommercesGeoJSON(testCommercesService, testProductsService, nil, nil)
+		_, secondEtag, _ := CommercesGeoJSON(testCommercesService, testProductsService, nil, nil)
+
+		require.Equal(t, firstEtag, secondEtag)
+	})
+
+	t.Run("an inverted bounding box is refused", func(t *testing.T) {
+		testCommercesService, testProductsService := newServices()
+		box := geojson.BoundingBox{West: 2, South: 48, East: -5, North: 49}
+		_, _, err := CommercesGeoJSON(testCommercesService, testProductsService, nil, &box)
+
+		require.IsType(t, &commerces.InvalidBoundingBoxError{}, err)
+		testCommercesService.AssertNotCalled(t, "GetActive", mock.Anything, mock.Anything)
+	})
+}
+
+// Tests sur la lecture du paramètre bbox
+func TestParseBoundingBox(t *testing.T) {
+	t.Run("the box is read as west, south, east, north", func(t *testing.T) {
+		box, err := parseBoundingBox("-5.2, 47.2, -1, 48.9")
+
+		require.NoError(t, err)
+		require.Equal(t, geojson.BoundingBox{West: -5.2, South: 47.2, East: -1, North: 48.9}, *box)
+	})
+
+	t.Run("a malformed box is refused", func(t *testing.T) {
+		_, err := parseBoundingBox("-5.2,47.2,-1")
+		require.Error(t, err)
+
+		_, err = parseBoundingBox("-5.2,47.2,est,48.9")
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,270 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"chemin-du-local.bzh/graphql/internal/commerces"
+	"chemin-du-local.bzh/graphql/internal/config"
+	"chemin-du-local.bzh/graphql/internal/products"
+	"chemin-du-local.bzh/graphql/internal/seo"
+	"chemin-du-local.bzh/graphql/internal/services/paniers"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Fichiers générés dans le dossier static, et donc servis sous /static/
+const sitemapFileName = "sitemap.xml"
+const productFeedFileName = "products-feed.xml"
+
+// Disponibilités du flux produits, correspondant à celles de schema.org.
+// Les produits vendus uniquement en magasin n'ont pas d'équivalent et
+// restent hors du flux
+var feedAvailabilities = map[string]string{
+	seo.AVAILABILITY_IN_STOCK:     "in_stock",
+	seo.AVAILABILITY_OUT_OF_STOCK: "out_of_stock",
+}
+
+// Sitemap
+
+type urlSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc        string `xml:"loc"`
+	ChangeFreq string `xml:"changefreq,omitempty"`
+}
+
+// Flux produits Google Merchant
+
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	XmlnsG  string     `xml:"xmlns:g,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	Description string        `xml:"description"`
+	Items       []productItem `xml:"item"`
+}
+
+type productItem struct {
+	ID           string `xml:"g:id"`
+	ItemGroupID  string `xml:"g:item_group_id,omitempty"`
+	Title        string `xml:"g:title"`
+	Description  string `xml:"g:description"`
+	Link         string `xml:"g:link"`
+	ImageLink    string `xml:"g:image_link,omitempty"`
+	Price        string `xml:"g:price"`
+	Availability string `xml:"g:availability"`
+	Brand        string `xml:"g:brand"`
+	Condition    string `xml:"g:condition"`
+}
+
+func ExecuteSitemapRoutine() {
+	commercesService := commerces.NewCommercesService()
+	productsService := products.NewProductsService()
+	paniersService := paniers.NewPaniersService(productsService)
+
+	activeCommerces, err := commercesService.GetActive(nil, nil)
+
+	if err != nil {
+		fmt.Print("[Sitemap] ERREUR : Impossible de récupérer les commerces ; ")
+		fmt.Println(err)
+		return
+	}
+
+	commercesByID := map[primitive.ObjectID]*commerces.Commerce{}
+	commerceIDs := []primitive.ObjectID{}
+
+	for i := range activeCommerces {
+		commercesByID[activeCommerces[i].ID] = &activeCommerces[i]
+		commerceIDs = append(commerceIDs, activeCommerces[i].ID)
+	}
+
+	commercesFilter := bson.M{
+		"commerceID": bson.M{
+			"$in": commerceIDs,
+		},
+	}
+
+	databaseProducts, err := productsService.GetFiltered(bson.M{
+		"$and": []bson.M{
+			commercesFilter,
+			products.VisibleFilter(time.Now()),
+		},
+	}, nil)
+
+	if err != nil {
+		fmt.Print("[Sitemap] ERREUR : Impossible de récupérer les produits ; ")
+		fmt.Println(err)
+		return
+	}
+
+	databasePaniers, err := paniersService.GetFiltered(commercesFilter, nil)
+
+	if err != nil {
+		fmt.Print("[Sitemap] ERREUR : Impossible de récupérer les paniers ; ")
+		fmt.Println(err)
+		return
+	}
+
+	err = writeSitemap(activeCommerces, databaseProducts, databasePaniers)
+
+	if err != nil {
+		fmt.Print("[Sitemap] ERREUR : Impossible d'écrire le sitemap ; ")
+		fmt.Println(err)
+	}
+
+	err = writeProductFeed(commercesByID, databaseProducts)
+
+	if err != nil {
+		fmt.Print("[Sitemap] ERREUR : Impossible d'écrire le flux produits ; ")
+		fmt.Println(err)
+	}
+}
+
+func writeSitemap(activeCommerces []commerces.Commerce, databaseProducts []products.Product, databasePaniers []paniers.Panier) error {
+	sitemap := urlSet{
+		Xmlns: "http://www.sitemaps.org/schemas/sitemap/0.9",
+		URLs: []sitemapURL{
+			{Loc: config.Cfg.Urls.Website + "/", ChangeFreq: "daily"},
+		},
+	}
+
+	for i := range activeCommerces {
+		sitemap.URLs = append(sitemap.URLs, sitemapURL{
+			Loc:        seo.CommerceURL(&activeCommerces[i]),
+			ChangeFreq: "weekly",
+		})
+	}
+
+	for i := range databaseProducts {
+		sitemap.URLs = append(sitemap.URLs, sitemapURL{
+			Loc:        seo.ProductURL(&databaseProducts[i]),
+			ChangeFreq: "weekly",
+		})
+	}
+
+	now := time.Now()
+
+	for i := range databasePaniers {
+		// Les paniers terminés ne sont plus consultables
+		if databasePaniers[i].EndingDate != nil && databasePaniers[i].EndingDate.Before(now) {
+			continue
+		}
+
+		sitemap.URLs = append(sitemap.URLs, sitemapURL{
+			Loc:        seo.PanierURL(&databasePaniers[i]),
+			ChangeFreq: "daily",
+		})
+	}
+
+	return writeXMLFile(sitemapFileName, sitemap)
+}
+
+func writeProductFeed(commercesByID map[primitive.ObjectID]*commerces.Commerce, databaseProducts []products.Product) error {
+	feed := rss{
+		Version: "2.0",
+		XmlnsG:  "http://base.google.com/ns/1.0",
+		Channel: rssChannel{
+			Title:       "Chemin du local",
+			Link:        config.Cfg.Urls.Website,
+			Description: "Les produits des commerces partenaires de Chemin du local",
+			Items:       []productItem{},
+		},
+	}
+
+	for i := range databaseProducts {
+		product := &databaseProducts[i]
+		commerce := commercesByID[product.CommerceID]
+
+		if commerce == nil {
+			continue
+		}
+
+		item := productItem{
+			ID:          product.ID.Hex(),
+			Title:       product.Name,
+			Description: product.Description,
+			Link:        seo.ProductURL(product),
+			ImageLink:   seo.ProductImageURL(product),
+			Price:       feedPrice(product.Price),
+			Brand:       commerce.Name,
+			Condition:   "new",
+		}
+
+		// Une entrée par variante, regroupées sous le produit, chacune à
+		// son prix
+		if len(product.Variants) > 0 {
+			for j := range product.Variants {
+				variant := &product.Variants[j]
+				availability, isOnline := feedAvailabilities[seo.VariantAvailability(product, variant, commerce)]
+
+				if !isOnline {
+					continue
+				}
+
+				variantItem := item
+				variantItem.ID = product.ID.Hex() + "-" + variant.ID.Hex()
+				variantItem.ItemGroupID = product.ID.Hex()
+				variantItem.Title = product.Name + " - " + variant.Name
+				variantItem.Price = feedPrice(variant.Price)
+				variantItem.Availability = availability
+
+				feed.Channel.Items = append(feed.Channel.Items, variantItem)
+			}
+
+			continue
+		}
+
+		availability, isOnline := feedAvailabilities[seo.ProductAvailability(product, commerce)]
+
+		if !isOnline {
+			continue
+		}
+
+		item.Availability = availability
+		feed.Channel.Items = append(feed.Channel.Items, item)
+	}
+
+	return writeXMLFile(productFeedFileName, feed)
+}
+
+func feedPrice(price float64) string {
+	return strconv.FormatFloat(price, 'f', 2, 64) + " EUR"
+}
+
+// Le fichier est d'abord écrit à côté puis renommé, pour ne jamais
+// servir un fichier à moitié écrit
+func writeXMLFile(fileName string, content interface{}) error {
+	data, err := xml.MarshalIndent(content, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(config.Cfg.Paths.Static, os.ModePerm)
+
+	if err != nil {
+		return err
+	}
+
+	path := config.Cfg.Paths.Static + "/" + fileName
+	err = os.WriteFile(path+".tmp", append([]byte(xml.Header), data...), 0644)
+
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(path+".tmp", path)
+}
@@ -0,0 +1,135 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"chemin-du-local.bzh/graphql/internal/commerces"
+	"chemin-du-local.bzh/graphql/internal/config"
+	"chemin-du-local.bzh/graphql/internal/products"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type feedItem struct {
+	ID           string `xml:"id"`
+	ItemGroupID  string `xml:"item_group_id"`
+	Title        string `xml:"title"`
+	Price        string `xml:"price"`
+	Availability string `xml:"availability"`
+	Brand        string `xml:"brand"`
+}
+
+type feed struct {
+	Items []feedItem `xml:"channel>item"`
+}
+
+func readFeed(t *testing.T) map[string]feedItem {
+	data, err := os.ReadFile(filepath.Join(config.Cfg.Paths.Static, productFeedFileName))
+	require.NoError(t, err)
+
+	var result feed
+	require.NoError(t, xml.Unmarshal(data, &result))
+
+	items := map[string]feedItem{}
+
+	for _, item := range result.Items {
+		items[item.ID] = item
+	}
+
+	return items
+}
+
+func TestWriteProductFeed(t *testing.T) {
+	config.Cfg.Paths.Static = t.TempDir()
+	config.Cfg.Urls.Website = "https://chemin-du-local.bzh"
+
+	commerce := commerces.Commerce{
+		ID:   primitive.NewObjectID(),
+		Name: "Mon Super Commerce",
+	}
+
+	clickAndCollectProduct := products.Product{
+		ID:         primitive.NewObjectID(),
+		CommerceID: commerce.ID,
+		Name:       "Fraises de Plougastel",
+		Price:      4.5,
+	}
+	inStoreProduct := products.Product{
+		ID:         primitive.NewObjectID(),
+		CommerceID: commerce.ID,
+		Name:       "Cidre fermier",
+		Price:      6,
+	}
+	outOfStockProduct := products.Product{
+		ID:         primitive.NewObjectID(),
+		CommerceID: commerce.ID,
+		Name:       "Kouign-amann",
+		Price:      3,
+		Stock:      &products.ProductStock{Quantity: 0},
+	}
+	variantsProduct := products.Product{
+		ID:         primitive.NewObjectID(),
+		CommerceID: commerce.ID,
+		Name:       "Miel",
+		Price:      5,
+		Variants: []products.ProductVariant{
+			{ID: primitive.NewObjectID(), Name: "250 g", Price: 5},
+			{ID: primitive.NewObjectID(), Name: "500 g", Price: 9.5},
+			{ID: primitive.NewObjectID(), Name: "1 kg", Price: 17, Stock: &products.ProductStock{Quantity: 0}},
+		},
+	}
+	orphanProduct := products.Product{
+		ID:         primitive.NewObjectID(),
+		CommerceID: primitive.NewObjectID(),
+		Name:       "Produit d'un commerce inactif",
+	}
+
+	commerce.ProductsAvailableForClickAndCollect = []string{clickAndCollectProduct.ID.Hex(), outOfStockProduct.ID.Hex()}
+	// La variante de 500 g n'est vendue qu'en magasin
+	commerce.VariantsAvailableForClickAndCollect = []string{variantsProduct.Variants[0].ID.Hex(), variantsProduct.Variants[2].ID.Hex()}
+
+	err := writeProductFeed(
+		map[primitive.ObjectID]*commerces.Commerce{commerce.ID: &commerce},
+		[]products.Product{clickAndCollectProduct, inStoreProduct, outOfStockProduct, variantsProduct, orphanProduct},
+	)
+	require.NoError(t, err)
+
+	items := readFeed(t)
+
+	require.Len(t, items, 4)
+	require.Equal(t, "in_stock", items[clickAndCollectProduct.ID.Hex()].Availability)
+	require.Equal(t, "4.50 EUR", items[clickAndCollectProduct.ID.Hex()].Price)
+	require.Equal(t, "Mon Super Commerce", items[clickAndCollectProduct.ID.Hex()].Brand)
+	require.Equal(t, "out_of_stock", items[outOfStockProduct.ID.Hex()].Availability)
+
+	// Les produits vendus uniquement en magasin ne sont pas dans le flux
+	require.NotContains(t, items, inStoreProduct.ID.Hex())
+	require.NotContains(t, items, variantsProduct.ID.Hex())
+
+	smallVariant := items[variantsProduct.ID.Hex()+"-"+variantsProduct.Variants[0].ID.Hex()]
+	require.Equal(t, variantsProduct.ID.Hex(), smallVariant.ItemGroupID)
+	require.Equal(t, "Miel - 250 g", smallVariant.Title)
+	require.Equal(t, "5.00 EUR", smallVariant.Price)
+	require.Equal(t, "in_stock", smallVariant.Availability)
+
+	require.NotContains(t, items, variantsProduct.ID.Hex()+"-"+variantsProduct.Variants[1].ID.Hex())
+
+	bigVariant := items[variantsProduct.ID.Hex()+"-"+variantsProduct.Variants[2].ID.Hex()]
+	require.Equal(t, "17.00 EUR", bigVariant.Price)
+	require.Equal(t, "out_of_stock", bigVariant.Availability)
+}
+
+func TestWriteXMLFile_StaticFolderError(t *testing.T) {
+	// Le dossier static ne peut pas être créé sous un fichier
+	file := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(file, []byte{}, 0644))
+
+	config.Cfg.Paths.Static = filepath.Join(file, "static")
+
+	err := writeXMLFile(sitemapFileName, urlSet{})
+
+	require.Error(t, err)
+}
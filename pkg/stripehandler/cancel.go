@@ -0,0 +1,57 @@
+package stripehandler
+
+import (
+	"chemin-du-local.bzh/graphql/internal/config"
+	"chemin-du-local.bzh/graphql/internal/services/commands"
+	"github.com/stripe/stripe-go/v72"
+	"github.com/stripe/stripe-go/v72/paymentintent"
+	"github.com/stripe/stripe-go/v72/refund"
+)
+
+// Rend au client ce qu'il a payé par carte pour une commande annulée :
+// une autorisation non capturée est annulée, un paiement prélevé est
+// remboursé de ce qui ne l'a pas déjà été, par exemple après une pesée
+func RefundCanceledOrder(commerceCommand *commands.CommerceCommand) error {
+	if commerceCommand.PaymentIntentID == nil {
+		return nil
+	}
+
+	// Set Stripe API key
+	apiKey := config.Cfg.Stripe.Key
+	stripe.Key = apiKey
+
+	pi, err := paymentintent.Get(*commerceCommand.PaymentIntentID, nil)
+
+	if err != nil {
+		return err
+	}
+
+	switch pi.Status {
+	case stripe.PaymentIntentStatusSucceeded:
+		refundable := pi.AmountReceived
+
+		if pi.Charges != nil {
+			for _, charge := range pi.Charges.Data {
+				refundable = refundable - charge.AmountRefunded
+			}
+		}
+
+		if refundable <= 0 {
+			return nil
+		}
+
+		_, err = refund.New(&stripe.RefundParams{
+			PaymentIntent: stripe.String(pi.ID),
+			Amount:        stripe.Int64(refundable),
+		})
+
+		return err
+	case stripe.PaymentIntentStatusCanceled:
+		return nil
+	}
+
+	// Autorisation en attente de capture ou paiement inabouti
+	_, err = paymentintent.Cancel(pi.ID, nil)
+
+	return err
+}
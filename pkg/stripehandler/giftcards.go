@@ -0,0 +1,223 @@
+package stripehandler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"chemin-du-local.bzh/graphql/graph/model"
+	"chemin-du-local.bzh/graphql/internal/config"
+	"chemin-du-local.bzh/graphql/internal/giftcards"
+	"chemin-du-local.bzh/graphql/internal/users"
+	"chemin-du-local.bzh/graphql/pkg/notifications"
+	"github.com/stripe/stripe-go/v72"
+	"github.com/stripe/stripe-go/v72/paymentintent"
+)
+
+// Une fois payée, la carte est créditée et envoyée au destinataire s'il
+// y en a un
+func activateGiftCard(
+	user users.User,
+	giftCard *giftcards.GiftCard,
+	paymentIntentID string,
+	giftCardsService giftcards.GiftCardsService,
+) error {
+	err := giftCardsService.Activate(giftCard, paymentIntentID)
+
+	if err != nil {
+		return err
+	}
+
+	if giftCard.RecipientEmail != nil {
+		recipientName := ""
+
+		if giftCard.RecipientName != nil {
+			recipientName = *giftCard.RecipientName
+		}
+
+		senderName := user.Email
+
+		if user.FirstName != nil {
+			senderName = *user.FirstName
+		}
+
+		notifications.SendMailGiftCard(
+			recipientName,
+			*giftCard.RecipientEmail,
+			senderName,
+			giftCard.Code,
+			float64(giftCard.InitialAmount)/100,
+			giftCard.ExpiryDate,
+			giftCard.Message,
+		)
+	}
+
+	return nil
+}
+
+func HandleCreateGiftCard(
+	w http.ResponseWriter,
+	r *http.Request,
+	usersService users.UsersService,
+	giftCardsService giftcards.GiftCardsService,
+) {
+	// Set Stripe API key
+	apiKey := config.Cfg.Stripe.Key
+	stripe.Key = apiKey
+
+	if r.Method != "POST" {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		PaymentMethodID *string           `json:"paymentMethodId"`
+		GiftCard        model.NewGiftCard `json:"giftCard"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	stripeCustomer, user := authentification(w, r, usersService)
+
+	if user == nil {
+		return
+	}
+
+	if req.PaymentMethodID == nil {
+		http.Error(w, "le moyen de paiement est obligatoire", http.StatusBadRequest)
+		return
+	}
+
+	databaseGiftCard, err := giftCardsService.Create(user.ID, req.GiftCard)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	confirm := true
+	confirmationMethode := "manual"
+	params := &stripe.PaymentIntentParams{
+		Amount:             stripe.Int64(int64(databaseGiftCard.InitialAmount)),
+		Currency:           stripe.String(string(stripe.CurrencyEUR)),
+		PaymentMethod:      req.PaymentMethodID,
+		Confirm:            &confirm,
+		ConfirmationMethod: &confirmationMethode,
+		Customer:           stripeCustomer,
+	}
+	params.AddMetadata("giftCardID", databaseGiftCard.ID.Hex())
+
+	pi, err := paymentintent.New(params)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		log.Printf("pi.New: %v", err)
+		return
+	}
+
+	if pi.Status == stripe.PaymentIntentStatusSucceeded {
+		err = activateGiftCard(*user, databaseGiftCard, pi.ID, giftCardsService)
+
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	writeJSON(w, struct {
+		ClientSecret   string `json:"clientSecret"`
+		RequiresAction bool   `json:"requiresAction"`
+		GiftCardID     string `json:"giftCardId"`
+		Status         string `json:"status"`
+	}{
+		ClientSecret:   pi.ClientSecret,
+		RequiresAction: pi.Status == stripe.PaymentIntentStatusRequiresAction,
+		GiftCardID:     databaseGiftCard.ID.Hex(),
+		Status:         databaseGiftCard.Status,
+	})
+}
+
+// Appelé après l'authentification 3D Secure du paiement
+func HandleCompleteGiftCard(
+	w http.ResponseWriter,
+	r *http.Request,
+	usersService users.UsersService,
+	giftCardsService giftcards.GiftCardsService,
+) {
+	// Set Stripe API key
+	apiKey := config.Cfg.Stripe.Key
+	stripe.Key = apiKey
+
+	if r.Method != "POST" {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		GiftCardID      *string `json:"giftCardId"`
+		PaymentIntentID *string `json:"paymentIntentId"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	_, user := authentification(w, r, usersService)
+
+	if user == nil {
+		return
+	}
+
+	if req.GiftCardID == nil || req.PaymentIntentID == nil {
+		http.Error(w, "la carte cadeau et le paiement sont obligatoires", http.StatusBadRequest)
+		return
+	}
+
+	databaseGiftCard, err := giftCardsService.GetById(*req.GiftCardID)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if databaseGiftCard == nil || databaseGiftCard.PurchaserID != user.ID {
+		http.Error(w, "la carte cadeau n'a pas été trouvée", http.StatusNotFound)
+		return
+	}
+
+	pi, err := paymentintent.Confirm(*req.PaymentIntentID, nil)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Le paiement doit bien être celui de cette carte
+	if pi.Metadata["giftCardID"] != databaseGiftCard.ID.Hex() {
+		http.Error(w, "le paiement ne correspond pas à la carte cadeau", http.StatusBadRequest)
+		return
+	}
+
+	if pi.Status == stripe.PaymentIntentStatusSucceeded {
+		err = activateGiftCard(*user, databaseGiftCard, pi.ID, giftCardsService)
+
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	writeJSON(w, struct {
+		ClientSecret   string `json:"clientSecret"`
+		RequiresAction bool   `json:"requiresAction"`
+		Status         string `json:"status"`
+	}{
+		ClientSecret:   pi.ClientSecret,
+		RequiresAction: pi.Status == stripe.PaymentIntentStatusRequiresAction,
+		Status:         databaseGiftCard.Status,
+	})
+}
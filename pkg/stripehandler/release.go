@@ -0,0 +1,185 @@
+package stripehandler
+
+import (
+	"log"
+	"math"
+	"time"
+
+	"chemin-du-local.bzh/graphql/internal/commerces"
+	"chemin-du-local.bzh/graphql/internal/giftcards"
+	"chemin-du-local.bzh/graphql/internal/products"
+	"chemin-du-local.bzh/graphql/internal/promocodes"
+	"chemin-du-local.bzh/graphql/internal/services/commands"
+	"chemin-du-local.bzh/graphql/internal/stock"
+	"chemin-du-local.bzh/graphql/internal/users"
+	"github.com/stripe/stripe-go/v72"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Délai laissé au client pour payer sa commande, authentification
+// bancaire comprise, avant qu'elle ne soit abandonnée
+const abandonedOrderDelay = time.Hour
+
+// Annule une commande de commerce qui n'a pas été payée, remet en stock
+// les produits qu'elle avait réservés et recrédite la carte cadeau. Le
+// code promo est rendu au client lorsqu'aucune commande de commerce de sa
+// commande ne reste.
+func releaseCommerceCommand(
+	commerceCommand *commands.CommerceCommand,
+	commerceCommandsService commands.CommerceCommandsService,
+	stockService stock.StockService,
+	promoCodesService promocodes.PromoCodesService,
+	giftCardsService giftcards.GiftCardsService,
+) error {
+	now := time.Now()
+	commerceCommand.Status = commands.COMMERCE_COMMAND_STATUS_CANCELED
+	commerceCommand.PaymentStatus = commands.COMMERCE_COMMAND_PAYMENT_STATUS_FAILED
+	commerceCommand.CanceledAt = &now
+
+	err := commerceCommandsService.Update(commerceCommand)
+
+	if err != nil {
+		return err
+	}
+
+	err = stockService.Release(commerceCommand.ID)
+
+	if err != nil {
+		return err
+	}
+
+	if commerceCommand.GiftCardID != nil && commerceCommand.GiftCardAmount > 0 {
+		err = giftCardsService.Refund(
+			*commerceCommand.GiftCardID,
+			int(math.Round(commerceCommand.GiftCardAmount*100)),
+			commerceCommand.CommerceID,
+			commerceCommand.ID,
+		)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if commerceCommand.PromoCode == nil {
+		return nil
+	}
+
+	remainingCommerceCommands, err := commerceCommandsService.GetFiltered(bson.M{
+		"commandID": commerceCommand.CommandID,
+		"status": bson.M{
+			"$ne": commands.COMMERCE_COMMAND_STATUS_CANCELED,
+		},
+	}, nil)
+
+	if err != nil {
+		return err
+	}
+
+	if len(remainingCommerceCommands) > 0 {
+		return nil
+	}
+
+	return promoCodesService.Release(*commerceCommand.PromoCode, commerceCommand.CommandID)
+}
+
+// Enregistre le paiement d'une commande de commerce et crédite le
+// commerce. Avec des produits au poids, le commerce n'est crédité qu'une
+// fois le montant réel connu.
+func payCommerceCommand(
+	commerceCommand *commands.CommerceCommand,
+	commerceCommandsService commands.CommerceCommandsService,
+	commercesService commerces.CommercesService,
+) error {
+	commerceCommand.Status = commands.COMMERCE_COMMAND_STATUS_DONE
+	commerceCommand.PaymentStatus = commands.COMMERCE_COMMAND_PAYMENT_STATUS_PAID
+
+	err := commerceCommandsService.Update(commerceCommand)
+
+	if err != nil {
+		return err
+	}
+
+	if commerceCommand.IsAwaitingWeights() {
+		return nil
+	}
+
+	return commercesService.UpdateBalancesForOrder(commerceCommand.CommerceID.Hex(), commerceCommand.Price, commerceCommand.PriceClickAndCollect, commerceCommand.PricePaniers, commerceCommand.PlatformFundedAmount())
+}
+
+// Suite donnée à une commande de commerce selon le statut de son
+// paiement. Une authentification bancaire en attente laisse la commande
+// en suspens, la routine des commandes abandonnées l'annulant au besoin.
+func handlePaymentStatus(
+	commerceCommand *commands.CommerceCommand,
+	status stripe.PaymentIntentStatus,
+	commerceCommandsService commands.CommerceCommandsService,
+	commercesService commerces.CommercesService,
+	stockService stock.StockService,
+	promoCodesService promocodes.PromoCodesService,
+	giftCardsService giftcards.GiftCardsService,
+) error {
+	switch status {
+	case stripe.PaymentIntentStatusSucceeded, stripe.PaymentIntentStatusRequiresCapture:
+		return payCommerceCommand(commerceCommand, commerceCommandsService, commercesService)
+	case stripe.PaymentIntentStatusRequiresAction:
+		return nil
+	}
+
+	return releaseCommerceCommand(commerceCommand, commerceCommandsService, stockService, promoCodesService, giftCardsService)
+}
+
+// Annule les commandes qui n'ont pas été payées à temps, pour rendre
+// leur stock aux autres clients
+func releaseAbandonedOrders(
+	commerceCommandsService commands.CommerceCommandsService,
+	stockService stock.StockService,
+	promoCodesService promocodes.PromoCodesService,
+	giftCardsService giftcards.GiftCardsService,
+	now time.Time,
+) error {
+	filter := bson.M{
+		"paymentStatus": commands.COMMERCE_COMMAND_PAYMENT_STATUS_PENDING,
+		"status": bson.M{
+			"$ne": commands.COMMERCE_COMMAND_STATUS_CANCELED,
+		},
+		"_id": bson.M{
+			"$lt": primitive.NewObjectIDFromTimestamp(now.Add(-abandonedOrderDelay)),
+		},
+	}
+
+	abandonedCommerceCommands, err := commerceCommandsService.GetFiltered(filter, nil)
+
+	if err != nil {
+		return err
+	}
+
+	for i := range abandonedCommerceCommands {
+		err = releaseCommerceCommand(&abandonedCommerceCommands[i], commerceCommandsService, stockService, promoCodesService, giftCardsService)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func ExecuteAbandonedOrdersRoutine() {
+	// On doit créer les services
+	commercesService := commerces.NewCommercesService()
+	usersService := users.NewUsersService(commercesService)
+	productsService := products.NewProductsService()
+	commandsService := commands.NewCommandsService(usersService)
+	commerceCommandsService := commands.NewCommerceCommandsService(usersService, commercesService, commandsService)
+	stockService := stock.NewStockService(productsService, commercesService)
+	promoCodesService := promocodes.NewPromoCodesService()
+	giftCardsService := giftcards.NewGiftCardsService()
+
+	err := releaseAbandonedOrders(commerceCommandsService, stockService, promoCodesService, giftCardsService, time.Now())
+
+	if err != nil {
+		log.Printf("[AbandonedOrders] ERREUR : %v", err)
+	}
+}
@@ -0,0 +1,230 @@
+package stripehandler
+
+import (
+	"testing"
+	"time"
+
+	"chemin-du-local.bzh/graphql/graph/model"
+	"chemin-du-local.bzh/graphql/internal/mocks"
+	"chemin-du-local.bzh/graphql/internal/services/commands"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stripe/stripe-go/v72"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newPendingCommerceCommand() *commands.CommerceCommand {
+	return &commands.CommerceCommand{
+		ID:            primitive.NewObjectID(),
+		CommerceID:    primitive.NewObjectID(),
+		Price:         1250,
+		Status:        commands.COMMERCE_COMMAND_STATUS_IN_PROGRESS,
+		PaymentStatus: commands.COMMERCE_COMMAND_PAYMENT_STATUS_PENDING,
+	}
+}
+
+func TestGetStockItems(t *testing.T) {
+	productID := primitive.NewObjectID()
+	otherProductID := primitive.NewObjectID()
+
+	t.Run("a started unit is reserved whole", func(t *testing.T) {
+		items, err := getStockItems(model.NewBasketCommerce{
+			Products: []*model.NewBasketProduct{
+				{ProductID: productID.Hex(), Quantity: 0.5},
+				{ProductID: otherProductID.Hex(), Quantity: 2},
+			},
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, 1, items[0].Quantity)
+		require.Equal(t, 2, items[1].Quantity)
+	})
+
+	t.Run("the lines of a product are added before rounding", func(t *testing.T) {
+		variantID := primitive.NewObjectID().Hex()
+
+		items, err := getStockItems(model.NewBasketCommerce{
+			Products: []*model.NewBasketProduct{
+				{ProductID: productID.Hex(), Quantity: 0.4},
+				{ProductID: productID.Hex(), Quantity: 0.4},
+				{ProductID: productID.Hex(), VariantID: &variantID, Quantity: 1},
+			},
+		})
+
+		require.NoError(t, err)
+		require.Len(t, items, 2)
+		require.Equal(t, 1, items[0].Quantity)
+		require.Equal(t, 1, items[1].Quantity)
+	})
+
+	t.Run("an invalid product ID is refused", func(t *testing.T) {
+		_, err := getStockItems(model.NewBasketCommerce{
+			Products: []*model.NewBasketProduct{
+				{ProductID: "invalid", Quantity: 1},
+			},
+		})
+
+		require.Error(t, err)
+	})
+}
+
+func TestReleaseCommerceCommand(t *testing.T) {
+	commerceCommand := newPendingCommerceCommand()
+
+	commerceCommandsService := new(mocks.CommerceCommandsService)
+	stockService := new(mocks.StockService)
+
+	commerceCommandsService.On("Update", commerceCommand).Return(nil)
+	stockService.On("Release", commerceCommand.ID).Return(nil)
+
+	err := releaseCommerceCommand(commerceCommand, commerceCommandsService, stockService, nil, nil)
+
+	require.NoError(t, err)
+	require.Equal(t, commands.COMMERCE_COMMAND_STATUS_CANCELED, commerceCommand.Status)
+	require.Equal(t, commands.COMMERCE_COMMAND_PAYMENT_STATUS_FAILED, commerceCommand.PaymentStatus)
+	stockService.AssertExpectations(t)
+}
+
+func TestReleaseCommerceCommandGiftCard(t *testing.T) {
+	giftCardID := primitive.NewObjectID()
+	commerceCommand := newPendingCommerceCommand()
+	commerceCommand.GiftCardID = &giftCardID
+	commerceCommand.GiftCardAmount = 12.5
+
+	commerceCommandsService := new(mocks.CommerceCommandsService)
+	stockService := new(mocks.StockService)
+	giftCardsService := new(mocks.GiftCardsService)
+
+	commerceCommandsService.On("Update", commerceCommand).Return(nil)
+	stockService.On("Release", commerceCommand.ID).Return(nil)
+	giftCardsService.On("Refund", giftCardID, 1250, commerceCommand.CommerceID, commerceCommand.ID).Return(nil)
+
+	err := releaseCommerceCommand(commerceCommand, commerceCommandsService, stockService, nil, giftCardsService)
+
+	require.NoError(t, err)
+	giftCardsService.AssertExpectations(t)
+}
+
+func TestReleaseCommerceCommandPromoCode(t *testing.T) {
+	promoCode := "BIENVENUE"
+
+	newCommerceCommand := func() *commands.CommerceCommand {
+		commerceCommand := newPendingCommerceCommand()
+		commerceCommand.CommandID = primitive.NewObjectID()
+		commerceCommand.PromoCode = &promoCode
+
+		return commerceCommand
+	}
+
+	t.Run("the code is given back with the last commerce command", func(t *testing.T) {
+		commerceCommand := newCommerceCommand()
+
+		commerceCommandsService := new(mocks.CommerceCommandsService)
+		stockService := new(mocks.StockService)
+		promoCodesService := new(mocks.PromoCodesService)
+
+		commerceCommandsService.On("Update", commerceCommand).Return(nil)
+		commerceCommandsService.On("GetFiltered", mock.Anything, mock.Anything).Return([]commands.CommerceCommand{}, nil)
+		stockService.On("Release", commerceCommand.ID).Return(nil)
+		promoCodesService.On("Release", promoCode, commerceCommand.CommandID).Return(nil)
+
+		err := releaseCommerceCommand(commerceCommand, commerceCommandsService, stockService, promoCodesService, nil)
+
+		require.NoError(t, err)
+		promoCodesService.AssertExpectations(t)
+	})
+
+	t.Run("the code stays used while another commerce command remains", func(t *testing.T) {
+		commerceCommand := newCommerceCommand()
+		otherCommerceCommand := newCommerceCommand()
+
+		commerceCommandsService := new(mocks.CommerceCommandsService)
+		stockService := new(mocks.StockService)
+		promoCodesService := new(mocks.PromoCodesService)
+
+		commerceCommandsService.On("Update", commerceCommand).Return(nil)
+		commerceCommandsService.On("GetFiltered", mock.Anything, mock.Anything).Return([]commands.CommerceCommand{*otherCommerceCommand}, nil)
+		stockService.On("Release", commerceCommand.ID).Return(nil)
+
+		err := releaseCommerceCommand(commerceCommand, commerceCommandsService, stockService, promoCodesService, nil)
+
+		require.NoError(t, err)
+		promoCodesService.AssertNotCalled(t, "Release", mock.Anything, mock.Anything)
+	})
+}
+
+func TestHandlePaymentStatus(t *testing.T) {
+	t.Run("a successful payment credits the commerce", func(t *testing.T) {
+		commerceCommand := newPendingCommerceCommand()
+
+		commerceCommandsService := new(mocks.CommerceCommandsService)
+		commercesService := new(mocks.CommercesService)
+		stockService := new(mocks.StockService)
+
+		commerceCommandsService.On("Update", commerceCommand).Return(nil)
+		commercesService.On("UpdateBalancesForOrder", commerceCommand.CommerceID.Hex(), 1250, 0.0, 0.0, 0.0).Return(nil)
+
+		err := handlePaymentStatus(commerceCommand, stripe.PaymentIntentStatusSucceeded, commerceCommandsService, commercesService, stockService, nil, nil)
+
+		require.NoError(t, err)
+		require.Equal(t, commands.COMMERCE_COMMAND_STATUS_DONE, commerceCommand.Status)
+		require.Equal(t, commands.COMMERCE_COMMAND_PAYMENT_STATUS_PAID, commerceCommand.PaymentStatus)
+		commercesService.AssertExpectations(t)
+		stockService.AssertNotCalled(t, "Release", mock.Anything)
+	})
+
+	t.Run("a payment awaiting authentication is left pending", func(t *testing.T) {
+		commerceCommand := newPendingCommerceCommand()
+
+		commerceCommandsService := new(mocks.CommerceCommandsService)
+		commercesService := new(mocks.CommercesService)
+		stockService := new(mocks.StockService)
+
+		err := handlePaymentStatus(commerceCommand, stripe.PaymentIntentStatusRequiresAction, commerceCommandsService, commercesService, stockService, nil, nil)
+
+		require.NoError(t, err)
+		require.Equal(t, commands.COMMERCE_COMMAND_PAYMENT_STATUS_PENDING, commerceCommand.PaymentStatus)
+		commerceCommandsService.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("a failed payment releases the stock", func(t *testing.T) {
+		commerceCommand := newPendingCommerceCommand()
+
+		commerceCommandsService := new(mocks.CommerceCommandsService)
+		commercesService := new(mocks.CommercesService)
+		stockService := new(mocks.StockService)
+
+		commerceCommandsService.On("Update", commerceCommand).Return(nil)
+		stockService.On("Release", commerceCommand.ID).Return(nil)
+
+		err := handlePaymentStatus(commerceCommand, stripe.PaymentIntentStatusRequiresPaymentMethod, commerceCommandsService, commercesService, stockService, nil, nil)
+
+		require.NoError(t, err)
+		require.Equal(t, commands.COMMERCE_COMMAND_STATUS_CANCELED, commerceCommand.Status)
+		stockService.AssertExpectations(t)
+		commercesService.AssertNotCalled(t, "UpdateBalancesForOrder", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestReleaseAbandonedOrders(t *testing.T) {
+	now := time.Date(2022, 10, 12, 14, 0, 0, 0, time.UTC)
+	abandonedCommerceCommand := newPendingCommerceCommand()
+
+	commerceCommandsService := new(mocks.CommerceCommandsService)
+	stockService := new(mocks.StockService)
+
+	commerceCommandsService.On("GetFiltered", mock.MatchedBy(func(filter bson.M) bool {
+		createdBefore := filter["_id"].(bson.M)["$lt"].(primitive.ObjectID)
+
+		return filter["paymentStatus"] == commands.COMMERCE_COMMAND_PAYMENT_STATUS_PENDING &&
+			createdBefore.Timestamp().Equal(now.Add(-abandonedOrderDelay))
+	}), mock.Anything).Return([]commands.CommerceCommand{*abandonedCommerceCommand}, nil)
+	commerceCommandsService.On("Update", mock.AnythingOfType("*commands.CommerceCommand")).Return(nil)
+	stockService.On("Release", abandonedCommerceCommand.ID).Return(nil)
+
+	err := releaseAbandonedOrders(commerceCommandsService, stockService, nil, nil, now)
+
+	require.NoError(t, err)
+	stockService.AssertExpectations(t)
+}
@@ -5,76 +5,577 @@ import (
 	"encoding/json"
 	"io"
 	"log"
+	"math"
 	"net/http"
 	"time"
 
 	"chemin-du-local.bzh/graphql/graph/model"
+	"chemin-du-local.bzh/graphql/internal/address"
 	"chemin-du-local.bzh/graphql/internal/auth"
 	"chemin-du-local.bzh/graphql/internal/commerces"
 	"chemin-du-local.bzh/graphql/internal/config"
+	"chemin-du-local.bzh/graphql/internal/deliveryzones"
+	"chemin-du-local.bzh/graphql/internal/giftcards"
+	"chemin-du-local.bzh/graphql/internal/markets"
+	"chemin-du-local.bzh/graphql/internal/pickuppoints"
 	"chemin-du-local.bzh/graphql/internal/products"
+	"chemin-du-local.bzh/graphql/internal/promocodes"
+	"chemin-du-local.bzh/graphql/internal/promotions"
 	"chemin-du-local.bzh/graphql/internal/services/commands"
 	"chemin-du-local.bzh/graphql/internal/services/paniers"
+	"chemin-du-local.bzh/graphql/internal/stock"
 	"chemin-du-local.bzh/graphql/internal/users"
 	"chemin-du-local.bzh/graphql/pkg/notifications"
+	"chemin-du-local.bzh/graphql/pkg/utils"
 	"github.com/stripe/stripe-go/v72"
 	"github.com/stripe/stripe-go/v72/customer"
 	"github.com/stripe/stripe-go/v72/paymentintent"
 	"github.com/stripe/stripe-go/v72/paymentmethod"
 	"github.com/stripe/stripe-go/v72/setupintent"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// Retrouve l'adresse de livraison choisie parmi celles de l'utilisateur
+func getDeliveryAddress(
+	user users.User,
+	commerce model.NewBasketCommerce,
+) (*address.Address, error) {
+	if commerce.DeliveryAddressID == nil {
+		return nil, &deliveryzones.DeliveryAddressRequiredError{}
+	}
+
+	for _, userAddress := range user.Addresses {
+		if userAddress.ID.Hex() == *commerce.DeliveryAddressID {
+			if userAddress.Location == nil {
+				return nil, &deliveryzones.DeliveryAddressWithoutLocationError{}
+			}
+
+			return userAddress, nil
+		}
+	}
+
+	return nil, &deliveryzones.DeliveryAddressRequiredError{}
+}
+
+// Les quantités d'un même produit sont additionnées avant d'être
+// arrondies : seuls les produits au poids ont des quantités décimales, et
+// une unité entamée est réservée entière pour ne jamais vendre plus que
+// le stock
+func getStockItems(commerce model.NewBasketCommerce) ([]stock.StockItem, error) {
+	stockItems := []stock.StockItem{}
+	quantities := []float64{}
+	indexes := map[string]int{}
+
+	for _, product := range commerce.Products {
+		productID, err := primitive.ObjectIDFromHex(product.ProductID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		variantID, err := utils.OptionalObjectID(product.VariantID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		key := productID.Hex()
+
+		if variantID != nil {
+			key = key + "/" + variantID.Hex()
+		}
+
+		index, ok := indexes[key]
+
+		if !ok {
+			index = len(stockItems)
+			indexes[key] = index
+			stockItems = append(stockItems, stock.StockItem{
+				ProductID: productID,
+				VariantID: variantID,
+			})
+			quantities = append(quantities, 0)
+		}
+
+		quantities[index] = quantities[index] + product.Quantity
+	}
+
+	for i := range stockItems {
+		stockItems[i].Quantity = int(math.Ceil(quantities[i]))
+	}
+
+	return stockItems, nil
+}
+
+// Le prix d'un produit décliné est celui de la déclinaison choisie
+func getProductPrice(product *products.Product, variantID *string) (float64, error) {
+	if variantID == nil {
+		if len(product.Variants) > 0 {
+			return 0, &products.ProductVariantRequiredError{}
+		}
+
+		return product.Price, nil
+	}
+
+	variantObjectID, err := primitive.ObjectIDFromHex(*variantID)
+
+	if err != nil {
+		return 0, err
+	}
+
+	variant := product.GetVariant(variantObjectID)
+
+	if variant == nil {
+		return 0, &products.ProductVariantNotFoundError{}
+	}
+
+	return variant.Price, nil
+}
+
+func isDelivery(commerce model.NewBasketCommerce) bool {
+	return commerce.FulfilmentMode != nil && *commerce.FulfilmentMode == model.FulfilmentModeDelivery
+}
+
 func calculateOrderAmountForCommerce(
+	user users.User,
 	commerce model.NewBasketCommerce,
 	commercesService commerces.CommercesService,
 	productsService products.ProductsService,
 	paniersService paniers.PaniersService,
-) (int64, float64, float64, error) {
+	deliveryZonesService deliveryzones.DeliveryZonesService,
+	promotionsService promotions.PromotionsService,
+) (*commerceOrderAmount, error) {
 	result := 0
 	resultPaniers := 0.0
 	resultClickAndCollect := 0.0
+	deliveryFee := 0.0
 
 	databaseCommerce, err := commercesService.GetById(commerce.CommerceID)
 
 	if err != nil {
-		return 0, 0, 0, err
+		return nil, err
 	}
 
 	if databaseCommerce == nil {
-		return 0, 0, 0, &commerces.CommerceErrorNotFound{}
+		return nil, &commerces.CommerceErrorNotFound{}
+	}
+
+	// Les promotions en cours du commerce sont appliquées à chaque ligne,
+	// et conservées avec la commande
+	activePromotions, err := promotionsService.GetActiveForCommerce(databaseCommerce.ID, time.Now())
+
+	if err != nil {
+		return nil, err
 	}
 
+	appliedPromotions := []promotions.AppliedPromotion{}
+	productLines := []orderProductLine{}
+	panierLines := make([]orderPanierLine, len(commerce.Paniers))
+	weighedPrice := 0.0
+
 	for _, product := range commerce.Products {
 		databaseProduct, err := productsService.GetById(product.ProductID)
 
 		if err != nil {
-			return 0, 0, 0, err
+			return nil, err
 		}
 
 		if databaseProduct == nil {
-			return 0, 0, 0, &products.ProductNotFoundError{}
+			return nil, &products.ProductNotFoundError{}
+		}
+
+		// Le commerçant choisit les produits et les déclinaisons qu'il
+		// propose en Click&Collect
+		if !databaseProduct.IsVisible(time.Now()) || !databaseCommerce.IsAvailableForClickAndCollect(product.ProductID, product.VariantID) {
+			return nil, &products.ProductNotAvailableError{ProductName: databaseProduct.Name}
+		}
+
+		if !databaseProduct.IsInSeason(time.Now()) {
+			return nil, &products.ProductOutOfSeasonError{ProductName: databaseProduct.Name}
+		}
+
+		if !databaseProduct.IsValidOrderQuantity(product.Quantity) {
+			return nil, &products.InvalidOrderQuantityError{ProductName: databaseProduct.Name}
+		}
+
+		price, err := getProductPrice(databaseProduct, product.VariantID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		line := orderProductLine{
+			name:      databaseProduct.Name,
+			price:     price,
+			unit:      databaseProduct.Unit,
+			tva:       databaseProduct.Tva,
+			isWeighed: databaseProduct.IsWeighed,
+		}
+
+		if variantID, _ := utils.OptionalObjectID(product.VariantID); variantID != nil {
+			if variant := databaseProduct.GetVariant(*variantID); variant != nil {
+				line.variantName = &variant.Name
+			}
+		}
+
+		productPromotions := promotions.ForProduct(activePromotions, databaseProduct.ID, databaseProduct.Categories)
+		promotion, discount := promotions.Best(productPromotions, price, product.Quantity)
+
+		if promotion != nil {
+			appliedPromotion := promotions.NewAppliedPromotion(promotion, discount)
+			appliedPromotion.ProductID = &databaseProduct.ID
+			appliedPromotion.VariantID, _ = utils.OptionalObjectID(product.VariantID)
+			appliedPromotions = append(appliedPromotions, appliedPromotion)
+
+			line.discount = discount
+			line.promotion = &appliedPromotion
+		}
+
+		lineTotal := price*product.Quantity - discount
+		result = result + int(math.Round(lineTotal*100))
+		resultClickAndCollect = resultClickAndCollect + lineTotal
+
+		// Le prix unitaire payé sert à recalculer la ligne une fois
+		// le produit pesé
+		line.unitPrice = price
+
+		if product.Quantity > 0 {
+			line.unitPrice = lineTotal / product.Quantity
 		}
 
-		result = result + int(databaseProduct.Price*product.Quantity*100)
-		resultClickAndCollect = resultClickAndCollect + databaseProduct.Price*product.Quantity
+		productLines = append(productLines, line)
+
+		if databaseProduct.IsWeighed {
+			weighedPrice = weighedPrice + lineTotal
+		}
+	}
+
+	// Un même panier peut être pris plusieurs fois, ce qui compte pour
+	// les promotions par quantité
+	panierIDs := []string{}
+	panierIndexes := map[string][]int{}
+
+	for index, panier := range commerce.Paniers {
+		if len(panierIndexes[panier]) == 0 {
+			panierIDs = append(panierIDs, panier)
+		}
+
+		panierIndexes[panier] = append(panierIndexes[panier], index)
 	}
 
-	for _, panier := range commerce.Paniers {
+	for _, panier := range panierIDs {
 		databasePanier, err := paniersService.GetById(panier)
 
 		if err != nil {
-			return 0, 0, 0, err
+			return nil, err
 		}
 
 		if databasePanier == nil {
-			return 0, 0, 0, &paniers.PanierNotFoundError{}
+			return nil, &paniers.PanierNotFoundError{}
+		}
+
+		quantity := float64(len(panierIndexes[panier]))
+		panierPromotions := promotions.ForPanier(activePromotions, databasePanier.ID)
+		// La réduction du panier s'applique avant les promotions
+		price := databasePanier.GetPrice()
+		promotion, discount := promotions.Best(panierPromotions, price, quantity)
+
+		var appliedPromotion *promotions.AppliedPromotion
+
+		if promotion != nil {
+			panierPromotion := promotions.NewAppliedPromotion(promotion, discount)
+			panierPromotion.PanierID = &databasePanier.ID
+			appliedPromotions = append(appliedPromotions, panierPromotion)
+			appliedPromotion = &panierPromotion
+		}
+
+		// Chaque panier commandé porte sa part de la remise
+		shares := splitDiscount(discount, len(panierIndexes[panier]))
+
+		for i, index := range panierIndexes[panier] {
+			line := orderPanierLine{
+				name:  databasePanier.Name,
+				price: price,
+			}
+
+			if appliedPromotion != nil {
+				linePromotion := *appliedPromotion
+				linePromotion.Discount = shares[i]
+				line.discount = shares[i]
+				line.promotion = &linePromotion
+			}
+
+			panierLines[index] = line
+		}
+
+		lineTotal := price*quantity - discount
+		result = result + int(math.Round(lineTotal*100))
+		resultPaniers = resultPaniers + lineTotal
+	}
+
+	// Livraison : l'adresse doit se trouver dans l'une des zones du
+	// commerce, le minimum de commande et les créneaux de la zone
+	// doivent être respectés
+	if isDelivery(commerce) {
+		deliveryAddress, err := getDeliveryAddress(user, commerce)
+
+		if err != nil {
+			return nil, err
+		}
+
+		zone, err := deliveryZonesService.GetForLocation(
+			commerce.CommerceID,
+			deliveryAddress.Location.Coordinates[0],
+			deliveryAddress.Location.Coordinates[1],
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if zone == nil {
+			return nil, &deliveryzones.AddressNotDeliverableError{}
+		}
+
+		if resultClickAndCollect+resultPaniers < zone.MinimumOrder {
+			return nil, &deliveryzones.MinimumOrderNotReachedError{MinimumOrder: zone.MinimumOrder}
+		}
+
+		if commerce.PickupDate != nil && !zone.IsDeliveryTime(*commerce.PickupDate) {
+			return nil, &deliveryzones.DeliveryTimeUnavailableError{}
+		}
+
+		deliveryFee = zone.Fee
+		result = result + int(math.Round(zone.Fee*100))
+	}
+
+	return &commerceOrderAmount{
+		price:                int64(result),
+		priceClickAndCollect: resultClickAndCollect,
+		pricePaniers:         resultPaniers,
+		deliveryFee:          deliveryFee,
+		weighedPrice:         weighedPrice,
+		promotions:           appliedPromotions,
+		productLines:         productLines,
+		panierLines:          panierLines,
+	}, nil
+}
+
+// Montants calculés pour la commande d'un commerce
+type commerceOrderAmount struct {
+	price                int64
+	priceClickAndCollect float64
+	pricePaniers         float64
+	deliveryFee          float64
+	weighedPrice         float64
+	promotions           []promotions.AppliedPromotion
+	// Dans l'ordre des produits du panier
+	productLines []orderProductLine
+	// Dans l'ordre des paniers commandés
+	panierLines []orderPanierLine
+}
+
+// Répartit une remise entre les exemplaires d'une ligne. Les centimes
+// restants vont un par un aux premiers exemplaires, pour que la somme des
+// parts retombe sur la remise sans qu'aucune ne soit négative.
+func splitDiscount(discount float64, count int) []float64 {
+	shares := make([]float64, count)
+
+	if count == 0 {
+		return shares
+	}
+
+	total := int(math.Round(discount * 100))
+	share := total / count
+	remainder := total % count
+
+	for i := range shares {
+		cents := share
+
+		if i < remainder {
+			cents++
 		}
 
-		result = result + int(databasePanier.Price*100)
-		resultPaniers = resultPaniers + databasePanier.Price
+		shares[i] = float64(cents) / 100
+	}
+
+	return shares
+}
+
+// Ce qui est conservé de chaque ligne au moment de la commande
+type orderProductLine struct {
+	name        string
+	variantName *string
+	price       float64
+	unit        string
+	tva         float64
+	discount    float64
+	promotion   *promotions.AppliedPromotion
+	unitPrice   float64
+	isWeighed   bool
+}
+
+type orderPanierLine struct {
+	name      string
+	price     float64
+	discount  float64
+	promotion *promotions.AppliedPromotion
+}
+
+func (line orderProductLine) toInput(product model.NewBasketProduct) *model.NewCCProcuct {
+	input := model.NewCCProcuct{
+		Quantity:    product.Quantity,
+		ProductID:   product.ProductID,
+		VariantID:   product.VariantID,
+		UnitPrice:   &line.unitPrice,
+		IsWeighed:   &line.isWeighed,
+		Name:        &line.name,
+		VariantName: line.variantName,
+		Price:       &line.price,
+		Unit:        &line.unit,
+		Tva:         &line.tva,
+		Discount:    &line.discount,
+	}
+
+	if line.promotion != nil {
+		input.Promotion = line.promotion.ToInput()
+	}
+
+	return &input
+}
+
+func (line orderPanierLine) toInput(panierID string, pickupDate time.Time) model.NewPanierCommand {
+	input := model.NewPanierCommand{
+		PanierID:   panierID,
+		PickupDate: pickupDate,
+		Name:       &line.name,
+		Price:      &line.price,
+		Discount:   &line.discount,
+	}
+
+	if line.promotion != nil {
+		input.Promotion = line.promotion.ToInput()
+	}
+
+	return input
+}
+
+// Seules les commandes payées comptent : une commande abandonnée ou
+// refusée ne fait pas perdre le bénéfice de la première commande. Les
+// commandes créées avant le suivi du paiement comptent si elles ont été
+// menées à bien.
+func isFirstOrder(
+	user users.User,
+	commandsService commands.CommandsService,
+	commerceCommandsService commands.CommerceCommandsService,
+) (bool, error) {
+	previousCommands, err := commandsService.GetFiltered(bson.M{"userID": user.ID}, nil)
+
+	if err != nil {
+		return false, err
+	}
+
+	if len(previousCommands) == 0 {
+		return true, nil
+	}
+
+	commandIDs := []primitive.ObjectID{}
+
+	for _, previousCommand := range previousCommands {
+		commandIDs = append(commandIDs, previousCommand.ID)
 	}
 
-	return int64(result), resultClickAndCollect, resultPaniers, nil
+	filter := bson.M{
+		"commandID": bson.M{
+			"$in": commandIDs,
+		},
+		"$or": commands.PaidFilter(),
+	}
+
+	paidCommerceCommands, err := commerceCommandsService.GetFiltered(filter, options.Find().SetLimit(1))
+
+	if err != nil {
+		return false, err
+	}
+
+	return len(paidCommerceCommands) == 0, nil
+}
+
+// Vérifie le code promo du panier et renvoie la remise de chaque
+// commerce, nulle s'il n'y a pas de code
+func getPromoCodeDiscounts(
+	user users.User,
+	basket model.NewBasket,
+	basketParts []promocodes.BasketPart,
+	commandsService commands.CommandsService,
+	commerceCommandsService commands.CommerceCommandsService,
+	promoCodesService promocodes.PromoCodesService,
+) (*promocodes.PromoCode, []float64, error) {
+	if basket.PromoCode == nil || *basket.PromoCode == "" {
+		return nil, make([]float64, len(basketParts)), nil
+	}
+
+	promoCode, err := promoCodesService.GetByCode(*basket.PromoCode)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if promoCode == nil {
+		return nil, nil, &promocodes.PromoCodeNotFoundError{}
+	}
+
+	firstOrder, err := isFirstOrder(user, commandsService, commerceCommandsService)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	userUsageCount, err := promoCodesService.CountUserUsages(promoCode.Code, user.ID)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	discounts, err := promoCode.Apply(userUsageCount, firstOrder, basketParts, time.Now())
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return promoCode, discounts, nil
+}
+
+// Vérifie la carte cadeau du panier et renvoie le montant, en centimes,
+// débité pour chaque commerce, nul s'il n'y a pas de carte
+func getGiftCardAmounts(
+	basket model.NewBasket,
+	giftCardParts []giftcards.BasketPart,
+	giftCardsService giftcards.GiftCardsService,
+) (*giftcards.GiftCard, []int, error) {
+	if basket.GiftCardCode == nil || *basket.GiftCardCode == "" {
+		return nil, make([]int, len(giftCardParts)), nil
+	}
+
+	giftCard, err := giftCardsService.GetByCode(*basket.GiftCardCode)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if giftCard == nil {
+		return nil, nil, &giftcards.GiftCardNotFoundError{}
+	}
+
+	giftCardAmounts, err := giftCard.Allocate(giftCardParts, time.Now())
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return giftCard, giftCardAmounts, nil
 }
 
 func order(
@@ -88,7 +589,170 @@ func order(
 	commerceCommandsService commands.CommerceCommandsService,
 	ccCommandsService commands.CCCommandsService,
 	panierCommandsService commands.PanierCommandsService,
-) error {
+	deliveryZonesService deliveryzones.DeliveryZonesService,
+	marketsService markets.MarketsService,
+	pickupPointsService pickuppoints.PickupPointsService,
+	stockService stock.StockService,
+	promotionsService promotions.PromotionsService,
+	promoCodesService promocodes.PromoCodesService,
+	giftCardsService giftcards.GiftCardsService,
+) (err error) {
+	// Toutes les commandes peuvent être déposées au même point relais
+	var pickupPoint *pickuppoints.PickupPoint
+
+	if basket.PickupPointID != nil {
+		databasePickupPoint, err := pickupPointsService.GetById(*basket.PickupPointID)
+
+		if err != nil {
+			return err
+		}
+
+		if databasePickupPoint == nil {
+			return &pickuppoints.PickupPointNotFoundError{}
+		}
+
+		pickupPoint = databasePickupPoint
+	}
+
+	// Les commerces en congés ne peuvent pas recevoir de commandes,
+	// on vérifie donc tout le panier avant de créer quoi que ce soit
+	acceptsGiftCards := []bool{}
+
+	for _, commerce := range basket.Commerces {
+		databaseCommerce, err := commercesService.GetById(commerce.CommerceID)
+
+		if err != nil {
+			return err
+		}
+
+		if databaseCommerce == nil {
+			return &commerces.CommerceErrorNotFound{}
+		}
+
+		if !databaseCommerce.IsActive() {
+			return &commerces.CommerceNotActiveError{Name: databaseCommerce.Name}
+		}
+
+		acceptsGiftCards = append(acceptsGiftCards, databaseCommerce.AcceptsGiftCards)
+
+		isOnVacation := databaseCommerce.IsOnVacation(time.Now())
+
+		if commerce.PickupDate != nil {
+			isOnVacation = isOnVacation || databaseCommerce.IsOnVacation(*commerce.PickupDate)
+		}
+
+		if isOnVacation {
+			return &commerces.CommerceOnVacationError{
+				Name:    databaseCommerce.Name,
+				Message: databaseCommerce.Vacation.Message,
+			}
+		}
+
+		stockItems, err := getStockItems(*commerce)
+
+		if err != nil {
+			return err
+		}
+
+		err = stockService.CheckAvailability(stockItems)
+
+		if err != nil {
+			return err
+		}
+
+		// Point relais : le commerce doit y déposer ses commandes le
+		// jour du retrait
+		if pickupPoint != nil {
+			if isDelivery(*commerce) || commerce.PickupMarketID != nil {
+				return &pickuppoints.PickupPointFulfilmentError{}
+			}
+
+			if commerce.PickupDate == nil || !pickupPoint.AcceptsCommerceAt(databaseCommerce.ID, *commerce.PickupDate) {
+				return &pickuppoints.PickupPointUnavailableError{CommerceName: databaseCommerce.Name}
+			}
+
+			continue
+		}
+
+		// Retrait sur un marché : le commerce doit y participer et
+		// le marché doit se tenir à la date de retrait
+		if commerce.PickupMarketID != nil && !isDelivery(*commerce) {
+			databaseMarket, err := marketsService.GetById(*commerce.PickupMarketID)
+
+			if err != nil {
+				return err
+			}
+
+			if databaseMarket == nil {
+				return &markets.MarketNotFoundError{}
+			}
+
+			if !databaseMarket.HasCommerce(databaseCommerce.ID) {
+				return &markets.CommerceNotInMarketError{}
+			}
+
+			if commerce.PickupDate == nil || !databaseMarket.IsOpenAt(*commerce.PickupDate) {
+				return &markets.MarketClosedError{}
+			}
+		}
+	}
+
+	// Les montants sont calculés pour tous les commerces avant de créer
+	// la commande, le code promo étant réparti entre eux
+	amounts := []*commerceOrderAmount{}
+	basketParts := []promocodes.BasketPart{}
+
+	for _, commerce := range basket.Commerces {
+		amount, err := calculateOrderAmountForCommerce(
+			user,
+			*commerce,
+			commercesService,
+			productsService,
+			paniersService,
+			deliveryZonesService,
+			promotionsService,
+		)
+
+		if err != nil {
+			return err
+		}
+
+		commerceID, err := primitive.ObjectIDFromHex(commerce.CommerceID)
+
+		if err != nil {
+			return err
+		}
+
+		amounts = append(amounts, amount)
+		basketParts = append(basketParts, promocodes.BasketPart{
+			CommerceID: commerceID,
+			Amount:     amount.priceClickAndCollect + amount.pricePaniers,
+		})
+	}
+
+	promoCode, promoCodeDiscounts, err := getPromoCodeDiscounts(user, basket, basketParts, commandsService, commerceCommandsService, promoCodesService)
+
+	if err != nil {
+		return err
+	}
+
+	// La carte cadeau est utilisée sur ce qui reste à payer après le code
+	// promo, livraison comprise
+	giftCardParts := []giftcards.BasketPart{}
+
+	for index, amount := range amounts {
+		giftCardParts = append(giftCardParts, giftcards.BasketPart{
+			Amount:           int(amount.price) - int(math.Round(promoCodeDiscounts[index]*100)),
+			AcceptsGiftCards: acceptsGiftCards[index],
+		})
+	}
+
+	giftCard, giftCardAmounts, err := getGiftCardAmounts(basket, giftCardParts, giftCardsService)
+
+	if err != nil {
+		return err
+	}
+
 	price := 0
 	databaseCommand, err := commandsService.Create(model.NewCommand{
 		CreationDate: time.Now(),
@@ -99,18 +763,102 @@ func order(
 		return err
 	}
 
-	for _, commerce := range basket.Commerces {
-		calculatedPrice, priceClickAndCollect, pricePaniers, err := calculateOrderAmountForCommerce(
-			*commerce,
-			commercesService,
-			productsService,
-			paniersService,
-		)
-		price = int(calculatedPrice)
+	if promoCode != nil {
+		err = promoCodesService.Redeem(promoCode, user.ID, databaseCommand.ID)
 
 		if err != nil {
 			return err
 		}
+	}
+
+	// Si une commande de commerce ne peut pas être créée, celles qui
+	// l'ont déjà été sont annulées pour libérer leur stock
+	createdCommerceCommands := []*commands.CommerceCommand{}
+
+	defer func() {
+		if err == nil {
+			return
+		}
+
+		for _, createdCommerceCommand := range createdCommerceCommands {
+			releaseErr := releaseCommerceCommand(createdCommerceCommand, commerceCommandsService, stockService, promoCodesService, giftCardsService)
+
+			if releaseErr != nil {
+				log.Printf("releaseCommerceCommand: %v", releaseErr)
+			}
+		}
+
+		// Le code a pu être utilisé sans qu'aucune commande de commerce
+		// n'ait été créée
+		if promoCode != nil {
+			releaseErr := promoCodesService.Release(promoCode.Code, databaseCommand.ID)
+
+			if releaseErr != nil {
+				log.Printf("promoCodesService.Release: %v", releaseErr)
+			}
+		}
+	}()
+
+	for index, commerce := range basket.Commerces {
+		amount := amounts[index]
+		price = giftCardParts[index].Amount - giftCardAmounts[index]
+
+		var commandPromoCode *string
+		var promoCodeFundedBy *model.PromoCodeFunding
+
+		if promoCodeDiscounts[index] > 0 {
+			fundedBy := model.PromoCodeFunding(promoCode.FundedBy)
+			commandPromoCode = &promoCode.Code
+			promoCodeFundedBy = &fundedBy
+		}
+
+		var giftCardID *string
+		var giftCardCode *string
+		giftCardAmount := float64(giftCardAmounts[index]) / 100
+
+		if giftCardAmounts[index] > 0 {
+			giftCardIDValue := giftCard.ID.Hex()
+			giftCardID = &giftCardIDValue
+			giftCardCode = &giftCard.Code
+		}
+
+		fulfilmentMode := model.FulfilmentModePickup
+		var deliveryAddress *model.NewAddress
+		var pickupMarketID *string
+		var pickupPointID *string
+
+		if pickupPoint != nil {
+			pickupPointIDValue := pickupPoint.ID.Hex()
+			pickupPointID = &pickupPointIDValue
+		} else if isDelivery(*commerce) {
+			fulfilmentMode = model.FulfilmentModeDelivery
+
+			userAddress, err := getDeliveryAddress(user, *commerce)
+
+			if err != nil {
+				return err
+			}
+
+			deliveryAddress = &model.NewAddress{
+				Number:        userAddress.Number,
+				Route:         userAddress.Route,
+				OptionalRoute: userAddress.OptionalRoute,
+				PostalCode:    userAddress.PostalCode,
+				City:          userAddress.City,
+				Longitude:     &userAddress.Location.Coordinates[0],
+				Latitude:      &userAddress.Location.Coordinates[1],
+			}
+		} else {
+			pickupMarketID = commerce.PickupMarketID
+		}
+
+		discount := 0.0
+		commandPromotions := []*model.NewAppliedPromotion{}
+
+		for i := range amount.promotions {
+			discount = discount + amount.promotions[i].Discount
+			commandPromotions = append(commandPromotions, amount.promotions[i].ToInput())
+		}
 
 		// La command
 		databaseCommerceCommand, err := commerceCommandsService.Create(model.NewCommerceCommand{
@@ -118,22 +866,60 @@ func order(
 			PickupDate:           *commerce.PickupDate,
 			PaymentMethod:        paymentMethod,
 			Price:                price,
-			PriceClickAndCollect: priceClickAndCollect,
-			PricePaniers:         pricePaniers,
+			PriceClickAndCollect: amount.priceClickAndCollect,
+			PricePaniers:         amount.pricePaniers,
+			FulfilmentMode:       &fulfilmentMode,
+			DeliveryAddress:      deliveryAddress,
+			DeliveryFee:          &amount.deliveryFee,
+			PickupMarketID:       pickupMarketID,
+			PickupPointID:        pickupPointID,
+			Discount:             &discount,
+			Promotions:           commandPromotions,
+			PromoCode:            commandPromoCode,
+			PromoCodeDiscount:    &promoCodeDiscounts[index],
+			PromoCodeFundedBy:    promoCodeFundedBy,
+			GiftCardID:           giftCardID,
+			GiftCardCode:         giftCardCode,
+			GiftCardAmount:       &giftCardAmount,
+			WeighedPrice:         &amount.weighedPrice,
 		}, databaseCommand.ID)
 
 		if err != nil {
 			return err
 		}
 
+		createdCommerceCommands = append(createdCommerceCommands, databaseCommerceCommand)
+
+		// Le stock est réservé au moment de la commande et rendu si elle
+		// n'est pas payée. S'il a été vendu entre temps, la commande est
+		// annulée.
+		stockItems, err := getStockItems(*commerce)
+
+		if err != nil {
+			return err
+		}
+
+		err = stockService.Reserve(databaseCommerceCommand.ID, stockItems)
+
+		if err != nil {
+			return err
+		}
+
+		// Le solde a pu être utilisé par une autre commande depuis la
+		// vérification du panier
+		if giftCardAmounts[index] > 0 {
+			err = giftCardsService.Redeem(giftCard, giftCardAmounts[index], databaseCommerceCommand.CommerceID, databaseCommerceCommand.ID)
+
+			if err != nil {
+				return err
+			}
+		}
+
 		// Click & collect
 		commandProducts := []*model.NewCCProcuct{}
 
-		for _, product := range commerce.Products {
-			commandProducts = append(commandProducts, &model.NewCCProcuct{
-				Quantity:  int(product.Quantity),
-				ProductID: product.ProductID,
-			})
+		for i, product := range commerce.Products {
+			commandProducts = append(commandProducts, amount.productLines[i].toInput(*product))
 		}
 
 		command := model.NewCCCommand{
@@ -148,11 +934,8 @@ func order(
 		}
 
 		// Paniers
-		for _, panier := range commerce.Paniers {
-			panierCommand := model.NewPanierCommand{
-				PanierID:   panier,
-				PickupDate: command.PickupDate,
-			}
+		for index, panier := range commerce.Paniers {
+			panierCommand := amount.panierLines[index].toInput(panier, command.PickupDate)
 
 			_, err = panierCommandsService.Create(databaseCommerceCommand.ID, panierCommand)
 
@@ -400,6 +1183,13 @@ func HandleCreateOrder(
 	commerceCommandsService commands.CommerceCommandsService,
 	ccCommandsService commands.CCCommandsService,
 	panierCommandsService commands.PanierCommandsService,
+	deliveryZonesService deliveryzones.DeliveryZonesService,
+	marketsService markets.MarketsService,
+	pickupPointsService pickuppoints.PickupPointsService,
+	stockService stock.StockService,
+	promotionsService promotions.PromotionsService,
+	promoCodesService promocodes.PromoCodesService,
+	giftCardsService giftcards.GiftCardsService,
 ) {
 	if r.Method != "POST" {
 		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
@@ -429,6 +1219,13 @@ func HandleCreateOrder(
 		commerceCommandsService,
 		ccCommandsService,
 		panierCommandsService,
+		deliveryZonesService,
+		marketsService,
+		pickupPointsService,
+		stockService,
+		promotionsService,
+		promoCodesService,
+		giftCardsService,
 	)
 
 	if err != nil {
@@ -450,7 +1247,11 @@ func HandleCompleteOrder(
 	r *http.Request,
 	usersService users.UsersService,
 	commercesService commerces.CommercesService,
+	commandsService commands.CommandsService,
 	commerceCommandsService commands.CommerceCommandsService,
+	stockService stock.StockService,
+	promoCodesService promocodes.PromoCodesService,
+	giftCardsService giftcards.GiftCardsService,
 ) {
 	// Set Stripe API key
 	apiKey := config.Cfg.Stripe.Key
@@ -471,7 +1272,12 @@ func HandleCompleteOrder(
 		return
 	}
 
-	var stripeCustomer, _ = authentification(w, r, usersService)
+	var stripeCustomer, user = authentification(w, r, usersService)
+
+	if user == nil {
+		return
+	}
+
 	databaseCommerceCommand, err := commerceCommandsService.GetById(*req.CommerceCommandID)
 
 	if err != nil {
@@ -484,25 +1290,73 @@ func HandleCompleteOrder(
 		return
 	}
 
+	// Seul le client qui a passé la commande peut la payer
+	databaseCommand, err := commandsService.GetById(databaseCommerceCommand.CommandID.Hex())
+
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if databaseCommand == nil || databaseCommand.UserID != user.ID {
+		http.Error(w, "access denied", http.StatusForbidden)
 		return
 	}
 
-	databaseCommerceCommand.Status = commands.COMMERCE_COMMAND_STATUS_DONE
+	// Une commande abandonnée a rendu son stock, elle ne peut plus être
+	// payée
+	if databaseCommerceCommand.Status == commands.COMMERCE_COMMAND_STATUS_CANCELED {
+		http.Error(w, "la commande a été annulée", http.StatusConflict)
+		return
+	}
 
-	if req.PaymentIntentID != nil {
-		err := commerceCommandsService.Update(databaseCommerceCommand)
+	// Une commande déjà payée ne doit être ni prélevée ni créditée une
+	// seconde fois
+	if databaseCommerceCommand.IsPaid() {
+		writeJSON(w, struct {
+			ClientSecret   string `json:"clientSecret"`
+			RequiresAction bool   `json:"requiresAction"`
+		}{
+			ClientSecret:   "",
+			RequiresAction: false,
+		})
+
+		return
+	}
+
+	// Commande entièrement payée par carte cadeau, il n'y a rien à
+	// prélever
+	if databaseCommerceCommand.Price <= 0 {
+		err := payCommerceCommand(databaseCommerceCommand, commerceCommandsService, commercesService)
 
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 
-		err = commercesService.UpdateBalancesForOrder(databaseCommerceCommand.CommerceID.Hex(), databaseCommerceCommand.Price, databaseCommerceCommand.PriceClickAndCollect, databaseCommerceCommand.PricePaniers)
+		writeJSON(w, struct {
+			ClientSecret   string `json:"clientSecret"`
+			RequiresAction bool   `json:"requiresAction"`
+		}{
+			ClientSecret:   "",
+			RequiresAction: false,
+		})
+
+		return
+	}
+
+	if req.PaymentIntentID != nil {
+		// Le paiement doit bien être celui de cette commande, à son
+		// montant et au nom du client
+		clientPaymentIntent, err := paymentintent.Get(*req.PaymentIntentID, nil)
 
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if !isCommerceCommandPaymentIntent(clientPaymentIntent, databaseCommerceCommand, stripeCustomer) {
+			http.Error(w, "le paiement ne correspond pas à la commande", http.StatusBadRequest)
 			return
 		}
 
@@ -512,6 +1366,15 @@ func HandleCompleteOrder(
 
 		pi, err := paymentintent.Confirm(*req.PaymentIntentID, params)
 
+		if err != nil {
+			releaseCommerceCommand(databaseCommerceCommand, commerceCommandsService, stockService, promoCodesService, giftCardsService)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		databaseCommerceCommand.PaymentIntentID = req.PaymentIntentID
+		err = handlePaymentStatus(databaseCommerceCommand, pi.Status, commerceCommandsService, commercesService, stockService, promoCodesService, giftCardsService)
+
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -533,37 +1396,31 @@ func HandleCompleteOrder(
 		}
 
 		params := &stripe.PaymentIntentParams{
-			Amount:             stripe.Int64(int64(databaseCommerceCommand.Price)),
+			Amount:             stripe.Int64(int64(databaseCommerceCommand.AuthorizedPrice())),
 			Currency:           stripe.String(string(stripe.CurrencyEUR)),
 			PaymentMethod:      &databaseCommerceCommand.PaymentMethod,
 			Confirm:            &confirm,
 			ConfirmationMethod: &confirmationMethode,
+			CaptureMethod:      getCaptureMethod(databaseCommerceCommand),
 			Customer:           stripeCustomer,
 		}
+		params.AddMetadata("commerceCommandID", databaseCommerceCommand.ID.Hex())
 
 		pi, err := paymentintent.New(params)
-		log.Printf("pi.New: %v", pi.ClientSecret)
 
 		if err != nil {
+			releaseCommerceCommand(databaseCommerceCommand, commerceCommandsService, stockService, promoCodesService, giftCardsService)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			log.Printf("pi.New: %v", err)
 			return
 		}
 
-		if pi.Status == stripe.PaymentIntentStatusSucceeded {
-			err := commerceCommandsService.Update(databaseCommerceCommand)
-
-			if err != nil {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
-				return
-			}
-
-			err = commercesService.UpdateBalancesForOrder(databaseCommerceCommand.CommerceID.Hex(), databaseCommerceCommand.Price, databaseCommerceCommand.PriceClickAndCollect, databaseCommerceCommand.PricePaniers)
+		databaseCommerceCommand.PaymentIntentID = &pi.ID
+		err = handlePaymentStatus(databaseCommerceCommand, pi.Status, commerceCommandsService, commercesService, stockService, promoCodesService, giftCardsService)
 
-			if err != nil {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
-				return
-			}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
 		}
 
 		writeJSON(w, struct {
@@ -577,6 +1434,18 @@ func HandleCompleteOrder(
 
 }
 
+func isCommerceCommandPaymentIntent(pi *stripe.PaymentIntent, commerceCommand *commands.CommerceCommand, stripeCustomer *string) bool {
+	if pi.Metadata["commerceCommandID"] != commerceCommand.ID.Hex() {
+		return false
+	}
+
+	if pi.Amount != int64(commerceCommand.AuthorizedPrice()) {
+		return false
+	}
+
+	return pi.Customer != nil && stripeCustomer != nil && pi.Customer.ID == *stripeCustomer
+}
+
 func BillStoresServices(price int, paymentMethod string, customer string) (bool, error) {
 	apiKey := config.Cfg.Stripe.Key
 	stripe.Key = apiKey
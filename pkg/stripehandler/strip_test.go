@@ -0,0 +1,226 @@
+package stripehandler
+
+import (
+	"testing"
+
+	"chemin-du-local.bzh/graphql/graph/model"
+	"chemin-du-local.bzh/graphql/internal/commerces"
+	"chemin-du-local.bzh/graphql/internal/mocks"
+	"chemin-du-local.bzh/graphql/internal/products"
+	"chemin-du-local.bzh/graphql/internal/promotions"
+	"chemin-du-local.bzh/graphql/internal/services/commands"
+	"chemin-du-local.bzh/graphql/internal/services/paniers"
+	"chemin-du-local.bzh/graphql/internal/users"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stripe/stripe-go/v72"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCalculateOrderAmountForCommerce(t *testing.T) {
+	product := products.Product{
+		ID:    primitive.NewObjectID(),
+		Name:  "Cidre",
+		Price: 4,
+		Variants: []products.ProductVariant{
+			{ID: primitive.NewObjectID(), Name: "75 cl", Price: 4.5},
+			{ID: primitive.NewObjectID(), Name: "1,5 l", Price: 7},
+		},
+	}
+	commerce := commerces.Commerce{
+		ID:                                  primitive.NewObjectID(),
+		VariantsAvailableForClickAndCollect: []string{product.Variants[0].ID.Hex()},
+	}
+
+	commercesService := new(mocks.CommercesService)
+	productsService := new(mocks.ProductsService)
+	promotionsService := new(mocks.PromotionsService)
+
+	commercesService.On("GetById", commerce.ID.Hex()).Return(&commerce, nil)
+	productsService.On("GetById", product.ID.Hex()).Return(&product, nil)
+	promotionsService.On("GetActiveForCommerce", commerce.ID, mock.AnythingOfType("time.Time")).Return([]promotions.Promotion{}, nil)
+
+	basketCommerce := func(variantID string) model.NewBasketCommerce {
+		return model.NewBasketCommerce{
+			CommerceID: commerce.ID.Hex(),
+			Products: []*model.NewBasketProduct{
+				{ProductID: product.ID.Hex(), VariantID: &variantID, Quantity: 2},
+			},
+		}
+	}
+
+	t.Run("a variant offered in click and collect can be ordered", func(t *testing.T) {
+		amount, err := calculateOrderAmountForCommerce(users.User{}, basketCommerce(product.Variants[0].ID.Hex()), commercesService, productsService, nil, nil, promotionsService)
+
+		require.NoError(t, err)
+		require.Equal(t, int64(900), amount.price)
+	})
+
+	t.Run("a product sold by the unit cannot be ordered by fraction", func(t *testing.T) {
+		commerceProduct := basketCommerce(product.Variants[0].ID.Hex())
+		commerceProduct.Products[0].Quantity = 1.5
+
+		_, err := calculateOrderAmountForCommerce(users.User{}, commerceProduct, commercesService, productsService, nil, nil, promotionsService)
+
+		require.IsType(t, &products.InvalidOrderQuantityError{}, err)
+	})
+
+	t.Run("a variant not offered in click and collect is refused", func(t *testing.T) {
+		_, err := calculateOrderAmountForCommerce(users.User{}, basketCommerce(product.Variants[1].ID.Hex()), commercesService, productsService, nil, nil, promotionsService)
+
+		require.IsType(t, &products.ProductNotAvailableError{}, err)
+	})
+
+	t.Run("the reduction of a panier is applied", func(t *testing.T) {
+		panier := paniers.Panier{
+			ID:         primitive.NewObjectID(),
+			CommerceID: commerce.ID,
+			Price:      20,
+			Reduction:  10,
+		}
+
+		paniersService := new(mocks.PaniersService)
+		paniersService.On("GetById", panier.ID.Hex()).Return(&panier, nil)
+
+		amount, err := calculateOrderAmountForCommerce(users.User{}, model.NewBasketCommerce{
+			CommerceID: commerce.ID.Hex(),
+			Paniers:    []string{panier.ID.Hex(), panier.ID.Hex()},
+		}, commercesService, productsService, paniersService, nil, promotionsService)
+
+		require.NoError(t, err)
+		require.Equal(t, int64(3600), amount.price)
+	})
+
+	t.Run("the discount of a panier is split without losing cents", func(t *testing.T) {
+		panier := paniers.Panier{
+			ID:         primitive.NewObjectID(),
+			CommerceID: commerce.ID,
+			Price:      10,
+		}
+
+		// "2 achetés, 1 offert" : 10€ de remise pour trois paniers
+		panierPromotionsService := new(mocks.PromotionsService)
+		panierPromotionsService.On("GetActiveForCommerce", commerce.ID, mock.AnythingOfType("time.Time")).Return([]promotions.Promotion{
+			{
+				ID:           primitive.NewObjectID(),
+				CommerceID:   commerce.ID,
+				Type:         model.PromotionTypeBuyXGetY.String(),
+				Target:       model.PromotionTargetPanier.String(),
+				PanierID:     &panier.ID,
+				BuyQuantity:  2,
+				FreeQuantity: 1,
+			},
+		}, nil)
+
+		paniersService := new(mocks.PaniersService)
+		paniersService.On("GetById", panier.ID.Hex()).Return(&panier, nil)
+
+		amount, err := calculateOrderAmountForCommerce(users.User{}, model.NewBasketCommerce{
+			CommerceID: commerce.ID.Hex(),
+			Paniers:    []string{panier.ID.Hex(), panier.ID.Hex(), panier.ID.Hex()},
+		}, commercesService, productsService, paniersService, nil, panierPromotionsService)
+
+		require.NoError(t, err)
+		require.Equal(t, int64(2000), amount.price)
+		require.Len(t, amount.panierLines, 3)
+		require.Equal(t, 3.34, amount.panierLines[0].discount)
+		require.Equal(t, 3.34, amount.panierLines[0].promotion.Discount)
+		require.Equal(t, 3.33, amount.panierLines[1].discount)
+		require.Equal(t, 3.33, amount.panierLines[2].discount)
+	})
+}
+
+func TestSplitDiscount(t *testing.T) {
+	require.Equal(t, []float64{3.34, 3.33, 3.33}, splitDiscount(10, 3))
+	require.Equal(t, []float64{0.67, 0.66}, splitDiscount(1.33, 2))
+	require.Equal(t, []float64{2.5, 2.5}, splitDiscount(5, 2))
+	require.Equal(t, []float64{0.01, 0.01, 0, 0}, splitDiscount(0.02, 4))
+	require.Equal(t, []float64{0, 0}, splitDiscount(0, 2))
+	require.Equal(t, []float64{}, splitDiscount(1, 0))
+}
+
+func TestIsFirstOrder(t *testing.T) {
+	user := users.User{ID: primitive.NewObjectID()}
+
+	t.Run("a customer without any command", func(t *testing.T) {
+		commandsService := new(mocks.CommandsService)
+		commerceCommandsService := new(mocks.CommerceCommandsService)
+
+		commandsService.On("GetFiltered", bson.M{"userID": user.ID}, mock.Anything).Return([]commands.Command{}, nil)
+
+		firstOrder, err := isFirstOrder(user, commandsService, commerceCommandsService)
+
+		require.NoError(t, err)
+		require.True(t, firstOrder)
+	})
+
+	t.Run("unpaid commands are not counted", func(t *testing.T) {
+		commandsService := new(mocks.CommandsService)
+		commerceCommandsService := new(mocks.CommerceCommandsService)
+
+		commandsService.On("GetFiltered", bson.M{"userID": user.ID}, mock.Anything).Return([]commands.Command{{ID: primitive.NewObjectID()}}, nil)
+		commerceCommandsService.On("GetFiltered", mock.MatchedBy(func(filter bson.M) bool {
+			return filter["$or"] != nil
+		}), mock.Anything).Return([]commands.CommerceCommand{}, nil)
+
+		firstOrder, err := isFirstOrder(user, commandsService, commerceCommandsService)
+
+		require.NoError(t, err)
+		require.True(t, firstOrder)
+	})
+
+	t.Run("a paid command is counted", func(t *testing.T) {
+		commandsService := new(mocks.CommandsService)
+		commerceCommandsService := new(mocks.CommerceCommandsService)
+
+		commandsService.On("GetFiltered", bson.M{"userID": user.ID}, mock.Anything).Return([]commands.Command{{ID: primitive.NewObjectID()}}, nil)
+		commerceCommandsService.On("GetFiltered", mock.Anything, mock.Anything).Return([]commands.CommerceCommand{{ID: primitive.NewObjectID()}}, nil)
+
+		firstOrder, err := isFirstOrder(user, commandsService, commerceCommandsService)
+
+		require.NoError(t, err)
+		require.False(t, firstOrder)
+	})
+}
+
+func TestIsCommerceCommandPaymentIntent(t *testing.T) {
+	stripeCustomer := "cus_client"
+	commerceCommand := commands.CommerceCommand{
+		ID:    primitive.NewObjectID(),
+		Price: 1500,
+	}
+
+	newPaymentIntent := func() *stripe.PaymentIntent {
+		return &stripe.PaymentIntent{
+			Amount:   1500,
+			Customer: &stripe.Customer{ID: stripeCustomer},
+			Metadata: map[string]string{"commerceCommandID": commerceCommand.ID.Hex()},
+		}
+	}
+
+	t.Run("the payment of the order is accepted", func(t *testing.T) {
+		require.True(t, isCommerceCommandPaymentIntent(newPaymentIntent(), &commerceCommand, &stripeCustomer))
+	})
+
+	t.Run("the payment of another order is refused", func(t *testing.T) {
+		pi := newPaymentIntent()
+		pi.Metadata["commerceCommandID"] = primitive.NewObjectID().Hex()
+
+		require.False(t, isCommerceCommandPaymentIntent(pi, &commerceCommand, &stripeCustomer))
+	})
+
+	t.Run("a payment of another amount is refused", func(t *testing.T) {
+		pi := newPaymentIntent()
+		pi.Amount = 100
+
+		require.False(t, isCommerceCommandPaymentIntent(pi, &commerceCommand, &stripeCustomer))
+	})
+
+	t.Run("the payment of another customer is refused", func(t *testing.T) {
+		pi := newPaymentIntent()
+		pi.Customer = &stripe.Customer{ID: "cus_other"}
+
+		require.False(t, isCommerceCommandPaymentIntent(pi, &commerceCommand, &stripeCustomer))
+	})
+}
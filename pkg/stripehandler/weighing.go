@@ -0,0 +1,88 @@
+package stripehandler
+
+import (
+	"time"
+
+	"chemin-du-local.bzh/graphql/internal/config"
+	"chemin-du-local.bzh/graphql/internal/services/commands"
+	"github.com/stripe/stripe-go/v72"
+	"github.com/stripe/stripe-go/v72/paymentintent"
+	"github.com/stripe/stripe-go/v72/refund"
+)
+
+// Stripe annule les autorisations non capturées au bout de sept jours.
+// Pour un retrait plus lointain, le montant autorisé est prélevé tout de
+// suite et l'écart remboursé après la pesée.
+const paymentAuthorizationValidity = 6 * 24 * time.Hour
+
+// Une commande avec des produits au poids n'est qu'autorisée, le montant
+// réel étant capturé après la pesée
+func getCaptureMethod(commerceCommand *commands.CommerceCommand) *string {
+	if commerceCommand.IsAwaitingWeights() && commerceCommand.PickupDate.Before(time.Now().Add(paymentAuthorizationValidity)) {
+		return stripe.String(string(stripe.PaymentIntentCaptureMethodManual))
+	}
+
+	return stripe.String(string(stripe.PaymentIntentCaptureMethodAutomatic))
+}
+
+// Règle le paiement d'une commande dont les produits au poids ont été
+// pesés : capture du montant réel si le paiement n'a été qu'autorisé,
+// remboursement de la différence s'il a déjà été prélevé. Renvoie le
+// montant payé par carte en centimes, qui ne peut dépasser le montant
+// autorisé.
+func SettleWeighedOrder(commerceCommand *commands.CommerceCommand) (int, error) {
+	// Set Stripe API key
+	apiKey := config.Cfg.Stripe.Key
+	stripe.Key = apiKey
+
+	pi, err := paymentintent.Get(*commerceCommand.PaymentIntentID, nil)
+
+	if err != nil {
+		return 0, err
+	}
+
+	price := int64(commerceCommand.Price)
+
+	switch pi.Status {
+	case stripe.PaymentIntentStatusRequiresCapture:
+		if price > pi.AmountCapturable {
+			price = pi.AmountCapturable
+		}
+
+		// Rien à payer, par exemple grâce à une carte cadeau
+		if price <= 0 {
+			_, err = paymentintent.Cancel(pi.ID, nil)
+
+			return 0, err
+		}
+
+		_, err = paymentintent.Capture(pi.ID, &stripe.PaymentIntentCaptureParams{
+			AmountToCapture: stripe.Int64(price),
+		})
+
+		if err != nil {
+			return 0, err
+		}
+
+		return int(price), nil
+	case stripe.PaymentIntentStatusSucceeded:
+		if price > pi.AmountReceived {
+			price = pi.AmountReceived
+		}
+
+		if price < pi.AmountReceived {
+			_, err = refund.New(&stripe.RefundParams{
+				PaymentIntent: stripe.String(pi.ID),
+				Amount:        stripe.Int64(pi.AmountReceived - price),
+			})
+
+			if err != nil {
+				return 0, err
+			}
+		}
+
+		return int(price), nil
+	}
+
+	return 0, &commands.PaymentNotSettleableError{}
+}
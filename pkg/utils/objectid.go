@@ -0,0 +1,18 @@
+package utils
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// Convertit un identifiant facultatif reçu de l'API
+func OptionalObjectID(id *string) (*primitive.ObjectID, error) {
+	if id == nil {
+		return nil, nil
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(*id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &objectID, nil
+}
@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+var slugReplacements = map[rune]string{
+	'à': "a", 'â': "a", 'ä': "a", 'á': "a", 'ã': "a",
+	'ç': "c",
+	'é': "e", 'è': "e", 'ê': "e", 'ë': "e",
+	'î': "i", 'ï': "i", 'í': "i",
+	'ñ': "n",
+	'ô': "o", 'ö': "o", 'ó': "o", 'õ': "o",
+	'ù': "u", 'û': "u", 'ü': "u", 'ú': "u",
+	'ÿ': "y",
+	'æ': "ae", 'œ': "oe",
+}
+
+// Transforme un texte en identifiant lisible pour une URL :
+// "Crêperie Ty Breizh, Quimper" donne "creperie-ty-breizh-quimper"
+func Slugify(text string) string {
+	builder := strings.Builder{}
+	lastIsDash := true
+
+	for _, character := range strings.ToLower(text) {
+		if replacement, ok := slugReplacements[character]; ok {
+			builder.WriteString(replacement)
+			lastIsDash = false
+		} else if character < unicode.MaxASCII && (unicode.IsLetter(character) || unicode.IsDigit(character)) {
+			builder.WriteRune(character)
+			lastIsDash = false
+		} else if !lastIsDash {
+			builder.WriteRune('-')
+			lastIsDash = true
+		}
+	}
+
+	return strings.TrimSuffix(builder.String(), "-")
+}
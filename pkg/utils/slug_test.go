@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlugify(t *testing.T) {
+	cases := map[string]string{
+		"Crêperie Ty Breizh, Quimper": "creperie-ty-breizh-quimper",
+		"  L'Œuf & la Poule  ":        "l-oeuf-la-poule",
+		"Boulangerie n°2 -- Rennes":   "boulangerie-n-2-rennes",
+		"ÉPICERIE Ça Va":              "epicerie-ca-va",
+		"Café 1900":                   "cafe-1900",
+		"Bière ß":                     "biere",
+		"!!!":                         "",
+		"":                            "",
+	}
+
+	for text, expected := range cases {
+		require.Equal(t, expected, Slugify(text), text)
+	}
+}
@@ -0,0 +1,371 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Lecture et écriture minimales de classeurs Excel (Office Open XML) :
+// seule la première feuille est lue, et les cellules sont traitées comme
+// du texte. Cela suffit pour échanger des tableaux avec les commerçants
+// sans dépendre d'une bibliothèque complète.
+
+const CONTENT_TYPE = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// Limites de lecture : une référence de cellule ou de ligne démesurée ne
+// doit pas faire allouer des millions de cellules vides
+const MAX_ROWS = 10000
+const MAX_COLUMNS = 100
+const MAX_FILE_SIZE = 20 << 20
+
+var ErrInvalidWorkbook = errors.New("le fichier Excel est invalide")
+var ErrWorkbookTooLarge = errors.New("le fichier Excel est trop volumineux")
+
+// Lecture
+
+type sharedStrings struct {
+	Items []stringItem `xml:"si"`
+}
+
+type stringItem struct {
+	Text string    `xml:"t"`
+	Runs []textRun `xml:"r"`
+}
+
+type textRun struct {
+	Text string `xml:"t"`
+}
+
+func (item stringItem) String() string {
+	if len(item.Runs) == 0 {
+		return item.Text
+	}
+
+	builder := strings.Builder{}
+
+	for _, run := range item.Runs {
+		builder.WriteString(run.Text)
+	}
+
+	return builder.String()
+}
+
+type worksheet struct {
+	Rows []row `xml:"sheetData>row"`
+}
+
+type row struct {
+	Index int    `xml:"r,attr"`
+	Cells []cell `xml:"c"`
+}
+
+type cell struct {
+	Reference    string      `xml:"r,attr"`
+	Type         string      `xml:"t,attr"`
+	Value        string      `xml:"v"`
+	InlineString *stringItem `xml:"is"`
+}
+
+type workbook struct {
+	Sheets []struct {
+		RelationID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type relationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// Renvoie les lignes de la première feuille du classeur
+func ReadRows(data []byte) ([][]string, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+
+	if err != nil {
+		return nil, ErrInvalidWorkbook
+	}
+
+	files := map[string]*zip.File{}
+
+	for _, file := range reader.File {
+		files[file.Name] = file
+	}
+
+	sharedValues := []string{}
+
+	if file, ok := files["xl/sharedStrings.xml"]; ok {
+		var shared sharedStrings
+
+		if err := decodeFile(file, &shared); err != nil {
+			return nil, err
+		}
+
+		for _, item := range shared.Items {
+			sharedValues = append(sharedValues, item.String())
+		}
+	}
+
+	sheetFile, ok := files[firstSheetPath(files)]
+
+	if !ok {
+		return nil, ErrInvalidWorkbook
+	}
+
+	var sheet worksheet
+
+	if err := decodeFile(sheetFile, &sheet); err != nil {
+		return nil, err
+	}
+
+	rows := [][]string{}
+
+	for _, sheetRow := range sheet.Rows {
+		if sheetRow.Index > MAX_ROWS || len(rows) >= MAX_ROWS {
+			return nil, ErrWorkbookTooLarge
+		}
+
+		// Les lignes vides ne sont pas écrites dans le fichier
+		for sheetRow.Index > len(rows)+1 {
+			rows = append(rows, []string{})
+		}
+
+		values := []string{}
+
+		for index, sheetCell := range sheetRow.Cells {
+			column := index
+
+			if sheetCell.Reference != "" {
+				column = columnIndex(sheetCell.Reference)
+			}
+
+			if column >= MAX_COLUMNS {
+				return nil, ErrWorkbookTooLarge
+			}
+
+			for len(values) < column {
+				values = append(values, "")
+			}
+
+			values = append(values, cellValue(sheetCell, sharedValues))
+		}
+
+		rows = append(rows, values)
+	}
+
+	return rows, nil
+}
+
+// Le chemin de la première feuille est donné par le classeur, avec un
+// repli sur le chemin utilisé par Excel
+func firstSheetPath(files map[string]*zip.File) string {
+	defaultPath := "xl/worksheets/sheet1.xml"
+
+	workbookFile, ok := files["xl/workbook.xml"]
+	relationshipsFile, relationshipsOk := files["xl/_rels/workbook.xml.rels"]
+
+	if !ok || !relationshipsOk {
+		return defaultPath
+	}
+
+	var book workbook
+	var rels relationships
+
+	if decodeFile(workbookFile, &book) != nil || decodeFile(relationshipsFile, &rels) != nil || len(book.Sheets) == 0 {
+		return defaultPath
+	}
+
+	for _, relationship := range rels.Relationships {
+		if relationship.ID != book.Sheets[0].RelationID {
+			continue
+		}
+
+		if strings.HasPrefix(relationship.Target, "/") {
+			return strings.TrimPrefix(relationship.Target, "/")
+		}
+
+		return path.Join("xl", relationship.Target)
+	}
+
+	return defaultPath
+}
+
+func decodeFile(file *zip.File, to interface{}) error {
+	reader, err := file.Open()
+
+	if err != nil {
+		return ErrInvalidWorkbook
+	}
+
+	defer reader.Close()
+
+	// La taille décompressée annoncée par l'archive n'est pas fiable
+	content, err := io.ReadAll(io.LimitReader(reader, MAX_FILE_SIZE+1))
+
+	if err != nil {
+		return ErrInvalidWorkbook
+	}
+
+	if len(content) > MAX_FILE_SIZE {
+		return ErrWorkbookTooLarge
+	}
+
+	if err := xml.Unmarshal(content, to); err != nil {
+		return ErrInvalidWorkbook
+	}
+
+	return nil
+}
+
+func cellValue(sheetCell cell, sharedStrings []string) string {
+	switch sheetCell.Type {
+	case "s":
+		index, err := strconv.Atoi(sheetCell.Value)
+
+		if err != nil || index < 0 || index >= len(sharedStrings) {
+			return ""
+		}
+
+		return sharedStrings[index]
+	case "inlineStr":
+		if sheetCell.InlineString == nil {
+			return ""
+		}
+
+		return sheetCell.InlineString.String()
+	case "b":
+		if sheetCell.Value == "1" {
+			return "true"
+		}
+
+		return "false"
+	}
+
+	return sheetCell.Value
+}
+
+// "C12" donne 2
+func columnIndex(reference string) int {
+	index := 0
+
+	for _, character := range reference {
+		if character < 'A' || character > 'Z' {
+			break
+		}
+
+		index = index*26 + int(character-'A'+1)
+
+		// Évite le dépassement sur une référence démesurée
+		if index > MAX_COLUMNS {
+			return MAX_COLUMNS
+		}
+	}
+
+	return index - 1
+}
+
+// "2" donne "C"
+func columnName(index int) string {
+	name := ""
+
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+
+	return name
+}
+
+// Écriture
+
+const contentTypesXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+</Types>`
+
+const rootRelationshipsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`
+
+const workbookRelationshipsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+</Relationships>`
+
+// Écrit un classeur d'une seule feuille, toutes les cellules étant du
+// texte
+func WriteRows(sheetName string, rows [][]string) ([]byte, error) {
+	buffer := &bytes.Buffer{}
+	writer := zip.NewWriter(buffer)
+
+	workbookXML := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="` + escape(sheetName) + `" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`
+
+	sheet := strings.Builder{}
+	sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	sheet.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	for rowIndex, values := range rows {
+		rowNumber := strconv.Itoa(rowIndex + 1)
+		sheet.WriteString(`<row r="` + rowNumber + `">`)
+
+		for columnIndex, value := range values {
+			sheet.WriteString(`<c r="` + columnName(columnIndex) + rowNumber + `" t="inlineStr"><is><t xml:space="preserve">`)
+			sheet.WriteString(escape(value))
+			sheet.WriteString(`</t></is></c>`)
+		}
+
+		sheet.WriteString(`</row>`)
+	}
+
+	sheet.WriteString(`</sheetData></worksheet>`)
+
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", contentTypesXML},
+		{"_rels/.rels", rootRelationshipsXML},
+		{"xl/workbook.xml", workbookXML},
+		{"xl/_rels/workbook.xml.rels", workbookRelationshipsXML},
+		{"xl/worksheets/sheet1.xml", sheet.String()},
+	}
+
+	for _, file := range files {
+		fileWriter, err := writer.Create(file.name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := fileWriter.Write([]byte(file.content)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func escape(value string) string {
+	buffer := &bytes.Buffer{}
+	xml.EscapeText(buffer, []byte(value))
+
+	return buffer.String()
+}
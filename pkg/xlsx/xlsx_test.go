@@ -0,0 +1,80 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Classeur réduit à une feuille, avec le contenu de sheetData donné
+func workbookWithSheetData(t *testing.T, sheetData string) []byte {
+	buffer := &bytes.Buffer{}
+	writer := zip.NewWriter(buffer)
+
+	fileWriter, err := writer.Create("xl/worksheets/sheet1.xml")
+	require.NoError(t, err)
+
+	_, err = fileWriter.Write([]byte(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` + sheetData + `</sheetData></worksheet>`))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	return buffer.Bytes()
+}
+
+func TestReadRows(t *testing.T) {
+	t.Run("written rows are read back", func(t *testing.T) {
+		data, err := WriteRows("Produits", [][]string{
+			{"externalRef", "name"},
+			{"REF-1", "Galette <complète> & œuf"},
+		})
+		require.NoError(t, err)
+
+		rows, err := ReadRows(data)
+
+		require.NoError(t, err)
+		require.Equal(t, [][]string{
+			{"externalRef", "name"},
+			{"REF-1", "Galette <complète> & œuf"},
+		}, rows)
+	})
+
+	t.Run("missing rows and cells are padded", func(t *testing.T) {
+		rows, err := ReadRows(workbookWithSheetData(t, `<row r="2"><c r="C2"><v>3</v></c></row>`))
+
+		require.NoError(t, err)
+		require.Equal(t, [][]string{{}, {"", "", "3"}}, rows)
+	})
+
+	t.Run("a far away row is refused", func(t *testing.T) {
+		_, err := ReadRows(workbookWithSheetData(t, `<row r="1048576"><c r="A1048576"><v>1</v></c></row>`))
+
+		require.Equal(t, ErrWorkbookTooLarge, err)
+	})
+
+	t.Run("a far away column is refused", func(t *testing.T) {
+		_, err := ReadRows(workbookWithSheetData(t, `<row r="1"><c r="XFD1"><v>1</v></c></row>`))
+
+		require.Equal(t, ErrWorkbookTooLarge, err)
+	})
+
+	t.Run("a column reference too long to fit is refused", func(t *testing.T) {
+		_, err := ReadRows(workbookWithSheetData(t, `<row r="1"><c r="ZZZZZZZZZZZZZZZZZZZZ1"><v>1</v></c></row>`))
+
+		require.Equal(t, ErrWorkbookTooLarge, err)
+	})
+
+	t.Run("a sheet too large once decompressed is refused", func(t *testing.T) {
+		_, err := ReadRows(workbookWithSheetData(t, strings.Repeat(" ", MAX_FILE_SIZE)))
+
+		require.Equal(t, ErrWorkbookTooLarge, err)
+	})
+
+	t.Run("a file that is not a workbook is refused", func(t *testing.T) {
+		_, err := ReadRows([]byte("externalRef;name"))
+
+		require.Equal(t, ErrInvalidWorkbook, err)
+	})
+}
@@ -10,15 +10,28 @@ import (
 	"chemin-du-local.bzh/graphql/graph/model"
 	"chemin-du-local.bzh/graphql/graph/resolvers"
 	"chemin-du-local.bzh/graphql/internal/auth"
+	"chemin-du-local.bzh/graphql/internal/categories"
 	"chemin-du-local.bzh/graphql/internal/commerces"
 	"chemin-du-local.bzh/graphql/internal/config"
 	"chemin-du-local.bzh/graphql/internal/database"
+	"chemin-du-local.bzh/graphql/internal/deliveryzones"
+	"chemin-du-local.bzh/graphql/internal/giftcards"
+	"chemin-du-local.bzh/graphql/internal/markets"
+	"chemin-du-local.bzh/graphql/internal/migrations"
+	"chemin-du-local.bzh/graphql/internal/pickuppoints"
 	"chemin-du-local.bzh/graphql/internal/products"
+	"chemin-du-local.bzh/graphql/internal/promocodes"
+	"chemin-du-local.bzh/graphql/internal/promotions"
 	"chemin-du-local.bzh/graphql/internal/services/commands"
 	"chemin-du-local.bzh/graphql/internal/services/paniers"
+	"chemin-du-local.bzh/graphql/internal/sirene"
+	"chemin-du-local.bzh/graphql/internal/statistics"
+	"chemin-du-local.bzh/graphql/internal/stock"
 	"chemin-du-local.bzh/graphql/internal/users"
 	"chemin-du-local.bzh/graphql/pkg/banking"
 	"chemin-du-local.bzh/graphql/pkg/mapshandler"
+	"chemin-du-local.bzh/graphql/pkg/opendatahandler"
+	"chemin-du-local.bzh/graphql/pkg/sitemap"
 	"chemin-du-local.bzh/graphql/pkg/stripehandler"
 	"github.com/99designs/gqlgen/graphql"
 	"github.com/99designs/gqlgen/graphql/handler"
@@ -46,9 +59,20 @@ func main() {
 	commerceCommandsService := commands.NewCommerceCommandsService(usersService, commercesService, commandsService)
 	ccCommandsService := commands.NewCCCommandsService(productsService)
 	panierCommandsService := commands.NewPanierCommandsService()
+	sireneService := sirene.NewSireneService()
+	statisticsService := statistics.NewStatisticsService(productsService, paniersService)
+	deliveryZonesService := deliveryzones.NewDeliveryZonesService()
+	marketsService := markets.NewMarketsService()
+	pickupPointsService := pickuppoints.NewPickupPointsService()
+	stockService := stock.NewStockService(productsService, commercesService)
+	promotionsService := promotions.NewPromotionsService()
+	promoCodesService := promocodes.NewPromoCodesService()
+	giftCardsService := giftcards.NewGiftCardsService()
+	categoriesService := categories.NewCategoriesService()
 
 	router := chi.NewRouter()
 	router.Use(auth.Middleware(usersService))
+	router.Use(promotions.Middleware(promotionsService))
 	// Add CORS middleware around every request
 	// See https://github.com/rs/cors for full option listing
 	router.Use(cors.New(cors.Options{
@@ -71,6 +95,38 @@ func main() {
 	shouldDropDb := false
 	database.Init(&shouldDropDb)
 
+	// Les commerces créés avant la correction de leur position l'ont
+	// enregistrée à l'envers
+	if err := migrations.Run("commerces-swapped-coordinates", commercesService.FixSwappedCoordinates); err != nil {
+		log.Println(err)
+	}
+
+	// Les adresses enregistrées avant l'ajout de leur position
+	if err := migrations.Run("addresses-location", func() error {
+		if err := commercesService.LocateAddresses(); err != nil {
+			return err
+		}
+
+		return usersService.LocateAddresses()
+	}); err != nil {
+		log.Println(err)
+	}
+
+	// Les commerces créés avant l'ajout des slugs en reçoivent un
+	if err := migrations.Run("commerces-slugs", commercesService.GenerateMissingSlugs); err != nil {
+		log.Println(err)
+	}
+
+	// Les produits créés avant les catégories ne portaient que leurs noms
+	if err := migrations.Run("products-categories", categoriesService.MigrateProductCategories); err != nil {
+		log.Println(err)
+	}
+
+	// Les utilisations des codes promo étaient gardées dans les codes
+	if err := migrations.Run("promocodes-usages", promoCodesService.MigrateUsages); err != nil {
+		log.Println(err)
+	}
+
 	// Directives GraphQL
 	c := generated.Config{Resolvers: &resolvers.Resolver{
 		UsersService:            usersService,
@@ -81,6 +137,16 @@ func main() {
 		CommerceCommandsService: commerceCommandsService,
 		CCCommandsService:       ccCommandsService,
 		PanierCommandsService:   panierCommandsService,
+		SireneService:           sireneService,
+		StatisticsService:       statisticsService,
+		DeliveryZonesService:    deliveryZonesService,
+		MarketsService:          marketsService,
+		PickupPointsService:     pickupPointsService,
+		StockService:            stockService,
+		PromotionsService:       promotionsService,
+		PromoCodesService:       promoCodesService,
+		GiftCardsService:        giftCardsService,
+		CategoriesService:       categoriesService,
 	}}
 	c.Directives.NeedAuthentication = func(ctx context.Context, obj interface{}, next graphql.Resolver) (interface{}, error) {
 		if auth.ForContext(ctx) == nil {
@@ -114,6 +180,8 @@ func main() {
 	cron := cron.New()
 
 	cron.AddFunc("0 0 1 * * *", banking.ExecutreBankingRoutine)
+	cron.AddFunc("0 0 3 * * *", sitemap.ExecuteSitemapRoutine)
+	cron.AddFunc("0 */15 * * * *", stripehandler.ExecuteAbandonedOrdersRoutine)
 	cron.Start()
 
 	srv := handler.NewDefaultServer(generated.NewExecutableSchema(c))
@@ -142,6 +210,13 @@ func main() {
 			commerceCommandsService,
 			ccCommandsService,
 			panierCommandsService,
+			deliveryZonesService,
+			marketsService,
+			pickupPointsService,
+			stockService,
+			promotionsService,
+			promoCodesService,
+			giftCardsService,
 		)
 	})
 	router.HandleFunc("/complete-order", func(w http.ResponseWriter, r *http.Request) {
@@ -150,7 +225,35 @@ func main() {
 			r,
 			usersService,
 			commercesService,
+			commandsService,
 			commerceCommandsService,
+			stockService,
+			promoCodesService,
+			giftCardsService,
+		)
+	})
+	router.HandleFunc("/create-gift-card", func(w http.ResponseWriter, r *http.Request) {
+		stripehandler.HandleCreateGiftCard(
+			w,
+			r,
+			usersService,
+			giftCardsService,
+		)
+	})
+	router.HandleFunc("/complete-gift-card", func(w http.ResponseWriter, r *http.Request) {
+		stripehandler.HandleCompleteGiftCard(
+			w,
+			r,
+			usersService,
+			giftCardsService,
+		)
+	})
+	router.HandleFunc("/opendata/commerces.geojson", func(w http.ResponseWriter, r *http.Request) {
+		opendatahandler.HandleCommercesGeoJSON(
+			w,
+			r,
+			commercesService,
+			productsService,
 		)
 	})
 	router.HandleFunc("/maps/autocomplete", mapshandler.HandleAutocomplete)